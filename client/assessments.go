@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateAssessment records an assessment and returns it as created.
+func (c *Client) CreateAssessment(ctx context.Context, req AssessmentRequest) (*Assessment, error) {
+	var a Assessment
+	if err := c.do(ctx, http.MethodPost, "/assessments", req, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Assessment is assessment id.
+func (c *Client) Assessment(ctx context.Context, id int) (*Assessment, error) {
+	var a Assessment
+	if err := c.do(ctx, http.MethodGet, "/assessments/"+strconv.Itoa(id), nil, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Assessments lists the assessments matching f by id.
+func (c *Client) Assessments(ctx context.Context, f AssessmentFilter) ([]Assessment, error) {
+	q := url.Values{}
+	if f.CycleId != 0 {
+		q.Set("cycle_id", strconv.Itoa(f.CycleId))
+	}
+	if f.UserId != 0 {
+		q.Set("user_id", strconv.Itoa(f.UserId))
+	}
+	if f.Project != "" {
+		q.Set("project", f.Project)
+	}
+	path := "/assessments"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	as := make([]Assessment, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &as); err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// UpdateAssessment replaces the ratings of assessment id and returns it
+// as updated.
+func (c *Client) UpdateAssessment(ctx context.Context, id int, r Ratings) (*Assessment, error) {
+	var a Assessment
+	if err := c.do(ctx, http.MethodPut, "/assessments/"+strconv.Itoa(id), r, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Calibration is the calibration view of project in cycle.
+func (c *Client) Calibration(ctx context.Context, cycle int, project string) (*Calibration, error) {
+	return c.calibration(ctx, http.MethodGet, cycle, project)
+}
+
+// Calibrate locks the assessments of project in cycle and returns its
+// calibration view.
+func (c *Client) Calibrate(ctx context.Context, cycle int, project string) (*Calibration, error) {
+	return c.calibration(ctx, http.MethodPost, cycle, project)
+}
+
+func (c *Client) calibration(ctx context.Context, method string, cycle int, project string) (*Calibration, error) {
+	var cal Calibration
+	path := "/cycles/" + strconv.Itoa(cycle) + "/calibration?" + url.Values{"project": {project}}.Encode()
+	if err := c.do(ctx, method, path, nil, &cal); err != nil {
+		return nil, err
+	}
+	return &cal, nil
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AddCertification records a certification or course of user and returns
+// it as created.
+func (c *Client) AddCertification(ctx context.Context, user int, req CertificationRequest) (*Certification, error) {
+	var cert Certification
+	if err := c.do(ctx, http.MethodPost, "/users/"+strconv.Itoa(user)+"/certifications", req, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// Certification is certification id.
+func (c *Client) Certification(ctx context.Context, id int) (*Certification, error) {
+	var cert Certification
+	if err := c.do(ctx, http.MethodGet, "/certifications/"+strconv.Itoa(id), nil, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// UserCertifications lists the certifications and courses of user, by id.
+func (c *Client) UserCertifications(ctx context.Context, user int) ([]Certification, error) {
+	cs := make([]Certification, 0)
+	if err := c.do(ctx, http.MethodGet, "/users/"+strconv.Itoa(user)+"/certifications", nil, &cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// UpdateCertification replaces certification id and returns it as updated.
+func (c *Client) UpdateCertification(ctx context.Context, id int, req CertificationRequest) (*Certification, error) {
+	var cert Certification
+	if err := c.do(ctx, http.MethodPut, "/certifications/"+strconv.Itoa(id), req, &cert); err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// DeleteCertification deletes certification id.
+func (c *Client) DeleteCertification(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/certifications/"+strconv.Itoa(id), nil, nil)
+}
+
+// ExpiringCertifications lists the certifications expiring within days
+// from today, the server notice when negative.
+func (c *Client) ExpiringCertifications(ctx context.Context, days int) ([]Certification, error) {
+	path := "/certifications/expiring"
+	if days >= 0 {
+		path += "?days=" + strconv.Itoa(days)
+	}
+	cs := make([]Certification, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// CertificationReport tells who on project holds which certification,
+// only certification unless empty.
+func (c *Client) CertificationReport(ctx context.Context, project, certification string) (*CertificationReport, error) {
+	path := "/projects/" + url.PathEscape(project) + "/certifications"
+	if certification != "" {
+		path += "?" + url.Values{"certification": {certification}}.Encode()
+	}
+	var r CertificationReport
+	if err := c.do(ctx, http.MethodGet, path, nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,441 @@
+// Package client is a typed Go client for the Andersen Promo HTTP API.
+//
+// A Client satisfies promo.ContextDBConnexion, so code written against that
+// interface can run on a local store (promo.WithContext) or a remote server.
+package client
+
+import (
+	promo "AndersenPromo/internal"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseGrade accepts either a grade name ("middle") or its numeric value ("3").
+func ParseGrade(s string) (Grade, error) {
+	return promo.ParseGrade(s)
+}
+
+var _ promo.ContextDBConnexion = &Client{}
+
+type Client struct {
+	base    string
+	hc      *http.Client
+	token   string
+	retries int
+	backoff time.Duration
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.hc = hc }
+}
+
+// WithToken sends "Authorization: Bearer <token>" with every request.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRetries sets how many times a request is repeated after a network
+// error or a 429/502/503/504, waiting backoff, 2*backoff, ... between
+// attempts. Writes are sent with an Idempotency-Key, so a repeat never
+// applies them twice, and a DELETE repeated after the first one went
+// through succeeds rather than failing with ErrNotFound.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) { c.retries, c.backoff = retries, backoff }
+}
+
+// New returns a Client for the API served at baseURL, e.g. "http://localhost:8080".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		base:    strings.TrimRight(baseURL, "/"),
+		hc:      http.DefaultClient,
+		retries: 2,
+		backoff: 100 * time.Millisecond,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+func (c *Client) AddUser(ctx context.Context, name string, surname string, position Grade, project string) error {
+	u := User{Name: name, Surname: surname, Position: position, Project: project}
+	return c.do(ctx, http.MethodPost, "/create", u, nil)
+}
+
+// CreateUser adds u and returns its id. The server refuses duplicates with
+// an *Error matching ErrDuplicate; force only allows same names.
+func (c *Client) CreateUser(ctx context.Context, u User, force bool) (int, error) {
+	path := "/create"
+	if force {
+		path += "?force=true"
+	}
+	if err := c.do(ctx, http.MethodPost, path, u, &u); err != nil {
+		return 0, err
+	}
+	return u.Id, nil
+}
+
+// MergeUsers folds user from into user into, see promo.Registry.MergeUsers.
+func (c *Client) MergeUsers(ctx context.Context, into, from int) error {
+	return c.do(ctx, http.MethodPost, "/users/"+strconv.Itoa(into)+"/merge?from="+strconv.Itoa(from), nil, nil)
+}
+
+// Duplicates lists the pairs of users whose names are at most distance
+// edits apart.
+func (c *Client) Duplicates(ctx context.Context, distance int) ([]DuplicatePair, error) {
+	pairs := make([]DuplicatePair, 0)
+	path := "/users/duplicates?distance=" + strconv.Itoa(distance)
+	if err := c.do(ctx, http.MethodGet, path, nil, &pairs); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// Search lists at most limit live users matching q, best first; 0 leaves
+// the limit to the server.
+func (c *Client) Search(ctx context.Context, q string, limit int) ([]SearchResult, error) {
+	v := url.Values{"q": {q}}
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	results := make([]SearchResult, 0)
+	if err := c.do(ctx, http.MethodGet, "/users/search?"+v.Encode(), nil, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Reports lists the users at most depth levels under id, nearest first.
+func (c *Client) Reports(ctx context.Context, id, depth int) ([]Report, error) {
+	reports := make([]Report, 0)
+	path := "/users/" + strconv.Itoa(id) + "/reports?depth=" + strconv.Itoa(depth)
+	if err := c.do(ctx, http.MethodGet, path, nil, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Chain lists the managers of id up to the top, direct manager first.
+func (c *Client) Chain(ctx context.Context, id int) ([]Report, error) {
+	chain := make([]Report, 0)
+	if err := c.do(ctx, http.MethodGet, "/users/"+strconv.Itoa(id)+"/chain", nil, &chain); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// OrgChart returns the hierarchy of the users matching f.
+func (c *Client) OrgChart(ctx context.Context, f UserFilter) ([]OrgNode, error) {
+	path := "/orgchart"
+	if q := filterQuery(f); len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	roots := make([]OrgNode, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &roots); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// RenderOrgChart returns the hierarchy of the users matching f rendered by
+// the server, format being "dot" or "mermaid".
+func (c *Client) RenderOrgChart(ctx context.Context, f UserFilter, format string) ([]byte, error) {
+	q := filterQuery(f)
+	q.Set("format", format)
+	path := "/orgchart?" + q.Encode()
+	resp, err := c.send(ctx, http.MethodGet, path, "", "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, newError(resp, http.MethodGet, path, b)
+	}
+	return b, nil
+}
+
+// Projects lists the projects users can be put on.
+func (c *Client) Projects(ctx context.Context) ([]Project, error) {
+	projects := make([]Project, 0)
+	if err := c.do(ctx, http.MethodGet, "/projects", nil, &projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// CreateProject adds a project users can then be put on.
+func (c *Client) CreateProject(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/projects", Project{Name: name}, nil)
+}
+
+func (c *Client) DeleteUser(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/delete/"+strconv.Itoa(id), nil, nil)
+}
+
+func (c *Client) RestoreUser(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPost, "/users/"+strconv.Itoa(id)+"/restore", nil, nil)
+}
+
+// UpdateUser takes the same column map as promo.DBConnexion: name, surname,
+// position (grade name), project, email, employee_number, hire_date,
+// location and manager_id. Empty optional fields are sent as null, which
+// clears them.
+func (c *Client) UpdateUser(ctx context.Context, id int, m map[string]string) error {
+	body, err := patchBody(m)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPatch, "/update/"+strconv.Itoa(id), body, nil)
+}
+
+// UpdateUserIf is UpdateUser applied only while user id still has etag, as
+// UserTagged told it; a change made meanwhile fails with
+// ErrPreconditionFailed instead of being overwritten.
+func (c *Client) UpdateUserIf(ctx context.Context, id int, etag string, m map[string]string) error {
+	body, err := patchBody(m)
+	if err != nil {
+		return err
+	}
+	_, err = c.exchange(ctx, http.MethodPatch, "/update/"+strconv.Itoa(id), http.Header{"If-Match": {etag}}, body, nil)
+	return err
+}
+
+// patchBody is the body of a PATCH /update/{id} setting the columns of m.
+func patchBody(m map[string]string) (map[string]any, error) {
+	body := make(map[string]any, len(m))
+	for k, v := range m {
+		switch k {
+		case "name", "surname", "project":
+			body[k] = v
+		case "position":
+			g, err := promo.ParseGrade(v)
+			if err != nil {
+				return nil, err
+			}
+			body[k] = g
+		case "email", "employee_number", "hire_date", "location":
+			body[k] = nil
+			if v != "" {
+				body[k] = v
+			}
+		case "manager_id":
+			body[k] = nil
+			if v != "" {
+				id, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("illegal manager_id %q", v)
+				}
+				body[k] = id
+			}
+		default:
+			return nil, fmt.Errorf("illegal key in the map")
+		}
+	}
+	return body, nil
+}
+
+func (c *Client) GetUser(ctx context.Context, id int) (*User, error) {
+	u, _, err := c.UserTagged(ctx, id)
+	return u, err
+}
+
+// UserTagged is GetUser also telling the ETag of the user, for
+// UpdateUserIf.
+func (c *Client) UserTagged(ctx context.Context, id int) (*User, string, error) {
+	u := &User{}
+	h, err := c.exchange(ctx, http.MethodGet, "/get/"+strconv.Itoa(id), nil, nil, u)
+	if err != nil {
+		return nil, "", err
+	}
+	return u, h.Get("ETag"), nil
+}
+
+func filterQuery(f UserFilter) url.Values {
+	q := url.Values{}
+	if f.Project != "" {
+		q.Set("project", f.Project)
+	}
+	if f.Position != 0 {
+		q.Set("grade", f.Position.String())
+	}
+	if f.IncludeDeleted {
+		q.Set("include_deleted", "true")
+	}
+	for k, v := range map[string]string{
+		"email":           f.Email,
+		"employee_number": f.EmployeeNumber,
+		"location":        f.Location,
+		"hired_from":      f.HiredFrom,
+		"hired_to":        f.HiredTo,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if f.ManagerId != 0 {
+		q.Set("manager_id", strconv.Itoa(f.ManagerId))
+	}
+	return q
+}
+
+func (c *Client) GetAllUsers(ctx context.Context, f UserFilter) (*[]User, error) {
+	path := "/getall"
+	if q := filterQuery(f); len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	us := make([]User, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &us); err != nil {
+		return nil, err
+	}
+	return &us, nil
+}
+
+// UserAsOf is user id as it was at t.
+func (c *Client) UserAsOf(ctx context.Context, id int, t time.Time) (*User, error) {
+	u := &User{}
+	q := url.Values{"as_of": {t.Format(time.RFC3339Nano)}}
+	if err := c.do(ctx, http.MethodGet, "/users/"+strconv.Itoa(id)+"?"+q.Encode(), nil, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// UsersAsOf lists the users live at t as they were then, f applying to
+// those past versions. f.IncludeDeleted is refused.
+func (c *Client) UsersAsOf(ctx context.Context, f UserFilter, t time.Time) ([]User, error) {
+	q := filterQuery(f)
+	q.Set("as_of", t.Format(time.RFC3339Nano))
+	us := make([]User, 0)
+	if err := c.do(ctx, http.MethodGet, "/users?"+q.Encode(), nil, &us); err != nil {
+		return nil, err
+	}
+	return us, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in any, out any) error {
+	_, err := c.exchange(ctx, method, path, nil, in, out)
+	return err
+}
+
+// exchange is do sending the headers of h too, telling the headers of the
+// response.
+func (c *Client) exchange(ctx context.Context, method, path string, h http.Header, in any, out any) (http.Header, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+	h = h.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	// Writes carry an idempotency key so that retrying them cannot apply
+	// them twice.
+	if method != http.MethodGet && method != http.MethodHead {
+		h.Set(promo.IdempotencyKeyHeader, newIdempotencyKey())
+	}
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, "application/json", "application/json", body, h)
+		if attempt < c.retries && retryable(resp, err) {
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// A repeated DELETE finding nothing left means an earlier attempt
+		// went through unanswered.
+		if attempt > 0 && method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+			_ = resp.Body.Close()
+			return resp.Header, nil
+		}
+		return resp.Header, decode(resp, method, path, out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path, contentType, accept string, body []byte, h http.Header) (*http.Response, error) {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, rd)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for k, vs := range h {
+		req.Header[k] = vs
+	}
+	return c.hc.Do(req)
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func decode(resp *http.Response, method, path string, out any) error {
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return newError(resp, method, path, b)
+	}
+	if out == nil {
+		return nil
+	}
+	if err = json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("unable to decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,586 @@
+package client
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/assessments"
+	"AndersenPromo/internal/certifications"
+	"AndersenPromo/internal/cycles"
+	"AndersenPromo/internal/eligibility"
+	"AndersenPromo/internal/feedback"
+	"AndersenPromo/internal/goals"
+	"AndersenPromo/internal/mentoring"
+	"AndersenPromo/internal/reports"
+	"AndersenPromo/internal/skills"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	m := promo.NewMemory()
+	require.NoError(t, m.AddProject("Test"))
+	router := mux.NewRouter()
+	promo.NewHandlersWith(m).Register(router)
+	reports.NewHandlers(m).Register(router)
+	eligibility.NewHandlers(m, eligibility.DefaultRules()).Register(router)
+	cycles.NewHandlers(m, eligibility.DefaultRules()).Register(router)
+	assessments.NewHandlers(m, assessments.DefaultScale).Register(router)
+	feedback.NewHandlers(m, assessments.DefaultScale).Register(router)
+	skills.NewHandlers(m, assessments.DefaultScale).Register(router)
+	mentoring.NewHandlers(m, 1).Register(router)
+	goals.NewHandlers(m).Register(router)
+	certifications.NewHandlers(m, certifications.DefaultNotice).Register(router)
+	var h http.Handler = router
+	if wrap != nil {
+		h = wrap(h)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Check CRUD round trip", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		require.NoError(t, c.CreateProject(ctx, "Other"))
+		assert.ErrorIs(t, c.CreateProject(ctx, "Other"), promo.ErrProjectExists)
+		projects, err := c.Projects(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []promo.Project{{Name: "Other"}, {Name: "Test"}}, projects)
+		require.NoError(t, c.AddUser(ctx, "Bob", "Smith", 3, "Other"))
+		us, err := c.GetAllUsers(ctx, UserFilter{Project: "Test"})
+		require.NoError(t, err)
+		assert.Equal(t, []User{{Id: 1, Name: "And", Surname: "Ersen", Position: 2, Project: "Test"}}, *us)
+
+		require.NoError(t, c.UpdateUser(ctx, 1, map[string]string{"position": "senior"}))
+		u, err := c.GetUser(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, Grade(4), u.Position)
+		require.NoError(t, c.UpdateUser(ctx, 1, map[string]string{"email": "and@example.com", "location": "Minsk"}))
+		require.NoError(t, c.UpdateUser(ctx, 1, map[string]string{"email": ""}))
+		u, err = c.GetUser(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, User{Id: 1, Name: "And", Surname: "Ersen", Position: 4, Project: "Test", Location: "Minsk"}, *u)
+
+		require.NoError(t, c.DeleteUser(ctx, 1))
+		us, err = c.GetAllUsers(ctx, UserFilter{})
+		require.NoError(t, err)
+		assert.Len(t, *us, 1)
+	})
+	t.Run("Check problem decoding", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+
+		_, err := c.GetUser(ctx, 42)
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+		var e *Error
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, http.StatusNotFound, e.Status)
+
+		err = c.AddUser(ctx, "A1d", "Ersen", 2, "Test")
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, http.StatusUnprocessableEntity, e.Status)
+		assert.Equal(t, "name: may only hold letters, spaces, hyphens and apostrophes", e.Detail)
+		assert.Equal(t, []promo.FieldError{{Field: "name", Rule: promo.RuleCharset,
+			Detail: "may only hold letters, spaces, hyphens and apostrophes"}}, e.Errors)
+		assert.False(t, errors.Is(err, promo.ErrNotFound))
+	})
+	t.Run("Check problem types", func(t *testing.T) {
+		var typ string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			promo.WriteProblem(w, promo.Problem{Type: typ, Status: http.StatusConflict, Detail: "mentor has no room for another mentee"})
+		}))
+		t.Cleanup(srv.Close)
+		c := New(srv.URL)
+
+		typ = "/problems/cycle-state"
+		_, err := c.AddMentorship(ctx, MentorshipRequest{MentorId: 2, MenteeId: 1})
+		assert.ErrorIs(t, err, ErrCycleState, "the type is mapped, not the path")
+		assert.False(t, errors.Is(err, ErrMentorBusy), "the detail is not mapped")
+
+		typ = ""
+		_, err = c.AddMentorship(ctx, MentorshipRequest{MentorId: 2, MenteeId: 1})
+		var e *Error
+		require.ErrorAs(t, err, &e)
+		assert.Nil(t, errors.Unwrap(err))
+	})
+	t.Run("Check duplicates and merge", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+
+		id, err := c.CreateUser(ctx, User{Name: "Bob", Surname: "Smith", Position: 2, Email: "bob@example.com"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+		_, err = c.CreateUser(ctx, User{Name: "BOB", Surname: "smith", Position: 2}, false)
+		assert.ErrorIs(t, err, promo.ErrDuplicate)
+		var e *Error
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, 1, e.ConflictingId)
+		id, err = c.CreateUser(ctx, User{Name: "Bob", Surname: "Smyth", Position: 2, Project: "Test"}, false)
+		require.NoError(t, err)
+
+		results, err := c.Search(ctx, "Smyht", 0)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, []int{2, 1}, []int{results[0].Id, results[1].Id})
+		assert.Equal(t, "<mark>Smyth</mark>", results[0].Highlights["surname"])
+
+		pairs, err := c.Duplicates(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, pairs, 1)
+		assert.Equal(t, []int{1, 2, 1}, []int{pairs[0].First.Id, pairs[0].Second.Id, pairs[0].Distance})
+
+		require.NoError(t, c.MergeUsers(ctx, 1, id))
+		u, err := c.GetUser(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Test", u.Project)
+		_, err = c.GetUser(ctx, id)
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+	})
+	t.Run("Check org chart", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+
+		for _, u := range []User{
+			{Name: "Ann", Surname: "Boss", Position: 4, Project: "Test"},
+			{Name: "Bob", Surname: "Smith", Position: 3, Project: "Test", ManagerId: 1},
+			{Name: "Cid", Surname: "Jones", Position: 2, Project: "Test", ManagerId: 2},
+		} {
+			_, err := c.CreateUser(ctx, u, false)
+			require.NoError(t, err)
+		}
+		reports, err := c.Reports(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		assert.Equal(t, []int{2, 1}, []int{reports[0].Id, reports[0].Depth})
+		chain, err := c.Chain(ctx, 3)
+		require.NoError(t, err)
+		require.Len(t, chain, 2)
+		assert.Equal(t, []int{2, 1}, []int{chain[0].Id, chain[1].Id})
+		_, err = c.Chain(ctx, 42)
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+
+		roots, err := c.OrgChart(ctx, UserFilter{Project: "Test"})
+		require.NoError(t, err)
+		require.Len(t, roots, 1)
+		assert.Equal(t, 3, roots[0].Reports[0].Reports[0].Id)
+		dot, err := c.RenderOrgChart(ctx, UserFilter{}, "dot")
+		require.NoError(t, err)
+		assert.Contains(t, string(dot), "u2 -> u3;")
+		_, err = c.RenderOrgChart(ctx, UserFilter{}, "svg")
+		assert.Error(t, err)
+	})
+	t.Run("Check reading users as of a time", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		before := time.Now()
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		created := time.Now()
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, c.UpdateUser(ctx, 1, map[string]string{"position": "middle"}))
+
+		u, err := c.UserAsOf(ctx, 1, created)
+		require.NoError(t, err)
+		assert.Equal(t, Grade(2), u.Position)
+		_, err = c.UserAsOf(ctx, 1, before)
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+		us, err := c.UsersAsOf(ctx, UserFilter{Project: "Test"}, created)
+		require.NoError(t, err)
+		require.Len(t, us, 1)
+		us, err = c.UsersAsOf(ctx, UserFilter{}, before)
+		require.NoError(t, err)
+		assert.Empty(t, us)
+	})
+	t.Run("Check eligibility", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		e, err := c.Eligibility(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, e.Eligible)
+		assert.Equal(t, "middle", e.Next)
+		es, err := c.Eligible(ctx, "Test")
+		require.NoError(t, err)
+		assert.Empty(t, es)
+		_, err = c.Eligibility(ctx, 42)
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+	})
+	t.Run("Check review cycles", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		opens := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		cycle, err := c.CreateCycle(ctx, cycles.CycleRequest{Name: "Spring", OpensAt: opens, ClosesAt: opens.AddDate(0, 1, 0),
+			Projects: []string{"Test"}})
+		require.NoError(t, err)
+		assert.Equal(t, promo.CycleScheduled, cycle.Status)
+		require.NoError(t, c.OpenCycle(ctx, cycle.Id))
+		assert.ErrorIs(t, c.OpenCycle(ctx, cycle.Id), promo.ErrCycleState)
+		rs, err := c.Reviews(ctx, cycle.Id)
+		require.NoError(t, err)
+		assert.Empty(t, rs, "no one is eligible yet")
+		assert.ErrorIs(t, c.SetReview(ctx, cycle.Id, 1, promo.ReviewApproved, ""), ErrReviewNotFound)
+		require.NoError(t, c.CloseCycle(ctx, cycle.Id))
+		cycle, err = c.Cycle(ctx, cycle.Id)
+		require.NoError(t, err)
+		assert.Equal(t, promo.CycleClosed, cycle.Status)
+		cs, err := c.Cycles(ctx)
+		require.NoError(t, err)
+		assert.Len(t, cs, 1)
+		_, err = c.Cycle(ctx, 42)
+		assert.ErrorIs(t, err, ErrCycleNotFound)
+	})
+	t.Run("Check assessments", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		opens := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		cycle, err := c.CreateCycle(ctx, cycles.CycleRequest{Name: "Spring", OpensAt: opens, ClosesAt: opens.AddDate(0, 1, 0),
+			Projects: []string{"Test"}})
+		require.NoError(t, err)
+		a, err := c.CreateAssessment(ctx, assessments.AssessmentRequest{CycleId: cycle.Id, UserId: 1,
+			Ratings: assessments.Ratings{Rating: 4, Scores: map[string]int{"coding": 5}}})
+		require.NoError(t, err)
+		assert.Equal(t, "Test", a.Project)
+		a, err = c.UpdateAssessment(ctx, a.Id, assessments.Ratings{Rating: 3, Summary: "fair"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, a.Rating)
+		cal, err := c.Calibrate(ctx, cycle.Id, "Test")
+		require.NoError(t, err)
+		assert.True(t, cal.Locked)
+		require.Len(t, cal.Grades, 1)
+		assert.Equal(t, 3.0, cal.Grades[0].Mean)
+		_, err = c.Calibrate(ctx, cycle.Id, "Test")
+		assert.ErrorIs(t, err, promo.ErrAssessmentLocked)
+		_, err = c.UpdateAssessment(ctx, a.Id, assessments.Ratings{Rating: 5})
+		assert.ErrorIs(t, err, promo.ErrAssessmentLocked)
+		cal, err = c.Calibration(ctx, cycle.Id, "Test")
+		require.NoError(t, err)
+		assert.True(t, cal.Locked)
+		list, err := c.Assessments(ctx, promo.AssessmentFilter{UserId: 1})
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.True(t, list[0].Locked)
+		_, err = c.Assessment(ctx, 42)
+		assert.ErrorIs(t, err, ErrAssessmentNotFound)
+	})
+	t.Run("Check feedback", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		for _, u := range []User{
+			{Name: "Ann", Surname: "Boss", Position: 4, Project: "Test"},
+			{Name: "Bob", Surname: "Smith", Position: 3, Project: "Test", ManagerId: 1},
+			{Name: "Cid", Surname: "Jones", Position: 3, Project: "Test", ManagerId: 1},
+		} {
+			_, err := c.CreateUser(ctx, u, false)
+			require.NoError(t, err)
+		}
+		due := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+		created, err := c.AskFeedback(ctx, feedback.FeedbackRequest{UserId: 2, DueAt: due, Respondents: []int{1, 3}})
+		require.NoError(t, err)
+		assert.Equal(t, due, created.DueAt)
+		require.Len(t, created.RespondentKeys, 2)
+		peer := created.RespondentKeys[1]
+		require.Equal(t, 3, peer.UserId)
+
+		_, err = c.Respond(ctx, created.Id, peer.Key, promo.FeedbackResponse{Rating: 4, Strengths: "care", Anonymous: true})
+		require.NoError(t, err)
+		r, err := c.FeedbackResponse(ctx, created.Id, peer.Key)
+		require.NoError(t, err)
+		assert.Equal(t, "care", r.Strengths)
+		_, err = c.FeedbackSummary(ctx, created.Id, peer.Key)
+		assert.ErrorIs(t, err, promo.ErrFeedbackKey)
+		s, err := c.FeedbackSummary(ctx, created.Id, created.ManagerKey)
+		require.NoError(t, err)
+		assert.Equal(t, 1, s.Answered)
+		assert.Equal(t, []feedback.Comment{{Strengths: "care"}}, s.Comments)
+
+		fs, err := c.Feedbacks(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, fs, 1)
+		f, err := c.Feedback(ctx, created.Id)
+		require.NoError(t, err)
+		assert.True(t, f.Respondents[1].Answered)
+		_, err = c.Feedback(ctx, 42)
+		assert.ErrorIs(t, err, ErrFeedbackNotFound)
+	})
+	t.Run("Check skills", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		comp, err := c.CreateCompetency(ctx, skills.CompetencyRequest{Name: "design",
+			Levels: []promo.Expectation{{Grade: 3, Level: 3}}})
+		require.NoError(t, err)
+		_, err = c.CreateCompetency(ctx, skills.CompetencyRequest{Name: "design"})
+		assert.ErrorIs(t, err, promo.ErrCompetencyExists)
+		s, err := c.SetSkill(ctx, 1, comp.Id, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "design", s.Competency)
+		r, err := c.UserSkills(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, r.Skills, 1)
+		assert.Equal(t, 1, r.Skills[0].Gap)
+		cov, err := c.SkillsCoverage(ctx, "Test")
+		require.NoError(t, err)
+		assert.Equal(t, 1, cov.Users)
+		_, err = c.SkillsCoverage(ctx, "Omega")
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+
+		comp, err = c.UpdateCompetency(ctx, comp.Id, skills.CompetencyRequest{Name: "system design"})
+		require.NoError(t, err)
+		assert.Empty(t, comp.Levels)
+		require.NoError(t, c.DeleteCompetency(ctx, comp.Id))
+		_, err = c.Competency(ctx, comp.Id)
+		assert.ErrorIs(t, err, ErrCompetencyNotFound)
+		cs, err := c.Competencies(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, cs)
+	})
+	t.Run("Check mentorships", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		require.NoError(t, c.AddUser(ctx, "Ann", "Boss", 4, "Test"))
+		require.NoError(t, c.AddUser(ctx, "Bob", "Smith", 2, "Test"))
+		list, err := c.SuggestMentors(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, 2, list[0].UserId)
+		m, err := c.AddMentorship(ctx, mentoring.MentorshipRequest{MentorId: 2, MenteeId: 1, Goals: "design"})
+		require.NoError(t, err)
+		assert.Equal(t, "design", m.Goals)
+		_, err = c.AddMentorship(ctx, mentoring.MentorshipRequest{MentorId: 2, MenteeId: 3})
+		assert.ErrorIs(t, err, ErrMentorBusy)
+		ms, err := c.Mentorships(ctx, 2, 0, true)
+		require.NoError(t, err)
+		assert.Len(t, ms, 1)
+		m, err = c.EndMentorship(ctx, m.Id)
+		require.NoError(t, err)
+		assert.NotNil(t, m.EndsAt)
+		_, err = c.EndMentorship(ctx, m.Id)
+		assert.ErrorIs(t, err, promo.ErrMentorshipState)
+		_, err = c.Mentorship(ctx, 42)
+		assert.ErrorIs(t, err, ErrMentorshipNotFound)
+	})
+	t.Run("Check goals", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		g, err := c.AddGoal(ctx, 1, goals.GoalRequest{Title: "Lead a feature", DueDate: "2020-01-01"})
+		require.NoError(t, err)
+		assert.Equal(t, promo.Grade(3), g.TargetGrade)
+		_, err = c.AddGoal(ctx, 42, goals.GoalRequest{Title: "Lost"})
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+		overdue, err := c.OverdueGoals(ctx, "Test")
+		require.NoError(t, err)
+		assert.Len(t, overdue, 1)
+		e, err := c.Eligibility(ctx, 1)
+		require.NoError(t, err)
+		require.NotNil(t, e.Goals)
+		assert.Equal(t, 1, e.Goals.Overdue)
+
+		g, err = c.AddGoalNote(ctx, g.Id, "design reviewed")
+		require.NoError(t, err)
+		assert.Len(t, g.Notes, 1)
+		g, err = c.UpdateGoal(ctx, g.Id, goals.GoalRequest{Title: "Lead a feature", Status: promo.GoalDone})
+		require.NoError(t, err)
+		assert.Equal(t, promo.GoalDone, g.Status)
+		g, err = c.Goal(ctx, g.Id)
+		require.NoError(t, err)
+		assert.Len(t, g.Notes, 1)
+		gs, err := c.UserGoals(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, gs, 1)
+		_, err = c.Goal(ctx, 42)
+		assert.ErrorIs(t, err, ErrGoalNotFound)
+	})
+	t.Run("Check certifications", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		cert, err := c.AddCertification(ctx, 1, certifications.CertificationRequest{Name: "CKA", Issuer: "CNCF",
+			IssuedOn: "2020-01-01", ExpiresOn: "2999-01-01", Evidence: "files/cka.pdf"})
+		require.NoError(t, err)
+		assert.Equal(t, promo.KindCertification, cert.Kind)
+		_, err = c.AddCertification(ctx, 42, certifications.CertificationRequest{Name: "CKA", Issuer: "CNCF", IssuedOn: "2020-01-01"})
+		assert.ErrorIs(t, err, promo.ErrNotFound)
+		r, err := c.CertificationReport(ctx, "Test", "CKA")
+		require.NoError(t, err)
+		require.Len(t, r.Certifications, 1)
+		assert.Equal(t, 1, r.Certifications[0].Holders[0].UserId)
+		expiring, err := c.ExpiringCertifications(ctx, -1)
+		require.NoError(t, err)
+		assert.Empty(t, expiring)
+
+		cert, err = c.UpdateCertification(ctx, cert.Id, certifications.CertificationRequest{Kind: promo.KindCourse, Name: "CKA prep",
+			Issuer: "CNCF", IssuedOn: "2020-01-01"})
+		require.NoError(t, err)
+		assert.Empty(t, cert.ExpiresOn)
+		cs, err := c.UserCertifications(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, cs, 1)
+		require.NoError(t, c.DeleteCertification(ctx, cert.Id))
+		_, err = c.Certification(ctx, cert.Id)
+		assert.ErrorIs(t, err, ErrCertificationNotFound)
+	})
+	t.Run("Check reports", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		for _, u := range []User{
+			{Name: "Ann", Surname: "Boss", Position: 4, Project: "Test", HireDate: "2025-01-10"},
+			{Name: "Bob", Surname: "Smith", Position: 2, Project: "Test", HireDate: "2025-02-10"},
+		} {
+			_, err := c.CreateUser(ctx, u, false)
+			require.NoError(t, err)
+		}
+		ds, err := c.GradeDistribution(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, ds, 1)
+		assert.Equal(t, 2, ds[0].Total)
+		p, err := c.Pyramid(ctx, "Test")
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, p.Ratios["senior/junior"])
+		hs, err := c.Headcount(ctx, "Test", "2025-01", "2025-02")
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, []int{hs[0].Total, hs[1].Total})
+		_, err = c.Headcount(ctx, "", "2025", "")
+		var e *Error
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, http.StatusBadRequest, e.Status)
+	})
+	t.Run("Check bulk import", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+
+		require.NoError(t, c.AddUsers(ctx, []User{
+			{Name: "And", Surname: "Ersen", Position: 2, Project: "Test"},
+			{Name: "Bob", Surname: "Smith", Position: 3},
+		}))
+		us, err := c.GetAllUsers(ctx, UserFilter{})
+		require.NoError(t, err)
+		assert.Len(t, *us, 2)
+
+		csv := strings.NewReader("Vorname,Nachname,Stufe\nA1d,Ersen,middle\nBob,Smith,senior\n")
+		rep, err := c.ImportUsers(ctx, csv, "text/csv", ImportOptions{
+			DryRun:  true,
+			Mapping: map[string]string{"name": "Vorname", "surname": "Nachname", "position": "Stufe"},
+		})
+		var e *Error
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, http.StatusUnprocessableEntity, e.Status)
+		require.NotNil(t, rep)
+		require.Len(t, rep.Errors, 2)
+		assert.Equal(t, 2, rep.Errors[0].Row)
+		assert.Equal(t, []promo.FieldError{{Field: "name", Rule: promo.RuleCharset,
+			Detail: "may only hold letters, spaces, hyphens and apostrophes"}}, rep.Errors[0].Fields)
+		assert.Equal(t, 3, rep.Errors[1].Row)
+		assert.Equal(t, []FieldError{{Field: "name", Rule: RuleDuplicate, Detail: "is the name of user 2"}},
+			rep.Errors[1].Fields)
+	})
+	t.Run("Check export", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		require.NoError(t, c.CreateProject(ctx, "Other"))
+		require.NoError(t, c.AddUser(ctx, "Bob", "Smith", 3, "Other"))
+
+		var buf bytes.Buffer
+		require.NoError(t, c.ExportUsers(ctx, "csv", UserFilter{Position: 3}, &buf))
+		assert.Equal(t, "id,name,surname,position,project,email,employee_number,hire_date,location,manager_id\n2,Bob,Smith,middle,Other,,,,,\n", buf.String())
+
+		var names []string
+		require.NoError(t, c.EachUser(ctx, UserFilter{}, func(u User) error {
+			names = append(names, u.Name)
+			return nil
+		}))
+		assert.Equal(t, []string{"And", "Bob"}, names)
+
+		err := c.ExportUsers(ctx, "pdf", UserFilter{}, &buf)
+		var e *Error
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, http.StatusBadRequest, e.Status)
+	})
+	t.Run("Check auth header injection", func(t *testing.T) {
+		var got string
+		srv := newServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				next.ServeHTTP(w, r)
+			})
+		})
+		_, err := New(srv.URL, WithToken("secret")).GetAllUsers(ctx, UserFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer secret", got)
+	})
+	t.Run("Check retries", func(t *testing.T) {
+		var calls int
+		srv := newServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+		c := New(srv.URL, WithRetries(2, time.Millisecond))
+		_, err := c.GetAllUsers(ctx, UserFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+
+	})
+	t.Run("Check retried writes are applied once", func(t *testing.T) {
+		var keys []string
+		srv := newServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				keys = append(keys, r.Header.Get(promo.IdempotencyKeyHeader))
+				if len(keys) == 1 {
+					// Served, but the response is lost on the way back.
+					next.ServeHTTP(httptest.NewRecorder(), r)
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+		c := New(srv.URL, WithRetries(2, time.Millisecond))
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+		us, err := c.GetAllUsers(ctx, UserFilter{})
+		require.NoError(t, err)
+		assert.Len(t, *us, 1)
+	})
+	t.Run("Check retried delete applied before succeeds", func(t *testing.T) {
+		var keys []string
+		srv := newServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete {
+					next.ServeHTTP(w, r)
+					return
+				}
+				keys = append(keys, r.Header.Get(promo.IdempotencyKeyHeader))
+				if len(keys) == 1 {
+					next.ServeHTTP(httptest.NewRecorder(), r)
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		})
+		c := New(srv.URL, WithRetries(2, time.Millisecond))
+		require.NoError(t, c.AddUser(ctx, "And", "Ersen", 2, "Test"))
+		require.NoError(t, c.DeleteUser(ctx, 1))
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+		assert.ErrorIs(t, c.DeleteUser(ctx, 1), ErrNotFound, "not retried")
+	})
+	t.Run("Check cancelled context", func(t *testing.T) {
+		c := New(newServer(t, nil).URL)
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := c.GetAllUsers(cctx, UserFilter{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateCycle schedules a review cycle and returns it as created.
+func (c *Client) CreateCycle(ctx context.Context, req CycleRequest) (*Cycle, error) {
+	var out Cycle
+	if err := c.do(ctx, http.MethodPost, "/cycles", req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Cycles lists the review cycles by id.
+func (c *Client) Cycles(ctx context.Context) ([]Cycle, error) {
+	cs := make([]Cycle, 0)
+	if err := c.do(ctx, http.MethodGet, "/cycles", nil, &cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// Cycle is review cycle id.
+func (c *Client) Cycle(ctx context.Context, id int) (*Cycle, error) {
+	var out Cycle
+	if err := c.do(ctx, http.MethodGet, "/cycles/"+strconv.Itoa(id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OpenCycle opens scheduled cycle id ahead of time.
+func (c *Client) OpenCycle(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPost, "/cycles/"+strconv.Itoa(id)+"/open", nil, nil)
+}
+
+// CloseCycle closes open cycle id ahead of time, applying its approved
+// promotions.
+func (c *Client) CloseCycle(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPost, "/cycles/"+strconv.Itoa(id)+"/close", nil, nil)
+}
+
+// Reviews lists the reviews of cycle id by user.
+func (c *Client) Reviews(ctx context.Context, id int) ([]Review, error) {
+	rs := make([]Review, 0)
+	if err := c.do(ctx, http.MethodGet, "/cycles/"+strconv.Itoa(id)+"/reviews", nil, &rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// SetReview sets the status and comment of the review of user in open cycle
+// id.
+func (c *Client) SetReview(ctx context.Context, id, user int, status ReviewStatus, comment string) error {
+	path := "/cycles/" + strconv.Itoa(id) + "/reviews/" + strconv.Itoa(user)
+	return c.do(ctx, http.MethodPatch, path, ReviewRequest{Status: status, Comment: comment}, nil)
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Eligibility tells whether user id meets the promotion rule of their grade.
+func (c *Client) Eligibility(ctx context.Context, id int) (*Eligibility, error) {
+	var e Eligibility
+	if err := c.do(ctx, http.MethodGet, "/users/"+strconv.Itoa(id)+"/eligibility", nil, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Eligible lists the users of project, or of every project when empty,
+// currently eligible for promotion.
+func (c *Client) Eligible(ctx context.Context, project string) ([]Eligibility, error) {
+	path := "/eligibility"
+	if project != "" {
+		path += "?" + url.Values{"project": {project}}.Encode()
+	}
+	es := make([]Eligibility, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &es); err != nil {
+		return nil, err
+	}
+	return es, nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	promo "AndersenPromo/internal"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Error is a non-2xx answer of the API. It matches with errors.Is the
+// sentinel its Problem type names, such as ErrNotFound, ErrDuplicate or
+// ErrCycleState, the same the local stores return; ConflictingId tells
+// which user is duplicated.
+type Error struct {
+	Method string
+	Path   string
+	promo.Problem
+}
+
+func newError(resp *http.Response, method, path string, body []byte) *Error {
+	e := &Error{Method: method, Path: path}
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mt != promo.ProblemContentType || json.Unmarshal(body, &e.Problem) != nil {
+		e.Problem = promo.Problem{Detail: strings.TrimSpace(string(body))}
+	}
+	e.Status = resp.StatusCode
+	if e.Title == "" {
+		e.Title = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.Status, e.Title)
+	}
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.Status, e.Title, e.Detail)
+}
+
+func (e *Error) Unwrap() error {
+	return promo.ProblemError(e.Type)
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ExportUsers streams the users matching f to w as "csv", "xlsx" or "ndjson".
+func (c *Client) ExportUsers(ctx context.Context, format string, f UserFilter, w io.Writer) error {
+	body, path, err := c.export(ctx, format, f)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if _, err = io.Copy(w, body); err != nil {
+		return fmt.Errorf("unable to read response of %s %s: %w", http.MethodGet, path, err)
+	}
+	return nil
+}
+
+// EachUser reads the NDJSON export and hands the users to fn one by one,
+// stopping at the first error fn returns.
+func (c *Client) EachUser(ctx context.Context, f UserFilter, fn func(User) error) error {
+	body, path, err := c.export(ctx, "ndjson", f)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	dec := json.NewDecoder(body)
+	for {
+		var u User
+		err = dec.Decode(&u)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to decode response of %s %s: %w", http.MethodGet, path, err)
+		}
+		if err = fn(u); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) export(ctx context.Context, format string, f UserFilter) (io.ReadCloser, string, error) {
+	q := filterQuery(f)
+	q.Set("format", format)
+	path := "/users/export?" + q.Encode()
+	resp, err := c.send(ctx, http.MethodGet, path, "", "", nil, nil)
+	if err != nil {
+		return nil, path, err
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, path, decode(resp, http.MethodGet, path, nil)
+	}
+	return resp.Body, path, nil
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// AskFeedback asks for feedback and returns it with the keys to it.
+func (c *Client) AskFeedback(ctx context.Context, req FeedbackRequest) (*FeedbackCreated, error) {
+	var created FeedbackCreated
+	if err := c.do(ctx, http.MethodPost, "/feedback", req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Feedback is feedback id, who is asked and who answered.
+func (c *Client) Feedback(ctx context.Context, id int) (*Feedback, error) {
+	var f Feedback
+	if err := c.do(ctx, http.MethodGet, "/feedback/"+strconv.Itoa(id), nil, &f); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Feedbacks lists the feedback on user, or on everyone when 0, by id.
+func (c *Client) Feedbacks(ctx context.Context, user int) ([]Feedback, error) {
+	path := "/feedback"
+	if user != 0 {
+		path += "?user_id=" + strconv.Itoa(user)
+	}
+	fs := make([]Feedback, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// withKey is c sending key instead of its token.
+func (c *Client) withKey(key string) *Client {
+	k := *c
+	k.token = key
+	return &k
+}
+
+// FeedbackResponse is the response to feedback id of the respondent
+// holding key.
+func (c *Client) FeedbackResponse(ctx context.Context, id int, key string) (*FeedbackResponse, error) {
+	var r FeedbackResponse
+	if err := c.withKey(key).do(ctx, http.MethodGet, "/feedback/"+strconv.Itoa(id)+"/response", nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Respond records the response to feedback id of the respondent holding
+// key and returns it as recorded.
+func (c *Client) Respond(ctx context.Context, id int, key string, r FeedbackResponse) (*FeedbackResponse, error) {
+	var out FeedbackResponse
+	if err := c.withKey(key).do(ctx, http.MethodPut, "/feedback/"+strconv.Itoa(id)+"/response", r, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FeedbackSummary sums up the answers to feedback id for the manager
+// holding key.
+func (c *Client) FeedbackSummary(ctx context.Context, id int, key string) (*FeedbackSummary, error) {
+	var s FeedbackSummary
+	if err := c.withKey(key).do(ctx, http.MethodGet, "/feedback/"+strconv.Itoa(id)+"/summary", nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
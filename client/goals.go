@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AddGoal adds a goal to the development plan of user and returns it as
+// created.
+func (c *Client) AddGoal(ctx context.Context, user int, req GoalRequest) (*Goal, error) {
+	var g Goal
+	if err := c.do(ctx, http.MethodPost, "/users/"+strconv.Itoa(user)+"/goals", req, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// Goal is goal id with its notes.
+func (c *Client) Goal(ctx context.Context, id int) (*Goal, error) {
+	var g Goal
+	if err := c.do(ctx, http.MethodGet, "/goals/"+strconv.Itoa(id), nil, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// UserGoals is the development plan of user, by id.
+func (c *Client) UserGoals(ctx context.Context, user int) ([]Goal, error) {
+	gs := make([]Goal, 0)
+	if err := c.do(ctx, http.MethodGet, "/users/"+strconv.Itoa(user)+"/goals", nil, &gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+// UpdateGoal replaces goal id and returns it as updated.
+func (c *Client) UpdateGoal(ctx context.Context, id int, req GoalRequest) (*Goal, error) {
+	var g Goal
+	if err := c.do(ctx, http.MethodPut, "/goals/"+strconv.Itoa(id), req, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// AddGoalNote notes the progress of goal id and returns it with the note.
+func (c *Client) AddGoalNote(ctx context.Context, id int, note string) (*Goal, error) {
+	var g Goal
+	if err := c.do(ctx, http.MethodPost, "/goals/"+strconv.Itoa(id)+"/notes", NoteRequest{Note: note}, &g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// OverdueGoals lists the goals of the users of project past their due
+// date, by id.
+func (c *Client) OverdueGoals(ctx context.Context, project string) ([]Goal, error) {
+	gs := make([]Goal, 0)
+	if err := c.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(project)+"/overdue-goals", nil, &gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
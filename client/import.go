@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type ImportOptions struct {
+	// DryRun only validates the document.
+	DryRun bool
+	// Force imports rows even if a user or an earlier row has the same
+	// name. Same emails or employee numbers are always refused.
+	Force bool
+	// Mapping maps User fields (name, surname, position, project, email,
+	// employee_number, hire_date, location, manager_id) to the document
+	// headers, when these differ from the defaults.
+	Mapping map[string]string
+}
+
+// AddUsers imports us in one transaction through the import endpoint. Like
+// the local stores, it accepts users with the same name as others.
+func (c *Client) AddUsers(ctx context.Context, us []User) error {
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	_ = cw.Write([]string{"name", "surname", "position", "project", "email", "employee_number", "hire_date",
+		"location", "manager_id"})
+	for _, u := range us {
+		manager := ""
+		if u.ManagerId != 0 {
+			manager = strconv.Itoa(u.ManagerId)
+		}
+		_ = cw.Write([]string{u.Name, u.Surname, u.Position.String(), u.Project, u.Email, u.EmployeeNumber,
+			u.HireDate, u.Location, manager})
+	}
+	cw.Flush()
+	_, err := c.ImportUsers(ctx, &buf, "text/csv", ImportOptions{Force: true})
+	return err
+}
+
+// ImportUsers uploads a CSV ("text/csv") or XLSX document. When rows fail
+// validation the report listing them comes back along with the error.
+func (c *Client) ImportUsers(ctx context.Context, r io.Reader, contentType string, opts ImportOptions) (*ImportReport, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if opts.DryRun {
+		q.Set("dry_run", "true")
+	}
+	if opts.Force {
+		q.Set("force", "true")
+	}
+	for field, header := range opts.Mapping {
+		q.Add("map", field+":"+header)
+	}
+	path := "/users/import"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	resp, err := c.send(ctx, http.MethodPost, path, contentType, "application/json", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		rep := &ImportReport{}
+		if err = decode(resp, http.MethodPost, path, rep); err != nil {
+			return nil, err
+		}
+		return rep, nil
+	}
+	defer resp.Body.Close()
+	rep := &ImportReport{}
+	if err = json.NewDecoder(resp.Body).Decode(rep); err != nil {
+		return nil, fmt.Errorf("unable to decode response of %s %s: %w", http.MethodPost, path, err)
+	}
+	e := &Error{Method: http.MethodPost, Path: path}
+	e.Status = resp.StatusCode
+	e.Title = http.StatusText(resp.StatusCode)
+	e.Detail = fmt.Sprintf("%d of %d rows failed validation", len(rep.Errors), rep.Rows)
+	return rep, e
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AddMentorship pairs a mentee with a mentor and returns the mentorship as
+// created.
+func (c *Client) AddMentorship(ctx context.Context, req MentorshipRequest) (*Mentorship, error) {
+	var m Mentorship
+	if err := c.do(ctx, http.MethodPost, "/mentorships", req, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Mentorship is mentorship id.
+func (c *Client) Mentorship(ctx context.Context, id int) (*Mentorship, error) {
+	var m Mentorship
+	if err := c.do(ctx, http.MethodGet, "/mentorships/"+strconv.Itoa(id), nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Mentorships lists the mentorships of mentor and mentee, unless 0, by id,
+// only those running now when active.
+func (c *Client) Mentorships(ctx context.Context, mentor, mentee int, active bool) ([]Mentorship, error) {
+	q := url.Values{}
+	if mentor != 0 {
+		q.Set("mentor_id", strconv.Itoa(mentor))
+	}
+	if mentee != 0 {
+		q.Set("mentee_id", strconv.Itoa(mentee))
+	}
+	if active {
+		q.Set("active", "true")
+	}
+	path := "/mentorships"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	ms := make([]Mentorship, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &ms); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+// EndMentorship ends mentorship id now and returns it as ended.
+func (c *Client) EndMentorship(ctx context.Context, id int) (*Mentorship, error) {
+	var m Mentorship
+	if err := c.do(ctx, http.MethodPost, "/mentorships/"+strconv.Itoa(id)+"/end", nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SuggestMentors lists up to limit mentors for user, the server default
+// when 0, the best first.
+func (c *Client) SuggestMentors(ctx context.Context, user, limit int) ([]MentorSuggestion, error) {
+	path := "/users/" + strconv.Itoa(user) + "/mentor-suggestions"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	list := make([]MentorSuggestion, 0)
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GradeDistribution is the current headcount per grade of every project, or
+// of project.
+func (c *Client) GradeDistribution(ctx context.Context, project string) ([]Distribution, error) {
+	ds := make([]Distribution, 0)
+	if err := c.do(ctx, http.MethodGet, "/reports/grade-distribution"+reportQuery(project, "", ""), nil, &ds); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// Pyramid is the current grade pyramid of project, or of everyone.
+func (c *Client) Pyramid(ctx context.Context, project string) (*Pyramid, error) {
+	var p Pyramid
+	if err := c.do(ctx, http.MethodGet, "/reports/pyramid"+reportQuery(project, "", ""), nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Headcount is the headcount per grade at the end of every month from from
+// to to, written as MonthLayout; empty ones leave them to the
+// server (the last twelve months).
+func (c *Client) Headcount(ctx context.Context, project, from, to string) ([]Headcount, error) {
+	hs := make([]Headcount, 0)
+	if err := c.do(ctx, http.MethodGet, "/reports/headcount"+reportQuery(project, from, to), nil, &hs); err != nil {
+		return nil, err
+	}
+	return hs, nil
+}
+
+func reportQuery(project, from, to string) string {
+	q := url.Values{}
+	for k, v := range map[string]string{"project": project, "from": from, "to": to} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateCompetency adds a competency to the framework and returns it as
+// created.
+func (c *Client) CreateCompetency(ctx context.Context, req CompetencyRequest) (*Competency, error) {
+	var comp Competency
+	if err := c.do(ctx, http.MethodPost, "/competencies", req, &comp); err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+// Competency is competency id.
+func (c *Client) Competency(ctx context.Context, id int) (*Competency, error) {
+	var comp Competency
+	if err := c.do(ctx, http.MethodGet, "/competencies/"+strconv.Itoa(id), nil, &comp); err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+// Competencies lists the framework by name.
+func (c *Client) Competencies(ctx context.Context) ([]Competency, error) {
+	cs := make([]Competency, 0)
+	if err := c.do(ctx, http.MethodGet, "/competencies", nil, &cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// UpdateCompetency replaces competency id and returns it as updated.
+func (c *Client) UpdateCompetency(ctx context.Context, id int, req CompetencyRequest) (*Competency, error) {
+	var comp Competency
+	if err := c.do(ctx, http.MethodPut, "/competencies/"+strconv.Itoa(id), req, &comp); err != nil {
+		return nil, err
+	}
+	return &comp, nil
+}
+
+// DeleteCompetency removes competency id with the skills in it.
+func (c *Client) DeleteCompetency(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/competencies/"+strconv.Itoa(id), nil, nil)
+}
+
+// SetSkill records the level of user in competency.
+func (c *Client) SetSkill(ctx context.Context, user, competency, level int) (*Skill, error) {
+	var s Skill
+	path := "/users/" + strconv.Itoa(user) + "/skills/" + strconv.Itoa(competency)
+	if err := c.do(ctx, http.MethodPut, path, SkillRequest{Level: level}, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UserSkills is the gap report of user to the next grade.
+func (c *Client) UserSkills(ctx context.Context, user int) (*UserSkills, error) {
+	var r UserSkills
+	if err := c.do(ctx, http.MethodGet, "/users/"+strconv.Itoa(user)+"/skills", nil, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// SkillsCoverage is how the users of project cover the framework.
+func (c *Client) SkillsCoverage(ctx context.Context, project string) (*SkillCoverage, error) {
+	var cov SkillCoverage
+	if err := c.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(project)+"/skills-coverage", nil, &cov); err != nil {
+		return nil, err
+	}
+	return &cov, nil
+}
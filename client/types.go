@@ -0,0 +1,169 @@
+package client
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/assessments"
+	"AndersenPromo/internal/certifications"
+	"AndersenPromo/internal/cycles"
+	"AndersenPromo/internal/eligibility"
+	"AndersenPromo/internal/feedback"
+	"AndersenPromo/internal/goals"
+	"AndersenPromo/internal/mentoring"
+	"AndersenPromo/internal/reports"
+	"AndersenPromo/internal/skills"
+)
+
+// The API types, re-exported so callers don't need the internal packages.
+type (
+	User             = promo.User
+	Grade            = promo.Grade
+	UserFilter       = promo.UserFilter
+	Problem          = promo.Problem
+	FieldError       = promo.FieldError
+	ValidationErrors = promo.ValidationErrors
+
+	Project       = promo.Project
+	Report        = promo.Report
+	OrgNode       = promo.OrgNode
+	SearchResult  = promo.SearchResult
+	DuplicatePair = promo.DuplicatePair
+	ImportReport  = promo.ImportReport
+	RowError      = promo.RowError
+
+	Cycle         = promo.Cycle
+	CycleStatus   = promo.CycleStatus
+	Review        = promo.Review
+	ReviewStatus  = promo.ReviewStatus
+	CycleRequest  = cycles.CycleRequest
+	ReviewRequest = cycles.ReviewRequest
+
+	Assessment        = promo.Assessment
+	AssessmentFilter  = promo.AssessmentFilter
+	AssessmentRequest = assessments.AssessmentRequest
+	Ratings           = assessments.Ratings
+	Calibration       = assessments.Calibration
+	GradeRatings      = assessments.GradeRatings
+	RatingCount       = assessments.RatingCount
+
+	Feedback         = promo.Feedback
+	FeedbackResponse = promo.FeedbackResponse
+	FeedbackAnswer   = promo.FeedbackAnswer
+	Respondent       = promo.Respondent
+	Relation         = promo.Relation
+	FeedbackRequest  = feedback.FeedbackRequest
+	FeedbackCreated  = feedback.Created
+	FeedbackSummary  = feedback.Summary
+	FeedbackComment  = feedback.Comment
+	RelationSummary  = feedback.RelationSummary
+	RespondentKey    = feedback.RespondentKey
+
+	Competency         = promo.Competency
+	Skill              = promo.Skill
+	Expectation        = promo.Expectation
+	CompetencyRequest  = skills.CompetencyRequest
+	SkillRequest       = skills.SkillRequest
+	UserSkills         = skills.UserSkills
+	SkillCoverage      = skills.Coverage
+	SkillGap           = skills.Gap
+	CompetencyCoverage = skills.CompetencyCoverage
+
+	Mentorship        = promo.Mentorship
+	MentorshipRequest = mentoring.MentorshipRequest
+	MentorSuggestion  = mentoring.Suggestion
+
+	Goal        = promo.Goal
+	GoalStatus  = promo.GoalStatus
+	GoalNote    = promo.GoalNote
+	GoalRequest = goals.GoalRequest
+	NoteRequest = goals.NoteRequest
+
+	Certification        = promo.Certification
+	CertificationKind    = promo.CertificationKind
+	CertificationRequest = certifications.CertificationRequest
+	CertificationReport  = certifications.Report
+	CertificationHolder  = certifications.Holder
+	CertificationHolding = certifications.Holding
+
+	Eligibility    = eligibility.Eligibility
+	Check          = eligibility.Check
+	GoalCompletion = eligibility.GoalCompletion
+
+	Headcount    = reports.Headcount
+	Distribution = reports.Distribution
+	Pyramid      = reports.Pyramid
+	PyramidLevel = reports.Level
+)
+
+// The values of the API enumerations.
+const (
+	CycleScheduled = promo.CycleScheduled
+	CycleOpen      = promo.CycleOpen
+	CycleClosed    = promo.CycleClosed
+
+	ReviewPending  = promo.ReviewPending
+	ReviewApproved = promo.ReviewApproved
+	ReviewRejected = promo.ReviewRejected
+	ReviewPromoted = promo.ReviewPromoted
+	ReviewSkipped  = promo.ReviewSkipped
+
+	RelationPeer    = promo.RelationPeer
+	RelationManager = promo.RelationManager
+	RelationReport  = promo.RelationReport
+
+	GoalOpen       = promo.GoalOpen
+	GoalInProgress = promo.GoalInProgress
+	GoalDone       = promo.GoalDone
+	GoalDropped    = promo.GoalDropped
+
+	KindCertification = promo.KindCertification
+	KindCourse        = promo.KindCourse
+
+	RuleRequired  = promo.RuleRequired
+	RuleTooLong   = promo.RuleTooLong
+	RuleTooSmall  = promo.RuleTooSmall
+	RuleCharset   = promo.RuleCharset
+	RuleFormat    = promo.RuleFormat
+	RuleEnum      = promo.RuleEnum
+	RulePattern   = promo.RulePattern
+	RuleEmail     = promo.RuleEmail
+	RuleDate      = promo.RuleDate
+	RuleReference = promo.RuleReference
+	RuleDuplicate = promo.RuleDuplicate
+	RuleInvalid   = promo.RuleInvalid
+
+	// DateLayout writes the dates of the API, MonthLayout its months.
+	DateLayout  = promo.DateLayout
+	MonthLayout = reports.MonthLayout
+)
+
+// The errors an Error matches with errors.Is, the same the local stores
+// return.
+var (
+	ErrNotFound              = promo.ErrNotFound
+	ErrDuplicate             = promo.ErrDuplicate
+	ErrInvalidManager        = promo.ErrInvalidManager
+	ErrPreconditionFailed    = promo.ErrPreconditionFailed
+	ErrNotSupported          = promo.ErrNotSupported
+	ErrNotReplayed           = promo.ErrNotReplayed
+	ErrProjectExists         = promo.ErrProjectExists
+	ErrProjectNotFound       = promo.ErrProjectNotFound
+	ErrCycleNotFound         = promo.ErrCycleNotFound
+	ErrReviewNotFound        = promo.ErrReviewNotFound
+	ErrCycleState            = promo.ErrCycleState
+	ErrAssessmentNotFound    = promo.ErrAssessmentNotFound
+	ErrAssessmentExists      = promo.ErrAssessmentExists
+	ErrAssessmentLocked      = promo.ErrAssessmentLocked
+	ErrFeedbackNotFound      = promo.ErrFeedbackNotFound
+	ErrFeedbackKey           = promo.ErrFeedbackKey
+	ErrFeedbackClosed        = promo.ErrFeedbackClosed
+	ErrResponseNotFound      = promo.ErrResponseNotFound
+	ErrCompetencyNotFound    = promo.ErrCompetencyNotFound
+	ErrCompetencyExists      = promo.ErrCompetencyExists
+	ErrMentorshipNotFound    = promo.ErrMentorshipNotFound
+	ErrInvalidMentor         = promo.ErrInvalidMentor
+	ErrMentorshipExists      = promo.ErrMentorshipExists
+	ErrMentorshipState       = promo.ErrMentorshipState
+	ErrMentorBusy            = promo.ErrMentorBusy
+	ErrGoalNotFound          = promo.ErrGoalNotFound
+	ErrCertificationNotFound = promo.ErrCertificationNotFound
+)
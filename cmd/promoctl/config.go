@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultURL = "http://localhost:8080"
+	envURL     = "PROMO_URL"
+	envToken   = "PROMO_TOKEN"
+)
+
+type config struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "promo", "config.json")
+}
+
+// loadConfig reads the config file (a missing one is not an error) and lets
+// PROMO_URL and PROMO_TOKEN override what it says.
+func loadConfig(path string) (*config, error) {
+	c := &config{URL: defaultURL}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("unable to read config %s: %w", path, err)
+		default:
+			if err = json.Unmarshal(b, c); err != nil {
+				return nil, fmt.Errorf("unable to parse config %s: %w", path, err)
+			}
+		}
+	}
+	if v := os.Getenv(envURL); v != "" {
+		c.URL = v
+	}
+	if v := os.Getenv(envToken); v != "" {
+		c.Token = v
+	}
+	return c, nil
+}
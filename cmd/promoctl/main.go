@@ -0,0 +1,400 @@
+// Command promoctl is a command-line client for the Andersen Promo HTTP API.
+//
+// Usage:
+//
+//	promoctl [-url URL] [-token TOKEN] [-o table|json|csv] users <command> [args]
+//
+// Commands:
+//
+//	users list [-project NAME] [-grade GRADE] [-location PLACE] [-manager ID] [-deleted] [-as-of DATE]
+//	users get [-as-of DATE] ID
+//	users create -name NAME -surname SURNAME -grade GRADE [-project NAME] [-email EMAIL] [-employee-number N]
+//	    [-hire-date YYYY-MM-DD] [-location PLACE] [-manager ID] [-force]
+//	users promote ID
+//	users delete ID
+//	users restore ID
+//	users search [-limit N] QUERY
+//	users duplicates [-distance N]
+//	users merge ID FROM_ID
+//	users reports [-depth N] ID
+//	users chain ID
+//	users orgchart [-format dot|mermaid|json] [-project NAME]
+//	users import [-dry-run] [-map FIELD=HEADER]... FILE.csv|FILE.xlsx
+//	users export [-format csv|xlsx|ndjson] [-project NAME] [-grade GRADE] [-deleted] [-out FILE]
+//
+// The base URL and token are read from $XDG_CONFIG_HOME/promo/config.json
+// ({"url": "...", "token": "..."}), then from PROMO_URL and PROMO_TOKEN, and
+// finally from the flags.
+package main
+
+import (
+	"AndersenPromo/client"
+	promo "AndersenPromo/internal"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usage = `usage: promoctl [flags] users <command> [args]
+
+commands:
+  users list [-project NAME] [-grade GRADE] [-location PLACE] [-manager ID] [-deleted] [-as-of DATE]
+  users get [-as-of DATE] ID
+  users create -name NAME -surname SURNAME -grade GRADE [-project NAME] [-email EMAIL] [-employee-number N]
+      [-hire-date YYYY-MM-DD] [-location PLACE] [-manager ID] [-force]
+  users promote ID
+  users delete ID
+  users restore ID
+  users search [-limit N] QUERY
+  users duplicates [-distance N]
+  users merge ID FROM_ID
+  users reports [-depth N] ID
+  users chain ID
+  users orgchart [-format dot|mermaid|json] [-project NAME]
+  users import [-dry-run] [-map FIELD=HEADER]... FILE.csv|FILE.xlsx
+  users export [-format csv|xlsx|ndjson] [-project NAME] [-grade GRADE] [-deleted] [-out FILE]
+
+flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("promoctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	cfgPath := fs.String("config", defaultConfigPath(), "path to the config file")
+	baseURL := fs.String("url", "", "base URL of the API (overrides config and "+envURL+")")
+	token := fs.String("token", "", "bearer token (overrides config and "+envToken+")")
+	format := fs.String("o", formatTable, "output format: table, json or csv")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	c, err := loadConfig(*cfgPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if *baseURL != "" {
+		c.URL = *baseURL
+	}
+	if *token != "" {
+		c.Token = *token
+	}
+
+	rest := fs.Args()
+	if len(rest) < 2 || rest[0] != "users" {
+		fs.Usage()
+		return 2
+	}
+	cl := client.New(c.URL, client.WithToken(c.Token), client.WithHTTPClient(&http.Client{Timeout: 30 * time.Second}))
+	err = runUsers(context.Background(), cl, rest[1], rest[2:], *format, stdout, stderr)
+	if errors.Is(err, errUsage) {
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func runUsers(ctx context.Context, cl *client.Client, cmd string, args []string, format string, stdout, stderr io.Writer) error {
+	switch cmd {
+	case "list":
+		fs := flag.NewFlagSet("users list", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		project := fs.String("project", "", "only users of this project")
+		grade := fs.String("grade", "", "only users of this grade")
+		deleted := fs.Bool("deleted", false, "list deleted users too")
+		location := fs.String("location", "", "only users at this location")
+		manager := fs.Int("manager", 0, "only the direct reports of this user")
+		asOf := fs.String("as-of", "", "users as they were then, YYYY-MM-DD or RFC 3339")
+		if err := fs.Parse(args); err != nil {
+			return errUsage
+		}
+		f := promo.UserFilter{Project: *project, IncludeDeleted: *deleted, Location: *location, ManagerId: *manager}
+		if *grade != "" {
+			g, err := promo.ParseGrade(*grade)
+			if err != nil {
+				return err
+			}
+			f.Position = g
+		}
+		if *asOf != "" {
+			t, err := parseTime(*asOf)
+			if err != nil {
+				return err
+			}
+			us, err := cl.UsersAsOf(ctx, f, t)
+			if err != nil {
+				return err
+			}
+			return printUsers(stdout, format, us)
+		}
+		us, err := cl.GetAllUsers(ctx, f)
+		if err != nil {
+			return err
+		}
+		return printUsers(stdout, format, *us)
+	case "get":
+		fs := flag.NewFlagSet("users get", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		asOf := fs.String("as-of", "", "the user as they were then, YYYY-MM-DD or RFC 3339")
+		if err := fs.Parse(args); err != nil {
+			return errUsage
+		}
+		id, err := idArg(fs.Args())
+		if err != nil {
+			return err
+		}
+		var u *promo.User
+		if *asOf != "" {
+			var t time.Time
+			if t, err = parseTime(*asOf); err != nil {
+				return err
+			}
+			u, err = cl.UserAsOf(ctx, id, t)
+		} else {
+			u, err = cl.GetUser(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+		return printUser(stdout, format, *u)
+	case "create":
+		fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		name := fs.String("name", "", "first name")
+		surname := fs.String("surname", "", "last name")
+		grade := fs.String("grade", "", "grade: trainee, junior, middle or senior")
+		project := fs.String("project", "", "project name")
+		email := fs.String("email", "", "email address, unique")
+		number := fs.String("employee-number", "", "employee number, unique")
+		hired := fs.String("hire-date", "", "hire date, YYYY-MM-DD")
+		location := fs.String("location", "", "location")
+		manager := fs.Int("manager", 0, "id of the manager")
+		force := fs.Bool("force", false, "create even if a user has the same name")
+		if err := fs.Parse(args); err != nil {
+			return errUsage
+		}
+		if *name == "" || *surname == "" || *grade == "" {
+			return fmt.Errorf("-name, -surname and -grade are required")
+		}
+		g, err := promo.ParseGrade(*grade)
+		if err != nil {
+			return err
+		}
+		u := promo.User{Name: *name, Surname: *surname, Position: g, Project: *project, Email: *email, EmployeeNumber: *number,
+			HireDate: *hired, Location: *location, ManagerId: *manager}
+		id, err := cl.CreateUser(ctx, u, *force)
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) && apiErr.ConflictingId != 0 {
+			return fmt.Errorf("%w (merge with: promoctl users merge %d ID)", err, apiErr.ConflictingId)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "user %d created\n", id)
+		return nil
+	case "promote":
+		id, err := idArg(args)
+		if err != nil {
+			return err
+		}
+		// The grade is only bumped while the user is as read, so that a
+		// change made meanwhile, another promotion included, is not undone.
+		u, etag, err := cl.UserTagged(ctx, id)
+		if err != nil {
+			return err
+		}
+		next, ok := u.Position.Next()
+		if !ok {
+			return fmt.Errorf("user %d is already %s", id, u.Position)
+		}
+		err = cl.UpdateUserIf(ctx, id, etag, map[string]string{"position": next.String()})
+		if errors.Is(err, client.ErrPreconditionFailed) {
+			return fmt.Errorf("user %d changed while being promoted, not promoted: %w", id, err)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "user %d promoted to %s\n", id, next)
+		return nil
+	case "delete":
+		id, err := idArg(args)
+		if err != nil {
+			return err
+		}
+		return cl.DeleteUser(ctx, id)
+	case "restore":
+		id, err := idArg(args)
+		if err != nil {
+			return err
+		}
+		return cl.RestoreUser(ctx, id)
+	case "search":
+		fs := flag.NewFlagSet("users search", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		limit := fs.Int("limit", 0, "most results (server default when 0)")
+		if err := fs.Parse(args); err != nil || fs.NArg() == 0 {
+			return errUsage
+		}
+		results, err := cl.Search(ctx, strings.Join(fs.Args(), " "), *limit)
+		if err != nil {
+			return err
+		}
+		return printSearch(stdout, format, results)
+	case "duplicates":
+		fs := flag.NewFlagSet("users duplicates", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		distance := fs.Int("distance", 2, "most edits between two names")
+		if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+		pairs, err := cl.Duplicates(ctx, *distance)
+		if err != nil {
+			return err
+		}
+		return printDuplicates(stdout, format, pairs)
+	case "merge":
+		if len(args) != 2 {
+			return errUsage
+		}
+		into, err := idArg(args[:1])
+		if err != nil {
+			return err
+		}
+		from, err := idArg(args[1:])
+		if err != nil {
+			return err
+		}
+		if err = cl.MergeUsers(ctx, into, from); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "user %d merged into %d\n", from, into)
+		return nil
+	case "reports":
+		fs := flag.NewFlagSet("users reports", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		depth := fs.Int("depth", 100, "levels to walk down")
+		if err := fs.Parse(args); err != nil {
+			return errUsage
+		}
+		id, err := idArg(fs.Args())
+		if err != nil {
+			return err
+		}
+		reports, err := cl.Reports(ctx, id, *depth)
+		if err != nil {
+			return err
+		}
+		return printReports(stdout, format, reports)
+	case "chain":
+		id, err := idArg(args)
+		if err != nil {
+			return err
+		}
+		chain, err := cl.Chain(ctx, id)
+		if err != nil {
+			return err
+		}
+		return printReports(stdout, format, chain)
+	case "orgchart":
+		fs := flag.NewFlagSet("users orgchart", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		chartFormat := fs.String("format", "dot", "chart format: dot, mermaid or json")
+		project := fs.String("project", "", "only users of this project")
+		if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+		f := promo.UserFilter{Project: *project}
+		if *chartFormat == "json" {
+			roots, err := cl.OrgChart(ctx, f)
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(roots)
+		}
+		chart, err := cl.RenderOrgChart(ctx, f, *chartFormat)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(chart)
+		return err
+	case "import":
+		fs := flag.NewFlagSet("users import", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		dryRun := fs.Bool("dry-run", false, "only validate the file")
+		mapping := mappingFlag{}
+		fs.Var(mapping, "map", "use HEADER column for FIELD (name, surname, position, project), repeatable")
+		if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+			return errUsage
+		}
+		return importUsers(ctx, cl, fs.Arg(0), client.ImportOptions{DryRun: *dryRun, Mapping: mapping}, format, stdout)
+	case "export":
+		fs := flag.NewFlagSet("users export", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		exportFormat := fs.String("format", "csv", "file format: csv, xlsx or ndjson")
+		project := fs.String("project", "", "only users of this project")
+		grade := fs.String("grade", "", "only users of this grade")
+		deleted := fs.Bool("deleted", false, "export deleted users too")
+		out := fs.String("out", "", "write to this file instead of stdout")
+		if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+		f := promo.UserFilter{Project: *project, IncludeDeleted: *deleted}
+		if *grade != "" {
+			g, err := promo.ParseGrade(*grade)
+			if err != nil {
+				return err
+			}
+			f.Position = g
+		}
+		return exportUsers(ctx, cl, *exportFormat, f, *out, stdout)
+	}
+	return errUsage
+}
+
+// parseTime reads a date, standing for its midnight UTC, or an RFC 3339
+// time.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(promo.DateLayout, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, want %s or RFC 3339", s, promo.DateLayout)
+	}
+	return t, nil
+}
+
+func idArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errUsage
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid user id %q", args[0])
+	}
+	return id, nil
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T) (*httptest.Server, *[]string) {
+	var calls []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/getall", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`[{"id":1,"name":"And","surname":"Ersen","position":3,"project":"Test"}]`))
+	})
+	mux.HandleFunc("/get/5", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"id":5,"name":"And","surname":"Ersen","position":2,"project":"Test"}`))
+	})
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		_, _ = w.Write([]byte(`[{"id":1,"name":"And","surname":"Ersen","position":2,"project":"Test"}]`))
+	})
+	mux.HandleFunc("/get/6", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no rows in result set", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/update/5", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("If-Match")+" "+string(b))
+	})
+	mux.HandleFunc("/update/7", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		_, _ = w.Write([]byte(`{"type":"/problems/precondition-failed","title":"Precondition Failed","status":412}`))
+	})
+	mux.HandleFunc("/get/7", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"id":7,"name":"And","surname":"Ersen","position":2,"project":"Test"}`))
+	})
+	mux.HandleFunc("/users/5/restore", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+	})
+	mux.HandleFunc("/users/5/merge", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		_, _ = w.Write([]byte(`{"id":5,"name":"And","surname":"Ersen","position":2,"project":"Test"}`))
+	})
+	mux.HandleFunc("/users/5/chain", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		_, _ = w.Write([]byte(`[{"id":2,"name":"Ann","surname":"Boss","position":4,"project":"Test","depth":1}]`))
+	})
+	mux.HandleFunc("/users/search", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		_, _ = w.Write([]byte(`[{"id":2,"name":"Ann","surname":"Boss","position":4,"project":"Test","rank":0.75}]`))
+	})
+	mux.HandleFunc("/orgchart", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.RequestURI())
+		_, _ = w.Write([]byte("graph TD\n\tu2[\"Ann Boss\"]\n"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv(envURL, "")
+	t.Setenv(envToken, "")
+	t.Run("Check listing users as csv", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "-token", "secret", "-o", "csv",
+			"users", "list", "-project", "Test", "-grade", "middle"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "id,name,surname,grade,project\n1,And,Ersen,middle,Test\n", stdout.String())
+		assert.Equal(t, []string{"GET /getall?grade=middle&project=Test Bearer secret"}, *calls)
+	})
+	t.Run("Check getting user as table", func(t *testing.T) {
+		srv, _ := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "get", "5"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "ID  NAME  SURNAME  GRADE   PROJECT\n5   And   Ersen    junior  Test\n", stdout.String())
+	})
+	t.Run("Check listing users as of a date", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "-o", "csv", "users", "list", "-as-of", "2026-03-01"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "id,name,surname,grade,project\n1,And,Ersen,junior,Test\n", stdout.String())
+		assert.Equal(t, []string{"GET /users?as_of=2026-03-01T00%3A00%3A00Z"}, *calls)
+	})
+	t.Run("Check promoting user", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "promote", "5"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "user 5 promoted to middle\n", stdout.String())
+		assert.Equal(t, []string{
+			"GET /get/5",
+			`PATCH /update/5 "v1" {"position":3}`,
+		}, *calls)
+	})
+	t.Run("Check promoting user changed meanwhile", func(t *testing.T) {
+		srv, _ := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "promote", "7"}, &stdout, &stderr)
+		assert.Equal(t, 1, code)
+		assert.Empty(t, stdout.String())
+		assert.Contains(t, stderr.String(), "user 7 changed while being promoted, not promoted")
+	})
+	t.Run("Check listing deleted users", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "list", "-deleted"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, []string{"GET /getall?include_deleted=true "}, *calls)
+	})
+	t.Run("Check restoring user", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "restore", "5"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, []string{"POST /users/5/restore"}, *calls)
+	})
+	t.Run("Check merging users", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "merge", "5", "6"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "user 6 merged into 5\n", stdout.String())
+		assert.Equal(t, []string{"POST /users/5/merge?from=6"}, *calls)
+	})
+	t.Run("Check management chain", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "-o", "csv", "users", "chain", "5"}, &stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "depth,id,name,surname,grade,project\n1,2,Ann,Boss,senior,Test\n", stdout.String())
+		assert.Equal(t, []string{"GET /users/5/chain"}, *calls)
+	})
+	t.Run("Check searching users", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "-o", "csv", "users", "search", "-limit", "5", "Ann", "Bos"},
+			&stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "rank,id,name,surname,grade,project\n0.75,2,Ann,Boss,senior,Test\n", stdout.String())
+		assert.Equal(t, []string{"GET /users/search?limit=5&q=Ann+Bos"}, *calls)
+	})
+	t.Run("Check rendering org chart", func(t *testing.T) {
+		srv, calls := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "orgchart", "-format", "mermaid", "-project", "Test"},
+			&stdout, &stderr)
+		assert.Equal(t, 0, code, stderr.String())
+		assert.Equal(t, "graph TD\n\tu2[\"Ann Boss\"]\n", stdout.String())
+		assert.Equal(t, []string{"GET /orgchart?format=mermaid&project=Test"}, *calls)
+	})
+	t.Run("Check server error", func(t *testing.T) {
+		srv, _ := newTestServer(t)
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "-url", srv.URL, "users", "get", "6"}, &stdout, &stderr)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "no rows in result set")
+	})
+	t.Run("Check usage error", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-config", "", "users", "get"}, &stdout, &stderr)
+		assert.Equal(t, 2, code)
+		assert.Contains(t, stderr.String(), "usage: promoctl")
+	})
+}
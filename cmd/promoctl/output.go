@@ -0,0 +1,134 @@
+package main
+
+import (
+	promo "AndersenPromo/internal"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+var userHeader = []string{"id", "name", "surname", "grade", "project"}
+
+func userRecord(u promo.User) []string {
+	return []string{strconv.Itoa(u.Id), u.Name, u.Surname, u.Position.String(), u.Project}
+}
+
+func printUsers(w io.Writer, format string, us []promo.User) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tSURNAME\tGRADE\tPROJECT")
+		for _, u := range us {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", u.Id, u.Name, u.Surname, u.Position, u.Project)
+		}
+		return tw.Flush()
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(us)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(userHeader)
+		for _, u := range us {
+			_ = cw.Write(userRecord(u))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func printUser(w io.Writer, format string, u promo.User) error {
+	if format == formatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(u)
+	}
+	return printUsers(w, format, []promo.User{u})
+}
+
+func printReports(w io.Writer, format string, reports []promo.Report) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "DEPTH\tID\tNAME\tSURNAME\tGRADE\tPROJECT")
+		for _, r := range reports {
+			fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\n", r.Depth, r.Id, r.Name, r.Surname, r.Position, r.Project)
+		}
+		return tw.Flush()
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(append([]string{"depth"}, userHeader...))
+		for _, r := range reports {
+			_ = cw.Write(append([]string{strconv.Itoa(r.Depth)}, userRecord(r.User)...))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func printSearch(w io.Writer, format string, results []promo.SearchResult) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RANK\tID\tNAME\tSURNAME\tGRADE\tPROJECT")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%.2f\t%d\t%s\t%s\t%s\t%s\n", r.Rank, r.Id, r.Name, r.Surname, r.Position, r.Project)
+		}
+		return tw.Flush()
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write(append([]string{"rank"}, userHeader...))
+		for _, r := range results {
+			_ = cw.Write(append([]string{strconv.FormatFloat(r.Rank, 'f', -1, 64)}, userRecord(r.User)...))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+func printDuplicates(w io.Writer, format string, pairs []promo.DuplicatePair) error {
+	switch format {
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tID\tNAME\tDISTANCE")
+		for _, p := range pairs {
+			fmt.Fprintf(tw, "%d\t%s %s\t%d\t%s %s\t%d\n", p.First.Id, p.First.Name, p.First.Surname,
+				p.Second.Id, p.Second.Name, p.Second.Surname, p.Distance)
+		}
+		return tw.Flush()
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(pairs)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"first_id", "first_name", "second_id", "second_name", "distance"})
+		for _, p := range pairs {
+			_ = cw.Write([]string{strconv.Itoa(p.First.Id), p.First.Name + " " + p.First.Surname,
+				strconv.Itoa(p.Second.Id), p.Second.Name + " " + p.Second.Surname, strconv.Itoa(p.Distance)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"AndersenPromo/client"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// mappingFlag collects repeated -map FIELD=HEADER flags.
+type mappingFlag map[string]string
+
+func (m mappingFlag) String() string {
+	var s []string
+	for k, v := range m {
+		s = append(s, k+"="+v)
+	}
+	return strings.Join(s, ",")
+}
+
+func (m mappingFlag) Set(v string) error {
+	field, header, ok := strings.Cut(v, "=")
+	if !ok || field == "" || header == "" {
+		return fmt.Errorf("expected FIELD=HEADER, got %q", v)
+	}
+	m[field] = header
+	return nil
+}
+
+func importUsers(ctx context.Context, cl *client.Client, path string, opts client.ImportOptions, format string, stdout io.Writer) error {
+	var contentType string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		contentType = "text/csv"
+	case ".xlsx":
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return fmt.Errorf("%s: expected a .csv or .xlsx file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rep, err := cl.ImportUsers(ctx, f, contentType, opts)
+	if rep == nil {
+		return err
+	}
+	if format == formatJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if perr := enc.Encode(rep); perr != nil {
+			return perr
+		}
+		return err
+	}
+	for _, e := range rep.Errors {
+		fmt.Fprintf(stdout, "row %d: %s\n", e.Row, e.Error)
+	}
+	switch {
+	case err != nil:
+	case rep.DryRun:
+		fmt.Fprintf(stdout, "%d rows are valid\n", rep.Rows)
+	default:
+		fmt.Fprintf(stdout, "imported %d of %d rows\n", rep.Imported, rep.Rows)
+	}
+	return err
+}
+
+func exportUsers(ctx context.Context, cl *client.Client, format string, f client.UserFilter, out string, stdout io.Writer) (err error) {
+	w := stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = file
+	}
+	return cl.ExportUsers(ctx, format, f, w)
+}
@@ -0,0 +1,116 @@
+package promo
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrAssessmentNotFound = errors.New("assessment not found")
+	// ErrAssessmentExists is returned for a second assessment of a user by
+	// the same reviewer in a cycle.
+	ErrAssessmentExists = errors.New("assessment already exists")
+	// ErrAssessmentLocked is returned when changing the assessments of a
+	// project in a cycle once calibrated.
+	ErrAssessmentLocked = errors.New("assessment is locked by calibration")
+)
+
+// Assessment is the assessment of user UserId in cycle CycleId by reviewer
+// ReviewerId, 0 when unknown: an overall rating, scores by competency and
+// a summary. Grade and Project are those of the user when assessed. Locked
+// is true once the assessments of Project in the cycle are calibrated.
+type Assessment struct {
+	Id         int            `json:"id" yaml:"id"`
+	CycleId    int            `json:"cycle_id" yaml:"cycle_id"`
+	UserId     int            `json:"user_id" yaml:"user_id"`
+	ReviewerId int            `json:"reviewer_id,omitempty" yaml:"reviewer_id,omitempty"`
+	Grade      Grade          `json:"grade" yaml:"grade"`
+	Project    string         `json:"project" yaml:"project"`
+	Rating     int            `json:"rating" yaml:"rating"`
+	Scores     map[string]int `json:"scores" yaml:"scores"`
+	Summary    string         `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Locked     bool           `json:"locked" yaml:"locked"`
+}
+
+// AssessmentFilter selects assessments; zero fields match everything.
+type AssessmentFilter struct {
+	CycleId int
+	UserId  int
+	Project string
+}
+
+// AssessmentStore keeps the assessments of users in review cycles. Ratings
+// and scores are stored as given, checking them against a scale is up to
+// the caller.
+type AssessmentStore interface {
+	// AddAssessment records a, taking its grade and project from the user,
+	// and returns its id. The user and reviewer have to be live and the
+	// cycle to exist; the project must not be calibrated in it yet.
+	AddAssessment(a Assessment) (int, error)
+	// Assessment is assessment id, ErrAssessmentNotFound if there is none.
+	Assessment(id int) (*Assessment, error)
+	// Assessments lists the assessments matching f by id.
+	Assessments(f AssessmentFilter) ([]Assessment, error)
+	// UpdateAssessment replaces the rating, scores and summary of
+	// assessment id unless locked.
+	UpdateAssessment(id, rating int, scores map[string]int, summary string) error
+	// Calibrate locks the assessments of project in cycle, ErrAssessmentLocked
+	// if it already is.
+	Calibrate(cycle int, project string) error
+	// Calibrated tells whether project is calibrated in cycle.
+	Calibrated(cycle int, project string) (bool, error)
+}
+
+// Assessment queries shared by Registry and SQLite.
+const (
+	cycleExists      = "SELECT EXISTS (SELECT 1 FROM review_cycle WHERE id=$1)"
+	assessmentUser   = "SELECT position, COALESCE(project, '') FROM usr WHERE id=$1 AND deleted_at IS NULL"
+	assessmentExists = "SELECT EXISTS (SELECT 1 FROM assessment WHERE cycle_id=$1 AND user_id=$2 AND COALESCE(reviewer_id, 0)=$3)"
+	calibrated       = "SELECT EXISTS (SELECT 1 FROM calibration WHERE cycle_id=$1 AND project=$2)"
+	addAssessment    = `INSERT INTO assessment (cycle_id, user_id, reviewer_id, grade, project, rating, summary)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	addScore         = "INSERT INTO assessment_score (assessment_id, competency, score) VALUES ($1, $2, $3)"
+	deleteScores     = "DELETE FROM assessment_score WHERE assessment_id=$1"
+	updateAssessment = `UPDATE assessment SET rating=$2, summary=$3
+WHERE id=$1 AND NOT EXISTS (SELECT 1 FROM calibration c WHERE c.cycle_id=assessment.cycle_id AND c.project=assessment.project)`
+	calibrate = "INSERT INTO calibration (cycle_id, project, calibrated_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"
+)
+
+// assessmentColumns are read by assessmentFields.
+const assessmentColumns = "a.id, a.cycle_id, a.user_id, COALESCE(a.reviewer_id, 0), a.grade, a.project, a.rating, COALESCE(a.summary, ''), " +
+	"EXISTS (SELECT 1 FROM calibration c WHERE c.cycle_id=a.cycle_id AND c.project=a.project)"
+
+// assessmentFields lists where the assessmentColumns of a row go, the grade
+// going to grade.
+func assessmentFields(a *Assessment, grade *string) []any {
+	return []any{&a.Id, &a.CycleId, &a.UserId, &a.ReviewerId, grade, &a.Project, &a.Rating, &a.Summary, &a.Locked}
+}
+
+// assessmentQueries select the assessments matching f, and assessment id
+// unless 0, by id, and their scores, with $n parameters.
+func assessmentQueries(f AssessmentFilter, id int) (assessments, scores string, args []any) {
+	var cond []string
+	if id != 0 {
+		args = append(args, id)
+		cond = append(cond, "a.id="+pgParam(len(args)))
+	}
+	if f.CycleId != 0 {
+		args = append(args, f.CycleId)
+		cond = append(cond, "a.cycle_id="+pgParam(len(args)))
+	}
+	if f.UserId != 0 {
+		args = append(args, f.UserId)
+		cond = append(cond, "a.user_id="+pgParam(len(args)))
+	}
+	if f.Project != "" {
+		args = append(args, f.Project)
+		cond = append(cond, "a.project="+pgParam(len(args)))
+	}
+	where := ""
+	if len(cond) > 0 {
+		where = " WHERE " + strings.Join(cond, " AND ")
+	}
+	assessments = "SELECT " + assessmentColumns + " FROM assessment a" + where + " ORDER BY a.id"
+	scores = "SELECT s.assessment_id, s.competency, s.score FROM assessment_score s JOIN assessment a ON a.id=s.assessment_id" + where
+	return assessments, scores, args
+}
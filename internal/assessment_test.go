@@ -0,0 +1,92 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Assessments(t *testing.T) {
+	exists := func(ok bool) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"exists"}).AddRow(ok)
+	}
+	userRows := func(position, project string) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"position", "project"}).AddRow(position, project)
+	}
+	expectAssessment := func(mock pgxmock.PgxPoolIface, locked bool) {
+		req, scores, args := assessmentQueries(AssessmentFilter{}, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "cycle_id", "user_id", "reviewer_id", "grade", "project", "rating",
+				"summary", "locked"}).AddRow(1, 1, 2, 1, "junior", "Alpha", 4, "solid", locked))
+		mock.ExpectQuery(regexp.QuoteMeta(scores)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"assessment_id", "competency", "score"}).AddRow(1, "coding", 5))
+	}
+
+	t.Run("Check assessing (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(cycleExists)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(2).WillReturnRows(userRows("junior", "Alpha"))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(1).WillReturnRows(userRows("senior", "Alpha"))
+		mock.ExpectQuery(regexp.QuoteMeta(calibrated)).WithArgs(1, "Alpha").WillReturnRows(exists(false))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentExists)).WithArgs(1, 2, 1).WillReturnRows(exists(false))
+		mock.ExpectQuery(regexp.QuoteMeta(addAssessment)).
+			WithArgs(1, 2, nullableId(1), "junior", "Alpha", 4, nullable("solid")).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(addScore)).WithArgs(1, "coding", 5).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		id, err := r.AddAssessment(Assessment{CycleId: 1, UserId: 2, ReviewerId: 1, Rating: 4,
+			Scores: map[string]int{"coding": 5}, Summary: "solid"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check assessing (registry, calibrated)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(cycleExists)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(2).WillReturnRows(userRows("junior", "Alpha"))
+		mock.ExpectQuery(regexp.QuoteMeta(calibrated)).WithArgs(1, "Alpha").WillReturnRows(exists(true))
+		mock.ExpectRollback()
+		_, err := r.AddAssessment(Assessment{CycleId: 1, UserId: 2, Rating: 4})
+		assert.ErrorIs(t, err, ErrAssessmentLocked)
+	})
+	t.Run("Check assessing (registry, absent user)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(cycleExists)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(42).
+			WillReturnRows(pgxmock.NewRows([]string{"position", "project"}))
+		mock.ExpectRollback()
+		_, err := r.AddAssessment(Assessment{CycleId: 1, UserId: 42, Rating: 4})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("Check getting an assessment (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		expectAssessment(mock, false)
+		a, err := r.Assessment(1)
+		require.NoError(t, err)
+		assert.Equal(t, &Assessment{Id: 1, CycleId: 1, UserId: 2, ReviewerId: 1, Grade: junior, Project: "Alpha", Rating: 4,
+			Scores: map[string]int{"coding": 5}, Summary: "solid"}, a)
+	})
+	t.Run("Check updating an assessment (registry, calibrated)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(updateAssessment)).WithArgs(1, 3, (*string)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		expectAssessment(mock, true)
+		mock.ExpectRollback()
+		assert.ErrorIs(t, r.UpdateAssessment(1, 3, nil, ""), ErrAssessmentLocked)
+	})
+	t.Run("Check calibrating (registry, twice)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(cycleExists)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectExec(regexp.QuoteMeta(calibrate)).WithArgs(1, "Alpha", pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+		assert.ErrorIs(t, r.Calibrate(1, "Alpha"), ErrAssessmentLocked)
+	})
+}
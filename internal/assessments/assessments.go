@@ -0,0 +1,152 @@
+// Package assessments records the performance assessments of users in
+// review cycles, rated on a scale, and calibrates them per project: the
+// ratings of a project in a cycle are compared by grade, then locked.
+//
+// Assessments are kept by stores implementing promo.AssessmentStore.
+package assessments
+
+import (
+	promo "AndersenPromo/internal"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNotSupported is returned against stores keeping no assessments.
+var ErrNotSupported = fmt.Errorf("assessments are %w", promo.ErrNotSupported)
+
+// maxCompetencyLength bounds the name of a competency.
+const maxCompetencyLength = 64
+
+// Scale is the range of ratings and competency scores, bounds included.
+type Scale struct {
+	Min int `json:"min" yaml:"min"`
+	Max int `json:"max" yaml:"max"`
+}
+
+// DefaultScale rates from 1 to 5.
+var DefaultScale = Scale{Min: 1, Max: 5}
+
+// ParseScale reads a scale written MIN-MAX, e.g. 1-5.
+func ParseScale(s string) (Scale, error) {
+	lo, hi, ok := strings.Cut(s, "-")
+	min, err := strconv.Atoi(lo)
+	if !ok || err != nil {
+		return Scale{}, fmt.Errorf("illegal scale %q, want MIN-MAX", s)
+	}
+	max, err := strconv.Atoi(hi)
+	if err != nil || max <= min {
+		return Scale{}, fmt.Errorf("illegal scale %q, want MIN-MAX with MIN below MAX", s)
+	}
+	return Scale{Min: min, Max: max}, nil
+}
+
+func (s Scale) String() string {
+	return fmt.Sprintf("%d-%d", s.Min, s.Max)
+}
+
+// check adds to errs a FieldError for field unless n is on the scale.
+func (s Scale) check(errs promo.ValidationErrors, field string, n int) promo.ValidationErrors {
+	if n < s.Min || n > s.Max {
+		return append(errs, promo.FieldError{Field: field, Rule: promo.RuleInvalid, Detail: fmt.Sprintf("is not on the scale %s", s)})
+	}
+	return errs
+}
+
+// Ratings is an assessment as written by a reviewer.
+type Ratings struct {
+	Rating  int            `json:"rating" yaml:"rating"`
+	Scores  map[string]int `json:"scores" yaml:"scores"`
+	Summary string         `json:"summary" yaml:"summary"`
+}
+
+// validate checks r against s, trimming its summary.
+func (s Scale) validate(r *Ratings) promo.ValidationErrors {
+	errs := s.check(nil, "rating", r.Rating)
+	competencies := make([]string, 0, len(r.Scores))
+	for c := range r.Scores {
+		competencies = append(competencies, c)
+	}
+	sort.Strings(competencies)
+	for _, c := range competencies {
+		n, field := r.Scores[c], "scores."+c
+		switch {
+		case strings.TrimSpace(c) == "":
+			errs = append(errs, promo.FieldError{Field: field, Rule: promo.RuleRequired, Detail: "names no competency"})
+		case utf8.RuneCountInString(c) > maxCompetencyLength:
+			errs = append(errs, promo.FieldError{Field: field, Rule: promo.RuleTooLong,
+				Detail: fmt.Sprintf("is longer than %d characters", maxCompetencyLength)})
+		default:
+			errs = s.check(errs, field, n)
+		}
+	}
+	r.Summary = strings.TrimSpace(r.Summary)
+	return errs
+}
+
+// RatingCount is how many assessments gave Rating.
+type RatingCount struct {
+	Rating int `json:"rating" yaml:"rating"`
+	Count  int `json:"count" yaml:"count"`
+}
+
+// GradeRatings is the distribution of the ratings of the users of Grade,
+// over the whole scale.
+type GradeRatings struct {
+	Grade   string        `json:"grade" yaml:"grade"`
+	Count   int           `json:"count" yaml:"count"`
+	Mean    float64       `json:"mean" yaml:"mean"`
+	Ratings []RatingCount `json:"ratings" yaml:"ratings"`
+}
+
+// Calibration compares the ratings of the assessments of Project in cycle
+// CycleId by grade, lowest first, leaving out grades with none. Locked is
+// true once calibrated.
+type Calibration struct {
+	CycleId int            `json:"cycle_id" yaml:"cycle_id"`
+	Project string         `json:"project" yaml:"project"`
+	Scale   Scale          `json:"scale" yaml:"scale"`
+	Locked  bool           `json:"locked" yaml:"locked"`
+	Grades  []GradeRatings `json:"grades" yaml:"grades"`
+}
+
+// CalibrationOf is the calibration view of project in cycle, by the grade
+// of the users when assessed. Ratings off the scale, given before it
+// changed, count in the mean only.
+func CalibrationOf(as promo.AssessmentStore, s Scale, cycle int, project string) (*Calibration, error) {
+	list, err := as.Assessments(promo.AssessmentFilter{CycleId: cycle, Project: project})
+	if err != nil {
+		return nil, err
+	}
+	locked, err := as.Calibrated(cycle, project)
+	if err != nil {
+		return nil, err
+	}
+	c := &Calibration{CycleId: cycle, Project: project, Scale: s, Locked: locked, Grades: []GradeRatings{}}
+	byGrade := make(map[promo.Grade][]int)
+	for _, a := range list {
+		byGrade[a.Grade] = append(byGrade[a.Grade], a.Rating)
+	}
+	for _, g := range promo.Grades() {
+		ratings := byGrade[g]
+		if len(ratings) == 0 {
+			continue
+		}
+		gr := GradeRatings{Grade: g.String(), Count: len(ratings), Ratings: make([]RatingCount, 0, s.Max-s.Min+1)}
+		for r := s.Min; r <= s.Max; r++ {
+			gr.Ratings = append(gr.Ratings, RatingCount{Rating: r})
+		}
+		sum := 0
+		for _, r := range ratings {
+			sum += r
+			if r >= s.Min && r <= s.Max {
+				gr.Ratings[r-s.Min].Count++
+			}
+		}
+		gr.Mean = float64(sum) / float64(len(ratings))
+		c.Grades = append(c.Grades, gr)
+	}
+	return c, nil
+}
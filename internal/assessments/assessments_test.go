@@ -0,0 +1,148 @@
+package assessments
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/promotest"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScale(t *testing.T) {
+	s, err := ParseScale("0-10")
+	require.NoError(t, err)
+	assert.Equal(t, Scale{Min: 0, Max: 10}, s)
+	assert.Equal(t, "0-10", s.String())
+	for _, bad := range []string{"", "5", "5-1", "1-1", "a-5", "1-b"} {
+		t.Run("Check parsing scale (bad "+bad+")", func(t *testing.T) {
+			_, err := ParseScale(bad)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func newStore(t *testing.T) *promo.Memory {
+	m := promotest.NewStore(t,
+		promo.User{Name: "Ann", Surname: "Boss", Position: 4, Project: "Alpha"},
+		promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Cid", Surname: "Jones", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Dan", Surname: "Brown", Position: 3, Project: "Alpha"})
+	opens := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	_, err := m.CreateCycle(promo.Cycle{Name: "Spring", OpensAt: opens, ClosesAt: opens.AddDate(0, 1, 0), Projects: []string{"Alpha"}})
+	require.NoError(t, err)
+	return m
+}
+
+func newRouter(dbc promo.DBConnexion) *mux.Router {
+	return promotest.NewRouter(NewHandlers(dbc, DefaultScale))
+}
+
+func TestCalibrationOf(t *testing.T) {
+	m := newStore(t)
+	for _, a := range []promo.Assessment{
+		{CycleId: 1, UserId: 2, Rating: 3},
+		{CycleId: 1, UserId: 3, Rating: 4},
+		{CycleId: 1, UserId: 4, Rating: 6},
+	} {
+		_, err := m.AddAssessment(a)
+		require.NoError(t, err)
+	}
+	c, err := CalibrationOf(m, Scale{Min: 3, Max: 5}, 1, "Alpha")
+	require.NoError(t, err)
+	assert.Equal(t, &Calibration{CycleId: 1, Project: "Alpha", Scale: Scale{Min: 3, Max: 5}, Grades: []GradeRatings{
+		{Grade: "junior", Count: 2, Mean: 3.5, Ratings: []RatingCount{{3, 1}, {4, 1}, {5, 0}}},
+		{Grade: "middle", Count: 1, Mean: 6, Ratings: []RatingCount{{3, 0}, {4, 0}, {5, 0}}},
+	}}, c)
+}
+
+func TestHandlers(t *testing.T) {
+	t.Run("Check assessing and calibrating", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/assessments",
+			`{"cycle_id": 1, "user_id": 2, "reviewer_id": 1, "rating": 4, "scores": {"coding": 5}, "summary": " solid "}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var a promo.Assessment
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&a))
+		assert.Equal(t, promo.Assessment{Id: 1, CycleId: 1, UserId: 2, ReviewerId: 1, Grade: 2, Project: "Alpha", Rating: 4,
+			Scores: map[string]int{"coding": 5}, Summary: "solid"}, a)
+		w = promotest.Serve(router, http.MethodPost, "/assessments", `{"cycle_id": 1, "user_id": 2, "reviewer_id": 1, "rating": 3}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = promotest.Serve(router, http.MethodPut, "/assessments/1", `{"rating": 5}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		a = promo.Assessment{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&a))
+		assert.Equal(t, 5, a.Rating)
+		assert.Empty(t, a.Scores)
+
+		w = promotest.Serve(router, http.MethodGet, "/cycles/1/calibration?project=Alpha", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var c Calibration
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.False(t, c.Locked)
+		require.Len(t, c.Grades, 1)
+		assert.Equal(t, 5.0, c.Grades[0].Mean)
+
+		w = promotest.Serve(router, http.MethodPost, "/cycles/1/calibration?project=Alpha", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.True(t, c.Locked)
+		w = promotest.Serve(router, http.MethodPut, "/assessments/1", `{"rating": 1}`)
+		assert.Equal(t, http.StatusConflict, w.Code, "locked")
+		w = promotest.Serve(router, http.MethodPost, "/cycles/1/calibration?project=Alpha", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = promotest.Serve(router, http.MethodGet, "/assessments?user_id=2", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []promo.Assessment
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list, 1)
+		assert.True(t, list[0].Locked)
+	})
+	t.Run("Check calibrating (retried)", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/assessments", `{"cycle_id": 1, "user_id": 2, "rating": 4}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		for _, replayed := range []string{"", "true"} {
+			w = promotest.Serve(router, http.MethodPost, "/cycles/1/calibration?project=Alpha", "", promo.IdempotencyKeyHeader, "k1")
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, replayed, w.Header().Get(promo.IdempotentReplayedHeader))
+		}
+	})
+	t.Run("Check assessing (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t)), http.MethodPost, "/assessments",
+			`{"cycle_id": 1, "user_id": 2, "reviewer_id": 2, "rating": 6, "scores": {"coding": 0, " ": 3}}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "reviewer_id", Rule: promo.RuleInvalid, Detail: "is the user assessed"},
+			{Field: "rating", Rule: promo.RuleInvalid, Detail: "is not on the scale 1-5"},
+			{Field: "scores. ", Rule: promo.RuleRequired, Detail: "names no competency"},
+			{Field: "scores.coding", Rule: promo.RuleInvalid, Detail: "is not on the scale 1-5"},
+		}, p.Errors)
+	})
+	for name, url := range map[string]string{
+		"absent assessment": "/assessments/42",
+		"absent cycle":      "/cycles/42/calibration?project=Alpha",
+	} {
+		t.Run("Check getting ("+name+")", func(t *testing.T) {
+			w := promotest.Serve(newRouter(newStore(t)), http.MethodGet, url, "")
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+	t.Run("Check calibration view (no project)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t)), http.MethodGet, "/cycles/1/calibration", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("Check assessments (no assessments)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(struct{ promo.DBConnexion }{newStore(t)}), http.MethodGet, "/assessments", "")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
@@ -0,0 +1,349 @@
+package assessments
+
+import (
+	promo "AndersenPromo/internal"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the assessments of a store, rated on scale.
+type Handlers struct {
+	dbc   promo.DBConnexion
+	scale Scale
+}
+
+func NewHandlers(dbc promo.DBConnexion, scale Scale) *Handlers {
+	return &Handlers{dbc, scale}
+}
+
+// Register mounts the assessment routes on router. POST and PUT routes
+// honour the Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/assessments", promo.Idempotent(h.dbc, h.CreateAssessment)).Methods(http.MethodPost)
+	router.HandleFunc("/assessments", h.GetAssessments).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id:[0-9]+}", h.GetAssessment).Methods(http.MethodGet)
+	router.HandleFunc("/assessments/{id:[0-9]+}", promo.Idempotent(h.dbc, h.UpdateAssessment)).Methods(http.MethodPut)
+	router.HandleFunc("/cycles/{id:[0-9]+}/calibration", h.GetCalibration).Methods(http.MethodGet)
+	router.HandleFunc("/cycles/{id:[0-9]+}/calibration", promo.Idempotent(h.dbc, h.Calibrate)).Methods(http.MethodPost)
+}
+
+// AssessmentRequest is an assessment to record.
+type AssessmentRequest struct {
+	CycleId    int `json:"cycle_id" yaml:"cycle_id" validate:"required,min=1"`
+	UserId     int `json:"user_id" yaml:"user_id" validate:"required,min=1"`
+	ReviewerId int `json:"reviewer_id" yaml:"reviewer_id"`
+	Ratings    `yaml:",inline"`
+}
+
+// CheckFields checks that nobody assesses themselves.
+func (a *AssessmentRequest) CheckFields() promo.ValidationErrors {
+	if a.ReviewerId != 0 && a.ReviewerId == a.UserId {
+		return promo.ValidationErrors{{Field: "reviewer_id", Rule: promo.RuleInvalid, Detail: "is the user assessed"}}
+	}
+	return nil
+}
+
+// store is the AssessmentStore of h, answering 501 itself when there is
+// none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.AssessmentStore, bool) {
+	return promo.Capability[promo.AssessmentStore](w, h.dbc, ErrNotSupported)
+}
+
+// queryId reads the id query parameter name, 0 when absent, answering 400
+// itself when illegal.
+func queryId(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return 0, true
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		promo.Error(w, fmt.Sprintf("illegal %s %q", name, s), http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateAssessment	 godoc
+//
+//	@Summary		Assess user
+//	@Description	record the assessment of a user in a review cycle, its rating and scores on the configured scale
+//	@Tags			assessments
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			assessment	body		assessments.AssessmentRequest	true	"Assessment"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201			{object}	promo.Assessment
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		404			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		409			{object}	promo.Problem
+//	@Failure		415			{object}	promo.Problem
+//	@Failure		422			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/assessments [post]
+func (h *Handlers) CreateAssessment(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var req AssessmentRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	var errs promo.ValidationErrors
+	if err := promo.NewValidator(h.dbc).Validate(&req, false); err != nil && !errors.As(err, &errs) {
+		promo.Invalid(w, err)
+		return
+	}
+	if err := append(errs, h.scale.validate(&req.Ratings)...).Err(); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	id, err := as.AddAssessment(promo.Assessment{CycleId: req.CycleId, UserId: req.UserId, ReviewerId: req.ReviewerId,
+		Rating: req.Rating, Scores: req.Scores, Summary: req.Summary})
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	a, err := as.Assessment(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, a)
+}
+
+// GetAssessments	 godoc
+//
+//	@Summary		Assessments
+//	@Description	the assessments of a cycle, user or project, by id; the project is that of the user when assessed
+//	@Tags			assessments
+//	@Produce		json,application/yaml
+//	@Param			cycle_id	query		int		false	"Cycle ID"
+//	@Param			user_id		query		int		false	"User ID"
+//	@Param			project		query		string	false	"Project name"
+//	@Success		200			{array}		promo.Assessment
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/assessments [get]
+func (h *Handlers) GetAssessments(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	f := promo.AssessmentFilter{Project: r.URL.Query().Get("project")}
+	if f.CycleId, ok = queryId(w, r, "cycle_id"); !ok {
+		return
+	}
+	if f.UserId, ok = queryId(w, r, "user_id"); !ok {
+		return
+	}
+	list, err := as.Assessments(f)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
+
+// GetAssessment	 godoc
+//
+//	@Summary		Assessment
+//	@Tags			assessments
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Assessment ID"
+//	@Success		200	{object}	promo.Assessment
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/assessments/{id} [get]
+func (h *Handlers) GetAssessment(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	a, err := as.Assessment(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, a)
+}
+
+// UpdateAssessment	 godoc
+//
+//	@Summary		Revise assessment
+//	@Description	replace the rating, scores and summary of an assessment until its project is calibrated in the cycle
+//	@Tags			assessments
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id		path		int					true	"Assessment ID"
+//	@Param			ratings	body		assessments.Ratings	true	"Ratings"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200		{object}	promo.Assessment
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		409		{object}	promo.Problem
+//	@Failure		415		{object}	promo.Problem
+//	@Failure		422		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/assessments/{id} [put]
+func (h *Handlers) UpdateAssessment(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req Ratings
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	if err := h.scale.validate(&req).Err(); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	if err := as.UpdateAssessment(id, req.Rating, req.Scores, req.Summary); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	a, err := as.Assessment(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, a)
+}
+
+// calibration reads the cycle and project of a calibration request,
+// answering 400 or 404 itself when they are wrong.
+func (h *Handlers) calibration(w http.ResponseWriter, r *http.Request) (int, string, bool) {
+	cycle, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return 0, "", false
+	}
+	project := r.URL.Query().Get("project")
+	if project == "" {
+		promo.Error(w, "project is required", http.StatusBadRequest)
+		return 0, "", false
+	}
+	if cs, ok := h.dbc.(promo.CycleStore); ok {
+		if _, err := cs.Cycle(cycle); err != nil {
+			promo.DBError(w, err)
+			return 0, "", false
+		}
+	}
+	return cycle, project, true
+}
+
+// GetCalibration	 godoc
+//
+//	@Summary		Calibration view
+//	@Description	the distribution of the ratings of a project in a cycle by grade, to compare before calibrating
+//	@Tags			assessments
+//	@Produce		json,application/yaml
+//	@Param			id		path		int		true	"Cycle ID"
+//	@Param			project	query		string	true	"Project name"
+//	@Success		200		{object}	assessments.Calibration
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/cycles/{id}/calibration [get]
+func (h *Handlers) GetCalibration(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	cycle, project, ok := h.calibration(w, r)
+	if !ok {
+		return
+	}
+	c, err := CalibrationOf(as, h.scale, cycle, project)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, c)
+}
+
+// Calibrate	 godoc
+//
+//	@Summary		Calibrate project
+//	@Description	mark the assessments of a project in a cycle calibrated, locking them
+//	@Tags			assessments
+//	@Produce		json,application/yaml
+//	@Param			id		path		int		true	"Cycle ID"
+//	@Param			project	query		string	true	"Project name"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200		{object}	assessments.Calibration
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		409		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/cycles/{id}/calibration [post]
+func (h *Handlers) Calibrate(w http.ResponseWriter, r *http.Request) {
+	as, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	cycle, project, ok := h.calibration(w, r)
+	if !ok {
+		return
+	}
+	if err := as.Calibrate(cycle, project); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	c, err := CalibrationOf(as, h.scale, cycle, project)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, c)
+}
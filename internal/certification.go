@@ -0,0 +1,136 @@
+package promo
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrCertificationNotFound = errors.New("certification not found")
+
+// CertificationKind tells certifications from courses.
+type CertificationKind string
+
+const (
+	KindCertification CertificationKind = "certification"
+	KindCourse        CertificationKind = "course"
+)
+
+// Valid tells whether k is a known kind.
+func (k CertificationKind) Valid() bool {
+	return k == KindCertification || k == KindCourse
+}
+
+// Certification is a certification or course of user UserId, issued by
+// Issuer on IssuedOn and valid until ExpiresOn included, forever when
+// empty. Evidence refers to the file proving it. FlaggedAt is when it was
+// flagged as expiring, nil until then.
+type Certification struct {
+	Id     int               `json:"id" yaml:"id"`
+	UserId int               `json:"user_id" yaml:"user_id"`
+	Kind   CertificationKind `json:"kind" yaml:"kind"`
+	Name   string            `json:"name" yaml:"name" validate:"required,max=120"`
+	Issuer string            `json:"issuer" yaml:"issuer" validate:"required,max=120"`
+	// IssuedOn and ExpiresOn are formatted as DateLayout.
+	IssuedOn  string     `json:"issued_on" yaml:"issued_on" validate:"required,date"`
+	ExpiresOn string     `json:"expires_on,omitempty" yaml:"expires_on,omitempty" validate:"date"`
+	Evidence  string     `json:"evidence,omitempty" yaml:"evidence,omitempty" validate:"max=500"`
+	FlaggedAt *time.Time `json:"flagged_at,omitempty" yaml:"flagged_at,omitempty"`
+}
+
+// CheckFields checks that c does not expire before it is issued.
+func (c *Certification) CheckFields() ValidationErrors {
+	issued, err := time.Parse(DateLayout, c.IssuedOn)
+	if err != nil {
+		return nil
+	}
+	if expires, err := time.Parse(DateLayout, c.ExpiresOn); err == nil && expires.Before(issued) {
+		return ValidationErrors{{Field: "expires_on", Rule: RuleInvalid, Detail: "is before issued_on"}}
+	}
+	return nil
+}
+
+// Valid tells whether c is held on day, formatted as DateLayout.
+func (c *Certification) Valid(day string) bool {
+	return c.IssuedOn <= day && (c.ExpiresOn == "" || day <= c.ExpiresOn)
+}
+
+// CertificationFilter selects the certifications of live users; zero
+// fields match everything. ExpiringBy, formatted as DateLayout, selects
+// those expiring that day or before, and Unflagged those not flagged yet.
+type CertificationFilter struct {
+	UserId     int
+	Project    string
+	Kind       CertificationKind
+	Name       string
+	ExpiringBy string
+	Unflagged  bool
+}
+
+// CertificationStore keeps the certifications and courses of users.
+type CertificationStore interface {
+	// AddCertification records c for a live user, ErrNotFound otherwise,
+	// and returns its id. It is not flagged.
+	AddCertification(c Certification) (int, error)
+	// Certification is certification id, ErrCertificationNotFound if there
+	// is none.
+	Certification(id int) (*Certification, error)
+	// Certifications lists the certifications matching f by id.
+	Certifications(f CertificationFilter) ([]Certification, error)
+	// UpdateCertification replaces the kind, name, issuer, dates and
+	// evidence of certification c.Id, unflagging it so that a renewal is
+	// flagged again.
+	UpdateCertification(c Certification) error
+	// DeleteCertification deletes certification id.
+	DeleteCertification(id int) error
+	// FlagCertification records that certification id was flagged as
+	// expiring at at.
+	FlagCertification(id int, at time.Time) error
+}
+
+// Certification queries shared by Registry and SQLite.
+const (
+	addCertificationQuery = `INSERT INTO certification (user_id, kind, name, issuer, issued_on, expires_on, evidence)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	updateCertificationQuery = `UPDATE certification SET kind=$2, name=$3, issuer=$4, issued_on=$5, expires_on=$6, evidence=$7,
+flagged_at=NULL WHERE id=$1`
+	deleteCertificationQuery = "DELETE FROM certification WHERE id=$1"
+	flagCertificationQuery   = "UPDATE certification SET flagged_at=$2 WHERE id=$1"
+)
+
+// certificationsQuery selects the certifications matching f, and
+// certification id unless 0, by id, with $n parameters.
+func certificationsQuery(f CertificationFilter, id int) (string, []any) {
+	cond := []string{"u.deleted_at IS NULL"}
+	var args []any
+	if id != 0 {
+		args = append(args, id)
+		cond = append(cond, "c.id="+pgParam(len(args)))
+	}
+	if f.UserId != 0 {
+		args = append(args, f.UserId)
+		cond = append(cond, "c.user_id="+pgParam(len(args)))
+	}
+	if f.Project != "" {
+		args = append(args, f.Project)
+		cond = append(cond, "u.project="+pgParam(len(args)))
+	}
+	if f.Kind != "" {
+		args = append(args, string(f.Kind))
+		cond = append(cond, "c.kind="+pgParam(len(args)))
+	}
+	if f.Name != "" {
+		args = append(args, f.Name)
+		cond = append(cond, "c.name="+pgParam(len(args)))
+	}
+	if f.ExpiringBy != "" {
+		args = append(args, f.ExpiringBy)
+		cond = append(cond, "c.expires_on <= "+pgParam(len(args)))
+	}
+	if f.Unflagged {
+		cond = append(cond, "c.flagged_at IS NULL")
+	}
+	return "SELECT c.id, c.user_id, c.kind, c.name, c.issuer, CAST(c.issued_on AS TEXT), COALESCE(CAST(c.expires_on AS TEXT), ''), " +
+		"COALESCE(c.evidence, ''), c.flagged_at FROM certification c JOIN usr u ON u.id=c.user_id WHERE " +
+		strings.Join(cond, " AND ") + " ORDER BY c.id", args
+}
@@ -0,0 +1,70 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Certifications(t *testing.T) {
+	certificationRows := func() *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"id", "user_id", "kind", "name", "issuer", "issued_on", "expires_on", "evidence",
+			"flagged_at"})
+	}
+	t.Run("Check adding a certification (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(liveUserQuery)).WithArgs(1).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(regexp.QuoteMeta(addCertificationQuery)).
+			WithArgs(1, "certification", "CKA", "CNCF", "2024-05-01", nullable("2026-05-01"), (*string)(nil)).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		id, err := r.AddCertification(Certification{UserId: 1, Kind: KindCertification, Name: "CKA", Issuer: "CNCF",
+			IssuedOn: "2024-05-01", ExpiresOn: "2026-05-01"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check listing certifications (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		flagged := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		f := CertificationFilter{UserId: 1}
+		req, args := certificationsQuery(f, 0)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(certificationRows().
+				AddRow(1, 1, "certification", "CKA", "CNCF", "2024-05-01", "2026-05-01", "", &flagged).
+				AddRow(2, 1, "course", "Go basics", "Andersen", "2025-01-15", "", "files/2.pdf", (*time.Time)(nil)))
+		cs, err := r.Certifications(f)
+		require.NoError(t, err)
+		assert.Equal(t, []Certification{
+			{Id: 1, UserId: 1, Kind: KindCertification, Name: "CKA", Issuer: "CNCF", IssuedOn: "2024-05-01",
+				ExpiresOn: "2026-05-01", FlaggedAt: &flagged},
+			{Id: 2, UserId: 1, Kind: KindCourse, Name: "Go basics", Issuer: "Andersen", IssuedOn: "2025-01-15",
+				Evidence: "files/2.pdf"},
+		}, cs)
+	})
+	t.Run("Check getting a certification (registry, absent)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		req, args := certificationsQuery(CertificationFilter{}, 42)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).WillReturnRows(certificationRows())
+		_, err := r.Certification(42)
+		assert.ErrorIs(t, err, ErrCertificationNotFound)
+	})
+	t.Run("Check changing a certification (registry, absent)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		at := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectExec(regexp.QuoteMeta(updateCertificationQuery)).
+			WithArgs(42, "course", "Go", "Andersen", "2025-01-15", (*string)(nil), (*string)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, r.UpdateCertification(Certification{Id: 42, Kind: KindCourse, Name: "Go", Issuer: "Andersen",
+			IssuedOn: "2025-01-15"}), ErrCertificationNotFound)
+		mock.ExpectExec(regexp.QuoteMeta(flagCertificationQuery)).WithArgs(42, at).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, r.FlagCertification(42, at), ErrCertificationNotFound)
+		mock.ExpectExec(regexp.QuoteMeta(deleteCertificationQuery)).WithArgs(42).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		assert.ErrorIs(t, r.DeleteCertification(42), ErrCertificationNotFound)
+	})
+}
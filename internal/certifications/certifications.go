@@ -0,0 +1,110 @@
+// Package certifications keeps the certifications and courses of users,
+// with their issuer, dates and a reference to the file proving them. A
+// watcher flags certifications nearing their expiry once, and a report
+// tells who on a project holds which certification, for contracts
+// requiring certified staff.
+//
+// Certifications are kept by stores implementing
+// promo.CertificationStore.
+package certifications
+
+import (
+	promo "AndersenPromo/internal"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNotSupported is returned against stores keeping no certifications.
+var ErrNotSupported = fmt.Errorf("certifications are %w", promo.ErrNotSupported)
+
+// DefaultNotice is how long before their expiry certifications are
+// flagged by default.
+const DefaultNotice = 30 * 24 * time.Hour
+
+// Holder is a user holding a certification until ExpiresOn, forever when
+// empty.
+type Holder struct {
+	UserId    int    `json:"user_id" yaml:"user_id"`
+	Name      string `json:"name" yaml:"name"`
+	Surname   string `json:"surname" yaml:"surname"`
+	Grade     string `json:"grade" yaml:"grade"`
+	ExpiresOn string `json:"expires_on,omitempty" yaml:"expires_on,omitempty"`
+}
+
+// Holding is the users holding a certification, by id.
+type Holding struct {
+	Certification string   `json:"certification" yaml:"certification"`
+	Holders       []Holder `json:"holders" yaml:"holders"`
+}
+
+// Report is who of the Users of Project holds which certification on
+// Date, by certification name.
+type Report struct {
+	Project        string    `json:"project" yaml:"project"`
+	Date           string    `json:"date" yaml:"date"`
+	Users          int       `json:"users" yaml:"users"`
+	Certifications []Holding `json:"certifications" yaml:"certifications"`
+}
+
+// ReportOf reports the certifications held by the live users of project at
+// now, only those named name unless empty. Courses are left out.
+func ReportOf(dbc promo.DBConnexion, cs promo.CertificationStore, project, name string, now time.Time) (*Report, error) {
+	users, err := dbc.GetAllUsers(promo.UserFilter{Project: project})
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[int]promo.User, len(*users))
+	for _, u := range *users {
+		byId[u.Id] = u
+	}
+	list, err := cs.Certifications(promo.CertificationFilter{Project: project, Kind: promo.KindCertification, Name: name})
+	if err != nil {
+		return nil, err
+	}
+	day := now.Format(promo.DateLayout)
+	r := &Report{Project: project, Date: day, Users: len(*users), Certifications: []Holding{}}
+	held := make(map[string]int)
+	seen := make(map[string]map[int]bool)
+	for _, c := range list {
+		u, ok := byId[c.UserId]
+		if !ok || !c.Valid(day) {
+			continue
+		}
+		i, ok := held[c.Name]
+		if !ok {
+			i = len(r.Certifications)
+			held[c.Name], seen[c.Name] = i, make(map[int]bool)
+			r.Certifications = append(r.Certifications, Holding{Certification: c.Name, Holders: []Holder{}})
+		}
+		if seen[c.Name][u.Id] {
+			continue
+		}
+		seen[c.Name][u.Id] = true
+		r.Certifications[i].Holders = append(r.Certifications[i].Holders, Holder{UserId: u.Id, Name: u.Name,
+			Surname: u.Surname, Grade: u.Position.String(), ExpiresOn: c.ExpiresOn})
+	}
+	sort.Slice(r.Certifications, func(i, j int) bool { return r.Certifications[i].Certification < r.Certifications[j].Certification })
+	for _, h := range r.Certifications {
+		sort.Slice(h.Holders, func(i, j int) bool { return h.Holders[i].UserId < h.Holders[j].UserId })
+	}
+	return r, nil
+}
+
+// Expiring lists the certifications of live users expiring between now and
+// now+within, by id.
+func Expiring(cs promo.CertificationStore, now time.Time, within time.Duration) ([]promo.Certification, error) {
+	list, err := cs.Certifications(promo.CertificationFilter{Kind: promo.KindCertification,
+		ExpiringBy: now.Add(within).Format(promo.DateLayout)})
+	if err != nil {
+		return nil, err
+	}
+	today := now.Format(promo.DateLayout)
+	out := make([]promo.Certification, 0, len(list))
+	for _, c := range list {
+		if c.ExpiresOn >= today {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
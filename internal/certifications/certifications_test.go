@@ -0,0 +1,190 @@
+package certifications
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/promotest"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC)
+
+// newStore has a junior and a senior on Alpha and a middle on Beta: the
+// senior holds CKA until May and took a course, the junior let CKA expire
+// and the middle holds it forever.
+func newStore(t *testing.T) *promo.Memory {
+	m := promotest.NewStore(t,
+		promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Ann", Surname: "Boss", Position: 4, Project: "Alpha"},
+		promo.User{Name: "Cid", Surname: "Jones", Position: 3, Project: "Beta"})
+	for _, c := range []promo.Certification{
+		{UserId: 2, Kind: promo.KindCertification, Name: "CKA", Issuer: "CNCF", IssuedOn: "2024-05-01", ExpiresOn: "2026-05-01"},
+		{UserId: 2, Kind: promo.KindCourse, Name: "Go basics", Issuer: "Andersen", IssuedOn: "2025-01-15"},
+		{UserId: 1, Kind: promo.KindCertification, Name: "CKA", Issuer: "CNCF", IssuedOn: "2023-01-01", ExpiresOn: "2026-01-01"},
+		{UserId: 3, Kind: promo.KindCertification, Name: "CKA", Issuer: "CNCF", IssuedOn: "2025-01-01"},
+	} {
+		_, err := m.AddCertification(c)
+		require.NoError(t, err)
+	}
+	return m
+}
+
+func newRouter(dbc promo.DBConnexion) *mux.Router {
+	h := NewHandlers(dbc, DefaultNotice)
+	h.clock = promo.ClockFunc(func() time.Time { return now })
+	return promotest.NewRouter(h)
+}
+
+func TestReportOf(t *testing.T) {
+	m := newStore(t)
+	_, err := m.AddCertification(promo.Certification{UserId: 1, Kind: promo.KindCertification, Name: "AWS SAA", Issuer: "AWS",
+		IssuedOn: "2026-03-01"})
+	require.NoError(t, err)
+	r, err := ReportOf(m, m, "Alpha", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, &Report{Project: "Alpha", Date: "2026-04-01", Users: 2, Certifications: []Holding{
+		{Certification: "AWS SAA", Holders: []Holder{{UserId: 1, Name: "Bob", Surname: "Smith", Grade: "junior"}}},
+		{Certification: "CKA", Holders: []Holder{{UserId: 2, Name: "Ann", Surname: "Boss", Grade: "senior", ExpiresOn: "2026-05-01"}}},
+	}}, r)
+
+	r, err = ReportOf(m, m, "Alpha", "CKA", now.AddDate(0, 2, 0))
+	require.NoError(t, err)
+	assert.Empty(t, r.Certifications, "expired")
+}
+
+func TestWatcher_Tick(t *testing.T) {
+	m := newStore(t)
+	var flagged []int
+	notifier := NotifierFunc(func(_ context.Context, c promo.Certification) error {
+		if c.UserId == 1 {
+			return errors.New("unreachable")
+		}
+		flagged = append(flagged, c.Id)
+		return nil
+	})
+	clock := promo.ClockFunc(func() time.Time { return now })
+	w := NewWatcher(m, notifier, clock, 30*24*time.Hour)
+	assert.Error(t, w.Tick(context.Background()), "Bob is unreachable")
+	assert.Equal(t, []int{1}, flagged)
+	c, err := m.Certification(1)
+	require.NoError(t, err)
+	require.NotNil(t, c.FlaggedAt)
+	assert.Equal(t, now, *c.FlaggedAt)
+	c, err = m.Certification(3)
+	require.NoError(t, err)
+	assert.Nil(t, c.FlaggedAt, "to try again")
+
+	require.NoError(t, m.DeleteCertification(3))
+	flagged = nil
+	require.NoError(t, w.Tick(context.Background()))
+	assert.Empty(t, flagged, "flagged once")
+
+	require.NoError(t, NewWatcher(struct{ promo.DBConnexion }{m}, notifier, clock, time.Hour).Tick(context.Background()))
+}
+
+func TestHandlers(t *testing.T) {
+	t.Run("Check certifications", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/users/1/certifications",
+			`{"name": " CKA ", "issuer": "CNCF", "issued_on": "2026-03-01", "expires_on": "2026-04-15", "evidence": "files/1.pdf"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c promo.Certification
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, promo.Certification{Id: 5, UserId: 1, Kind: promo.KindCertification, Name: "CKA", Issuer: "CNCF",
+			IssuedOn: "2026-03-01", ExpiresOn: "2026-04-15", Evidence: "files/1.pdf"}, c)
+
+		w = promotest.Serve(router, http.MethodGet, "/certifications/expiring", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list []promo.Certification
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		assert.Equal(t, []int{1, 5}, []int{list[0].Id, list[1].Id})
+		w = promotest.Serve(router, http.MethodGet, "/certifications/expiring?days=13", "")
+		list = nil
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		assert.Empty(t, list)
+
+		w = promotest.Serve(router, http.MethodPut, "/certifications/5",
+			`{"name": "CKA", "issuer": "CNCF", "issued_on": "2026-03-01", "expires_on": "2028-04-15"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		c = promo.Certification{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, "2028-04-15", c.ExpiresOn)
+		assert.Empty(t, c.Evidence)
+
+		w = promotest.Serve(router, http.MethodGet, "/projects/Alpha/certifications?certification=CKA", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var r Report
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&r))
+		require.Len(t, r.Certifications, 1)
+		assert.Len(t, r.Certifications[0].Holders, 2)
+
+		w = promotest.Serve(router, http.MethodGet, "/users/2/certifications", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		list = nil
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		assert.Len(t, list, 2)
+		w = promotest.Serve(router, http.MethodDelete, "/certifications/2", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		w = promotest.Serve(router, http.MethodGet, "/certifications/2", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+	t.Run("Check certifications (retried)", func(t *testing.T) {
+		m := newStore(t)
+		router := newRouter(m)
+		for _, replayed := range []string{"", "true"} {
+			w := promotest.Serve(router, http.MethodPost, "/users/1/certifications",
+				`{"name": "CKAD", "issuer": "CNCF", "issued_on": "2026-03-01"}`, promo.IdempotencyKeyHeader, "k1")
+			assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Equal(t, replayed, w.Header().Get(promo.IdempotentReplayedHeader))
+		}
+		w := promotest.Serve(router, http.MethodGet, "/users/1/certifications", "")
+		var list []promo.Certification
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		names := 0
+		for _, c := range list {
+			if c.Name == "CKAD" {
+				names++
+			}
+		}
+		assert.Equal(t, 1, names)
+	})
+	t.Run("Check certifications (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t)), http.MethodPost, "/users/1/certifications",
+			`{"kind": "diploma", "name": " ", "issuer": "CNCF", "issued_on": "2026-03-01", "expires_on": "2026-02-01"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "kind", Rule: promo.RuleEnum, Detail: "is not one of certification and course"},
+			{Field: "name", Rule: promo.RuleRequired, Detail: "is required"},
+			{Field: "expires_on", Rule: promo.RuleInvalid, Detail: "is before issued_on"},
+		}, p.Errors)
+	})
+	for name, req := range map[string]struct{ method, url, body string }{
+		"absent certification": {http.MethodGet, "/certifications/42", ""},
+		"update absent": {http.MethodPut, "/certifications/42",
+			`{"name": "CKA", "issuer": "CNCF", "issued_on": "2026-03-01"}`},
+		"delete absent":  {http.MethodDelete, "/certifications/42", ""},
+		"absent user":    {http.MethodGet, "/users/42/certifications", ""},
+		"of absent user": {http.MethodPost, "/users/42/certifications", `{"name": "CKA", "issuer": "CNCF", "issued_on": "2026-03-01"}`},
+		"absent project": {http.MethodGet, "/projects/Omega/certifications", ""},
+	} {
+		t.Run("Check certifications ("+name+")", func(t *testing.T) {
+			w := promotest.Serve(newRouter(newStore(t)), req.method, req.url, req.body)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+	t.Run("Check certifications (no certifications)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(struct{ promo.DBConnexion }{newStore(t)}), http.MethodGet, "/certifications/expiring", "")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
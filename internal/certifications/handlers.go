@@ -0,0 +1,371 @@
+package certifications
+
+import (
+	promo "AndersenPromo/internal"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the certifications of a store, listing those expiring
+// within notice unless told otherwise.
+type Handlers struct {
+	dbc    promo.DBConnexion
+	notice time.Duration
+	clock  promo.Clock
+}
+
+func NewHandlers(dbc promo.DBConnexion, notice time.Duration) *Handlers {
+	return &Handlers{dbc, notice, promo.SystemClock}
+}
+
+// Register mounts the certification routes on router. POST and PUT routes
+// honour the Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/users/{id:[0-9]+}/certifications", promo.Idempotent(h.dbc, h.CreateCertification)).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id:[0-9]+}/certifications", h.GetUserCertifications).Methods(http.MethodGet)
+	router.HandleFunc("/certifications/expiring", h.GetExpiring).Methods(http.MethodGet)
+	router.HandleFunc("/certifications/{id:[0-9]+}", h.GetCertification).Methods(http.MethodGet)
+	router.HandleFunc("/certifications/{id:[0-9]+}", promo.Idempotent(h.dbc, h.UpdateCertification)).Methods(http.MethodPut)
+	router.HandleFunc("/certifications/{id:[0-9]+}", h.DeleteCertification).Methods(http.MethodDelete)
+	router.HandleFunc("/projects/{name}/certifications", h.GetReport).Methods(http.MethodGet)
+}
+
+// CertificationRequest is a certification or course to record, a
+// certification unless Kind tells otherwise. Dates are written
+// promo.DateLayout and Evidence refers to the file proving it.
+type CertificationRequest struct {
+	Kind      promo.CertificationKind `json:"kind" yaml:"kind"`
+	Name      string                  `json:"name" yaml:"name"`
+	Issuer    string                  `json:"issuer" yaml:"issuer"`
+	IssuedOn  string                  `json:"issued_on" yaml:"issued_on"`
+	ExpiresOn string                  `json:"expires_on" yaml:"expires_on"`
+	Evidence  string                  `json:"evidence" yaml:"evidence"`
+}
+
+// store is the CertificationStore of h, answering 501 itself when there is
+// none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.CertificationStore, bool) {
+	return promo.Capability[promo.CertificationStore](w, h.dbc, ErrNotSupported)
+}
+
+// certification is the certification req describes for user, validated
+// against dbc. The error lists the failing fields as
+// promo.ValidationErrors.
+func certification(dbc promo.DBConnexion, user int, req CertificationRequest) (promo.Certification, error) {
+	c := promo.Certification{UserId: user, Kind: req.Kind, Name: strings.TrimSpace(req.Name),
+		Issuer: strings.TrimSpace(req.Issuer), IssuedOn: strings.TrimSpace(req.IssuedOn),
+		ExpiresOn: strings.TrimSpace(req.ExpiresOn), Evidence: strings.TrimSpace(req.Evidence)}
+	if c.Kind == "" {
+		c.Kind = promo.KindCertification
+	}
+	var errs promo.ValidationErrors
+	if !c.Kind.Valid() {
+		errs = append(errs, promo.FieldError{Field: "kind", Rule: promo.RuleEnum, Detail: "is not one of certification and course"})
+	}
+	var fieldErrs promo.ValidationErrors
+	if err := promo.NewValidator(dbc).Validate(&c, false); err != nil && !errors.As(err, &fieldErrs) {
+		return c, err
+	}
+	return c, append(errs, fieldErrs...).Err()
+}
+
+// CreateCertification	 godoc
+//
+//	@Summary		Add certification
+//	@Description	record a certification or course of a user
+//	@Tags			certifications
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id				path		int										true	"User ID"
+//	@Param			certification	body		certifications.CertificationRequest	true	"Certification"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201				{object}	promo.Certification
+//	@Failure		400				{object}	promo.Problem
+//	@Failure		404				{object}	promo.Problem
+//	@Failure		406				{object}	promo.Problem
+//	@Failure		415				{object}	promo.Problem
+//	@Failure		422				{object}	promo.Problem
+//	@Failure		500				{object}	promo.Problem
+//	@Failure		501				{object}	promo.Problem
+//	@Router			/users/{id}/certifications [post]
+func (h *Handlers) CreateCertification(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	user, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req CertificationRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	c, err := certification(h.dbc, user, req)
+	if err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	id, err := cs.AddCertification(c)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	created, err := cs.Certification(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, created)
+}
+
+// GetUserCertifications	 godoc
+//
+//	@Summary		User certifications
+//	@Description	the certifications and courses of a user, by id, expired ones included
+//	@Tags			certifications
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{array}		promo.Certification
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/users/{id}/certifications [get]
+func (h *Handlers) GetUserCertifications(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	user, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := h.dbc.GetUser(user); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	list, err := cs.Certifications(promo.CertificationFilter{UserId: user})
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
+
+// GetCertification	 godoc
+//
+//	@Summary		Certification
+//	@Tags			certifications
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Certification ID"
+//	@Success		200	{object}	promo.Certification
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/certifications/{id} [get]
+func (h *Handlers) GetCertification(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	c, err := cs.Certification(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, c)
+}
+
+// UpdateCertification	 godoc
+//
+//	@Summary		Update certification
+//	@Description	replace a certification, when renewed for instance, to be flagged again as it nears its new expiry
+//	@Tags			certifications
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id				path		int										true	"Certification ID"
+//	@Param			certification	body		certifications.CertificationRequest	true	"Certification"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200				{object}	promo.Certification
+//	@Failure		400				{object}	promo.Problem
+//	@Failure		404				{object}	promo.Problem
+//	@Failure		406				{object}	promo.Problem
+//	@Failure		415				{object}	promo.Problem
+//	@Failure		422				{object}	promo.Problem
+//	@Failure		500				{object}	promo.Problem
+//	@Failure		501				{object}	promo.Problem
+//	@Router			/certifications/{id} [put]
+func (h *Handlers) UpdateCertification(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req CertificationRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	old, err := cs.Certification(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	c, err := certification(h.dbc, old.UserId, req)
+	if err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	c.Id = id
+	if err = cs.UpdateCertification(c); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	updated, err := cs.Certification(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, updated)
+}
+
+// DeleteCertification	 godoc
+//
+//	@Summary		Delete certification
+//	@Tags			certifications
+//	@Param			id	path	int	true	"Certification ID"
+//	@Success		200
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/certifications/{id} [delete]
+func (h *Handlers) DeleteCertification(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := cs.DeleteCertification(id); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetExpiring	 godoc
+//
+//	@Summary		Expiring certifications
+//	@Description	the certifications of live users expiring within some days from today, by id; the flagging notice by default
+//	@Tags			certifications
+//	@Produce		json,application/yaml
+//	@Param			days	query		int	false	"Days from today"
+//	@Success		200		{array}		promo.Certification
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/certifications/expiring [get]
+func (h *Handlers) GetExpiring(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	within := h.notice
+	if s := r.URL.Query().Get("days"); s != "" {
+		days, err := strconv.Atoi(s)
+		if err != nil || days < 0 {
+			promo.Error(w, fmt.Sprintf("illegal days %q", s), http.StatusBadRequest)
+			return
+		}
+		within = time.Duration(days) * 24 * time.Hour
+	}
+	list, err := Expiring(cs, h.clock.Now(), within)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
+
+// GetReport	 godoc
+//
+//	@Summary		Project certifications
+//	@Description	who of the users of a project holds which certification today, courses left out
+//	@Tags			certifications
+//	@Produce		json,application/yaml
+//	@Param			name			path		string	true	"Project name"
+//	@Param			certification	query		string	false	"Only this certification"
+//	@Success		200				{object}	certifications.Report
+//	@Failure		404				{object}	promo.Problem
+//	@Failure		406				{object}	promo.Problem
+//	@Failure		500				{object}	promo.Problem
+//	@Failure		501				{object}	promo.Problem
+//	@Router			/projects/{name}/certifications [get]
+func (h *Handlers) GetReport(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	project := mux.Vars(r)["name"]
+	if ps, ok := h.dbc.(promo.ProjectStore); ok {
+		exists, err := ps.ProjectExists(project)
+		if err != nil {
+			promo.DBError(w, err)
+			return
+		}
+		if !exists {
+			promo.Fail(w, fmt.Errorf("no project %q: %w", project, promo.ErrProjectNotFound), http.StatusNotFound)
+			return
+		}
+	}
+	report, err := ReportOf(h.dbc, cs, project, strings.TrimSpace(r.URL.Query().Get("certification")), h.clock.Now())
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, report)
+}
@@ -0,0 +1,97 @@
+package certifications
+
+import (
+	promo "AndersenPromo/internal"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Notifier tells about certification c nearing or past its expiry.
+type Notifier interface {
+	Expiring(ctx context.Context, c promo.Certification) error
+}
+
+// NotifierFunc is a Notifier calling itself.
+type NotifierFunc func(ctx context.Context, c promo.Certification) error
+
+func (n NotifierFunc) Expiring(ctx context.Context, c promo.Certification) error {
+	return n(ctx, c)
+}
+
+// LogNotifier only logs expiring certifications, for want of a way to
+// reach users.
+var LogNotifier Notifier = NotifierFunc(func(_ context.Context, c promo.Certification) error {
+	log.Printf("Certification %d %q of user %d expires on %s", c.Id, c.Name, c.UserId, c.ExpiresOn)
+	return nil
+})
+
+// Watcher flags the certifications expiring within notice, once each,
+// until they are renewed.
+type Watcher struct {
+	dbc      promo.DBConnexion
+	notifier Notifier
+	clock    promo.Clock
+	notice   time.Duration
+}
+
+// NewWatcher watches the certifications of dbc, telling about those
+// expiring through notifier, LogNotifier when nil, telling time by clock,
+// promo.SystemClock when nil.
+func NewWatcher(dbc promo.DBConnexion, notifier Notifier, clock promo.Clock, notice time.Duration) *Watcher {
+	if notifier == nil {
+		notifier = LogNotifier
+	}
+	if clock == nil {
+		clock = promo.SystemClock
+	}
+	return &Watcher{dbc, notifier, clock, notice}
+}
+
+// Tick flags the certifications expiring within notice of now, or expired
+// already, that are not flagged yet. Failing notifications do not keep the
+// others from being sent; their errors are returned together.
+func (w *Watcher) Tick(ctx context.Context) error {
+	cs, ok := w.dbc.(promo.CertificationStore)
+	if !ok {
+		return nil
+	}
+	now := w.clock.Now()
+	list, err := cs.Certifications(promo.CertificationFilter{Kind: promo.KindCertification,
+		ExpiringBy: now.Add(w.notice).Format(promo.DateLayout), Unflagged: true})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, c := range list {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = w.notifier.Expiring(ctx, c); err != nil {
+			errs = append(errs, fmt.Errorf("unable to flag certification %d: %w", c.Id, err))
+			continue
+		}
+		if err = cs.FlagCertification(c.Id, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run ticks every interval until ctx is done, like cycles.Scheduler.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := w.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to flag expiring certifications: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package promo
+
+import "time"
+
+// Clock tells the time, for tests to move it at will.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a Clock calling itself.
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock of the system.
+var SystemClock Clock = ClockFunc(time.Now)
@@ -0,0 +1,121 @@
+package promo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrCycleNotFound  = errors.New("review cycle not found")
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrCycleState is returned when a review cycle is not in the status an
+	// operation needs, e.g. reviewing in a closed cycle.
+	ErrCycleState = errors.New("review cycle is not in the right status")
+)
+
+// CycleStatus is where a review cycle stands: scheduled until opened, open
+// while its users are reviewed, closed once the approved promotions are
+// applied.
+type CycleStatus string
+
+const (
+	CycleScheduled CycleStatus = "scheduled"
+	CycleOpen      CycleStatus = "open"
+	CycleClosed    CycleStatus = "closed"
+)
+
+// Cycle is a promotion review cycle of some projects, meant to open at
+// OpensAt and close at ClosesAt.
+type Cycle struct {
+	Id       int         `json:"id" yaml:"id"`
+	Name     string      `json:"name" yaml:"name"`
+	OpensAt  time.Time   `json:"opens_at" yaml:"opens_at"`
+	ClosesAt time.Time   `json:"closes_at" yaml:"closes_at"`
+	Projects []string    `json:"projects" yaml:"projects"`
+	Status   CycleStatus `json:"status" yaml:"status"`
+}
+
+// ReviewStatus is where the review of a user stands. Reviewers set pending,
+// approved or rejected while the cycle is open; closing it turns approved
+// into promoted, or skipped when the user left or changed grade meanwhile.
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+	ReviewPromoted ReviewStatus = "promoted"
+	ReviewSkipped  ReviewStatus = "skipped"
+)
+
+// Review is the review of user UserId in cycle CycleId for a promotion from
+// grade From to grade To.
+type Review struct {
+	CycleId int          `json:"cycle_id" yaml:"cycle_id"`
+	UserId  int          `json:"user_id" yaml:"user_id"`
+	From    Grade        `json:"from" yaml:"from"`
+	To      Grade        `json:"to" yaml:"to"`
+	Status  ReviewStatus `json:"status" yaml:"status"`
+	Comment string       `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// CycleStore keeps review cycles and their reviews. Opening and closing
+// are each done in one transaction.
+type CycleStore interface {
+	// CreateCycle adds a scheduled cycle and returns its id.
+	CreateCycle(c Cycle) (int, error)
+	// Cycle is cycle id, ErrCycleNotFound if there is none.
+	Cycle(id int) (*Cycle, error)
+	// Cycles lists the cycles by id.
+	Cycles() ([]Cycle, error)
+	// OpenCycle enrols the users of rs in scheduled cycle id and opens it.
+	OpenCycle(id int, rs []Review) error
+	// Reviews lists the reviews of cycle id by user.
+	Reviews(id int) ([]Review, error)
+	// SetReview sets the status, one of pending, approved and rejected, and
+	// the comment of the review of user in open cycle id.
+	SetReview(id, user int, status ReviewStatus, comment string) error
+	// CloseCycle applies the approved promotions of open cycle id and closes
+	// it.
+	CloseCycle(id int) error
+}
+
+// Review cycle queries shared by Registry and SQLite.
+const (
+	createCycleQuery  = "INSERT INTO review_cycle (name, opens_at, closes_at) VALUES ($1, $2, $3) RETURNING id"
+	cycleProjectQuery = "INSERT INTO review_cycle_project (cycle_id, project) VALUES ($1, $2)"
+	cyclesQuery       = "SELECT id, name, opens_at, closes_at, status FROM review_cycle ORDER BY id"
+	cycleQuery        = "SELECT id, name, opens_at, closes_at, status FROM review_cycle WHERE id=$1"
+	cycleProjects     = "SELECT cycle_id, project FROM review_cycle_project ORDER BY project"
+	// setCycleStatus moves cycle $1 from status $3 to $2.
+	setCycleStatus = "UPDATE review_cycle SET status=$2 WHERE id=$1 AND status=$3"
+	enrolQuery     = "INSERT INTO review (cycle_id, user_id, from_grade, to_grade) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING"
+	reviewsQuery   = "SELECT cycle_id, user_id, from_grade, to_grade, status, COALESCE(comment, '') FROM review WHERE cycle_id=$1 ORDER BY user_id"
+	setReviewQuery = `UPDATE review SET status=$3, comment=$4
+WHERE cycle_id=$1 AND user_id=$2 AND EXISTS (SELECT 1 FROM review_cycle WHERE id=$1 AND status='open')`
+)
+
+// closeCycleQueries apply the approved promotions of cycle $1, in order:
+// skip the users no longer live in the grade they were reviewed in, promote
+// the others.
+var closeCycleQueries = []string{
+	`UPDATE review SET status='skipped' WHERE cycle_id=$1 AND status='approved'
+	AND NOT EXISTS (SELECT 1 FROM usr u WHERE u.id=review.user_id AND u.deleted_at IS NULL AND u.position=review.from_grade)`,
+	`UPDATE usr SET position=(SELECT r.to_grade FROM review r WHERE r.cycle_id=$1 AND r.user_id=usr.id)
+WHERE id IN (SELECT user_id FROM review WHERE cycle_id=$1 AND status='approved')`,
+	"UPDATE review SET status='promoted' WHERE cycle_id=$1 AND status='approved'",
+}
+
+// cycleError tells why an operation needing cycle id in status want did
+// not apply: c is the cycle as it is, nil when missing. It is nil when the
+// cycle is as wanted.
+func cycleError(id int, c *Cycle, want CycleStatus) error {
+	if c == nil {
+		return fmt.Errorf("unable to get review cycle %d: %w", id, ErrCycleNotFound)
+	}
+	if c.Status != want {
+		return fmt.Errorf("review cycle %d is %s, not %s: %w", id, c.Status, want, ErrCycleState)
+	}
+	return nil
+}
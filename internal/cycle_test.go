@@ -0,0 +1,107 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Cycles(t *testing.T) {
+	opens := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	closes := opens.AddDate(0, 1, 0)
+	cycleRows := func(status CycleStatus) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"id", "name", "opens_at", "closes_at", "status"}).
+			AddRow(1, "Spring", opens, closes, status)
+	}
+	expectCycle := func(mock pgxmock.PgxPoolIface, status CycleStatus) {
+		mock.ExpectQuery(regexp.QuoteMeta(cycleQuery)).WithArgs(1).WillReturnRows(cycleRows(status))
+		mock.ExpectQuery(regexp.QuoteMeta(cycleProjects)).
+			WillReturnRows(pgxmock.NewRows([]string{"cycle_id", "project"}).AddRow(1, "Alpha").AddRow(2, "Beta"))
+	}
+
+	t.Run("Check creating a cycle (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(createCycleQuery)).WithArgs("Spring", opens, closes).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(cycleProjectQuery)).WithArgs(1, "Alpha").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		id, err := r.CreateCycle(Cycle{Name: "Spring", OpensAt: opens, ClosesAt: closes, Projects: []string{"Alpha"}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check getting a cycle (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		expectCycle(mock, CycleScheduled)
+		c, err := r.Cycle(1)
+		require.NoError(t, err)
+		assert.Equal(t, &Cycle{Id: 1, Name: "Spring", OpensAt: opens, ClosesAt: closes, Status: CycleScheduled,
+			Projects: []string{"Alpha"}}, c)
+	})
+	t.Run("Check getting a cycle (registry, absent)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(cycleQuery)).WithArgs(42).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "opens_at", "closes_at", "status"}))
+		_, err := r.Cycle(42)
+		assert.ErrorIs(t, err, ErrCycleNotFound)
+	})
+	t.Run("Check opening a cycle (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(setCycleStatus)).WithArgs(1, CycleOpen, CycleScheduled).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec(regexp.QuoteMeta(enrolQuery)).WithArgs(1, 2, "junior", "middle").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		assert.NoError(t, r.OpenCycle(1, []Review{{CycleId: 1, UserId: 2, From: junior, To: middle}}))
+	})
+	t.Run("Check opening a cycle (registry, already open)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(setCycleStatus)).WithArgs(1, CycleOpen, CycleScheduled).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		expectCycle(mock, CycleOpen)
+		mock.ExpectRollback()
+		assert.ErrorIs(t, r.OpenCycle(1, nil), ErrCycleState)
+	})
+	t.Run("Check reviewing (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectExec(regexp.QuoteMeta(setReviewQuery)).WithArgs(1, 2, ReviewApproved, nullable("ready")).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		require.NoError(t, r.SetReview(1, 2, ReviewApproved, "ready"))
+
+		expectCycle(mock, CycleOpen)
+		mock.ExpectQuery(regexp.QuoteMeta(reviewsQuery)).WithArgs(1).
+			WillReturnRows(pgxmock.NewRows([]string{"cycle_id", "user_id", "from_grade", "to_grade", "status", "comment"}).
+				AddRow(1, 2, "junior", "middle", ReviewApproved, "ready"))
+		rs, err := r.Reviews(1)
+		require.NoError(t, err)
+		assert.Equal(t, []Review{{CycleId: 1, UserId: 2, From: junior, To: middle, Status: ReviewApproved, Comment: "ready"}}, rs)
+	})
+	t.Run("Check reviewing (registry, not enrolled)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectExec(regexp.QuoteMeta(setReviewQuery)).WithArgs(1, 3, ReviewApproved, (*string)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		expectCycle(mock, CycleOpen)
+		assert.ErrorIs(t, r.SetReview(1, 3, ReviewApproved, ""), ErrReviewNotFound)
+	})
+	t.Run("Check closing a cycle (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(setCycleStatus)).WithArgs(1, CycleClosed, CycleOpen).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		for _, req := range closeCycleQueries {
+			mock.ExpectExec(regexp.QuoteMeta(req)).WithArgs(1).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		}
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		assert.NoError(t, r.CloseCycle(1))
+	})
+}
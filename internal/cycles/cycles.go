@@ -0,0 +1,139 @@
+// Package cycles runs promotion review cycles: a cycle is scheduled for
+// some projects between two dates, enrols the users eligible under the
+// promotion rules when it opens, and applies the approved promotions when
+// it closes.
+//
+// Cycles are kept by stores implementing promo.CycleStore. A Scheduler
+// opens and closes them on time; they can also be opened and closed by
+// hand.
+package cycles
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/eligibility"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrNotSupported is returned against stores keeping no review cycles.
+var ErrNotSupported = fmt.Errorf("review cycles are %w", promo.ErrNotSupported)
+
+// store is the CycleStore of dbc.
+func store(dbc promo.DBConnexion) (promo.CycleStore, error) {
+	cs, ok := dbc.(promo.CycleStore)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return cs, nil
+}
+
+// Open enrols the users of the projects of scheduled cycle id eligible
+// under rules at now, and opens it.
+func Open(ctx context.Context, dbc promo.DBConnexion, rules *eligibility.Rules, id int, now time.Time) error {
+	cs, err := store(dbc)
+	if err != nil {
+		return err
+	}
+	c, err := cs.Cycle(id)
+	if err != nil {
+		return err
+	}
+	if c.Status != promo.CycleScheduled {
+		return fmt.Errorf("review cycle %d is %s, not %s: %w", id, c.Status, promo.CycleScheduled, promo.ErrCycleState)
+	}
+	var rs []promo.Review
+	for _, p := range c.Projects {
+		es, err := rules.Eligible(ctx, dbc, p, now)
+		if err != nil {
+			return fmt.Errorf("unable to enrol the users of %s: %w", p, err)
+		}
+		for _, e := range es {
+			next, _ := e.User.Position.Next()
+			rs = append(rs, promo.Review{UserId: e.User.Id, From: e.User.Position, To: next})
+		}
+	}
+	return cs.OpenCycle(id, rs)
+}
+
+// Close applies the approved promotions of open cycle id and closes it.
+func Close(dbc promo.DBConnexion, id int) error {
+	cs, err := store(dbc)
+	if err != nil {
+		return err
+	}
+	return cs.CloseCycle(id)
+}
+
+// Scheduler opens the scheduled cycles once their opening time has come
+// and closes the open ones once their closing time has.
+type Scheduler struct {
+	dbc   promo.DBConnexion
+	rules *eligibility.Rules
+	clock promo.Clock
+}
+
+// NewScheduler schedules the cycles of dbc, telling time by clock,
+// promo.SystemClock when nil.
+func NewScheduler(dbc promo.DBConnexion, rules *eligibility.Rules, clock promo.Clock) *Scheduler {
+	if clock == nil {
+		clock = promo.SystemClock
+	}
+	return &Scheduler{dbc, rules, clock}
+}
+
+// Tick opens and closes the cycles due now. A cycle found past its closing
+// time before it opened is opened and closed at once, promoting no one.
+// Failing cycles do not keep the others from being handled; their errors
+// are returned together.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	cs, ok := s.dbc.(promo.CycleStore)
+	if !ok {
+		return nil
+	}
+	all, err := cs.Cycles()
+	if err != nil {
+		return err
+	}
+	now := s.clock.Now()
+	var errs []error
+	for _, c := range all {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if c.Status == promo.CycleScheduled && !now.Before(c.OpensAt) {
+			if err = Open(ctx, s.dbc, s.rules, c.Id, now); err != nil {
+				errs = append(errs, fmt.Errorf("unable to open review cycle %d: %w", c.Id, err))
+				continue
+			}
+			log.Printf("Opened review cycle %d %q", c.Id, c.Name)
+			c.Status = promo.CycleOpen
+		}
+		if c.Status == promo.CycleOpen && !now.Before(c.ClosesAt) {
+			if err = Close(s.dbc, c.Id); err != nil {
+				errs = append(errs, fmt.Errorf("unable to close review cycle %d: %w", c.Id, err))
+				continue
+			}
+			log.Printf("Closed review cycle %d %q", c.Id, c.Name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run ticks every interval until ctx is done, like promo.PurgeLoop.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to run review cycles: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package cycles
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/eligibility"
+	"AndersenPromo/internal/promotest"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRules make every junior eligible.
+const testRules = "rules: {junior: [{months_in_grade: 0}]}"
+
+func newStore(t *testing.T) *promo.Memory {
+	return promotest.NewStore(t,
+		promo.User{Name: "Ann", Surname: "Boss", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Cid", Surname: "Jones", Position: 3, Project: "Alpha"},
+		promo.User{Name: "Dan", Surname: "Brown", Position: 2, Project: "Beta"})
+}
+
+func newRouter(t *testing.T, dbc promo.DBConnexion) *mux.Router {
+	rules, err := eligibility.ParseRules([]byte(testRules))
+	require.NoError(t, err)
+	return promotest.NewRouter(NewHandlers(dbc, rules))
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	ctx := context.Background()
+	rules, err := eligibility.ParseRules([]byte(testRules))
+	require.NoError(t, err)
+	m := newStore(t)
+	start := time.Now()
+	now := start
+	s := NewScheduler(m, rules, promo.ClockFunc(func() time.Time { return now }))
+	id, err := m.CreateCycle(promo.Cycle{Name: "Spring", OpensAt: start.Add(time.Hour), ClosesAt: start.Add(48 * time.Hour),
+		Projects: []string{"Alpha"}})
+	require.NoError(t, err)
+	late, err := m.CreateCycle(promo.Cycle{Name: "Late", OpensAt: start.Add(-2 * time.Hour), ClosesAt: start.Add(-time.Hour),
+		Projects: []string{"Alpha"}})
+	require.NoError(t, err)
+
+	t.Run("Check ticking before opening", func(t *testing.T) {
+		require.NoError(t, s.Tick(ctx))
+		c, err := m.Cycle(id)
+		require.NoError(t, err)
+		assert.Equal(t, promo.CycleScheduled, c.Status)
+	})
+	t.Run("Check ticking past closing before opening", func(t *testing.T) {
+		c, err := m.Cycle(late)
+		require.NoError(t, err)
+		assert.Equal(t, promo.CycleClosed, c.Status)
+		rs, err := m.Reviews(late)
+		require.NoError(t, err)
+		for _, rv := range rs {
+			assert.Equal(t, promo.ReviewPending, rv.Status)
+		}
+	})
+	t.Run("Check ticking at opening", func(t *testing.T) {
+		now = start.Add(time.Hour)
+		require.NoError(t, s.Tick(ctx))
+		c, err := m.Cycle(id)
+		require.NoError(t, err)
+		assert.Equal(t, promo.CycleOpen, c.Status)
+		rs, err := m.Reviews(id)
+		require.NoError(t, err)
+		assert.Equal(t, []promo.Review{
+			{CycleId: id, UserId: 1, From: 2, To: 3, Status: promo.ReviewPending},
+			{CycleId: id, UserId: 2, From: 2, To: 3, Status: promo.ReviewPending},
+		}, rs, "only the eligible users of the projects of the cycle are enrolled")
+	})
+	t.Run("Check ticking at closing", func(t *testing.T) {
+		require.NoError(t, m.SetReview(id, 1, promo.ReviewApproved, ""))
+		require.NoError(t, m.SetReview(id, 2, promo.ReviewRejected, ""))
+		now = start.Add(48 * time.Hour)
+		require.NoError(t, s.Tick(ctx))
+		c, err := m.Cycle(id)
+		require.NoError(t, err)
+		assert.Equal(t, promo.CycleClosed, c.Status)
+		u, err := m.GetUser(1)
+		require.NoError(t, err)
+		assert.Equal(t, promo.Grade(3), u.Position)
+		u, err = m.GetUser(2)
+		require.NoError(t, err)
+		assert.Equal(t, promo.Grade(2), u.Position)
+	})
+	t.Run("Check ticking without review cycles", func(t *testing.T) {
+		s := NewScheduler(struct{ promo.DBConnexion }{m}, rules, nil)
+		assert.NoError(t, s.Tick(ctx))
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	const spring = `{"name": " Spring ", "opens_at": "2026-04-01T00:00:00Z", "closes_at": "2026-05-01T00:00:00Z", "projects": ["Alpha"]}`
+
+	t.Run("Check running a cycle by hand", func(t *testing.T) {
+		m := newStore(t)
+		router := newRouter(t, m)
+		w := promotest.Serve(router, http.MethodPost, "/cycles", spring)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c promo.Cycle
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, "Spring", c.Name)
+		assert.Equal(t, promo.CycleScheduled, c.Status)
+
+		w = promotest.Serve(router, http.MethodPatch, "/cycles/1/reviews/1", `{"status": "approved"}`)
+		assert.Equal(t, http.StatusConflict, w.Code, "not open yet")
+		w = promotest.Serve(router, http.MethodPost, "/cycles/1/open", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = promotest.Serve(router, http.MethodPost, "/cycles/1/open", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = promotest.Serve(router, http.MethodPatch, "/cycles/1/reviews/1", `{"status": "approved", "comment": "ready"}`)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = promotest.Serve(router, http.MethodPatch, "/cycles/1/reviews/1", `{"status": "promoted"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		w = promotest.Serve(router, http.MethodPatch, "/cycles/1/reviews/3", `{"status": "approved"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code, "not enrolled")
+
+		w = promotest.Serve(router, http.MethodPost, "/cycles/1/close", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, promo.CycleClosed, c.Status)
+
+		w = promotest.Serve(router, http.MethodGet, "/cycles/1/reviews", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var rs []promo.Review
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&rs))
+		require.Len(t, rs, 2)
+		assert.Equal(t, promo.ReviewPromoted, rs[0].Status)
+		assert.Equal(t, "ready", rs[0].Comment)
+		assert.Equal(t, promo.ReviewPending, rs[1].Status)
+
+		w = promotest.Serve(router, http.MethodGet, "/cycles", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var cs []promo.Cycle
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&cs))
+		assert.Len(t, cs, 1)
+	})
+	t.Run("Check creating a cycle (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(t, newStore(t)), http.MethodPost, "/cycles",
+			`{"opens_at": "2026-05-01T00:00:00Z", "closes_at": "2026-04-01T00:00:00Z", "projects": ["Alpha", "Gamma"]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "name", Rule: promo.RuleRequired, Detail: "is required"},
+			{Field: "closes_at", Rule: promo.RuleInvalid, Detail: "is not after opens_at"},
+			{Field: "projects[1]", Rule: promo.RuleReference, Detail: `no project "Gamma"`},
+		}, p.Errors)
+	})
+	t.Run("Check creating a cycle (YAML)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(t, newStore(t)), http.MethodPost, "/cycles",
+			"name: Spring\nopens_at: 2026-04-01T00:00:00Z\ncloses_at: 2026-05-01T00:00:00Z\nprojects: [Alpha]\n",
+			"Content-Type", "application/yaml", "Accept", "application/yaml")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "name: Spring")
+	})
+	t.Run("Check getting cycles (not acceptable)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(t, newStore(t)), http.MethodGet, "/cycles", "", "Accept", "text/csv")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+	})
+	t.Run("Check creating a cycle (retried)", func(t *testing.T) {
+		m := newStore(t)
+		router := newRouter(t, m)
+		for _, replayed := range []string{"", "true"} {
+			w := promotest.Serve(router, http.MethodPost, "/cycles", spring, promo.IdempotencyKeyHeader, "k1")
+			assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Equal(t, replayed, w.Header().Get(promo.IdempotentReplayedHeader))
+		}
+		cs, err := m.Cycles()
+		require.NoError(t, err)
+		assert.Len(t, cs, 1)
+	})
+	t.Run("Check getting a cycle (absent)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(t, newStore(t)), http.MethodGet, "/cycles/42", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+	})
+	t.Run("Check creating a cycle (no review cycles)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(t, struct{ promo.DBConnexion }{newStore(t)}), http.MethodPost, "/cycles", spring)
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
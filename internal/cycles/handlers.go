@@ -0,0 +1,376 @@
+package cycles
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/eligibility"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the review cycles of a store, enrolling users under
+// rules.
+type Handlers struct {
+	dbc   promo.DBConnexion
+	rules *eligibility.Rules
+}
+
+func NewHandlers(dbc promo.DBConnexion, rules *eligibility.Rules) *Handlers {
+	return &Handlers{dbc, rules}
+}
+
+// Register mounts the review cycle routes on router. POST and PATCH routes
+// honour the Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/cycles", promo.Idempotent(h.dbc, h.CreateCycle)).Methods(http.MethodPost)
+	router.HandleFunc("/cycles", h.GetCycles).Methods(http.MethodGet)
+	router.HandleFunc("/cycles/{id:[0-9]+}", h.GetCycle).Methods(http.MethodGet)
+	router.HandleFunc("/cycles/{id:[0-9]+}/open", promo.Idempotent(h.dbc, h.OpenCycle)).Methods(http.MethodPost)
+	router.HandleFunc("/cycles/{id:[0-9]+}/close", promo.Idempotent(h.dbc, h.CloseCycle)).Methods(http.MethodPost)
+	router.HandleFunc("/cycles/{id:[0-9]+}/reviews", h.GetReviews).Methods(http.MethodGet)
+	router.HandleFunc("/cycles/{id:[0-9]+}/reviews/{user_id:[0-9]+}", promo.Idempotent(h.dbc, h.SetReview)).Methods(http.MethodPatch)
+}
+
+// CycleRequest is a cycle to schedule.
+type CycleRequest struct {
+	Name     string    `json:"name" yaml:"name" validate:"required,max=100"`
+	OpensAt  time.Time `json:"opens_at" yaml:"opens_at" validate:"required"`
+	ClosesAt time.Time `json:"closes_at" yaml:"closes_at" validate:"required"`
+	Projects []string  `json:"projects" yaml:"projects"`
+}
+
+// CheckFields checks that c closes after it opens and names its projects
+// once each.
+func (c *CycleRequest) CheckFields() promo.ValidationErrors {
+	var errs promo.ValidationErrors
+	if !c.OpensAt.IsZero() && !c.ClosesAt.IsZero() && !c.ClosesAt.After(c.OpensAt) {
+		errs = append(errs, promo.FieldError{Field: "closes_at", Rule: promo.RuleInvalid, Detail: "is not after opens_at"})
+	}
+	if len(c.Projects) == 0 {
+		errs = append(errs, promo.FieldError{Field: "projects", Rule: promo.RuleRequired, Detail: "is required"})
+	}
+	seen := make(map[string]bool)
+	for i, p := range c.Projects {
+		if p == "" || seen[p] {
+			errs = append(errs, promo.FieldError{Field: fmt.Sprintf("projects[%d]", i), Rule: promo.RuleInvalid,
+				Detail: "is empty or repeated"})
+		}
+		seen[p] = true
+	}
+	return errs
+}
+
+// ReviewRequest is the outcome of a review so far.
+type ReviewRequest struct {
+	Status  promo.ReviewStatus `json:"status" yaml:"status"`
+	Comment string             `json:"comment" yaml:"comment"`
+}
+
+// CheckFields checks that r sets a status a reviewer may set.
+func (r *ReviewRequest) CheckFields() promo.ValidationErrors {
+	switch r.Status {
+	case promo.ReviewPending, promo.ReviewApproved, promo.ReviewRejected:
+		return nil
+	}
+	return promo.ValidationErrors{{Field: "status", Rule: promo.RuleEnum, Detail: "is not one of pending, approved and rejected"}}
+}
+
+// store is the CycleStore of h, answering 501 itself when there is none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.CycleStore, bool) {
+	return promo.Capability[promo.CycleStore](w, h.dbc, ErrNotSupported)
+}
+
+// validate checks c, the projects against those of the store when it keeps
+// them.
+func (h *Handlers) validate(c *CycleRequest) error {
+	c.Name = strings.TrimSpace(c.Name)
+	var errs promo.ValidationErrors
+	if err := promo.NewValidator(h.dbc).Validate(c, false); err != nil && !errors.As(err, &errs) {
+		return err
+	}
+	ps, keeps := h.dbc.(promo.ProjectStore)
+	if !keeps {
+		return errs.Err()
+	}
+	seen := make(map[string]bool)
+	for i, p := range c.Projects {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		ok, err := ps.ProjectExists(p)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			errs = append(errs, promo.FieldError{Field: fmt.Sprintf("projects[%d]", i), Rule: promo.RuleReference,
+				Detail: fmt.Sprintf("no project %q", p)})
+		}
+	}
+	return errs.Err()
+}
+
+// CreateCycle	 godoc
+//
+//	@Summary		Schedule review cycle
+//	@Description	schedule a promotion review cycle of some projects, opened and closed on time by the scheduler
+//	@Tags			cycles
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			cycle	body		cycles.CycleRequest	true	"Cycle"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201		{object}	promo.Cycle
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		415		{object}	promo.Problem
+//	@Failure		422		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/cycles [post]
+func (h *Handlers) CreateCycle(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var req CycleRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	if err := h.validate(&req); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	id, err := cs.CreateCycle(promo.Cycle{Name: req.Name, OpensAt: req.OpensAt, ClosesAt: req.ClosesAt, Projects: req.Projects})
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	c, err := cs.Cycle(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, c)
+}
+
+// GetCycles	 godoc
+//
+//	@Summary		Review cycles
+//	@Description	every review cycle, by id
+//	@Tags			cycles
+//	@Produce		json,application/yaml
+//	@Success		200	{array}		promo.Cycle
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/cycles [get]
+func (h *Handlers) GetCycles(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	all, err := cs.Cycles()
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, all)
+}
+
+// GetCycle	 godoc
+//
+//	@Summary		Review cycle
+//	@Tags			cycles
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Cycle ID"
+//	@Success		200	{object}	promo.Cycle
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/cycles/{id} [get]
+func (h *Handlers) GetCycle(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	c, err := cs.Cycle(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, c)
+}
+
+// OpenCycle	 godoc
+//
+//	@Summary		Open review cycle
+//	@Description	open a scheduled cycle ahead of time, enrolling the users of its projects eligible now
+//	@Tags			cycles
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Cycle ID"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200	{object}	promo.Cycle
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		409	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/cycles/{id}/open [post]
+func (h *Handlers) OpenCycle(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, func(id int) error {
+		return Open(r.Context(), h.dbc, h.rules, id, time.Now())
+	})
+}
+
+// CloseCycle	 godoc
+//
+//	@Summary		Close review cycle
+//	@Description	close an open cycle ahead of time, promoting the approved users still in the grade they were reviewed in
+//	@Tags			cycles
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Cycle ID"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200	{object}	promo.Cycle
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		409	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/cycles/{id}/close [post]
+func (h *Handlers) CloseCycle(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, func(id int) error {
+		return Close(h.dbc, id)
+	})
+}
+
+// transition applies move to the cycle of the path and answers it after.
+func (h *Handlers) transition(w http.ResponseWriter, r *http.Request, move func(id int) error) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := move(id); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	c, err := cs.Cycle(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, c)
+}
+
+// GetReviews	 godoc
+//
+//	@Summary		Cycle reviews
+//	@Description	the reviews of the users enrolled in a cycle, by user
+//	@Tags			cycles
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Cycle ID"
+//	@Success		200	{array}		promo.Review
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/cycles/{id}/reviews [get]
+func (h *Handlers) GetReviews(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	rs, err := cs.Reviews(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, rs)
+}
+
+// SetReview	 godoc
+//
+//	@Summary		Review user
+//	@Description	set the status, pending, approved or rejected, and comment of the review of a user in an open cycle
+//	@Tags			cycles
+//	@Accept			json,application/yaml
+//	@Param			id		path	int						true	"Cycle ID"
+//	@Param			user_id	path	int						true	"User ID"
+//	@Param			review	body	cycles.ReviewRequest	true	"Review"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		409	{object}	promo.Problem
+//	@Failure		415	{object}	promo.Problem
+//	@Failure		422	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/cycles/{id}/reviews/{user_id} [patch]
+func (h *Handlers) SetReview(w http.ResponseWriter, r *http.Request) {
+	cs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	user, ok := promo.PathID(w, r, "user_id")
+	if !ok {
+		return
+	}
+	var req ReviewRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	if err := promo.NewValidator(h.dbc).Validate(&req, false); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	if err := cs.SetReview(id, user, req.Status, strings.TrimSpace(req.Comment)); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,220 @@
+package promo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// userRef is a column holding a user id, moved over when users are merged.
+// Rows of the user merged away that would clash with a row of the user kept
+// are dropped instead: those matching one on the other columns of key,
+// unique with column, and those with the user kept in pair, a column that
+// cannot hold the same user as column.
+type userRef struct {
+	table, column string
+	key           []string
+	pair          string
+}
+
+// userRefs lists every column referring to usr(id) besides usr itself,
+// whose manager_id MergeUsers handles on its own.
+var userRefs = []userRef{
+	{table: "review", column: "user_id", key: []string{"cycle_id"}},
+	{table: "assessment", column: "user_id", key: []string{"cycle_id", "reviewer_id"}, pair: "reviewer_id"},
+	{table: "assessment", column: "reviewer_id", key: []string{"cycle_id", "user_id"}, pair: "user_id"},
+	{table: "feedback", column: "user_id"},
+	{table: "feedback", column: "manager_id"},
+	{table: "feedback_respondent", column: "respondent_id", key: []string{"feedback_id"}},
+	{table: "user_skill", column: "user_id", key: []string{"competency_id"}},
+	{table: "mentorship", column: "mentor_id", pair: "mentee_id"},
+	{table: "mentorship", column: "mentee_id", pair: "mentor_id"},
+	{table: "goal", column: "user_id"},
+	{table: "certification", column: "user_id"},
+}
+
+// mergeQueries are the statements moving the rows referring to user $2
+// through ref over to user $1, with $n parameters.
+func (ref userRef) mergeQueries() []string {
+	var qs []string
+	if ref.pair != "" {
+		qs = append(qs, fmt.Sprintf("DELETE FROM %s WHERE %s=$2 AND %s=$1", ref.table, ref.column, ref.pair))
+	}
+	if len(ref.key) > 0 {
+		cond := []string{fmt.Sprintf("o.%s=$1", ref.column)}
+		for _, k := range ref.key {
+			cond = append(cond, fmt.Sprintf("(o.%[1]s=%[2]s.%[1]s OR o.%[1]s IS NULL AND %[2]s.%[1]s IS NULL)", k, ref.table))
+		}
+		qs = append(qs, fmt.Sprintf("DELETE FROM %s WHERE %s=$2 AND EXISTS (SELECT 1 FROM %s o WHERE %s)",
+			ref.table, ref.column, ref.table, strings.Join(cond, " AND ")))
+	}
+	return append(qs, fmt.Sprintf("UPDATE %s SET %s=$1 WHERE %s=$2", ref.table, ref.column, ref.column))
+}
+
+const defaultDuplicateDistance = 2
+
+// DuplicatePair is two live users whose full names are within Distance
+// edits of each other.
+type DuplicatePair struct {
+	First    User `json:"first" yaml:"first"`
+	Second   User `json:"second" yaml:"second"`
+	Distance int  `json:"distance" yaml:"distance"`
+}
+
+func fullName(u User) string {
+	return strings.ToLower(u.Name + " " + u.Surname)
+}
+
+// nameDistance is the edit distance between the names of a and b, also
+// trying with name and surname swapped.
+func nameDistance(a, b User) int {
+	d := levenshtein(fullName(a), fullName(b))
+	if s := levenshtein(fullName(a), strings.ToLower(b.Surname+" "+b.Name)); s < d {
+		d = s
+	}
+	return d
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// findDuplicates pairs the live users whose names are at most maxDistance
+// edits apart, closest pairs first.
+func findDuplicates(dbc DBConnexion, maxDistance int) ([]DuplicatePair, error) {
+	var us []User
+	err := dbc.EachUser(UserFilter{}, func(u User) error {
+		us = append(us, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]DuplicatePair, 0)
+	for i := range us {
+		li := utf8.RuneCountInString(fullName(us[i]))
+		for j := i + 1; j < len(us); j++ {
+			// The distance is at least the difference in length.
+			lj := utf8.RuneCountInString(fullName(us[j]))
+			if li-lj > maxDistance || lj-li > maxDistance {
+				continue
+			}
+			if d := nameDistance(us[i], us[j]); d <= maxDistance {
+				pairs = append(pairs, DuplicatePair{First: us[i], Second: us[j], Distance: d})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Distance < pairs[j].Distance })
+	return pairs, nil
+}
+
+// Duplicates	 godoc
+//
+//	@Summary		Duplicate report
+//	@Description	pair the live users whose names look alike
+//	@Tags			users
+//	@Produce		json,application/yaml
+//	@Param			distance	query		int	false	"Most edits between two names, 2 by default"
+//	@Success		200			{array}		DuplicatePair
+//	@Failure		400			{object}	Problem
+//	@Failure		406			{object}	Problem
+//	@Failure		500			{object}	Problem
+//	@Router			/users/duplicates [get]
+func (h *Handlers) Duplicates(w http.ResponseWriter, r *http.Request) {
+	distance := defaultDuplicateDistance
+	if d := r.URL.Query().Get("distance"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			Error(w, fmt.Sprintf("illegal distance %q", d), http.StatusBadRequest)
+			return
+		}
+		distance = n
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	pairs, err := findDuplicates(h.dbc, distance)
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	Respond(w, c, http.StatusOK, pairs)
+}
+
+// MergeUsers	 godoc
+//
+//	@Summary		Merge users
+//	@Description	fold a duplicate into user id, which keeps its fields, takes those it lacks and inherits its history, reports and records; the duplicate is deleted
+//	@Tags			users
+//	@Produce		json,application/yaml
+//	@Param			id		path		int	true	"User ID to keep"
+//	@Param			from	query		int	true	"User ID to fold into it"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200		{object}	User
+//	@Failure		400		{object}	Problem
+//	@Failure		404		{object}	Problem
+//	@Failure		406		{object}	Problem
+//	@Failure		409		{object}	Problem
+//	@Failure		422		{object}	Problem
+//	@Failure		500		{object}	Problem
+//	@Router			/users/{id}/merge [post]
+func (h *Handlers) MergeUsers(w http.ResponseWriter, r *http.Request) {
+	into, ok := PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		Error(w, "from must be a user id", http.StatusBadRequest)
+		return
+	}
+	if from == into {
+		Error(w, "unable to merge a user into itself", http.StatusBadRequest)
+		return
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	if err = h.dbc.MergeUsers(into, from); err != nil {
+		DBError(w, err)
+		return
+	}
+	u, err := h.dbc.GetUser(into)
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	Respond(w, c, http.StatusOK, u)
+}
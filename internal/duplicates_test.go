@@ -0,0 +1,192 @@
+package promo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"and", "", 3},
+		{"smith", "smyth", 1},
+		{"kitten", "sitting", 3},
+		{"jürgen", "jurgen", 1},
+	} {
+		assert.Equal(t, c.d, levenshtein(c.a, c.b), "%s/%s", c.a, c.b)
+		assert.Equal(t, c.d, levenshtein(c.b, c.a), "%s/%s", c.b, c.a)
+	}
+	assert.Equal(t, 0, nameDistance(User{Name: "Bob", Surname: "Smith"}, User{Name: "smith", Surname: "bob"}))
+}
+
+func TestHandlers_Duplicates(t *testing.T) {
+	newHandlers := func(t *testing.T) *Handlers {
+		m := NewMemory()
+		require.NoError(t, m.AddUsers([]User{
+			{Name: "Bob", Surname: "Smith", Position: junior},
+			{Name: "And", Surname: "Ersen", Position: middle},
+			{Name: "Bob", Surname: "Smyth", Position: junior},
+			{Name: "Rob", Surname: "Smyth", Position: senior},
+		}))
+		return &Handlers{m}
+	}
+	t.Run("Check duplicate report", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/duplicates", nil)
+		w := httptest.NewRecorder()
+		h.Duplicates(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var pairs []DuplicatePair
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&pairs))
+		got := make([][3]int, len(pairs))
+		for i, p := range pairs {
+			got[i] = [3]int{p.First.Id, p.Second.Id, p.Distance}
+		}
+		assert.Equal(t, [][3]int{{1, 3, 1}, {3, 4, 1}, {1, 4, 2}}, got)
+	})
+	t.Run("Check duplicate report (exact names only)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/duplicates?distance=0", nil)
+		w := httptest.NewRecorder()
+		h.Duplicates(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "[]", w.Body.String())
+	})
+	t.Run("Check duplicate report (wrong distance)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/duplicates?distance=-1", nil)
+		w := httptest.NewRecorder()
+		h.Duplicates(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("Check merging users", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPost, "/users/1/merge?from=3", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.MergeUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id":1,"name":"Bob","surname":"Smith","position":2,"project":""}`, w.Body.String())
+		_, err := h.dbc.GetUser(3)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("Check merging users (into itself)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPost, "/users/1/merge?from=1", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.MergeUsers(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("Check merging users (absent index)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPost, "/users/1/merge?from=42", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.MergeUsers(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUserRef_mergeQueries(t *testing.T) {
+	assert.Equal(t, []string{"UPDATE goal SET user_id=$1 WHERE user_id=$2"},
+		userRef{table: "goal", column: "user_id"}.mergeQueries())
+	assert.Equal(t, []string{
+		"DELETE FROM mentorship WHERE mentor_id=$2 AND mentee_id=$1",
+		"UPDATE mentorship SET mentor_id=$1 WHERE mentor_id=$2",
+	}, userRef{table: "mentorship", column: "mentor_id", pair: "mentee_id"}.mergeQueries())
+	assert.Equal(t, []string{
+		"DELETE FROM review WHERE user_id=$2 AND EXISTS (SELECT 1 FROM review o WHERE o.user_id=$1 AND " +
+			"(o.cycle_id=review.cycle_id OR o.cycle_id IS NULL AND review.cycle_id IS NULL))",
+		"UPDATE review SET user_id=$1 WHERE user_id=$2",
+	}, userRef{table: "review", column: "user_id", key: []string{"cycle_id"}}.mergeQueries())
+}
+
+func TestRegistry_MergeUsers(t *testing.T) {
+	mergeRows := func() *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"project", "email", "employee_number", "hire_date", "location", "manager_id"})
+	}
+	t.Run("Check merging users (no errors)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+
+		email, hired, manager := "bob@example.com", "2020-01-13", 1
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE usr u SET email=NULL, employee_number=NULL, deleted_at=now\\(\\)").WithArgs(3).
+			WillReturnRows(mergeRows().AddRow("Beta", &email, (*string)(nil), &hired, (*string)(nil), &manager))
+		mock.ExpectExec("UPDATE usr SET project=").
+			WithArgs(1, "Beta", &email, (*string)(nil), &hired, (*string)(nil), (*int)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE usr SET manager_id=").WithArgs(1, 3, (*int)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectQuery("WITH RECURSIVE chain").WithArgs(1).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec("UPDATE usr_history SET id=\\$1").WithArgs(1, 3).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		for _, ref := range userRefs {
+			for _, q := range ref.mergeQueries() {
+				mock.ExpectExec(regexp.QuoteMeta(q)).WithArgs(1, 3).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			}
+		}
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		assert.NoError(t, r.MergeUsers(1, 3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Check merging users (absent target)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE usr u SET").WithArgs(3).
+			WillReturnRows(mergeRows().AddRow("", (*string)(nil), (*string)(nil), (*string)(nil), (*string)(nil), (*int)(nil)))
+		mock.ExpectExec("UPDATE usr SET project=").
+			WithArgs(42, "", (*string)(nil), (*string)(nil), (*string)(nil), (*string)(nil), (*int)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+		assert.ErrorIs(t, r.MergeUsers(42, 3), ErrNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Check merging users (manager cycle)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+
+		manager := 7
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE usr u SET").WithArgs(3).
+			WillReturnRows(mergeRows().AddRow("", (*string)(nil), (*string)(nil), (*string)(nil), (*string)(nil), &manager))
+		mock.ExpectExec("UPDATE usr SET project=").
+			WithArgs(1, "", (*string)(nil), (*string)(nil), (*string)(nil), (*string)(nil), &manager).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE usr SET manager_id=").WithArgs(1, 3, &manager).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectQuery("WITH RECURSIVE chain").WithArgs(1).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+		assert.ErrorIs(t, r.MergeUsers(1, 3), ErrInvalidManager)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,111 @@
+package eligibility
+
+import (
+	promo "AndersenPromo/internal"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// months reads a number of months, positive or 0.
+func months(param *yaml.Node) (int, error) {
+	var n int
+	if err := param.Decode(&n); err != nil {
+		return 0, fmt.Errorf("want a number of months: %w", err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("want a number of months, not %d", n)
+	}
+	return n, nil
+}
+
+// fullMonths counts the whole months from from to to, by calendar day.
+func fullMonths(from, to time.Time) int {
+	n := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		n--
+	}
+	return n
+}
+
+// monthsInGrade wants that many full months since the user got their
+// current grade.
+type monthsInGrade int
+
+func (m monthsInGrade) Check(_ context.Context, s *Subject) (bool, string, error) {
+	if s.History == nil {
+		return false, "time in grade is not recorded by this store", nil
+	}
+	since, known := gradeSince(s.History, s.User.Position)
+	if !known {
+		return false, fmt.Sprintf("in %s since before records began, time in grade unknown", s.User.Position), nil
+	}
+	n := fullMonths(since, s.Now)
+	return n >= int(m), fmt.Sprintf("%d of %d months in %s, since %s", n, int(m), s.User.Position,
+		since.Format(promo.DateLayout)), nil
+}
+
+// gradeSince is when the user got grade g, by the versions of its history
+// that follow each other with that grade up to the last one. known is false
+// when that predates the history.
+func gradeSince(vs []promo.Version, g promo.Grade) (since time.Time, known bool) {
+	known = false
+	for i := len(vs) - 1; i >= 0 && vs[i].Position == g; i-- {
+		if vs[i].ValidFrom == nil {
+			return time.Time{}, false
+		}
+		since, known = *vs[i].ValidFrom, true
+	}
+	return since, known
+}
+
+// monthsEmployed wants that many full months since the hire date.
+type monthsEmployed int
+
+func (m monthsEmployed) Check(_ context.Context, s *Subject) (bool, string, error) {
+	if s.User.HireDate == "" {
+		return false, "hire date unknown", nil
+	}
+	hired, err := time.Parse(promo.DateLayout, s.User.HireDate)
+	if err != nil {
+		return false, "", err
+	}
+	n := fullMonths(hired, s.Now)
+	return n >= int(m), fmt.Sprintf("%d of %d months since hired on %s", n, int(m), s.User.HireDate), nil
+}
+
+// minRating wants a mean rating of at least that much over the calibrated
+// assessments of the user in the latest cycle having any.
+type minRating float64
+
+func (m minRating) Check(_ context.Context, s *Subject) (bool, string, error) {
+	as, ok := s.Store.(promo.AssessmentStore)
+	if !ok {
+		return false, "assessments are not recorded by this store", nil
+	}
+	list, err := as.Assessments(promo.AssessmentFilter{UserId: s.User.Id})
+	if err != nil {
+		return false, "", err
+	}
+	cycle, sum, n := 0, 0, 0
+	for _, a := range list {
+		switch {
+		case !a.Locked || a.CycleId < cycle:
+			continue
+		case a.CycleId > cycle:
+			cycle, sum, n = a.CycleId, 0, 0
+		}
+		sum += a.Rating
+		n++
+	}
+	if n == 0 {
+		return false, "no calibrated assessment", nil
+	}
+	mean := float64(sum) / float64(n)
+	rated := strconv.FormatFloat(math.Round(mean*100)/100, 'f', -1, 64)
+	return mean >= float64(m), fmt.Sprintf("rated %s of %g in review cycle %d", rated, float64(m), cycle), nil
+}
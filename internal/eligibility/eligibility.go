@@ -0,0 +1,274 @@
+// Package eligibility tells which users meet the promotion policy for their
+// next grade, and why or why not.
+//
+// The policy is a set of rules in YAML, one per grade, each listing the
+// conditions a user of that grade has to meet, see rules.yaml for the
+// default one:
+//
+//	rules:
+//	  junior:
+//	    - months_in_grade: 12
+//
+// Time in grade is read from the history of stores implementing
+// promo.HistoryStore, ratings from the calibrated assessments of stores
+// implementing promo.AssessmentStore; against other stores they are
+// unknown and fail. The completion of the goals of users towards their next
+// grade is shown alongside for stores implementing promo.GoalStore; it is
+// not a condition.
+package eligibility
+
+import (
+	promo "AndersenPromo/internal"
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Check is the outcome of a condition for a user.
+type Check struct {
+	Condition string `json:"condition" yaml:"condition"`
+	Passed    bool   `json:"passed" yaml:"passed"`
+	Detail    string `json:"detail" yaml:"detail"`
+}
+
+// Eligibility tells whether User can be promoted to Next, the grade above
+// theirs, with the checks of the conditions for it and the completion of
+// their goals towards it, if any. Eligible is false when any check failed.
+type Eligibility struct {
+	User     promo.User      `json:"user" yaml:"user"`
+	Grade    string          `json:"grade" yaml:"grade"`
+	Next     string          `json:"next,omitempty" yaml:"next,omitempty"`
+	Eligible bool            `json:"eligible" yaml:"eligible"`
+	Checks   []Check         `json:"checks" yaml:"checks"`
+	Goals    *GoalCompletion `json:"goals,omitempty" yaml:"goals,omitempty"`
+}
+
+// GoalCompletion counts the goals of a user towards a grade, dropped ones
+// left out: how many are done and how many are overdue.
+type GoalCompletion struct {
+	Total   int `json:"total" yaml:"total"`
+	Done    int `json:"done" yaml:"done"`
+	Overdue int `json:"overdue" yaml:"overdue"`
+}
+
+// goalCompletion is the completion of the goals of u towards next at now,
+// nil when there are none or dbc keeps no goals.
+func goalCompletion(dbc promo.DBConnexion, u promo.User, next promo.Grade, now time.Time) (*GoalCompletion, error) {
+	gs, ok := dbc.(promo.GoalStore)
+	if !ok {
+		return nil, nil
+	}
+	list, err := gs.Goals(promo.GoalFilter{UserId: u.Id, TargetGrade: next})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the goals of user %d: %w", u.Id, err)
+	}
+	var c GoalCompletion
+	today := now.Format(promo.DateLayout)
+	for _, g := range list {
+		switch {
+		case g.Status == promo.GoalDropped:
+			continue
+		case g.Status == promo.GoalDone:
+			c.Done++
+		case g.Overdue(today):
+			c.Overdue++
+		}
+		c.Total++
+	}
+	if c.Total == 0 {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+// Subject is what conditions are checked against.
+type Subject struct {
+	User promo.User
+	Now  time.Time
+	// History lists the versions of User, oldest first, nil when the store
+	// keeps none.
+	History []promo.Version
+	Store   promo.DBConnexion
+}
+
+// Condition is a requirement of a rule.
+type Condition interface {
+	// Check tells whether s meets the condition, detail explaining why in
+	// words.
+	Check(ctx context.Context, s *Subject) (passed bool, detail string, err error)
+}
+
+// kinds build the condition of each name from its YAML parameter.
+var kinds = map[string]func(param *yaml.Node) (Condition, error){
+	"months_in_grade": func(param *yaml.Node) (Condition, error) {
+		n, err := months(param)
+		return monthsInGrade(n), err
+	},
+	"months_employed": func(param *yaml.Node) (Condition, error) {
+		n, err := months(param)
+		return monthsEmployed(n), err
+	},
+	"min_rating": func(param *yaml.Node) (Condition, error) {
+		var n float64
+		if err := param.Decode(&n); err != nil || n < 0 {
+			return nil, fmt.Errorf("want a rating, positive or 0")
+		}
+		return minRating(n), nil
+	},
+}
+
+// condition is a Condition of a rule with the name it was given by.
+type condition struct {
+	name string
+	Condition
+}
+
+// Rules are the conditions for promoting users of each grade.
+type Rules struct {
+	grades map[promo.Grade][]condition
+}
+
+//go:embed rules.yaml
+var defaultRules []byte
+
+// DefaultRules is the policy of rules.yaml.
+func DefaultRules() *Rules {
+	r, err := ParseRules(defaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadRules reads the rules at path, DefaultRules when empty.
+func LoadRules(path string) (*Rules, error) {
+	if path == "" {
+		return DefaultRules(), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read rules: %w", err)
+	}
+	r, err := ParseRules(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load rules %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// ParseRules reads rules from YAML. Every condition is a mapping of a
+// single kind to its parameter.
+func ParseRules(b []byte) (*Rules, error) {
+	var doc struct {
+		Rules map[string][]map[string]yaml.Node `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	r := &Rules{grades: make(map[promo.Grade][]condition)}
+	for name, conds := range doc.Rules {
+		g, err := promo.ParseGrade(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := g.Next(); !ok {
+			return nil, fmt.Errorf("%s is the top grade, it has no promotion rule", g)
+		}
+		for _, c := range conds {
+			if len(c) != 1 {
+				return nil, fmt.Errorf("rule of %s: a condition has exactly one kind, not %d", g, len(c))
+			}
+			for kind, param := range c {
+				build, ok := kinds[kind]
+				if !ok {
+					return nil, fmt.Errorf("rule of %s: unknown condition %q", g, kind)
+				}
+				cond, err := build(&param)
+				if err != nil {
+					return nil, fmt.Errorf("rule of %s: %s: %w", g, kind, err)
+				}
+				r.grades[g] = append(r.grades[g], condition{kind, cond})
+			}
+		}
+	}
+	return r, nil
+}
+
+// Evaluate checks u against the rule of their grade at now.
+func (r *Rules) Evaluate(ctx context.Context, dbc promo.DBConnexion, u promo.User, now time.Time) (*Eligibility, error) {
+	e := &Eligibility{User: u, Grade: u.Position.String(), Checks: []Check{}}
+	next, ok := u.Position.Next()
+	if !ok {
+		e.Checks = append(e.Checks, Check{Condition: "next_grade", Detail: fmt.Sprintf("%s is the top grade", u.Position)})
+		return e, nil
+	}
+	e.Next = next.String()
+	goals, err := goalCompletion(dbc, u, next, now)
+	if err != nil {
+		return nil, err
+	}
+	e.Goals = goals
+	conds, ok := r.grades[u.Position]
+	if !ok {
+		e.Checks = append(e.Checks, Check{Condition: "rule", Detail: fmt.Sprintf("no promotion rule for %s", u.Position)})
+		return e, nil
+	}
+	s := &Subject{User: u, Now: now, Store: dbc}
+	if hs, ok := dbc.(promo.HistoryStore); ok {
+		vs, err := hs.History(u.Id)
+		if err != nil {
+			return nil, err
+		}
+		s.History = vs
+	}
+	e.Eligible = true
+	for _, c := range conds {
+		passed, detail, err := c.Check(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check %s for user %d: %w", c.name, u.Id, err)
+		}
+		e.Checks = append(e.Checks, Check{Condition: c.name, Passed: passed, Detail: detail})
+		e.Eligible = e.Eligible && passed
+	}
+	return e, nil
+}
+
+// User is the eligibility of user id at now.
+func (r *Rules) User(ctx context.Context, dbc promo.DBConnexion, id int, now time.Time) (*Eligibility, error) {
+	u, err := dbc.GetUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.Evaluate(ctx, dbc, *u, now)
+}
+
+// Eligible lists the live users of project (everyone when empty) eligible at
+// now, by id.
+func (r *Rules) Eligible(ctx context.Context, dbc promo.DBConnexion, project string, now time.Time) ([]Eligibility, error) {
+	var us []promo.User
+	err := dbc.EachUser(promo.UserFilter{Project: project}, func(u promo.User) error {
+		us = append(us, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Eligibility, 0)
+	for _, u := range us {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		e, err := r.Evaluate(ctx, dbc, u, now)
+		if err != nil {
+			return nil, err
+		}
+		if e.Eligible {
+			out = append(out, *e)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,236 @@
+package eligibility
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/promotest"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRules = `
+rules:
+  junior:
+    - months_in_grade: 12
+  middle:
+    - months_in_grade: 18
+    - months_employed: 24
+`
+
+func TestParseRules(t *testing.T) {
+	assert.NotNil(t, DefaultRules())
+	for name, doc := range map[string]string{
+		"unknown grade":     "rules: {lead: [{months_in_grade: 1}]}",
+		"top grade":         "rules: {senior: [{months_in_grade: 1}]}",
+		"unknown condition": "rules: {junior: [{assessment: passed}]}",
+		"two kinds":         "rules: {junior: [{months_in_grade: 1, months_employed: 2}]}",
+		"not months":        "rules: {junior: [{months_in_grade: a year}]}",
+		"negative months":   "rules: {junior: [{months_in_grade: -1}]}",
+		"not a rating":      "rules: {junior: [{min_rating: good}]}",
+		"not YAML":          "rules: [",
+	} {
+		t.Run("Check parsing rules ("+name+")", func(t *testing.T) {
+			_, err := ParseRules([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFullMonths(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	assert.Equal(t, 0, fullMonths(day(2026, 1, 31), day(2026, 2, 28)))
+	assert.Equal(t, 1, fullMonths(day(2026, 1, 15), day(2026, 2, 15)))
+	assert.Equal(t, 13, fullMonths(day(2025, 1, 15), day(2026, 3, 1)))
+}
+
+func TestGradeSince(t *testing.T) {
+	t1, t2 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	version := func(g promo.Grade, from *time.Time) promo.Version {
+		return promo.Version{User: promo.User{Position: g}, ValidFrom: from}
+	}
+	since, known := gradeSince([]promo.Version{version(2, &t1), version(3, &t2), version(3, nil)}, 3)
+	assert.False(t, known, "a version before the history is of unknown start")
+	since, known = gradeSince([]promo.Version{version(2, nil), version(3, &t1), version(3, &t2)}, 3)
+	assert.True(t, known)
+	assert.Equal(t, t1, since)
+}
+
+func TestMinRating(t *testing.T) {
+	ctx := context.Background()
+	m := promo.NewMemory()
+	require.NoError(t, m.AddUsers([]promo.User{
+		{Name: "Ann", Surname: "Boss", Position: 2, Project: "Alpha"},
+		{Name: "Bob", Surname: "Smith", Position: 3, Project: "Alpha"},
+	}))
+	opens := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"Spring", "Autumn"} {
+		_, err := m.CreateCycle(promo.Cycle{Name: name, OpensAt: opens, ClosesAt: opens.AddDate(0, 1, 0), Projects: []string{"Alpha"}})
+		require.NoError(t, err)
+	}
+	for _, a := range []promo.Assessment{
+		{CycleId: 1, UserId: 1, Rating: 2},
+		{CycleId: 2, UserId: 1, Rating: 4},
+		{CycleId: 2, UserId: 1, ReviewerId: 2, Rating: 5},
+	} {
+		_, err := m.AddAssessment(a)
+		require.NoError(t, err)
+	}
+	s := &Subject{User: promo.User{Id: 1}, Store: m}
+	passed, detail, err := minRating(4).Check(ctx, s)
+	require.NoError(t, err)
+	assert.False(t, passed)
+	assert.Equal(t, "no calibrated assessment", detail)
+
+	require.NoError(t, m.Calibrate(1, "Alpha"))
+	passed, detail, err = minRating(4).Check(ctx, s)
+	require.NoError(t, err)
+	assert.False(t, passed)
+	assert.Equal(t, "rated 2 of 4 in review cycle 1", detail)
+
+	require.NoError(t, m.Calibrate(2, "Alpha"))
+	passed, detail, err = minRating(4.5).Check(ctx, s)
+	require.NoError(t, err)
+	assert.True(t, passed, "the latest calibrated cycle counts")
+	assert.Equal(t, "rated 4.5 of 4.5 in review cycle 2", detail)
+
+	passed, detail, err = minRating(1).Check(ctx, &Subject{User: promo.User{Id: 1}, Store: struct{ promo.DBConnexion }{m}})
+	require.NoError(t, err)
+	assert.False(t, passed)
+	assert.Equal(t, "assessments are not recorded by this store", detail)
+}
+
+func TestGoalCompletion(t *testing.T) {
+	m := promo.NewMemory()
+	require.NoError(t, m.AddUsers([]promo.User{{Name: "Ann", Surname: "Boss", Position: 2}}))
+	u, err := m.GetUser(1)
+	require.NoError(t, err)
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	c, err := goalCompletion(m, *u, 3, now)
+	require.NoError(t, err)
+	assert.Nil(t, c, "no goals")
+
+	for _, g := range []promo.Goal{
+		{UserId: 1, Title: "Lead", TargetGrade: 3, Status: promo.GoalDone, DueDate: "2026-03-01"},
+		{UserId: 1, Title: "Test", TargetGrade: 3, Status: promo.GoalInProgress, DueDate: "2026-03-31"},
+		{UserId: 1, Title: "Talk", TargetGrade: 3, Status: promo.GoalOpen, DueDate: "2026-04-01"},
+		{UserId: 1, Title: "Blog", TargetGrade: 3, Status: promo.GoalDropped, DueDate: "2026-01-01"},
+		{UserId: 1, Title: "Hire", TargetGrade: 4, Status: promo.GoalOpen, DueDate: "2026-01-01"},
+	} {
+		_, err = m.AddGoal(g)
+		require.NoError(t, err)
+	}
+	c, err = goalCompletion(m, *u, 3, now)
+	require.NoError(t, err)
+	assert.Equal(t, &GoalCompletion{Total: 3, Done: 1, Overdue: 1}, c)
+
+	c, err = goalCompletion(struct{ promo.DBConnexion }{m}, *u, 3, now)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestRules_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	rules, err := ParseRules([]byte(testRules))
+	require.NoError(t, err)
+	m := promo.NewMemory()
+	for _, u := range []promo.User{
+		{Name: "Ann", Surname: "Boss", Position: 4},
+		{Name: "Bob", Surname: "Smith", Position: 2, HireDate: "2020-01-10"},
+		{Name: "Cid", Surname: "Jones", Position: 2},
+		{Name: "Dan", Surname: "Brown", Position: 1},
+	} {
+		_, err = m.CreateUser(u, false)
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.UpdateUser(3, map[string]string{"position": "middle"}))
+	later := time.Now().AddDate(1, 1, 0)
+
+	e, err := rules.User(ctx, m, 2, later)
+	require.NoError(t, err)
+	assert.True(t, e.Eligible)
+	assert.Equal(t, "middle", e.Next)
+	require.Len(t, e.Checks, 1)
+	assert.Equal(t, "months_in_grade", e.Checks[0].Condition)
+	assert.Regexp(t, `^13 of 12 months in junior, since \d{4}-\d\d-\d\d$`, e.Checks[0].Detail)
+
+	e, err = rules.User(ctx, m, 2, time.Now())
+	require.NoError(t, err)
+	assert.False(t, e.Eligible)
+
+	e, err = rules.User(ctx, m, 3, later)
+	require.NoError(t, err)
+	assert.False(t, e.Eligible, "promoted, time in grade starts over")
+	assert.Equal(t, []Check{
+		{Condition: "months_in_grade", Passed: false, Detail: e.Checks[0].Detail},
+		{Condition: "months_employed", Passed: false, Detail: "hire date unknown"},
+	}, e.Checks)
+	assert.Contains(t, e.Checks[0].Detail, "13 of 18 months in middle")
+
+	e, err = rules.User(ctx, m, 1, later)
+	require.NoError(t, err)
+	assert.False(t, e.Eligible)
+	assert.Equal(t, []Check{{Condition: "next_grade", Detail: "senior is the top grade"}}, e.Checks)
+
+	e, err = rules.User(ctx, m, 4, later)
+	require.NoError(t, err)
+	assert.False(t, e.Eligible)
+	assert.Equal(t, []Check{{Condition: "rule", Detail: "no promotion rule for trainee"}}, e.Checks)
+
+	e, err = rules.User(ctx, struct{ promo.DBConnexion }{m}, 2, later)
+	require.NoError(t, err)
+	assert.False(t, e.Eligible)
+	assert.Equal(t, "time in grade is not recorded by this store", e.Checks[0].Detail)
+
+	_, err = rules.User(ctx, m, 42, later)
+	assert.ErrorIs(t, err, promo.ErrNotFound)
+
+	es, err := rules.Eligible(ctx, m, "", later)
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+	assert.Equal(t, 2, es[0].User.Id)
+}
+
+func TestHandlers(t *testing.T) {
+	rules, err := ParseRules([]byte("rules: {junior: [{months_in_grade: 0}], middle: [{months_in_grade: 1}]}"))
+	require.NoError(t, err)
+	get := func(t *testing.T, url string) *httptest.ResponseRecorder {
+		m := promotest.NewStore(t,
+			promo.User{Name: "Ann", Surname: "Boss", Position: 3, Project: "Alpha"},
+			promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+			promo.User{Name: "Cid", Surname: "Jones", Position: 2, Project: "Beta"})
+		return promotest.Serve(promotest.NewRouter(NewHandlers(m, rules)), http.MethodGet, url, "")
+	}
+	t.Run("Check user eligibility", func(t *testing.T) {
+		w := get(t, "/users/1/eligibility")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var e Eligibility
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&e))
+		assert.False(t, e.Eligible)
+		assert.Equal(t, "senior", e.Next)
+		assert.Regexp(t, `^0 of 1 months in middle`, e.Checks[0].Detail)
+	})
+	t.Run("Check user eligibility (absent user)", func(t *testing.T) {
+		w := get(t, "/users/42/eligibility")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+	})
+	t.Run("Check eligible users of a project", func(t *testing.T) {
+		w := get(t, "/eligibility?project=Alpha")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var es []Eligibility
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&es))
+		require.Len(t, es, 1)
+		assert.Equal(t, "Bob", es[0].User.Name)
+	})
+	t.Run("Check eligible users (none)", func(t *testing.T) {
+		w := get(t, "/eligibility?project=Gamma")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, "[]", w.Body.String())
+	})
+}
@@ -0,0 +1,82 @@
+package eligibility
+
+import (
+	promo "AndersenPromo/internal"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the eligibility of the users of a store under rules.
+type Handlers struct {
+	dbc   promo.DBConnexion
+	rules *Rules
+}
+
+func NewHandlers(dbc promo.DBConnexion, rules *Rules) *Handlers {
+	return &Handlers{dbc, rules}
+}
+
+// Register mounts the eligibility routes on router.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/users/{id:[0-9]+}/eligibility", h.UserEligibility).Methods(http.MethodGet)
+	router.HandleFunc("/eligibility", h.Eligible).Methods(http.MethodGet)
+}
+
+// UserEligibility	 godoc
+//
+//	@Summary		User eligibility
+//	@Description	whether a user meets the promotion rule of their grade, with every condition checked
+//	@Tags			eligibility
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	eligibility.Eligibility
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Router			/users/{id}/eligibility [get]
+func (h *Handlers) UserEligibility(w http.ResponseWriter, r *http.Request) {
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		promo.Error(w, fmt.Sprintf("illegal user id %q", mux.Vars(r)["id"]), http.StatusBadRequest)
+		return
+	}
+	e, err := h.rules.User(r.Context(), h.dbc, id, time.Now())
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, e)
+}
+
+// Eligible	 godoc
+//
+//	@Summary		Eligible users
+//	@Description	live users currently meeting the promotion rule of their grade, of a project or of every one
+//	@Tags			eligibility
+//	@Produce		json,application/yaml
+//	@Param			project	query		string	false	"Project name"
+//	@Success		200		{array}		eligibility.Eligibility
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Router			/eligibility [get]
+func (h *Handlers) Eligible(w http.ResponseWriter, r *http.Request) {
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	es, err := h.rules.Eligible(r.Context(), h.dbc, r.URL.Query().Get("project"), time.Now())
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, es)
+}
@@ -0,0 +1,188 @@
+package promo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// exportHeader names the columns of exportRecord, as importColumns does.
+var exportHeader = []string{"id", "name", "surname", "position", "project",
+	"email", "employee_number", "hire_date", "location", "manager_id"}
+
+// exportRecord is the row of u under exportHeader, a missing manager left
+// empty.
+func exportRecord(u User) []string {
+	manager := ""
+	if u.ManagerId != 0 {
+		manager = strconv.Itoa(u.ManagerId)
+	}
+	return []string{strconv.Itoa(u.Id), u.Name, u.Surname, u.Position.String(), u.Project,
+		u.Email, u.EmployeeNumber, u.HireDate, u.Location, manager}
+}
+
+// exporter writes users one at a time, so that an export never holds more
+// than a row in memory (the XLSX stream writer spills to a temp file).
+type exporter interface {
+	write(User) error
+	close() error
+}
+
+type csvExporter struct {
+	cw *csv.Writer
+}
+
+func (e *csvExporter) write(u User) error {
+	return e.cw.Write(exportRecord(u))
+}
+
+func (e *csvExporter) close() error {
+	e.cw.Flush()
+	return e.cw.Error()
+}
+
+type ndjsonExporter struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExporter) write(u User) error {
+	return e.enc.Encode(u)
+}
+
+func (e *ndjsonExporter) close() error {
+	return nil
+}
+
+type xlsxExporter struct {
+	w   io.Writer
+	f   *excelize.File
+	sw  *excelize.StreamWriter
+	row int
+}
+
+func newXLSXExporter(w io.Writer) (*xlsxExporter, error) {
+	f := excelize.NewFile()
+	sw, err := f.NewStreamWriter(f.GetSheetName(0))
+	if err != nil {
+		return nil, err
+	}
+	e := &xlsxExporter{w: w, f: f, sw: sw, row: 1}
+	cells := make([]any, len(exportHeader))
+	for i, h := range exportHeader {
+		cells[i] = h
+	}
+	return e, sw.SetRow("A1", cells)
+}
+
+func (e *xlsxExporter) write(u User) error {
+	e.row++
+	cell, _ := excelize.CoordinatesToCellName(1, e.row)
+	rec := exportRecord(u)
+	cells := make([]any, len(rec))
+	for i, c := range rec {
+		cells[i] = c
+	}
+	// Ids stay numbers for spreadsheets.
+	cells[0] = u.Id
+	if u.ManagerId != 0 {
+		cells[len(cells)-1] = u.ManagerId
+	}
+	return e.sw.SetRow(cell, cells)
+}
+
+func (e *xlsxExporter) close() error {
+	defer e.f.Close()
+	if err := e.sw.Flush(); err != nil {
+		return err
+	}
+	return e.f.Write(e.w)
+}
+
+// lazyWriter postpones the response headers until the first byte, so that
+// a failure before any row can still be answered with a problem.
+type lazyWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (l *lazyWriter) Write(p []byte) (int, error) {
+	if !l.started {
+		l.started = true
+		l.w.Header().Set("Content-Type", l.contentType)
+		l.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", l.filename))
+		l.w.WriteHeader(http.StatusOK)
+	}
+	return l.w.Write(p)
+}
+
+// ExportUsers	 godoc
+//
+//	@Summary		Export users
+//	@Description	stream users as CSV, XLSX or NDJSON
+//	@Tags			users
+//	@Produce		text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/x-ndjson
+//	@Param			format	query		string	false	"csv (default), xlsx or ndjson"
+//	@Param			project	query		string	false	"Project name"
+//	@Param			grade	query		string	false	"Grade name or number"
+//	@Param			include_deleted	query	bool	false	"Export deleted users too"
+//	@Param			email	query		string	false	"Email"
+//	@Param			employee_number	query	string	false	"Employee number"
+//	@Param			location	query	string	false	"Location"
+//	@Param			manager_id	query	int	false	"Direct reports of this user"
+//	@Param			hired_from	query	string	false	"Hired on or after this date, YYYY-MM-DD"
+//	@Param			hired_to	query	string	false	"Hired on or before this date, YYYY-MM-DD"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	Problem
+//	@Failure		500		{object}	Problem
+//	@Router			/users/export [get]
+func (h *Handlers) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r)
+	if err != nil {
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+
+	lw := &lazyWriter{w: w}
+	var e exporter
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "csv":
+		lw.contentType, lw.filename = csvContentType, "users.csv"
+		cw := csv.NewWriter(lw)
+		_ = cw.Write(exportHeader)
+		e = &csvExporter{cw}
+	case "ndjson":
+		lw.contentType, lw.filename = ndjsonContentType, "users.ndjson"
+		e = &ndjsonExporter{json.NewEncoder(lw)}
+	case "xlsx":
+		lw.contentType, lw.filename = xlsxContentType, "users.xlsx"
+		if e, err = newXLSXExporter(lw); err != nil {
+			Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
+			return
+		}
+	default:
+		Error(w, fmt.Sprintf("unknown format %q, expected csv, xlsx or ndjson", format), http.StatusBadRequest)
+		return
+	}
+
+	err = eachUser(r.Context(), h.dbc, f, e.write)
+	if err == nil {
+		err = e.close()
+	}
+	if err != nil {
+		if !lw.started {
+			DBError(w, err)
+			return
+		}
+		// The status is gone already, all that is left is to cut the body short.
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,158 @@
+package promo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func exportRows() *pgxmock.Rows {
+	return pgxmock.NewRows(userCols).
+		AddRow(1, "And1", "Ersen1", "middle", "Test", "", "", "", "", 0).
+		AddRow(2, "And2", "Ersen2", "senior", "Test", "", "", "", "", 0)
+}
+
+func TestHandlers_ExportUsers(t *testing.T) {
+	t.Run("Check exporting csv (filtered)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectQuery(selectUsers(" FROM usr WHERE project=\\$1 AND deleted_at IS NULL ORDER BY id")).
+			WithArgs("Test").WillReturnRows(exportRows())
+		req := httptest.NewRequest(http.MethodGet, "/users/export?project=Test", nil)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, csvContentType, w.Result().Header.Get("Content-Type"))
+		body, _ := io.ReadAll(w.Result().Body)
+		assert.Equal(t, "id,name,surname,position,project,email,employee_number,hire_date,location,manager_id\n1,And1,Ersen1,middle,Test,,,,,\n2,And2,Ersen2,senior,Test,,,,,\n", string(body))
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check exporting csv (profile)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectQuery(selectUsers(" FROM usr WHERE deleted_at IS NULL ORDER BY id")).WillReturnRows(pgxmock.NewRows(userCols).
+			AddRow(3, "Ann", "Boss", "senior", "Test", "ann@example.com", "E1", "2019-03-01", "Minsk", 0).
+			AddRow(4, "Bob", "Smith", "junior", "", "", "", "", "", 3))
+		req := httptest.NewRequest(http.MethodGet, "/users/export", nil)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		body, _ := io.ReadAll(w.Result().Body)
+		assert.Equal(t, "id,name,surname,position,project,email,employee_number,hire_date,location,manager_id\n"+
+			"3,Ann,Boss,senior,Test,ann@example.com,E1,2019-03-01,Minsk,\n"+
+			"4,Bob,Smith,junior,,,,,,3\n", string(body))
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check exporting ndjson", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectQuery(selectUsers(" FROM usr WHERE deleted_at IS NULL ORDER BY id")).WillReturnRows(exportRows())
+		req := httptest.NewRequest(http.MethodGet, "/users/export?format=ndjson", nil)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		body, _ := io.ReadAll(w.Result().Body)
+		assert.Equal(t, `{"id":1,"name":"And1","surname":"Ersen1","position":3,"project":"Test"}
+{"id":2,"name":"And2","surname":"Ersen2","position":4,"project":"Test"}
+`, string(body))
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check exporting xlsx", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectQuery(selectUsers(" FROM usr")).WillReturnRows(exportRows())
+		req := httptest.NewRequest(http.MethodGet, "/users/export?format=xlsx", nil)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		f, err := excelize.OpenReader(w.Result().Body)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening xlsx", err)
+		}
+		rows, err := f.GetRows(f.GetSheetName(0))
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{
+			exportHeader,
+			{"1", "And1", "Ersen1", "middle", "Test"},
+			{"2", "And2", "Ersen2", "senior", "Test"},
+		}, rows)
+	})
+	t.Run("Check exporting (wrong format)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		req := httptest.NewRequest(http.MethodGet, "/users/export?format=pdf", nil)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+	t.Run("Check exporting (request gone)", func(t *testing.T) {
+		m := NewMemory()
+		require.NoError(t, m.AddUsers([]User{{Name: "And", Surname: "Ersen", Position: middle}}))
+		h := &Handlers{m}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodGet, "/users/export", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.NotEqual(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "Ersen", "no user is read for a request gone")
+	})
+	t.Run("Check exporting (query error)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectQuery(selectUsers(" FROM usr")).WillReturnError(fmt.Errorf("query error"))
+		req := httptest.NewRequest(http.MethodGet, "/users/export", nil)
+		w := httptest.NewRecorder()
+		h.ExportUsers(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		assert.Equal(t, ProblemContentType, w.Result().Header.Get("Content-Type"))
+	})
+}
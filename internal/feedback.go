@@ -0,0 +1,115 @@
+package promo
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrFeedbackNotFound = errors.New("feedback not found")
+	// ErrFeedbackKey is returned for a key giving no access to a feedback.
+	ErrFeedbackKey = errors.New("key gives no access to this feedback")
+	// ErrFeedbackClosed is returned when responding past the due date.
+	ErrFeedbackClosed = errors.New("feedback is past due")
+	// ErrResponseNotFound is returned for a feedback the respondent has not answered yet.
+	ErrResponseNotFound = errors.New("feedback response not found")
+)
+
+// Relation is how a respondent stands to the user they give feedback on.
+type Relation string
+
+const (
+	RelationPeer    Relation = "peer"
+	RelationManager Relation = "manager"
+	RelationReport  Relation = "report"
+)
+
+// Respondent is a user asked for feedback, Answered once they responded.
+type Respondent struct {
+	UserId     int        `json:"user_id" yaml:"user_id"`
+	Relation   Relation   `json:"relation" yaml:"relation"`
+	Answered   bool       `json:"answered" yaml:"answered"`
+	RemindedAt *time.Time `json:"reminded_at,omitempty" yaml:"reminded_at,omitempty"`
+}
+
+// Feedback is 360-degree feedback asked on user UserId of Respondents by
+// DueAt, summed up for ManagerId, their manager when asked.
+type Feedback struct {
+	Id          int          `json:"id" yaml:"id"`
+	UserId      int          `json:"user_id" yaml:"user_id"`
+	ManagerId   int          `json:"manager_id,omitempty" yaml:"manager_id,omitempty"`
+	DueAt       time.Time    `json:"due_at" yaml:"due_at"`
+	Respondents []Respondent `json:"respondents" yaml:"respondents"`
+}
+
+// FeedbackResponse is the feedback of a respondent. Anonymous ones are
+// summed up without telling who gave them.
+type FeedbackResponse struct {
+	Rating       int    `json:"rating" yaml:"rating"`
+	Strengths    string `json:"strengths,omitempty" yaml:"strengths,omitempty" validate:"max=2000"`
+	Improvements string `json:"improvements,omitempty" yaml:"improvements,omitempty" validate:"max=2000"`
+	Anonymous    bool   `json:"anonymous" yaml:"anonymous"`
+}
+
+// FeedbackAnswer is the response of respondent RespondentId.
+type FeedbackAnswer struct {
+	RespondentId int
+	Relation     Relation
+	FeedbackResponse
+}
+
+// FeedbackStore keeps feedback and the hashes of the keys giving access to
+// it; making and checking keys is up to the caller.
+type FeedbackStore interface {
+	// CreateFeedback records f, its respondents unanswered, with the key
+	// of its manager and those of its respondents by user id, and returns
+	// its id.
+	CreateFeedback(f Feedback, managerKey string, keys map[int]string) (int, error)
+	// Feedback is feedback id, ErrFeedbackNotFound if there is none.
+	Feedback(id int) (*Feedback, error)
+	// Feedbacks lists the feedback on user, or on everyone when 0, by id.
+	Feedbacks(user int) ([]Feedback, error)
+	// FeedbackKey tells which respondent of feedback id holds key, 0 for
+	// its manager, ErrFeedbackKey if no one does.
+	FeedbackKey(id int, key string) (respondent int, err error)
+	// FeedbackResponse is the response of respondent to feedback id, nil
+	// until they answer.
+	FeedbackResponse(id, respondent int) (*FeedbackResponse, error)
+	// Respond records or replaces the response of respondent to feedback
+	// id.
+	Respond(id, respondent int, r FeedbackResponse) error
+	// FeedbackAnswers lists the responses to feedback id by respondent.
+	FeedbackAnswers(id int) ([]FeedbackAnswer, error)
+	// Remind records that respondent was reminded of feedback id at at.
+	Remind(id, respondent int, at time.Time) error
+}
+
+// Feedback queries shared by Registry and SQLite.
+const (
+	createFeedbackQuery   = "INSERT INTO feedback (user_id, manager_id, manager_key_hash, due_at) VALUES ($1, $2, $3, $4) RETURNING id"
+	addRespondentQuery    = "INSERT INTO feedback_respondent (feedback_id, respondent_id, relation, key_hash) VALUES ($1, $2, $3, $4)"
+	feedbackKeyQuery      = "SELECT respondent_id FROM feedback_respondent WHERE feedback_id=$1 AND key_hash=$2 UNION ALL SELECT 0 FROM feedback WHERE id=$1 AND manager_key_hash=$2"
+	feedbackResponseQuery = `SELECT answered_at IS NOT NULL, COALESCE(rating, 0), COALESCE(strengths, ''), COALESCE(improvements, ''), anonymous
+FROM feedback_respondent WHERE feedback_id=$1 AND respondent_id=$2`
+	respondQuery = `UPDATE feedback_respondent SET rating=$3, strengths=$4, improvements=$5, anonymous=$6, answered_at=$7
+WHERE feedback_id=$1 AND respondent_id=$2`
+	feedbackAnswersQuery = `SELECT respondent_id, relation, rating, COALESCE(strengths, ''), COALESCE(improvements, ''), anonymous
+FROM feedback_respondent WHERE feedback_id=$1 AND answered_at IS NOT NULL ORDER BY respondent_id`
+	remindQuery = "UPDATE feedback_respondent SET reminded_at=$3 WHERE feedback_id=$1 AND respondent_id=$2"
+)
+
+// feedbackQueries select feedback id, or the feedback on user when id is
+// 0, or every one when both are, by id, and their respondents.
+func feedbackQueries(id, user int) (feedback, respondents string, args []any) {
+	where := ""
+	switch {
+	case id != 0:
+		where, args = " WHERE id=$1", []any{id}
+	case user != 0:
+		where, args = " WHERE user_id=$1", []any{user}
+	}
+	feedback = "SELECT id, user_id, COALESCE(manager_id, 0), due_at FROM feedback" + where + " ORDER BY id"
+	respondents = "SELECT feedback_id, respondent_id, relation, answered_at IS NOT NULL, reminded_at FROM feedback_respondent " +
+		"WHERE feedback_id IN (SELECT id FROM feedback" + where + ") ORDER BY respondent_id"
+	return feedback, respondents, args
+}
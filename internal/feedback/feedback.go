@@ -0,0 +1,151 @@
+// Package feedback collects 360-degree feedback on a user before promotion
+// decisions: their peers, manager and reports are asked to rate them and
+// comment by a due date, are reminded while they have not, and the answers
+// are summed up for the manager of the user.
+//
+// There are no accounts: asking for feedback hands out a random key to
+// every respondent and one to the manager. A respondent key reads and
+// writes its own response only, the manager key reads the summary only;
+// stores keep the keys hashed. Feedback is kept by stores implementing
+// promo.FeedbackStore.
+package feedback
+
+import (
+	promo "AndersenPromo/internal"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrNotSupported is returned against stores keeping no feedback.
+	ErrNotSupported = fmt.Errorf("feedback is %w", promo.ErrNotSupported)
+	// ErrClosed is returned when responding past the due date.
+	ErrClosed = promo.ErrFeedbackClosed
+)
+
+// MinAnswers is how many answers a relation needs for its mean rating to
+// show in a summary, so that a lone manager or peer cannot be told apart.
+const MinAnswers = 2
+
+// NewKey makes a random key to a feedback.
+func NewKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashKey is key as kept by stores.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// RelationOf is how respondent stands to user: their manager, one of their
+// reports or else a peer.
+func RelationOf(user, respondent promo.User) promo.Relation {
+	switch {
+	case user.ManagerId != 0 && respondent.Id == user.ManagerId:
+		return promo.RelationManager
+	case respondent.ManagerId == user.Id:
+		return promo.RelationReport
+	}
+	return promo.RelationPeer
+}
+
+// RelationSummary sums up the answers of the respondents of a relation.
+// Mean is left out below MinAnswers answers.
+type RelationSummary struct {
+	Relation promo.Relation `json:"relation" yaml:"relation"`
+	Asked    int            `json:"asked" yaml:"asked"`
+	Answered int            `json:"answered" yaml:"answered"`
+	Mean     *float64       `json:"mean,omitempty" yaml:"mean,omitempty"`
+}
+
+// Comment is what a respondent wrote, telling who only when not anonymous.
+type Comment struct {
+	RespondentId int            `json:"respondent_id,omitempty" yaml:"respondent_id,omitempty"`
+	Relation     promo.Relation `json:"relation,omitempty" yaml:"relation,omitempty"`
+	Strengths    string         `json:"strengths,omitempty" yaml:"strengths,omitempty"`
+	Improvements string         `json:"improvements,omitempty" yaml:"improvements,omitempty"`
+}
+
+// Summary sums up the answers to feedback FeedbackId on user UserId: the
+// mean rating over the answers of the relations showing theirs, 0 while
+// none does, lest it give away the ratings they hide, the relations asked
+// in the order managers, peers, reports, and the comments, named ones first
+// by respondent, anonymous ones after by content.
+type Summary struct {
+	FeedbackId int               `json:"feedback_id" yaml:"feedback_id"`
+	UserId     int               `json:"user_id" yaml:"user_id"`
+	Asked      int               `json:"asked" yaml:"asked"`
+	Answered   int               `json:"answered" yaml:"answered"`
+	Mean       float64           `json:"mean" yaml:"mean"`
+	Relations  []RelationSummary `json:"relations" yaml:"relations"`
+	Comments   []Comment         `json:"comments" yaml:"comments"`
+}
+
+// relations orders the relations in a summary.
+var relations = []promo.Relation{promo.RelationManager, promo.RelationPeer, promo.RelationReport}
+
+// Summarize sums up the answers to feedback id.
+func Summarize(fs promo.FeedbackStore, id int) (*Summary, error) {
+	f, err := fs.Feedback(id)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := fs.FeedbackAnswers(id)
+	if err != nil {
+		return nil, err
+	}
+	s := &Summary{FeedbackId: f.Id, UserId: f.UserId, Asked: len(f.Respondents), Answered: len(answers),
+		Relations: []RelationSummary{}, Comments: []Comment{}}
+	asked := make(map[promo.Relation]int)
+	for _, rs := range f.Respondents {
+		asked[rs.Relation]++
+	}
+	sums, answered := make(map[promo.Relation]int), make(map[promo.Relation]int)
+	var named, anonymous []Comment
+	for _, a := range answers {
+		sums[a.Relation] += a.Rating
+		answered[a.Relation]++
+		if a.Strengths == "" && a.Improvements == "" {
+			continue
+		}
+		c := Comment{Strengths: a.Strengths, Improvements: a.Improvements}
+		if a.Anonymous {
+			anonymous = append(anonymous, c)
+			continue
+		}
+		c.RespondentId, c.Relation = a.RespondentId, a.Relation
+		named = append(named, c)
+	}
+	total, shown := 0, 0
+	for _, rel := range relations {
+		if asked[rel] == 0 {
+			continue
+		}
+		rs := RelationSummary{Relation: rel, Asked: asked[rel], Answered: answered[rel]}
+		if rs.Answered >= MinAnswers {
+			mean := float64(sums[rel]) / float64(rs.Answered)
+			rs.Mean = &mean
+			total, shown = total+sums[rel], shown+rs.Answered
+		}
+		s.Relations = append(s.Relations, rs)
+	}
+	if shown > 0 {
+		s.Mean = float64(total) / float64(shown)
+	}
+	sort.Slice(anonymous, func(i, j int) bool {
+		if anonymous[i].Strengths != anonymous[j].Strengths {
+			return anonymous[i].Strengths < anonymous[j].Strengths
+		}
+		return anonymous[i].Improvements < anonymous[j].Improvements
+	})
+	s.Comments = append(append(s.Comments, named...), anonymous...)
+	return s, nil
+}
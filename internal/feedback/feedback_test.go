@@ -0,0 +1,307 @@
+package feedback
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/assessments"
+	"AndersenPromo/internal/promotest"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var due = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// newStore has Bob, managed by Ann, managing Dan, and Cid, a peer.
+func newStore(t *testing.T) *promo.Memory {
+	return promotest.NewStore(t,
+		promo.User{Name: "Ann", Surname: "Boss", Position: 4},
+		promo.User{Name: "Bob", Surname: "Smith", Position: 3, ManagerId: 1},
+		promo.User{Name: "Cid", Surname: "Jones", Position: 3, ManagerId: 1},
+		promo.User{Name: "Dan", Surname: "Brown", Position: 2, ManagerId: 2},
+		promo.User{Name: "Eve", Surname: "White", Position: 2, ManagerId: 1})
+}
+
+func newRouter(dbc promo.DBConnexion, clock promo.Clock) *mux.Router {
+	h := NewHandlers(dbc, assessments.DefaultScale)
+	h.clock = clock
+	return promotest.NewRouter(h)
+}
+
+// bearer is the header presenting key, none without one.
+func bearer(key string) []string {
+	if key == "" {
+		return nil
+	}
+	return []string{"Authorization", "Bearer " + key}
+}
+
+func TestRelationOf(t *testing.T) {
+	bob := promo.User{Id: 2, ManagerId: 1}
+	assert.Equal(t, promo.RelationManager, RelationOf(bob, promo.User{Id: 1}))
+	assert.Equal(t, promo.RelationReport, RelationOf(bob, promo.User{Id: 4, ManagerId: 2}))
+	assert.Equal(t, promo.RelationPeer, RelationOf(bob, promo.User{Id: 3, ManagerId: 1}))
+	assert.Equal(t, promo.RelationPeer, RelationOf(promo.User{Id: 1}, promo.User{Id: 3}), "no manager")
+}
+
+func TestSummarize(t *testing.T) {
+	m := newStore(t)
+	id, err := m.CreateFeedback(promo.Feedback{UserId: 2, ManagerId: 1, DueAt: due, Respondents: []promo.Respondent{
+		{UserId: 1, Relation: promo.RelationManager},
+		{UserId: 3, Relation: promo.RelationPeer},
+		{UserId: 4, Relation: promo.RelationReport},
+		{UserId: 5, Relation: promo.RelationPeer},
+	}}, "m", map[int]string{1: "a", 3: "c", 4: "d", 5: "e"})
+	require.NoError(t, err)
+	require.NoError(t, m.Respond(id, 1, promo.FeedbackResponse{Rating: 5, Strengths: "ownership"}))
+	require.NoError(t, m.Respond(id, 3, promo.FeedbackResponse{Rating: 3, Improvements: "reviews", Anonymous: true}))
+	require.NoError(t, m.Respond(id, 5, promo.FeedbackResponse{Rating: 4, Strengths: "care", Anonymous: true}))
+
+	s, err := Summarize(m, id)
+	require.NoError(t, err)
+	peers := 3.5
+	assert.Equal(t, &Summary{FeedbackId: id, UserId: 2, Asked: 4, Answered: 3, Mean: peers, Relations: []RelationSummary{
+		{Relation: promo.RelationManager, Asked: 1, Answered: 1},
+		{Relation: promo.RelationPeer, Asked: 2, Answered: 2, Mean: &peers},
+		{Relation: promo.RelationReport, Asked: 1},
+	}, Comments: []Comment{
+		{RespondentId: 1, Relation: promo.RelationManager, Strengths: "ownership"},
+		{Improvements: "reviews"},
+		{Strengths: "care"},
+	}}, s)
+}
+
+func TestSummarize_HiddenRatings(t *testing.T) {
+	// Whatever the manager rated, the summary reads the same.
+	var first *Summary
+	for rating := 1; rating <= 5; rating++ {
+		m := newStore(t)
+		id, err := m.CreateFeedback(promo.Feedback{UserId: 2, ManagerId: 1, DueAt: due, Respondents: []promo.Respondent{
+			{UserId: 1, Relation: promo.RelationManager},
+			{UserId: 3, Relation: promo.RelationPeer},
+			{UserId: 5, Relation: promo.RelationPeer},
+		}}, "m", map[int]string{1: "a", 3: "c", 5: "e"})
+		require.NoError(t, err)
+		require.NoError(t, m.Respond(id, 1, promo.FeedbackResponse{Rating: rating}))
+		require.NoError(t, m.Respond(id, 3, promo.FeedbackResponse{Rating: 3}))
+		require.NoError(t, m.Respond(id, 5, promo.FeedbackResponse{Rating: 4}))
+		s, err := Summarize(m, id)
+		require.NoError(t, err)
+		if first == nil {
+			first = s
+			continue
+		}
+		assert.Equal(t, first, s, "rated %d", rating)
+	}
+}
+
+func TestReminder(t *testing.T) {
+	m := newStore(t)
+	id, err := m.CreateFeedback(promo.Feedback{UserId: 2, ManagerId: 1, DueAt: due, Respondents: []promo.Respondent{
+		{UserId: 1, Relation: promo.RelationManager},
+		{UserId: 3, Relation: promo.RelationPeer},
+	}}, "m", map[int]string{1: "a", 3: "c"})
+	require.NoError(t, err)
+	require.NoError(t, m.Respond(id, 3, promo.FeedbackResponse{Rating: 4}))
+
+	var reminded []int
+	now := due.Add(-4 * 24 * time.Hour)
+	r := NewReminder(m, NotifierFunc(func(_ context.Context, f promo.Feedback, respondent int) error {
+		assert.Equal(t, id, f.Id)
+		reminded = append(reminded, respondent)
+		return nil
+	}), promo.ClockFunc(func() time.Time { return now }), 3*24*time.Hour, 24*time.Hour)
+
+	for _, step := range []struct {
+		name  string
+		after time.Duration
+		want  []int
+	}{
+		{"before the window", 0, nil},
+		{"in the window", 24 * time.Hour, []int{1}},
+		{"before the repeat", 12 * time.Hour, nil},
+		{"repeated", 12 * time.Hour, []int{1}},
+		{"past due", 2 * 24 * time.Hour, nil},
+	} {
+		t.Run("Check reminding ("+step.name+")", func(t *testing.T) {
+			now, reminded = now.Add(step.after), nil
+			require.NoError(t, r.Tick(context.Background()))
+			assert.Equal(t, step.want, reminded)
+		})
+	}
+	f, err := m.Feedback(id)
+	require.NoError(t, err)
+	require.NotNil(t, f.Respondents[0].RemindedAt)
+	assert.Equal(t, due.Add(-2*24*time.Hour), *f.Respondents[0].RemindedAt)
+	assert.Nil(t, f.Respondents[1].RemindedAt, "answered")
+}
+
+func TestHandlers(t *testing.T) {
+	now := due.Add(-7 * 24 * time.Hour)
+	clock := promo.ClockFunc(func() time.Time { return now })
+
+	t.Run("Check asking, answering and summing up", func(t *testing.T) {
+		router := newRouter(newStore(t), clock)
+		w := promotest.Serve(router, http.MethodPost, "/feedback",
+			`{"user_id": 2, "due_at": "2026-05-01T00:00:00Z", "respondents": [3, 1, 4]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c Created
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, promo.Feedback{Id: 1, UserId: 2, ManagerId: 1, DueAt: due, Respondents: []promo.Respondent{
+			{UserId: 1, Relation: promo.RelationManager},
+			{UserId: 3, Relation: promo.RelationPeer},
+			{UserId: 4, Relation: promo.RelationReport},
+		}}, c.Feedback)
+		require.Len(t, c.RespondentKeys, 3)
+		keys := make(map[int]string)
+		for _, k := range c.RespondentKeys {
+			keys[k.UserId] = k.Key
+		}
+		assert.NotEqual(t, keys[1], keys[3])
+		assert.NotEmpty(t, c.ManagerKey)
+
+		w = promotest.Serve(router, http.MethodGet, "/feedback/1/response", "", bearer(keys[3])...)
+		assert.Equal(t, http.StatusNotFound, w.Code, "not answered yet")
+		assert.JSONEq(t, `{"type":"/problems/response-not-found","title":"Not Found","status":404,"detail":"feedback 1 is not answered yet: feedback response not found"}`, w.Body.String())
+		w = promotest.Serve(router, http.MethodPut, "/feedback/1/response",
+			`{"rating": 4, "strengths": " care ", "anonymous": true}`, bearer(keys[3])...)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = promotest.Serve(router, http.MethodPut, "/feedback/1/response", `{"rating": 5, "improvements": "scope"}`, bearer(keys[1])...)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		w = promotest.Serve(router, http.MethodGet, "/feedback/1/response", "", bearer(keys[3])...)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp promo.FeedbackResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, promo.FeedbackResponse{Rating: 4, Strengths: "care", Anonymous: true}, resp, "own response only")
+
+		w = promotest.Serve(router, http.MethodGet, "/feedback/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var f promo.Feedback
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&f))
+		assert.True(t, f.Respondents[1].Answered)
+		assert.NotContains(t, w.Body.String(), "care")
+
+		w = promotest.Serve(router, http.MethodGet, "/feedback/1/summary", "", bearer(c.ManagerKey)...)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var s Summary
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&s))
+		assert.Equal(t, 3, s.Asked)
+		assert.Equal(t, 2, s.Answered)
+		assert.Zero(t, s.Mean, "no relation has enough answers")
+		assert.Equal(t, []Comment{
+			{RespondentId: 1, Relation: promo.RelationManager, Improvements: "scope"},
+			{Strengths: "care"},
+		}, s.Comments)
+
+		now = due
+		w = promotest.Serve(router, http.MethodPut, "/feedback/1/response", `{"rating": 3}`, bearer(keys[4])...)
+		assert.Equal(t, http.StatusConflict, w.Code, "past due")
+		now = due.Add(-7 * 24 * time.Hour)
+	})
+	t.Run("Check answering (retried)", func(t *testing.T) {
+		router := newRouter(newStore(t), clock)
+		w := promotest.Serve(router, http.MethodPost, "/feedback", `{"user_id": 2, "due_at": "2026-05-01T00:00:00Z", "respondents": [3, 4]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c Created
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		answer := func(key string) *httptest.ResponseRecorder {
+			return promotest.Serve(router, http.MethodPut, "/feedback/1/response", `{"rating": 4}`,
+				append(bearer(key), promo.IdempotencyKeyHeader, "k1")...)
+		}
+		assert.Equal(t, http.StatusOK, answer(c.RespondentKeys[0].Key).Code)
+		w = answer(c.RespondentKeys[0].Key)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(promo.IdempotentReplayedHeader))
+		assert.Equal(t, http.StatusUnprocessableEntity, answer(c.RespondentKeys[1].Key).Code, "another respondent")
+	})
+	t.Run("Check asking (retried)", func(t *testing.T) {
+		m := newStore(t)
+		router := newRouter(m, clock)
+		const body = `{"user_id": 2, "due_at": "2026-05-01T00:00:00Z", "respondents": [3]}`
+		w := promotest.Serve(router, http.MethodPost, "/feedback", body, promo.IdempotencyKeyHeader, "k1")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c Created
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+
+		w = promotest.Serve(router, http.MethodPost, "/feedback", body, promo.IdempotencyKeyHeader, "k1")
+		assert.Equal(t, http.StatusConflict, w.Code, "the keys are not told twice")
+		assert.Equal(t, "true", w.Header().Get(promo.IdempotentReplayedHeader))
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, "/problems/not-replayed", p.Type)
+		fs, err := m.Feedbacks(2)
+		require.NoError(t, err)
+		assert.Len(t, fs, 1)
+
+		kept, err := m.BeginIdempotent("k1", "", now.Add(time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, kept)
+		assert.NotContains(t, string(kept.Body), c.ManagerKey)
+		assert.NotContains(t, string(kept.Body), c.RespondentKeys[0].Key)
+	})
+	t.Run("Check access control", func(t *testing.T) {
+		router := newRouter(newStore(t), clock)
+		w := promotest.Serve(router, http.MethodPost, "/feedback", `{"user_id": 2, "due_at": "2026-05-01T00:00:00Z", "respondents": [3]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c Created
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		respondent := c.RespondentKeys[0].Key
+
+		for name, req := range map[string]struct {
+			method, url, key string
+			want             int
+		}{
+			"no key":                 {http.MethodGet, "/feedback/1/response", "", http.StatusUnauthorized},
+			"wrong key":              {http.MethodGet, "/feedback/1/response", "nope", http.StatusForbidden},
+			"manager key responding": {http.MethodPut, "/feedback/1/response", c.ManagerKey, http.StatusForbidden},
+			"respondent key summing": {http.MethodGet, "/feedback/1/summary", respondent, http.StatusForbidden},
+			"other feedback":         {http.MethodGet, "/feedback/42/summary", c.ManagerKey, http.StatusForbidden},
+		} {
+			t.Run("Check access control ("+name+")", func(t *testing.T) {
+				w := promotest.Serve(router, req.method, req.url, `{"rating": 3}`, bearer(req.key)...)
+				assert.Equal(t, req.want, w.Code)
+				assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+			})
+		}
+	})
+	t.Run("Check asking (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t), clock), http.MethodPost, "/feedback",
+			`{"user_id": 2, "due_at": "2026-04-01T00:00:00Z", "respondents": [2, 3, 3]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "due_at", Rule: promo.RuleInvalid, Detail: "is past"},
+			{Field: "respondents[0]", Rule: promo.RuleInvalid, Detail: "is the user asked about"},
+			{Field: "respondents[2]", Rule: promo.RuleInvalid, Detail: "is asked twice"},
+		}, p.Errors)
+	})
+	for name, body := range map[string]string{
+		"no manager":        `{"user_id": 1, "due_at": "2026-05-01T00:00:00Z", "respondents": [2]}`,
+		"absent respondent": `{"user_id": 2, "due_at": "2026-05-01T00:00:00Z", "respondents": [42]}`,
+	} {
+		t.Run("Check asking ("+name+")", func(t *testing.T) {
+			w := promotest.Serve(newRouter(newStore(t), clock), http.MethodPost, "/feedback", body)
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		})
+	}
+	t.Run("Check responding (off the scale)", func(t *testing.T) {
+		router := newRouter(newStore(t), clock)
+		w := promotest.Serve(router, http.MethodPost, "/feedback", `{"user_id": 2, "due_at": "2026-05-01T00:00:00Z", "respondents": [3]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c Created
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		w = promotest.Serve(router, http.MethodPut, "/feedback/1/response", `{"rating": 6}`, bearer(c.RespondentKeys[0].Key)...)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+	t.Run("Check feedback (no feedback)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(struct{ promo.DBConnexion }{newStore(t)}, clock), http.MethodGet, "/feedback", "")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
@@ -0,0 +1,445 @@
+package feedback
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/assessments"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the feedback of a store, rated on scale.
+type Handlers struct {
+	dbc   promo.DBConnexion
+	scale assessments.Scale
+	clock promo.Clock
+}
+
+func NewHandlers(dbc promo.DBConnexion, scale assessments.Scale) *Handlers {
+	return &Handlers{dbc, scale, promo.SystemClock}
+}
+
+// Register mounts the feedback routes on router. POST and PUT routes honour
+// the Idempotency-Key header, though the keys POST /feedback tells are not
+// kept to be replayed.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/feedback", promo.IdempotentOnce(h.dbc, h.CreateFeedback)).Methods(http.MethodPost)
+	router.HandleFunc("/feedback", h.GetFeedbacks).Methods(http.MethodGet)
+	router.HandleFunc("/feedback/{id:[0-9]+}", h.GetFeedback).Methods(http.MethodGet)
+	router.HandleFunc("/feedback/{id:[0-9]+}/response", h.GetResponse).Methods(http.MethodGet)
+	router.HandleFunc("/feedback/{id:[0-9]+}/response", promo.Idempotent(h.dbc, h.Respond)).Methods(http.MethodPut)
+	router.HandleFunc("/feedback/{id:[0-9]+}/summary", h.GetSummary).Methods(http.MethodGet)
+}
+
+// FeedbackRequest asks Respondents for feedback on user UserId by DueAt.
+type FeedbackRequest struct {
+	UserId      int       `json:"user_id" yaml:"user_id" validate:"required,min=1"`
+	DueAt       time.Time `json:"due_at" yaml:"due_at" validate:"required"`
+	Respondents []int     `json:"respondents" yaml:"respondents"`
+}
+
+// CheckFields checks that f asks each respondent once and none about
+// themselves.
+func (f *FeedbackRequest) CheckFields() promo.ValidationErrors {
+	var errs promo.ValidationErrors
+	if len(f.Respondents) == 0 {
+		errs = append(errs, promo.FieldError{Field: "respondents", Rule: promo.RuleRequired, Detail: "is required"})
+	}
+	seen := make(map[int]bool)
+	for i, id := range f.Respondents {
+		field := fmt.Sprintf("respondents[%d]", i)
+		switch {
+		case id == f.UserId:
+			errs = append(errs, promo.FieldError{Field: field, Rule: promo.RuleInvalid, Detail: "is the user asked about"})
+		case seen[id]:
+			errs = append(errs, promo.FieldError{Field: field, Rule: promo.RuleInvalid, Detail: "is asked twice"})
+		}
+		seen[id] = true
+	}
+	return errs
+}
+
+// RespondentKey is the key of respondent UserId.
+type RespondentKey struct {
+	UserId int    `json:"user_id" yaml:"user_id"`
+	Key    string `json:"key" yaml:"key"`
+}
+
+// Created is feedback as asked, with the keys to it. They are only ever
+// told here.
+type Created struct {
+	promo.Feedback `yaml:",inline"`
+	ManagerKey     string          `json:"manager_key" yaml:"manager_key"`
+	RespondentKeys []RespondentKey `json:"respondent_keys" yaml:"respondent_keys"`
+}
+
+// store is the FeedbackStore of h, answering 501 itself when there is
+// none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.FeedbackStore, bool) {
+	return promo.Capability[promo.FeedbackStore](w, h.dbc, ErrNotSupported)
+}
+
+// holder tells who holds the bearer key of r to feedback id, as
+// FeedbackKey does, answering 401 or 403 itself when no one does.
+func holder(w http.ResponseWriter, r *http.Request, fs promo.FeedbackStore, id int) (int, bool) {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="feedback"`)
+		promo.Error(w, "a feedback key is required", http.StatusUnauthorized)
+		return 0, false
+	}
+	respondent, err := fs.FeedbackKey(id, HashKey(key))
+	if err != nil {
+		promo.DBError(w, err)
+		return 0, false
+	}
+	return respondent, true
+}
+
+// CreateFeedback	 godoc
+//
+//	@Summary		Ask for feedback
+//	@Description	ask users for feedback on a user by a due date; each respondent is the manager, a report or a peer of the user by the hierarchy. The keys to answer and to read the summary are only ever told here
+//	@Tags			feedback
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			feedback	body		feedback.FeedbackRequest	true	"Feedback request"
+//	@Param			Idempotency-Key	header	string	false	"Serve the request once; retries get 409 rather than the keys"
+//	@Success		201			{object}	feedback.Created
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		404			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		409			{object}	promo.Problem
+//	@Failure		415			{object}	promo.Problem
+//	@Failure		422			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/feedback [post]
+func (h *Handlers) CreateFeedback(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var req FeedbackRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	var errs promo.ValidationErrors
+	if !req.DueAt.IsZero() && !req.DueAt.After(h.clock.Now()) {
+		errs = append(errs, promo.FieldError{Field: "due_at", Rule: promo.RuleInvalid, Detail: "is past"})
+	}
+	var fieldErrs promo.ValidationErrors
+	if err := promo.NewValidator(h.dbc).Validate(&req, false); err != nil && !errors.As(err, &fieldErrs) {
+		promo.Invalid(w, err)
+		return
+	}
+	if errs = append(errs, fieldErrs...); len(errs) > 0 {
+		promo.Invalid(w, errs)
+		return
+	}
+	u, err := h.dbc.GetUser(req.UserId)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	if u.ManagerId == 0 {
+		promo.Invalid(w, promo.ValidationErrors{{Field: "user_id", Rule: promo.RuleInvalid,
+			Detail: "has no manager to read the feedback"}})
+		return
+	}
+	f := promo.Feedback{UserId: u.Id, ManagerId: u.ManagerId, DueAt: req.DueAt, Respondents: []promo.Respondent{}}
+	created := Created{RespondentKeys: []RespondentKey{}}
+	keys := make(map[int]string)
+	for i, id := range req.Respondents {
+		rs, err := h.dbc.GetUser(id)
+		if errors.Is(err, promo.ErrNotFound) {
+			errs = append(errs, promo.FieldError{Field: fmt.Sprintf("respondents[%d]", i), Rule: promo.RuleInvalid,
+				Detail: "is not a user"})
+			continue
+		}
+		if err != nil {
+			promo.DBError(w, err)
+			return
+		}
+		key, err := NewKey()
+		if err != nil {
+			promo.DBError(w, err)
+			return
+		}
+		f.Respondents = append(f.Respondents, promo.Respondent{UserId: id, Relation: RelationOf(*u, *rs)})
+		created.RespondentKeys = append(created.RespondentKeys, RespondentKey{UserId: id, Key: key})
+		keys[id] = HashKey(key)
+	}
+	if len(errs) > 0 {
+		promo.Invalid(w, errs)
+		return
+	}
+	if created.ManagerKey, err = NewKey(); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	id, err := fs.CreateFeedback(f, HashKey(created.ManagerKey), keys)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	g, err := fs.Feedback(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	created.Feedback = *g
+	promo.Respond(w, codec, http.StatusCreated, created)
+}
+
+// GetFeedbacks	 godoc
+//
+//	@Summary		Feedback
+//	@Description	the feedback asked on a user, or on everyone, by id: who is asked and who answered, not what
+//	@Tags			feedback
+//	@Produce		json,application/yaml
+//	@Param			user_id	query		int	false	"User ID"
+//	@Success		200		{array}		promo.Feedback
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/feedback [get]
+func (h *Handlers) GetFeedbacks(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	user := 0
+	if s := r.URL.Query().Get("user_id"); s != "" {
+		var err error
+		if user, err = strconv.Atoi(s); err != nil || user < 1 {
+			promo.Error(w, fmt.Sprintf("illegal user_id %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+	list, err := fs.Feedbacks(user)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
+
+// GetFeedback	 godoc
+//
+//	@Summary		Feedback request
+//	@Description	who is asked for a feedback and who answered, not what
+//	@Tags			feedback
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Feedback ID"
+//	@Success		200	{object}	promo.Feedback
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/feedback/{id} [get]
+func (h *Handlers) GetFeedback(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	f, err := fs.Feedback(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, f)
+}
+
+// respondent reads who answers feedback id with the bearer key of r,
+// answering 401 or 403 itself unless a respondent.
+func (h *Handlers) respondent(w http.ResponseWriter, r *http.Request) (promo.FeedbackStore, int, int, bool) {
+	fs, ok := h.store(w)
+	if !ok {
+		return nil, 0, 0, false
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return nil, 0, 0, false
+	}
+	respondent, ok := holder(w, r, fs, id)
+	if !ok {
+		return nil, 0, 0, false
+	}
+	if respondent == 0 {
+		promo.Fail(w, fmt.Errorf("the manager key does not answer feedback: %w", promo.ErrFeedbackKey), http.StatusForbidden)
+		return nil, 0, 0, false
+	}
+	return fs, id, respondent, true
+}
+
+// GetResponse	 godoc
+//
+//	@Summary		Own response
+//	@Description	the response to a feedback of the respondent holding the bearer key
+//	@Tags			feedback
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Feedback ID"
+//	@Success		200	{object}	promo.FeedbackResponse
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		401	{object}	promo.Problem
+//	@Failure		403	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/feedback/{id}/response [get]
+func (h *Handlers) GetResponse(w http.ResponseWriter, r *http.Request) {
+	fs, id, respondent, ok := h.respondent(w, r)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	resp, err := fs.FeedbackResponse(id, respondent)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	if resp == nil {
+		promo.Fail(w, fmt.Errorf("feedback %d is not answered yet: %w", id, promo.ErrResponseNotFound), http.StatusNotFound)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, resp)
+}
+
+// Respond	 godoc
+//
+//	@Summary		Respond
+//	@Description	record or replace the response to a feedback of the respondent holding the bearer key, until it is due. Anonymous responses are summed up without telling who gave them
+//	@Tags			feedback
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id			path		int						true	"Feedback ID"
+//	@Param			response	body		promo.FeedbackResponse	true	"Response"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200			{object}	promo.FeedbackResponse
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		401			{object}	promo.Problem
+//	@Failure		403			{object}	promo.Problem
+//	@Failure		404			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		409			{object}	promo.Problem
+//	@Failure		415			{object}	promo.Problem
+//	@Failure		422			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/feedback/{id}/response [put]
+func (h *Handlers) Respond(w http.ResponseWriter, r *http.Request) {
+	fs, id, respondent, ok := h.respondent(w, r)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var req promo.FeedbackResponse
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	var errs promo.ValidationErrors
+	if req.Rating < h.scale.Min || req.Rating > h.scale.Max {
+		errs = append(errs, promo.FieldError{Field: "rating", Rule: promo.RuleInvalid,
+			Detail: fmt.Sprintf("is not on the scale %s", h.scale)})
+	}
+	req.Strengths, req.Improvements = strings.TrimSpace(req.Strengths), strings.TrimSpace(req.Improvements)
+	var fieldErrs promo.ValidationErrors
+	if err := promo.NewValidator(h.dbc).Validate(&req, false); err != nil && !errors.As(err, &fieldErrs) {
+		promo.Invalid(w, err)
+		return
+	}
+	if errs = append(errs, fieldErrs...); len(errs) > 0 {
+		promo.Invalid(w, errs)
+		return
+	}
+	f, err := fs.Feedback(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	if !h.clock.Now().Before(f.DueAt) {
+		promo.Fail(w, fmt.Errorf("feedback %d was due %s: %w", id, f.DueAt.Format(time.RFC3339), ErrClosed),
+			http.StatusConflict)
+		return
+	}
+	if err = fs.Respond(id, respondent, req); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, req)
+}
+
+// GetSummary	 godoc
+//
+//	@Summary		Feedback summary
+//	@Description	the answers to a feedback summed up for the manager holding the bearer key: mean ratings, by relation once answered by enough respondents and overall over those relations, and the comments, anonymous ones telling no one
+//	@Tags			feedback
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Feedback ID"
+//	@Success		200	{object}	feedback.Summary
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		401	{object}	promo.Problem
+//	@Failure		403	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/feedback/{id}/summary [get]
+func (h *Handlers) GetSummary(w http.ResponseWriter, r *http.Request) {
+	fs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	holder, ok := holder(w, r, fs, id)
+	if !ok {
+		return
+	}
+	if holder != 0 {
+		promo.Fail(w, fmt.Errorf("only the manager key reads the summary: %w", promo.ErrFeedbackKey), http.StatusForbidden)
+		return
+	}
+	s, err := Summarize(fs, id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, s)
+}
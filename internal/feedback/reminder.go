@@ -0,0 +1,105 @@
+package feedback
+
+import (
+	promo "AndersenPromo/internal"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Notifier reminds respondent of feedback f they have not answered yet.
+type Notifier interface {
+	Remind(ctx context.Context, f promo.Feedback, respondent int) error
+}
+
+// NotifierFunc is a Notifier calling itself.
+type NotifierFunc func(ctx context.Context, f promo.Feedback, respondent int) error
+
+func (n NotifierFunc) Remind(ctx context.Context, f promo.Feedback, respondent int) error {
+	return n(ctx, f, respondent)
+}
+
+// LogNotifier only logs reminders, for want of a way to reach users.
+var LogNotifier Notifier = NotifierFunc(func(_ context.Context, f promo.Feedback, respondent int) error {
+	log.Printf("Reminding user %d of feedback %d on user %d, due %s", respondent, f.Id, f.UserId,
+		f.DueAt.Format(time.RFC3339))
+	return nil
+})
+
+// Reminder reminds the respondents who have not answered of feedback due
+// within before, again every every until they answer or it is due.
+type Reminder struct {
+	dbc      promo.DBConnexion
+	notifier Notifier
+	clock    promo.Clock
+	before   time.Duration
+	every    time.Duration
+}
+
+// NewReminder reminds the respondents of the feedback of dbc through
+// notifier, LogNotifier when nil, telling time by clock, promo.SystemClock
+// when nil.
+func NewReminder(dbc promo.DBConnexion, notifier Notifier, clock promo.Clock, before, every time.Duration) *Reminder {
+	if notifier == nil {
+		notifier = LogNotifier
+	}
+	if clock == nil {
+		clock = promo.SystemClock
+	}
+	return &Reminder{dbc, notifier, clock, before, every}
+}
+
+// Tick reminds the respondents due a reminder now and records it. Failing
+// reminders do not keep the others from being sent; their errors are
+// returned together.
+func (r *Reminder) Tick(ctx context.Context) error {
+	fs, ok := r.dbc.(promo.FeedbackStore)
+	if !ok {
+		return nil
+	}
+	all, err := fs.Feedbacks(0)
+	if err != nil {
+		return err
+	}
+	now := r.clock.Now()
+	var errs []error
+	for _, f := range all {
+		if !now.Before(f.DueAt) || now.Before(f.DueAt.Add(-r.before)) {
+			continue
+		}
+		for _, rs := range f.Respondents {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			if rs.Answered || rs.RemindedAt != nil && now.Sub(*rs.RemindedAt) < r.every {
+				continue
+			}
+			if err = r.notifier.Remind(ctx, f, rs.UserId); err != nil {
+				errs = append(errs, fmt.Errorf("unable to remind user %d of feedback %d: %w", rs.UserId, f.Id, err))
+				continue
+			}
+			if err = fs.Remind(f.Id, rs.UserId, now); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run ticks every interval until ctx is done, like cycles.Scheduler.
+func (r *Reminder) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := r.Tick(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to send feedback reminders: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
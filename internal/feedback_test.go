@@ -0,0 +1,98 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Feedback(t *testing.T) {
+	due := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	t.Run("Check asking for feedback (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(createFeedbackQuery)).WithArgs(2, nullableId(1), "m", due).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(addRespondentQuery)).WithArgs(1, 3, RelationPeer, "c").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		id, err := r.CreateFeedback(Feedback{UserId: 2, ManagerId: 1, DueAt: due,
+			Respondents: []Respondent{{UserId: 3, Relation: RelationPeer}}}, "m", map[int]string{3: "c"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check getting feedback (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		req, respondents, args := feedbackQueries(1, 0)
+		reminded := due.Add(-24 * time.Hour)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "manager_id", "due_at"}).AddRow(1, 2, 1, due))
+		mock.ExpectQuery(regexp.QuoteMeta(respondents)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"feedback_id", "respondent_id", "relation", "answered", "reminded_at"}).
+				AddRow(1, 1, RelationManager, false, &reminded).
+				AddRow(1, 3, RelationPeer, true, (*time.Time)(nil)))
+		f, err := r.Feedback(1)
+		require.NoError(t, err)
+		assert.Equal(t, &Feedback{Id: 1, UserId: 2, ManagerId: 1, DueAt: due, Respondents: []Respondent{
+			{UserId: 1, Relation: RelationManager, RemindedAt: &reminded},
+			{UserId: 3, Relation: RelationPeer, Answered: true},
+		}}, f)
+	})
+	t.Run("Check getting feedback (registry, absent)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		req, _, args := feedbackQueries(42, 0)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "manager_id", "due_at"}))
+		_, err := r.Feedback(42)
+		assert.ErrorIs(t, err, ErrFeedbackNotFound)
+	})
+	t.Run("Check feedback keys (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(feedbackKeyQuery)).WithArgs(1, "c").
+			WillReturnRows(pgxmock.NewRows([]string{"respondent_id"}).AddRow(3))
+		mock.ExpectQuery(regexp.QuoteMeta(feedbackKeyQuery)).WithArgs(1, "nope").
+			WillReturnRows(pgxmock.NewRows([]string{"respondent_id"}))
+		respondent, err := r.FeedbackKey(1, "c")
+		require.NoError(t, err)
+		assert.Equal(t, 3, respondent)
+		_, err = r.FeedbackKey(1, "nope")
+		assert.ErrorIs(t, err, ErrFeedbackKey)
+	})
+	t.Run("Check responding (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(feedbackResponseQuery)).WithArgs(1, 3).
+			WillReturnRows(pgxmock.NewRows([]string{"answered", "rating", "strengths", "improvements", "anonymous"}).
+				AddRow(false, 0, "", "", false))
+		resp, err := r.FeedbackResponse(1, 3)
+		require.NoError(t, err)
+		assert.Nil(t, resp, "not answered yet")
+
+		mock.ExpectExec(regexp.QuoteMeta(respondQuery)).
+			WithArgs(1, 3, 4, nullable("care"), (*string)(nil), true, pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		require.NoError(t, r.Respond(1, 3, FeedbackResponse{Rating: 4, Strengths: "care", Anonymous: true}))
+
+		mock.ExpectQuery(regexp.QuoteMeta(feedbackAnswersQuery)).WithArgs(1).
+			WillReturnRows(pgxmock.NewRows([]string{"respondent_id", "relation", "rating", "strengths", "improvements", "anonymous"}).
+				AddRow(3, RelationPeer, 4, "care", "", true))
+		as, err := r.FeedbackAnswers(1)
+		require.NoError(t, err)
+		assert.Equal(t, []FeedbackAnswer{{RespondentId: 3, Relation: RelationPeer,
+			FeedbackResponse: FeedbackResponse{Rating: 4, Strengths: "care", Anonymous: true}}}, as)
+	})
+	t.Run("Check responding (registry, not asked)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectExec(regexp.QuoteMeta(respondQuery)).
+			WithArgs(1, 9, 4, (*string)(nil), (*string)(nil), false, pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, r.Respond(1, 9, FeedbackResponse{Rating: 4}), ErrFeedbackNotFound)
+		mock.ExpectExec(regexp.QuoteMeta(remindQuery)).WithArgs(1, 9, due).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, r.Remind(1, 9, due), ErrFeedbackNotFound)
+	})
+}
@@ -0,0 +1,142 @@
+package promo
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrGoalNotFound = errors.New("goal not found")
+
+// GoalStatus is where a goal stands.
+type GoalStatus string
+
+const (
+	GoalOpen       GoalStatus = "open"
+	GoalInProgress GoalStatus = "in_progress"
+	GoalDone       GoalStatus = "done"
+	GoalDropped    GoalStatus = "dropped"
+)
+
+// GoalStatuses are the statuses of goals, in the order they are worked
+// through.
+var GoalStatuses = []GoalStatus{GoalOpen, GoalInProgress, GoalDone, GoalDropped}
+
+// Valid tells whether s is one of GoalStatuses.
+func (s GoalStatus) Valid() bool {
+	for _, v := range GoalStatuses {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Closed tells whether goals of status s are no longer worked on.
+func (s GoalStatus) Closed() bool {
+	return s == GoalDone || s == GoalDropped
+}
+
+// GoalNote is a note on the progress of a goal written at At.
+type GoalNote struct {
+	Note string    `json:"note" yaml:"note"`
+	At   time.Time `json:"at" yaml:"at"`
+}
+
+// Goal is a goal of the development plan of user UserId, towards their
+// promotion to TargetGrade unless 0, due by DueDate unless empty, with the
+// notes on its progress, oldest first.
+type Goal struct {
+	Id          int    `json:"id" yaml:"id"`
+	UserId      int    `json:"user_id" yaml:"user_id"`
+	Title       string `json:"title" yaml:"title" validate:"required,max=120"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty" validate:"max=2000"`
+	TargetGrade Grade  `json:"target_grade,omitempty" yaml:"target_grade,omitempty"`
+	// DueDate is formatted as DateLayout.
+	DueDate string     `json:"due_date,omitempty" yaml:"due_date,omitempty" validate:"date"`
+	Status  GoalStatus `json:"status" yaml:"status"`
+	Notes   []GoalNote `json:"notes" yaml:"notes"`
+}
+
+// CheckFields checks that g aims at a grade, if any, and has a known status.
+func (g *Goal) CheckFields() ValidationErrors {
+	var errs ValidationErrors
+	if g.TargetGrade != 0 && dGrades[g.TargetGrade] == "" {
+		errs = append(errs, FieldError{Field: "target_grade", Rule: RuleEnum, Detail: "is not a grade"})
+	}
+	if !g.Status.Valid() {
+		errs = append(errs, FieldError{Field: "status", Rule: RuleEnum, Detail: "is not one of open, in_progress, done and dropped"})
+	}
+	return errs
+}
+
+// Overdue tells whether g is still worked on past its due date on today,
+// formatted as DateLayout.
+func (g *Goal) Overdue(today string) bool {
+	return g.DueDate != "" && g.DueDate < today && !g.Status.Closed()
+}
+
+// GoalFilter selects the goals of live users; zero fields match
+// everything. OverdueOn, formatted as DateLayout, selects the goals
+// overdue on that day.
+type GoalFilter struct {
+	UserId      int
+	Project     string
+	TargetGrade Grade
+	OverdueOn   string
+}
+
+// GoalStore keeps the development plans of users.
+type GoalStore interface {
+	// AddGoal records g for a live user, ErrNotFound otherwise, and returns
+	// its id. Its notes are left out.
+	AddGoal(g Goal) (int, error)
+	// Goal is goal id, ErrGoalNotFound if there is none.
+	Goal(id int) (*Goal, error)
+	// Goals lists the goals matching f by id.
+	Goals(f GoalFilter) ([]Goal, error)
+	// UpdateGoal replaces the title, description, target grade, due date
+	// and status of goal g.Id.
+	UpdateGoal(g Goal) error
+	// AddGoalNote appends n to the notes of goal id.
+	AddGoalNote(id int, n GoalNote) error
+}
+
+// Goal queries shared by Registry and SQLite.
+const (
+	addGoalQuery     = "INSERT INTO goal (user_id, title, description, target_grade, due_date, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	updateGoalQuery  = "UPDATE goal SET title=$2, description=$3, target_grade=$4, due_date=$5, status=$6 WHERE id=$1"
+	goalExistsQuery  = "SELECT EXISTS (SELECT 1 FROM goal WHERE id=$1)"
+	addGoalNoteQuery = "INSERT INTO goal_note (goal_id, note, at) VALUES ($1, $2, $3)"
+)
+
+// goalQueries select the goals matching f, and goal id unless 0, by id,
+// and their notes, with $n parameters.
+func goalQueries(f GoalFilter, id int) (goals, notes string, args []any) {
+	cond := []string{"u.deleted_at IS NULL"}
+	if id != 0 {
+		args = append(args, id)
+		cond = append(cond, "g.id="+pgParam(len(args)))
+	}
+	if f.UserId != 0 {
+		args = append(args, f.UserId)
+		cond = append(cond, "g.user_id="+pgParam(len(args)))
+	}
+	if f.Project != "" {
+		args = append(args, f.Project)
+		cond = append(cond, "u.project="+pgParam(len(args)))
+	}
+	if f.TargetGrade != 0 {
+		args = append(args, dGrades[f.TargetGrade])
+		cond = append(cond, "g.target_grade="+pgParam(len(args)))
+	}
+	if f.OverdueOn != "" {
+		args = append(args, f.OverdueOn)
+		cond = append(cond, "g.due_date < "+pgParam(len(args))+" AND g.status NOT IN ('done', 'dropped')")
+	}
+	from := " FROM goal g JOIN usr u ON u.id=g.user_id WHERE " + strings.Join(cond, " AND ")
+	goals = "SELECT g.id, g.user_id, g.title, COALESCE(g.description, ''), COALESCE(CAST(g.target_grade AS TEXT), ''), " +
+		"COALESCE(CAST(g.due_date AS TEXT), ''), g.status" + from + " ORDER BY g.id"
+	notes = "SELECT goal_id, note, at FROM goal_note WHERE goal_id IN (SELECT g.id" + from + ") ORDER BY at, id"
+	return goals, notes, args
+}
@@ -0,0 +1,56 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Goals(t *testing.T) {
+	exists := func(ok bool) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"exists"}).AddRow(ok)
+	}
+	t.Run("Check setting a goal (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(liveUserQuery)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectQuery(regexp.QuoteMeta(addGoalQuery)).
+			WithArgs(1, "Lead", (*string)(nil), nullable("middle"), nullable("2026-03-01"), "open").
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		id, err := r.AddGoal(Goal{UserId: 1, Title: "Lead", TargetGrade: middle, DueDate: "2026-03-01", Status: GoalOpen})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check setting a goal (registry, absent user)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(liveUserQuery)).WithArgs(42).WillReturnRows(exists(false))
+		_, err := r.AddGoal(Goal{UserId: 42, Title: "Lead", Status: GoalOpen})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("Check getting a goal (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		at := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+		req, notes, args := goalQueries(GoalFilter{}, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "title", "description", "target_grade", "due_date", "status"}).
+				AddRow(1, 1, "Lead", "", "middle", "2026-03-01", "in_progress"))
+		mock.ExpectQuery(regexp.QuoteMeta(notes)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"goal_id", "note", "at"}).AddRow(1, "design reviewed", at))
+		g, err := r.Goal(1)
+		require.NoError(t, err)
+		assert.Equal(t, &Goal{Id: 1, UserId: 1, Title: "Lead", TargetGrade: middle, DueDate: "2026-03-01",
+			Status: GoalInProgress, Notes: []GoalNote{{Note: "design reviewed", At: at}}}, g)
+	})
+	t.Run("Check updating a goal (registry, absent)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectExec(regexp.QuoteMeta(updateGoalQuery)).
+			WithArgs(42, "Lead", (*string)(nil), (*string)(nil), (*string)(nil), "done").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		assert.ErrorIs(t, r.UpdateGoal(Goal{Id: 42, Title: "Lead", Status: GoalDone}), ErrGoalNotFound)
+		mock.ExpectQuery(regexp.QuoteMeta(goalExistsQuery)).WithArgs(42).WillReturnRows(exists(false))
+		assert.ErrorIs(t, r.AddGoalNote(42, GoalNote{Note: "lost"}), ErrGoalNotFound)
+	})
+}
@@ -0,0 +1,24 @@
+// Package goals keeps the individual development plans of users: goals
+// such as "lead a feature" towards the promotion to a target grade, due by
+// a date, with notes on their progress. Goals of a project still worked on
+// past their due date are reported as overdue.
+//
+// Goals are kept by stores implementing promo.GoalStore. Their completion
+// towards the next grade of a user shows on its eligibility, see package
+// eligibility.
+package goals
+
+import (
+	promo "AndersenPromo/internal"
+	"fmt"
+	"time"
+)
+
+// ErrNotSupported is returned against stores keeping no goals.
+var ErrNotSupported = fmt.Errorf("goals are %w", promo.ErrNotSupported)
+
+// Overdue lists the goals of the live users of project, everyone when
+// empty, still worked on past their due date at now, by id.
+func Overdue(gs promo.GoalStore, project string, now time.Time) ([]promo.Goal, error) {
+	return gs.Goals(promo.GoalFilter{Project: project, OverdueOn: now.Format(promo.DateLayout)})
+}
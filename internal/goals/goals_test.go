@@ -0,0 +1,144 @@
+package goals
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/promotest"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+
+// newStore has a junior on Alpha and a senior on Beta.
+func newStore(t *testing.T) *promo.Memory {
+	return promotest.NewStore(t,
+		promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Ann", Surname: "Boss", Position: 4, Project: "Beta"})
+}
+
+func newRouter(dbc promo.DBConnexion) *mux.Router {
+	h := NewHandlers(dbc)
+	h.clock = promo.ClockFunc(func() time.Time { return now })
+	return promotest.NewRouter(h)
+}
+
+func TestOverdue(t *testing.T) {
+	m := newStore(t)
+	for _, g := range []promo.Goal{
+		{UserId: 1, Title: "Lead", Status: promo.GoalOpen, DueDate: "2026-03-31"},
+		{UserId: 1, Title: "Test", Status: promo.GoalDone, DueDate: "2026-03-31"},
+		{UserId: 1, Title: "Talk", Status: promo.GoalInProgress, DueDate: "2026-04-01"},
+		{UserId: 1, Title: "Blog", Status: promo.GoalOpen},
+		{UserId: 2, Title: "Hire", Status: promo.GoalInProgress, DueDate: "2026-01-01"},
+	} {
+		_, err := m.AddGoal(g)
+		require.NoError(t, err)
+	}
+	list, err := Overdue(m, "Alpha", now)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Lead", list[0].Title)
+	list, err = Overdue(m, "", now)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func TestHandlers(t *testing.T) {
+	t.Run("Check goals", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/users/1/goals", `{"title": " Lead a feature ", "due_date": "2026-03-01"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var g promo.Goal
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&g))
+		assert.Equal(t, promo.Goal{Id: 1, UserId: 1, Title: "Lead a feature", TargetGrade: 3, DueDate: "2026-03-01",
+			Status: promo.GoalOpen, Notes: []promo.GoalNote{}}, g)
+		w = promotest.Serve(router, http.MethodPost, "/users/2/goals", `{"title": "Hire a team"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		g = promo.Goal{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&g))
+		assert.Zero(t, g.TargetGrade, "seniors have no grade above")
+
+		w = promotest.Serve(router, http.MethodGet, "/projects/Alpha/overdue-goals", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list []promo.Goal
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list, 1)
+		assert.Equal(t, 1, list[0].Id)
+
+		w = promotest.Serve(router, http.MethodPost, "/goals/1/notes", `{"note": "design reviewed"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		g = promo.Goal{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&g))
+		assert.Equal(t, []promo.GoalNote{{Note: "design reviewed", At: now}}, g.Notes)
+
+		w = promotest.Serve(router, http.MethodPut, "/goals/1", `{"title": "Lead a feature", "status": "done"}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		g = promo.Goal{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&g))
+		assert.Equal(t, promo.GoalDone, g.Status)
+		assert.Equal(t, promo.Grade(3), g.TargetGrade, "kept")
+		assert.Empty(t, g.DueDate)
+		assert.Len(t, g.Notes, 1)
+
+		w = promotest.Serve(router, http.MethodGet, "/projects/Alpha/overdue-goals", "")
+		list = nil
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		assert.Empty(t, list)
+		w = promotest.Serve(router, http.MethodGet, "/users/1/goals", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		list = nil
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		assert.Len(t, list, 1)
+	})
+	t.Run("Check adding a note (retried)", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/users/1/goals", `{"title": "Lead a feature"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		for _, replayed := range []string{"", "true"} {
+			w = promotest.Serve(router, http.MethodPost, "/goals/1/notes", `{"note": "design reviewed"}`, promo.IdempotencyKeyHeader, "k1")
+			assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Equal(t, replayed, w.Header().Get(promo.IdempotentReplayedHeader))
+		}
+		w = promotest.Serve(router, http.MethodGet, "/goals/1", "")
+		var g promo.Goal
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&g))
+		assert.Len(t, g.Notes, 1)
+	})
+	t.Run("Check goals (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t)), http.MethodPost, "/users/1/goals",
+			`{"title": " ", "target_grade": 2, "due_date": "April", "status": "started"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "title", Rule: promo.RuleRequired, Detail: "is required"},
+			{Field: "due_date", Rule: promo.RuleDate, Detail: "is not a date written 2006-01-02"},
+			{Field: "status", Rule: promo.RuleEnum, Detail: "is not one of open, in_progress, done and dropped"},
+			{Field: "target_grade", Rule: promo.RuleInvalid, Detail: "is not above the grade of the user"},
+		}, p.Errors)
+	})
+	for name, req := range map[string]struct{ method, url, body string }{
+		"absent goal":    {http.MethodGet, "/goals/42", ""},
+		"update absent":  {http.MethodPut, "/goals/42", `{"title": "lost"}`},
+		"note absent":    {http.MethodPost, "/goals/42/notes", `{"note": "lost"}`},
+		"absent user":    {http.MethodGet, "/users/42/goals", ""},
+		"goal of absent": {http.MethodPost, "/users/42/goals", `{"title": "lost"}`},
+		"absent project": {http.MethodGet, "/projects/Omega/overdue-goals", ""},
+	} {
+		t.Run("Check goals ("+name+")", func(t *testing.T) {
+			w := promotest.Serve(newRouter(newStore(t)), req.method, req.url, req.body)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+	t.Run("Check goals (no goals)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(struct{ promo.DBConnexion }{newStore(t)}), http.MethodGet, "/goals/1", "")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
@@ -0,0 +1,375 @@
+package goals
+
+import (
+	promo "AndersenPromo/internal"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the development plans of a store.
+type Handlers struct {
+	dbc   promo.DBConnexion
+	clock promo.Clock
+}
+
+func NewHandlers(dbc promo.DBConnexion) *Handlers {
+	return &Handlers{dbc, promo.SystemClock}
+}
+
+// Register mounts the goal routes on router. POST and PUT routes honour the
+// Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/users/{id:[0-9]+}/goals", promo.Idempotent(h.dbc, h.CreateGoal)).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id:[0-9]+}/goals", h.GetUserGoals).Methods(http.MethodGet)
+	router.HandleFunc("/goals/{id:[0-9]+}", h.GetGoal).Methods(http.MethodGet)
+	router.HandleFunc("/goals/{id:[0-9]+}", promo.Idempotent(h.dbc, h.UpdateGoal)).Methods(http.MethodPut)
+	router.HandleFunc("/goals/{id:[0-9]+}/notes", promo.Idempotent(h.dbc, h.AddNote)).Methods(http.MethodPost)
+	router.HandleFunc("/projects/{name}/overdue-goals", h.GetOverdue).Methods(http.MethodGet)
+}
+
+// GoalRequest is a goal to record. TargetGrade and Status default to the
+// grade above the user and open on creation, to the current ones on
+// update.
+type GoalRequest struct {
+	Title       string           `json:"title" yaml:"title"`
+	Description string           `json:"description" yaml:"description"`
+	TargetGrade promo.Grade      `json:"target_grade" yaml:"target_grade"`
+	DueDate     string           `json:"due_date" yaml:"due_date"`
+	Status      promo.GoalStatus `json:"status" yaml:"status"`
+}
+
+// NoteRequest is a note on the progress of a goal.
+type NoteRequest struct {
+	Note string `json:"note" yaml:"note" validate:"required,max=2000"`
+}
+
+// store is the GoalStore of h, answering 501 itself when there is none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.GoalStore, bool) {
+	return promo.Capability[promo.GoalStore](w, h.dbc, ErrNotSupported)
+}
+
+// isGrade tells whether g is one of promo.Grades.
+func isGrade(g promo.Grade) bool {
+	for _, v := range promo.Grades() {
+		if g == v {
+			return true
+		}
+	}
+	return false
+}
+
+// goal is the goal req describes over g, validated against dbc. The error
+// lists the failing fields as promo.ValidationErrors.
+func goal(dbc promo.DBConnexion, g promo.Goal, req GoalRequest) (promo.Goal, error) {
+	g.Title, g.Description = strings.TrimSpace(req.Title), strings.TrimSpace(req.Description)
+	g.DueDate = strings.TrimSpace(req.DueDate)
+	if req.TargetGrade != 0 {
+		g.TargetGrade = req.TargetGrade
+	}
+	if req.Status != "" {
+		g.Status = req.Status
+	}
+	return g, promo.NewValidator(dbc).Validate(&g, false)
+}
+
+// CreateGoal	 godoc
+//
+//	@Summary		Add goal
+//	@Description	add a goal to the development plan of a user, towards the grade above theirs unless told which
+//	@Tags			goals
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id		path		int					true	"User ID"
+//	@Param			goal	body		goals.GoalRequest	true	"Goal"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201		{object}	promo.Goal
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		415		{object}	promo.Problem
+//	@Failure		422		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/users/{id}/goals [post]
+func (h *Handlers) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	gs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	user, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req GoalRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	u, err := h.dbc.GetUser(user)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	g := promo.Goal{UserId: user, Status: promo.GoalOpen}
+	if next, ok := u.Position.Next(); ok {
+		g.TargetGrade = next
+	}
+	g, err = goal(h.dbc, g, req)
+	var errs promo.ValidationErrors
+	if err != nil && !errors.As(err, &errs) {
+		promo.Invalid(w, err)
+		return
+	}
+	if isGrade(g.TargetGrade) && g.TargetGrade <= u.Position {
+		errs = append(errs, promo.FieldError{Field: "target_grade", Rule: promo.RuleInvalid,
+			Detail: "is not above the grade of the user"})
+	}
+	if err = errs.Err(); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	id, err := gs.AddGoal(g)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	created, err := gs.Goal(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, created)
+}
+
+// GetUserGoals	 godoc
+//
+//	@Summary		User goals
+//	@Description	the development plan of a user, by id
+//	@Tags			goals
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{array}		promo.Goal
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/users/{id}/goals [get]
+func (h *Handlers) GetUserGoals(w http.ResponseWriter, r *http.Request) {
+	gs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	user, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if _, err := h.dbc.GetUser(user); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	list, err := gs.Goals(promo.GoalFilter{UserId: user})
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
+
+// GetGoal	 godoc
+//
+//	@Summary		Goal
+//	@Tags			goals
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Goal ID"
+//	@Success		200	{object}	promo.Goal
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/goals/{id} [get]
+func (h *Handlers) GetGoal(w http.ResponseWriter, r *http.Request) {
+	gs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	g, err := gs.Goal(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, g)
+}
+
+// UpdateGoal	 godoc
+//
+//	@Summary		Update goal
+//	@Description	replace the title, description, target grade, due date and status of a goal, keeping its notes
+//	@Tags			goals
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id		path		int					true	"Goal ID"
+//	@Param			goal	body		goals.GoalRequest	true	"Goal"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200		{object}	promo.Goal
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		415		{object}	promo.Problem
+//	@Failure		422		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/goals/{id} [put]
+func (h *Handlers) UpdateGoal(w http.ResponseWriter, r *http.Request) {
+	gs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req GoalRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	old, err := gs.Goal(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	g, err := goal(h.dbc, promo.Goal{Id: id, UserId: old.UserId, TargetGrade: old.TargetGrade, Status: old.Status}, req)
+	if err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	if err = gs.UpdateGoal(g); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	updated, err := gs.Goal(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, updated)
+}
+
+// AddNote	 godoc
+//
+//	@Summary		Add progress note
+//	@Description	note the progress of a goal now
+//	@Tags			goals
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id		path		int					true	"Goal ID"
+//	@Param			note	body		goals.NoteRequest	true	"Note"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201		{object}	promo.Goal
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		415		{object}	promo.Problem
+//	@Failure		422		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/goals/{id}/notes [post]
+func (h *Handlers) AddNote(w http.ResponseWriter, r *http.Request) {
+	gs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req NoteRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if err := promo.NewValidator(h.dbc).Validate(&req, false); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	if err := gs.AddGoalNote(id, promo.GoalNote{Note: req.Note, At: h.clock.Now()}); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	g, err := gs.Goal(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, g)
+}
+
+// GetOverdue	 godoc
+//
+//	@Summary		Overdue goals
+//	@Description	the goals of the users of a project still worked on past their due date, by id
+//	@Tags			goals
+//	@Produce		json,application/yaml
+//	@Param			name	path		string	true	"Project name"
+//	@Success		200		{array}		promo.Goal
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/projects/{name}/overdue-goals [get]
+func (h *Handlers) GetOverdue(w http.ResponseWriter, r *http.Request) {
+	gs, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	project := mux.Vars(r)["name"]
+	if ps, ok := h.dbc.(promo.ProjectStore); ok {
+		exists, err := ps.ProjectExists(project)
+		if err != nil {
+			promo.DBError(w, err)
+			return
+		}
+		if !exists {
+			promo.Fail(w, fmt.Errorf("no project %q: %w", project, promo.ErrProjectNotFound), http.StatusNotFound)
+			return
+		}
+	}
+	list, err := Overdue(gs, project, h.clock.Now())
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
@@ -0,0 +1,78 @@
+package promo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HistoryStore keeps every version of its users while live, so that past
+// states can be read back: deleting a user ends its current version and
+// restoring starts a new one. GetUser and GetUserList answer 501 for as_of
+// against other stores.
+type HistoryStore interface {
+	// UserAsOf is user id as it was at t, ErrNotFound if it was not live
+	// then.
+	UserAsOf(id int, t time.Time) (*User, error)
+	// UsersAsOf lists the users live at t as they were then, the filter
+	// applying to those past versions. IncludeDeleted is ignored.
+	UsersAsOf(f UserFilter, t time.Time) ([]User, error)
+	// History lists the versions of user id, oldest first, empty when
+	// there are none.
+	History(id int) ([]Version, error)
+}
+
+// Version is a user as it was from ValidFrom until ValidTo. ValidFrom is
+// nil for a user already there, with no hire date, when the history began;
+// ValidTo is nil for the current version.
+type Version struct {
+	User      `yaml:",inline"`
+	ValidFrom *time.Time `json:"valid_from,omitempty" yaml:"valid_from,omitempty"`
+	ValidTo   *time.Time `json:"valid_to,omitempty" yaml:"valid_to,omitempty"`
+}
+
+// userAsOfQuery reads the version of user $1 valid at $2. Versions are
+// valid from valid_from, included, to valid_to, excluded.
+const userAsOfQuery = "SELECT " + userColumns + " FROM usr_history WHERE id=$1 AND valid_from<=$2 AND (valid_to IS NULL OR valid_to>$2)"
+
+// userHistoryQuery lists the versions of user $1, oldest first. Any time $2
+// comes after the valid_from of users already there when the history began
+// (-infinity in Postgres, empty in SQLite), which reads as NULL.
+const userHistoryQuery = "SELECT " + userColumns + ", CASE WHEN valid_from>$2 THEN valid_from END, valid_to FROM usr_history WHERE id=$1 ORDER BY valid_from"
+
+// historyQuery is userQuery over the versions valid at t, with $n
+// parameters.
+func historyQuery(f UserFilter, t time.Time) (string, []any) {
+	cond, args := userConds(f, pgParam)
+	args = append(args, t)
+	p := pgParam(len(args))
+	cond = append(cond, "valid_from<="+p, "(valid_to IS NULL OR valid_to>"+p+")")
+	return "SELECT " + userColumns + " FROM usr_history WHERE " + strings.Join(cond, " AND ") + " ORDER BY id", args
+}
+
+// parseAsOf reads the as_of parameter, either a time in RFC 3339 or a date
+// standing for its midnight UTC. ok is false when there is none.
+func parseAsOf(r *http.Request) (t time.Time, ok bool, err error) {
+	s := r.URL.Query().Get("as_of")
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err = time.Parse(DateLayout, s); err == nil {
+		return t, true, nil
+	}
+	if t, err = time.Parse(time.RFC3339, s); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("illegal as_of %q, want %s or RFC 3339", s, DateLayout)
+}
+
+// historyStore is the HistoryStore of h, answering 501 itself when there is
+// none.
+func (h *Handlers) historyStore(w http.ResponseWriter) (HistoryStore, bool) {
+	hs, ok := h.dbc.(HistoryStore)
+	if !ok {
+		Error(w, "as_of is not supported by this store", http.StatusNotImplemented)
+	}
+	return hs, ok
+}
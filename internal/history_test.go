@@ -0,0 +1,81 @@
+package promo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlers_AsOf(t *testing.T) {
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	t.Run("Check getting user as of a date (registry)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		h := &Handlers{&Registry{mock}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(userAsOfQuery)).WithArgs(5, march).
+			WillReturnRows(pgxmock.NewRows(userCols).AddRow(5, "And", "Ersen", "junior", "Test", "", "", "", "", 0))
+		req := httptest.NewRequest(http.MethodGet, "/users/5?as_of=2026-03-01", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.GetUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"id":5,"name":"And","surname":"Ersen","position":2,"project":"Test"}`, w.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Check getting user list as of a time (registry)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		h := &Handlers{&Registry{mock}}
+
+		at := time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("", 3*3600))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT "+userColumns+" FROM usr_history WHERE project=$1 AND valid_from<=$2 AND (valid_to IS NULL OR valid_to>$2) ORDER BY id")).
+			WithArgs("Test", at).
+			WillReturnRows(pgxmock.NewRows(userCols).AddRow(5, "And", "Ersen", "junior", "Test", "", "", "", "", 0))
+		req := httptest.NewRequest(http.MethodGet, "/users?project=Test&as_of=2026-03-01T12:30:00%2B03:00", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `[{"id":5,"name":"And","surname":"Ersen","position":2,"project":"Test"}]`, w.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Check getting user as of a time (not live then)", func(t *testing.T) {
+		m := NewMemory()
+		require.NoError(t, m.AddUser("And", "Ersen", junior, "Test"))
+		h := &Handlers{m}
+		req := httptest.NewRequest(http.MethodGet, "/users/1?as_of=2026-03-01", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.GetUser(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+	for _, q := range []string{"as_of=2026-03", "as_of=yesterday", "as_of=2026-03-01&include_deleted=true"} {
+		t.Run("Check getting user list as of a time (bad query "+q+")", func(t *testing.T) {
+			h := &Handlers{NewMemory()}
+			req := httptest.NewRequest(http.MethodGet, "/users?"+q, nil)
+			w := httptest.NewRecorder()
+			h.GetUserList(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	t.Run("Check getting user list as of a time (no history)", func(t *testing.T) {
+		h := &Handlers{struct{ DBConnexion }{NewMemory()}}
+		req := httptest.NewRequest(http.MethodGet, "/users?as_of=2026-03-01", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
@@ -0,0 +1,203 @@
+package promo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader lets clients retry a POST, PUT or PATCH safely:
+	// the first response sent for a key is replayed for every retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on replayed responses.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyLease is how long a claim blocks its key while the first
+	// request is served, so that a crash does not block it for a whole TTL.
+	idempotencyLease  = 5 * time.Minute
+	maxIdempotencyKey = 255
+)
+
+// ErrNotReplayed is reported to the retries of a request whose response,
+// holding secrets, was not kept, see IdempotentOnce.
+var ErrNotReplayed = errors.New("response not replayed")
+
+// IdempotentResponse is what is kept of a request sent with an
+// Idempotency-Key.
+type IdempotentResponse struct {
+	Fingerprint string
+	// Status is 0 while the first request is still being served.
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore keeps IdempotentResponses until they expire. Stores that
+// do not implement it serve every request as if it had no key.
+type IdempotencyStore interface {
+	// BeginIdempotent claims key for a request with fingerprint until
+	// expires, the lease of the claim. It returns nil when the key was free
+	// (or expired), otherwise what is kept for the key.
+	BeginIdempotent(key, fingerprint string, expires time.Time) (*IdempotentResponse, error)
+	// FinishIdempotent records the response of the request that claimed key,
+	// kept until expires.
+	FinishIdempotent(key string, resp IdempotentResponse, expires time.Time) error
+	// ReleaseIdempotent frees key so that the request can be retried.
+	ReleaseIdempotent(key string) error
+	// PurgeIdempotent forgets the keys expired before t.
+	PurgeIdempotent(t time.Time) (int, error)
+}
+
+// Idempotent wraps next so that requests carrying an Idempotency-Key are
+// served at most once, keeping responses in dbc. A retry with the same
+// method, URL, credentials, Accept and body gets the stored response back, a
+// reuse of the key for another request gets 422. Server errors are not
+// stored, the request can be retried for real. A key claimed by a request
+// that never finished, the server having stopped, is free again once the
+// lease of the claim is over.
+func Idempotent(dbc DBConnexion, next http.HandlerFunc) http.HandlerFunc {
+	return idempotent(dbc, next, false)
+}
+
+// IdempotentOnce is Idempotent for the routes whose responses hold secrets,
+// such as keys, which are told once and never stored: a retry of a request
+// that succeeded gets 409 with ErrNotReplayed instead of the response.
+func IdempotentOnce(dbc DBConnexion, next http.HandlerFunc) http.HandlerFunc {
+	return idempotent(dbc, next, true)
+}
+
+func idempotent(dbc DBConnexion, next http.HandlerFunc, once bool) http.HandlerFunc {
+	store, ok := dbc.(IdempotencyStore)
+	if !ok {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			Error(w, fmt.Sprintf("%s longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKey),
+				http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fp := fingerprint(r, body)
+		prev, err := store.BeginIdempotent(key, fp, time.Now().Add(idempotencyLease))
+		if err != nil {
+			DBError(w, err)
+			return
+		}
+		if prev != nil {
+			switch {
+			case prev.Fingerprint != fp:
+				Error(w, fmt.Sprintf("%s already used for another request", IdempotencyKeyHeader),
+					http.StatusUnprocessableEntity)
+			case prev.Status == 0:
+				Error(w, fmt.Sprintf("a request with this %s is still in progress", IdempotencyKeyHeader),
+					http.StatusConflict)
+			default:
+				if prev.ContentType != "" {
+					w.Header().Set("Content-Type", prev.ContentType)
+				}
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(prev.Status)
+				_, _ = w.Write(prev.Body)
+			}
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w}
+		finished := false
+		defer func() {
+			if !finished {
+				if err := store.ReleaseIdempotent(key); err != nil {
+					log.Printf("Failed to release %s %q: %v", IdempotencyKeyHeader, key, err)
+				}
+			}
+		}()
+		next(rec, r)
+		if rec.status >= http.StatusInternalServerError {
+			return
+		}
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		resp := IdempotentResponse{
+			Fingerprint: fp,
+			Status:      rec.status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		}
+		if once && rec.status < http.StatusBadRequest {
+			resp = notReplayed(fp)
+		}
+		err = store.FinishIdempotent(key, resp, time.Now().Add(idempotencyTTL))
+		if err != nil {
+			log.Printf("Failed to store response for %s %q: %v", IdempotencyKeyHeader, key, err)
+			return
+		}
+		finished = true
+	}
+}
+
+// notReplayed is what IdempotentOnce keeps of a response in place of it.
+func notReplayed(fp string) IdempotentResponse {
+	content, _ := json.Marshal(Problem{Type: ProblemType(ErrNotReplayed), Title: http.StatusText(http.StatusConflict),
+		Status: http.StatusConflict,
+		Detail: fmt.Sprintf("the request with this %s was served, its response is told once: %v", IdempotencyKeyHeader, ErrNotReplayed)})
+	return IdempotentResponse{Fingerprint: fp, Status: http.StatusConflict, ContentType: ProblemContentType, Body: content}
+}
+
+// fingerprint tells apart the requests a key may be reused for, those of
+// different bearers of keys and those asking for another content type
+// included.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n%s\n%s\n", r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), r.Header.Get("Accept"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder passes a response through while keeping a copy of it.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
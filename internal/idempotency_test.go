@@ -0,0 +1,122 @@
+package promo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlers_Idempotency(t *testing.T) {
+	newServer := func(t *testing.T) (*Memory, http.Handler) {
+		m := NewMemory()
+		require.NoError(t, m.AddProject("Test"))
+		router := mux.NewRouter()
+		NewHandlersWith(m).Register(router)
+		return m, router
+	}
+	send := func(h http.Handler, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	const body = `{"name":"And","surname":"Ersen","position":3,"project":"Test"}`
+
+	t.Run("Check creating user (retried with key)", func(t *testing.T) {
+		m, h := newServer(t)
+		w := send(h, "k1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+		w = send(h, "k1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+		us, err := m.GetAllUsers(UserFilter{})
+		require.NoError(t, err)
+		assert.Len(t, *us, 1)
+	})
+	t.Run("Check creating user (retried without key)", func(t *testing.T) {
+		m, h := newServer(t)
+		assert.Equal(t, http.StatusOK, send(h, "", body).Code)
+		w := send(h, "", body)
+		assert.Equal(t, http.StatusConflict, w.Code, "the retry is served again, as a duplicate")
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+		us, err := m.GetAllUsers(UserFilter{})
+		require.NoError(t, err)
+		assert.Len(t, *us, 1)
+	})
+	t.Run("Check creating user (key reused for another body)", func(t *testing.T) {
+		_, h := newServer(t)
+		send(h, "k1", body)
+		w := send(h, "k1", strings.Replace(body, "And", "Bob", 1))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	})
+	t.Run("Check creating user (first request in progress)", func(t *testing.T) {
+		m, h := newServer(t)
+		req := httptest.NewRequest(http.MethodPost, "/create", nil)
+		_, err := m.BeginIdempotent("k1", fingerprint(req, []byte(body)), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		w := send(h, "k1", body)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("Check creating user (claim left by a crash)", func(t *testing.T) {
+		m, h := newServer(t)
+		req := httptest.NewRequest(http.MethodPost, "/create", nil)
+		_, err := m.BeginIdempotent("k1", fingerprint(req, []byte(body)), time.Now().Add(-time.Second))
+		require.NoError(t, err)
+		w := send(h, "k1", body)
+		assert.Equal(t, http.StatusOK, w.Code, "the lease of the claim is over")
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	})
+	t.Run("Check creating user (key reused with another Accept)", func(t *testing.T) {
+		_, h := newServer(t)
+		assert.Equal(t, http.StatusOK, send(h, "k1", body).Code)
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/yaml")
+		req.Header.Set(IdempotencyKeyHeader, "k1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	})
+	t.Run("Check creating user (client errors are replayed)", func(t *testing.T) {
+		_, h := newServer(t)
+		bad := strings.Replace(body, "And", "4nd", 1)
+		assert.Equal(t, http.StatusUnprocessableEntity, send(h, "k1", bad).Code)
+		w := send(h, "k1", bad)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	})
+	t.Run("Check server errors are not stored", func(t *testing.T) {
+		m := NewMemory()
+		calls := 0
+		next := Idempotent(m, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		})
+		for _, want := range []int{http.StatusInternalServerError, http.StatusCreated, http.StatusCreated} {
+			req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+			req.Header.Set(IdempotencyKeyHeader, "k1")
+			w := httptest.NewRecorder()
+			next(w, req)
+			assert.Equal(t, want, w.Code)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,298 @@
+package promo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	maxImportSize   = 10 << 20
+)
+
+// importColumns are the User fields a file may provide, with the headers
+// recognised for each of them when no explicit mapping is given. They take
+// back the exportHeader.
+var importColumns = map[string][]string{
+	"name":            {"name", "first name", "firstname"},
+	"surname":         {"surname", "last name", "lastname"},
+	"position":        {"position", "grade"},
+	"project":         {"project"},
+	"email":           {"email", "e-mail"},
+	"employee_number": {"employee_number", "employee number"},
+	"hire_date":       {"hire_date", "hire date"},
+	"location":        {"location"},
+	"manager_id":      {"manager_id", "manager id", "manager"},
+}
+
+type RowError struct {
+	Row   int    `json:"row" yaml:"row"`
+	Error string `json:"error" yaml:"error"`
+	// Fields lists the fields of the row failing validation.
+	Fields []FieldError `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// ImportReport is the answer of ImportUsers. Rows are numbered as in the
+// file, so the header is row 1.
+type ImportReport struct {
+	Rows     int        `json:"rows" yaml:"rows"`
+	Imported int        `json:"imported" yaml:"imported"`
+	DryRun   bool       `json:"dry_run" yaml:"dry_run"`
+	Errors   []RowError `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// userIndex finds the user a new one would duplicate, as CreateUser tells:
+// one with the same email or employee number, or, unless force, a live one
+// with the same name. Entries are named "user 3" or "row 2".
+type userIndex struct {
+	force                bool
+	email, number, names map[string]string
+	// live holds the users new ones may report to.
+	live map[int]bool
+}
+
+func newUserIndex(force bool) *userIndex {
+	return &userIndex{force: force, email: map[string]string{}, number: map[string]string{}, names: map[string]string{},
+		live: map[int]bool{}}
+}
+
+func nameKey(u User) string {
+	return strings.ToLower(u.Name) + "\x00" + strings.ToLower(u.Surname)
+}
+
+// add indexes u under entry, keeping the first entry for each key.
+func (x *userIndex) add(u User, entry string) {
+	keep := func(m map[string]string, key string) {
+		if _, ok := m[key]; key != "" && !ok {
+			m[key] = entry
+		}
+	}
+	keep(x.email, u.Email)
+	keep(x.number, u.EmployeeNumber)
+	if u.DeletedAt == nil {
+		keep(x.names, nameKey(u))
+		x.live[u.Id] = true
+	}
+}
+
+// conflicts lists the fields of u that an indexed entry has already.
+func (x *userIndex) conflicts(u User) ValidationErrors {
+	var errs ValidationErrors
+	if e, ok := x.email[u.Email]; ok && u.Email != "" {
+		errs = append(errs, FieldError{"email", RuleDuplicate, "is the email of " + e})
+	}
+	if e, ok := x.number[u.EmployeeNumber]; ok && u.EmployeeNumber != "" {
+		errs = append(errs, FieldError{"employee_number", RuleDuplicate, "is the employee number of " + e})
+	}
+	if e, ok := x.names[nameKey(u)]; ok && !x.force {
+		errs = append(errs, FieldError{"name", RuleDuplicate, "is the name of " + e})
+	}
+	return errs
+}
+
+// indexUsers indexes every user of dbc, deleted ones included.
+func indexUsers(dbc DBConnexion, force bool) (*userIndex, error) {
+	x := newUserIndex(force)
+	err := dbc.EachUser(UserFilter{IncludeDeleted: true}, func(u User) error {
+		x.add(u, fmt.Sprintf("user %d", u.Id))
+		return nil
+	})
+	return x, err
+}
+
+// readTable returns every row of a CSV or XLSX document, header included.
+func readTable(contentType string, b []byte) ([][]string, error) {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch mt {
+	case csvContentType, "application/csv":
+		cr := csv.NewReader(bytes.NewReader(b))
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		return cr.ReadAll()
+	case xlsxContentType:
+		f, err := excelize.OpenReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return f.GetRows(f.GetSheetName(0))
+	}
+	return nil, fmt.Errorf("unsupported content type %q, expected %s or %s", contentType, csvContentType, xlsxContentType)
+}
+
+// columnIndex maps each User field to its column. mapping entries look like
+// "name:First Name" and win over the default headers.
+func columnIndex(header []string, mapping []string) (map[string]int, error) {
+	names := make(map[string][]string, len(importColumns))
+	for k, v := range importColumns {
+		names[k] = v
+	}
+	for _, m := range mapping {
+		field, col, ok := strings.Cut(m, ":")
+		if _, known := importColumns[field]; !ok || !known {
+			return nil, fmt.Errorf("illegal mapping %q", m)
+		}
+		names[field] = []string{strings.ToLower(strings.TrimSpace(col))}
+	}
+	idx := make(map[string]int)
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for field, hs := range names {
+			for _, n := range hs {
+				if h == n {
+					idx[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"name", "surname", "position"} {
+		if _, ok := idx[field]; !ok {
+			return nil, fmt.Errorf("no column for %s", field)
+		}
+	}
+	return idx, nil
+}
+
+func blank(rec []string) bool {
+	for _, c := range rec {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRow(v Validator, rec []string, idx map[string]int) (User, error) {
+	cell := func(field string) string {
+		i, ok := idx[field]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+	u := User{Name: cell("name"), Surname: cell("surname"), Project: cell("project"), Email: cell("email"),
+		EmployeeNumber: cell("employee_number"), HireDate: cell("hire_date"), Location: cell("location")}
+	pos := cell("position")
+	u.Position, _ = ParseGrade(pos)
+	if u.Position == 0 && pos != "" {
+		// An unknown grade fails as such, not as a missing one.
+		u.Position = -1
+	}
+	if manager := cell("manager_id"); manager != "" {
+		var err error
+		if u.ManagerId, err = strconv.Atoi(manager); err != nil {
+			u.ManagerId = -1
+		}
+	}
+	normalizeUser(&u)
+	normalizeProfile(&u)
+	return u, v.Validate(u, false)
+}
+
+// ImportUsers	 godoc
+//
+//	@Summary		Import users
+//	@Description	add users from a CSV or XLSX file, all of them or none. Rows duplicating a user or an earlier row,
+//	@Description	on the same terms as creating a user, are reported as errors.
+//	@Tags			users
+//	@Accept			text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Produce		json,application/yaml
+//	@Param			dry_run	query		bool		false	"Only validate the file"
+//	@Param			map		query		[]string	false	"Column mapping, e.g. name:First Name"	collectionFormat(multi)
+//	@Param			force	query		bool		false	"Import even if a user has the same name"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200		{object}	ImportReport
+//	@Failure		400		{object}	Problem
+//	@Failure		406		{object}	Problem
+//	@Failure		409		{object}	Problem
+//	@Failure		422		{object}	ImportReport
+//	@Failure		500		{object}	Problem
+//	@Router			/users/import [post]
+func (h *Handlers) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+	rows, err := readTable(r.Header.Get("Content-Type"), b)
+	if err != nil {
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+	if len(rows) < 2 {
+		Error(w, "no rows to import", http.StatusBadRequest)
+		return
+	}
+	idx, err := columnIndex(rows[0], r.URL.Query()["map"])
+	if err != nil {
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+
+	rep := &ImportReport{}
+	rep.DryRun, _ = strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	known, err := indexUsers(h.dbc, force)
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	// Rows are checked against the users and the rows before them.
+	earlier := newUserIndex(force)
+	us := make([]User, 0, len(rows)-1)
+	v := NewValidator(h.dbc)
+	for i, rec := range rows[1:] {
+		if blank(rec) {
+			continue
+		}
+		rep.Rows++
+		u, err := parseRow(v, rec, idx)
+		var errs ValidationErrors
+		if err != nil && !errors.As(err, &errs) {
+			DBError(w, err)
+			return
+		}
+		if u.ManagerId > 0 && !known.live[u.ManagerId] {
+			errs = append(errs, FieldError{"manager_id", RuleReference, "is not an existing user"})
+		}
+		errs = append(errs, known.conflicts(u)...)
+		errs = append(errs, earlier.conflicts(u)...)
+		if len(errs) > 0 {
+			rep.Errors = append(rep.Errors, RowError{Row: i + 2, Error: errs.Error(), Fields: errs})
+			continue
+		}
+		earlier.add(u, fmt.Sprintf("row %d", i+2))
+		us = append(us, u)
+	}
+	if rep.Rows == 0 {
+		Error(w, "no rows to import", http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case len(rep.Errors) > 0:
+		status = http.StatusUnprocessableEntity
+	case !rep.DryRun:
+		if err = h.dbc.AddUsers(us); err != nil {
+			DBError(w, err)
+			return
+		}
+		rep.Imported = len(us)
+	}
+	Respond(w, c, status, rep)
+}
@@ -0,0 +1,311 @@
+package promo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var importCols = append([]string{"name", "surname", "position", "project"}, profileColumns...)
+
+// expectKnownUsers expects the users an import is checked against.
+func expectKnownUsers(mock pgxmock.PgxPoolIface, rows *pgxmock.Rows) {
+	mock.ExpectQuery(selectUsers(", deleted_at FROM usr ORDER BY id")).WillReturnRows(rows)
+}
+
+func knownUsers() *pgxmock.Rows {
+	return pgxmock.NewRows(append(userCols, "deleted_at"))
+}
+
+func TestHandlers_ImportUsers(t *testing.T) {
+	t.Run("Check importing csv (no errors)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expectKnownUsers(mock, knownUsers())
+		expectProject(mock, "Test", true)
+		expectProject(mock, "Test", true)
+		mock.ExpectBegin()
+		mock.ExpectCopyFrom(pgx.Identifier{"usr"}, importCols)
+		mock.ExpectCommit()
+		body := "First Name,Surname,Grade,Project\nAnd,Ersen,middle,Test\n,,,\nBob,Smith,1,Test\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import?map=name:First%20Name", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		var rep ImportReport
+		assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&rep))
+		assert.Equal(t, ImportReport{Rows: 2, Imported: 2}, rep)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check importing xlsx (dry run)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		f := excelize.NewFile()
+		sheet := f.GetSheetName(0)
+		_ = f.SetSheetRow(sheet, "A1", &[]string{"name", "surname", "position", "project"})
+		_ = f.SetSheetRow(sheet, "A2", &[]string{"And", "Ersen", "senior", "Test"})
+		expectKnownUsers(mock, knownUsers())
+		expectProject(mock, "Test", true)
+		body, err := f.WriteToBuffer()
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/users/import?dry_run=true", body)
+		req.Header.Set("Content-Type", xlsxContentType)
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		var rep ImportReport
+		assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&rep))
+		assert.Equal(t, ImportReport{Rows: 1, DryRun: true}, rep)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check importing csv (row errors)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expectKnownUsers(mock, knownUsers())
+		body := "name,surname,position\nAnd,Ersen,middle\nA1d,Ersen,middle\nBob,Smith,lead\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+		var rep ImportReport
+		assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&rep))
+		assert.Equal(t, ImportReport{Rows: 3, Errors: []RowError{
+			{Row: 3, Error: "name: may only hold letters, spaces, hyphens and apostrophes", Fields: []FieldError{
+				{Field: "name", Rule: RuleCharset, Detail: "may only hold letters, spaces, hyphens and apostrophes"},
+			}},
+			{Row: 4, Error: "position: is not a known grade", Fields: []FieldError{
+				{Field: "position", Rule: RuleEnum, Detail: "is not a known grade"},
+			}},
+		}}, rep)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check importing csv (missing column)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		body := "name,position\nAnd,middle\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+	t.Run("Check importing (unsupported type)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(`[]`)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+	})
+	t.Run("Check importing csv (copy error)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expectKnownUsers(mock, knownUsers())
+		mock.ExpectBegin()
+		mock.ExpectCopyFrom(pgx.Identifier{"usr"}, importCols).WillReturnError(fmt.Errorf("copy error"))
+		mock.ExpectRollback()
+		body := "name,surname,position\nAnd,Ersen,middle\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check importing csv (duplicates)", func(t *testing.T) {
+		m := NewMemory()
+		require.NoError(t, m.AddUsers([]User{
+			{Name: "Eve", Surname: "Stone", Position: junior},
+			{Name: "Bob", Surname: "Smith", Position: junior},
+		}))
+		require.NoError(t, m.DeleteUser(1))
+		h := &Handlers{m}
+
+		body := "name,surname,position\nbob,SMITH,middle\nAnd,Ersen,middle\nAnd,Ersen,senior\nEve,Stone,senior\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+		var rep ImportReport
+		assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&rep))
+		assert.Equal(t, ImportReport{Rows: 4, Errors: []RowError{
+			{Row: 2, Error: "name: is the name of user 2", Fields: []FieldError{
+				{Field: "name", Rule: RuleDuplicate, Detail: "is the name of user 2"},
+			}},
+			{Row: 4, Error: "name: is the name of row 3", Fields: []FieldError{
+				{Field: "name", Rule: RuleDuplicate, Detail: "is the name of row 3"},
+			}},
+		}}, rep, "deleted users keep their name free")
+		us, err := m.GetAllUsers(UserFilter{})
+		require.NoError(t, err)
+		assert.Len(t, *us, 1, "nothing is imported")
+	})
+	t.Run("Check importing csv (duplicates, forced)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expectKnownUsers(mock, knownUsers().AddRow(3, "Bob", "Smith", "junior", "", "", "", "", "", 0, nil))
+		mock.ExpectBegin()
+		mock.ExpectCopyFrom(pgx.Identifier{"usr"}, importCols)
+		mock.ExpectCommit()
+		body := "name,surname,position\nBob,Smith,middle\nBob,Smith,senior\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import?force=true", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check importing csv (concurrent duplicate)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expectKnownUsers(mock, knownUsers())
+		mock.ExpectBegin()
+		mock.ExpectCopyFrom(pgx.Identifier{"usr"}, importCols).WillReturnError(&pgconn.PgError{Code: "23505"})
+		mock.ExpectRollback()
+		body := "name,surname,position\nAnd,Ersen,middle\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check importing csv (profile)", func(t *testing.T) {
+		m := NewMemory()
+		require.NoError(t, m.AddUsers([]User{
+			{Name: "Ann", Surname: "Boss", Position: senior},
+			{Name: "Eve", Surname: "Stone", Position: senior},
+		}))
+		require.NoError(t, m.DeleteUser(2))
+		h := &Handlers{m}
+
+		body := "name,surname,position,e-mail,employee number,hire date,location,manager\n" +
+			"Bob,Smith,junior, Bob@Example.com ,E2,2022-07-15,Warsaw,1\n" +
+			"Cid,Jones,junior,,,,,2\n" +
+			"Dan,Brown,junior,,,,,boss\n" +
+			"Fay,Green,junior,fay,,2022-02-30,,\n"
+		req := httptest.NewRequest(http.MethodPost, "/users/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+		var rep ImportReport
+		assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&rep))
+		assert.Equal(t, ImportReport{Rows: 4, Errors: []RowError{
+			{Row: 3, Error: "manager_id: is not an existing user", Fields: []FieldError{
+				{Field: "manager_id", Rule: RuleReference, Detail: "is not an existing user"},
+			}},
+			{Row: 4, Error: "manager_id: is less than 1", Fields: []FieldError{
+				{Field: "manager_id", Rule: RuleTooSmall, Detail: "is less than 1"},
+			}},
+			{Row: 5, Error: "email: is not an email address; hire_date: is not a date written 2006-01-02", Fields: []FieldError{
+				{Field: "email", Rule: RuleEmail, Detail: "is not an email address"},
+				{Field: "hire_date", Rule: RuleDate, Detail: "is not a date written 2006-01-02"},
+			}},
+		}}, rep)
+
+		body = "name,surname,position,email,employee_number,hire_date,location,manager_id\n" +
+			"Bob,Smith,junior,bob@example.com,E2,2022-07-15,Warsaw,1\n" +
+			"Cid,Jones,junior,bob@example.com,,,,\n"
+		req = httptest.NewRequest(http.MethodPost, "/users/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/csv")
+		w = httptest.NewRecorder()
+		h.ImportUsers(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Result().StatusCode)
+		rep = ImportReport{}
+		assert.NoError(t, json.NewDecoder(w.Result().Body).Decode(&rep))
+		assert.Equal(t, []RowError{{Row: 3, Error: "email: is the email of row 2", Fields: []FieldError{
+			{Field: "email", Rule: RuleDuplicate, Detail: "is the email of row 2"},
+		}}}, rep.Errors)
+	})
+	t.Run("Check importing an export", func(t *testing.T) {
+		from := NewMemory()
+		ann := User{Name: "Ann", Surname: "Boss", Position: senior, Email: "ann@example.com",
+			EmployeeNumber: "E1", HireDate: "2019-03-01", Location: "Minsk"}
+		_, err := from.CreateUser(ann, false)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		(&Handlers{from}).ExportUsers(w, httptest.NewRequest(http.MethodGet, "/users/export", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		to := NewMemory()
+		req := httptest.NewRequest(http.MethodPost, "/users/import", w.Body)
+		req.Header.Set("Content-Type", "text/csv")
+		w = httptest.NewRecorder()
+		(&Handlers{to}).ImportUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		u, err := to.GetUser(1)
+		require.NoError(t, err)
+		ann.Id = 1
+		assert.Equal(t, ann, *u, "every exported column is imported back")
+	})
+}
@@ -0,0 +1,1421 @@
+package promo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Memory is a DBConnexion kept in process memory, for tests and demos. It
+// behaves like Registry: ids start at 1 and are never reused, missing and
+// deleted users are ErrNotFound and lists come ordered by id.
+type Memory struct {
+	mu       sync.RWMutex
+	next     int
+	users    map[int]User
+	keys     map[string]memoryKey
+	projects map[string]bool
+	history  map[int][]memoryVersion
+	cycles   []memoryCycle
+	// assessments are by id minus one, zero once dropped by a merge, and
+	// calibrated by cycle and project.
+	assessments []Assessment
+	calibrated  map[memoryCalibration]bool
+	feedback    []memoryFeedback
+	// competencies are by id, the last given in lastCompetency; skills are
+	// by user and competency.
+	competencies   map[int]Competency
+	lastCompetency int
+	skills         map[memorySkill]Skill
+	// mentorships and goals are by id minus one, mentorships zero once
+	// dropped by a merge.
+	mentorships []Mentorship
+	goals       []Goal
+	// certifications are by id, the last given in lastCertification.
+	certifications    map[int]Certification
+	lastCertification int
+}
+
+func NewMemory() *Memory {
+	return &Memory{users: make(map[int]User), keys: make(map[string]memoryKey), projects: make(map[string]bool),
+		history: make(map[int][]memoryVersion), calibrated: make(map[memoryCalibration]bool),
+		competencies: make(map[int]Competency), skills: make(map[memorySkill]Skill),
+		certifications: make(map[int]Certification)}
+}
+
+// memoryVersion is a version of a user, valid from from until to, zero
+// while current.
+type memoryVersion struct {
+	user     User
+	from, to time.Time
+}
+
+// record ends the current versions of ids and starts new ones for those
+// live, see HistoryStore. It is called after every change to m.users.
+func (m *Memory) record(ids ...int) {
+	now := time.Now()
+	for _, id := range ids {
+		vs := m.history[id]
+		if n := len(vs); n > 0 && vs[n-1].to.IsZero() {
+			vs[n-1].to = now
+		}
+		if u, ok := m.users[id]; ok && u.DeletedAt == nil {
+			vs = append(vs, memoryVersion{user: u, from: now})
+		}
+		m.history[id] = vs
+	}
+}
+
+// asOf is the version of user id valid at t.
+func (m *Memory) asOf(id int, t time.Time) (User, bool) {
+	for _, v := range m.history[id] {
+		if !v.from.After(t) && (v.to.IsZero() || v.to.After(t)) {
+			return v.user, true
+		}
+	}
+	return User{}, false
+}
+
+func (m *Memory) AddUser(name string, surname string, position Grade, project string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.add(User{Name: name, Surname: surname, Position: position, Project: project})
+	return nil
+}
+
+func (m *Memory) add(u User) {
+	m.next++
+	u.Id = m.next
+	m.users[u.Id] = u
+	m.record(u.Id)
+}
+
+// CreateUser adds u unless it duplicates a user, see Registry.CreateUser.
+func (m *Memory) CreateUser(u User, force bool) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var dup *DuplicateError
+	for _, o := range m.users {
+		field := ""
+		switch {
+		case u.Email != "" && o.Email == u.Email:
+			field = "email"
+		case u.EmployeeNumber != "" && o.EmployeeNumber == u.EmployeeNumber:
+			field = "employee_number"
+		case !force && o.DeletedAt == nil && strings.EqualFold(o.Name, u.Name) && strings.EqualFold(o.Surname, u.Surname):
+			field = "name"
+		default:
+			continue
+		}
+		if dup == nil || o.Id < dup.Id {
+			dup = &DuplicateError{Id: o.Id, Field: field}
+		}
+	}
+	if dup != nil {
+		return 0, dup
+	}
+	if u.ManagerId != 0 {
+		if err := m.checkManager(u.ManagerId, 0); err != nil {
+			return 0, err
+		}
+	}
+	u.DeletedAt = nil
+	m.add(u)
+	return m.next, nil
+}
+
+// MergeUsers folds user from into user into, see Registry.MergeUsers.
+func (m *Memory) MergeUsers(into, from int) error {
+	if into == from {
+		return fmt.Errorf("unable to merge user %d into itself", into)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.users[from]
+	if !ok || f.DeletedAt != nil {
+		return fmt.Errorf("unable to merge user %d: %w", from, ErrNotFound)
+	}
+	t, ok := m.users[into]
+	if !ok || t.DeletedAt != nil {
+		return fmt.Errorf("unable to merge into user %d: %w", into, ErrNotFound)
+	}
+	if t.Project == "" {
+		t.Project = f.Project
+	}
+	if t.Email == "" {
+		t.Email = f.Email
+	}
+	if t.EmployeeNumber == "" {
+		t.EmployeeNumber = f.EmployeeNumber
+	}
+	if t.HireDate == "" {
+		t.HireDate = f.HireDate
+	}
+	if t.Location == "" {
+		t.Location = f.Location
+	}
+	manager := f.ManagerId
+	if manager == into {
+		manager = 0
+	}
+	if t.ManagerId == 0 || t.ManagerId == from {
+		t.ManagerId = manager
+	}
+	// The reports of from move to into, which is checked not to end up
+	// managing themselves before anything is changed.
+	seen := map[int]bool{}
+	for id := t.ManagerId; id != 0 && !seen[id]; id = m.users[id].ManagerId {
+		seen[id] = true
+		if id == into || m.users[id].ManagerId == from {
+			return fmt.Errorf("user %d would manage themselves once merged: %w", into, ErrInvalidManager)
+		}
+	}
+	changed := []int{into, from}
+	for id, u := range m.users {
+		if u.ManagerId == from && id != into {
+			u.ManagerId = into
+			m.users[id] = u
+			changed = append(changed, id)
+		}
+	}
+	now := time.Now()
+	f.Email, f.EmployeeNumber, f.DeletedAt = "", "", &now
+	m.users[into], m.users[from] = t, f
+	m.record(changed...)
+	m.moveHistory(into, from)
+	m.moveRecords(into, from)
+	return nil
+}
+
+// moveRecords moves what refers to from over to into, dropping what would
+// clash with what refers to into already, as userRefs do for SQL stores.
+func (m *Memory) moveRecords(into, from int) {
+	for _, mc := range m.cycles {
+		if rv, ok := mc.reviews[from]; ok {
+			delete(mc.reviews, from)
+			if _, ok = mc.reviews[into]; !ok {
+				rv.UserId = into
+				mc.reviews[into] = rv
+			}
+		}
+	}
+	for i := range m.assessments {
+		a := &m.assessments[i]
+		if a.UserId != from && a.ReviewerId != from {
+			continue
+		}
+		moved := *a
+		if moved.UserId == from {
+			moved.UserId = into
+		}
+		if moved.ReviewerId == from {
+			moved.ReviewerId = into
+		}
+		clash := moved.UserId == moved.ReviewerId
+		for _, o := range m.assessments {
+			clash = clash || o.Id != a.Id && o.CycleId == moved.CycleId && o.UserId == moved.UserId && o.ReviewerId == moved.ReviewerId
+		}
+		if clash {
+			*a = Assessment{}
+		} else {
+			*a = moved
+		}
+	}
+	for i := range m.feedback {
+		f := &m.feedback[i]
+		if f.UserId == from {
+			f.UserId = into
+		}
+		if f.ManagerId == from {
+			f.ManagerId = into
+		}
+		rs := f.respondent(from)
+		if rs == nil {
+			continue
+		}
+		if f.respondent(into) == nil {
+			rs.UserId = into
+			f.keys[into] = f.keys[from]
+			if r, ok := f.responses[from]; ok {
+				f.responses[into] = r
+			}
+		} else {
+			*rs = f.Respondents[len(f.Respondents)-1]
+			f.Respondents = f.Respondents[:len(f.Respondents)-1]
+		}
+		delete(f.keys, from)
+		delete(f.responses, from)
+	}
+	for k, sk := range m.skills {
+		if k.user != from {
+			continue
+		}
+		delete(m.skills, k)
+		if _, ok := m.skills[memorySkill{into, k.competency}]; !ok {
+			sk.UserId = into
+			m.skills[memorySkill{into, k.competency}] = sk
+		}
+	}
+	for i := range m.mentorships {
+		ms := &m.mentorships[i]
+		switch {
+		case ms.MentorId == from && ms.MenteeId == into, ms.MenteeId == from && ms.MentorId == into:
+			*ms = Mentorship{}
+		case ms.MentorId == from:
+			ms.MentorId = into
+		case ms.MenteeId == from:
+			ms.MenteeId = into
+		}
+	}
+	for i := range m.goals {
+		if m.goals[i].UserId == from {
+			m.goals[i].UserId = into
+		}
+	}
+	for id, c := range m.certifications {
+		if c.UserId == from {
+			c.UserId = into
+			m.certifications[id] = c
+		}
+	}
+}
+
+// moveHistory hands the versions of from older than the first of into over
+// to into, ending the last one where the history of into begins.
+func (m *Memory) moveHistory(into, from int) {
+	vs := m.history[into]
+	if len(vs) == 0 {
+		return
+	}
+	first := vs[0].from
+	var moved, kept []memoryVersion
+	for _, v := range m.history[from] {
+		if !v.from.Before(first) {
+			kept = append(kept, v)
+			continue
+		}
+		if v.to.IsZero() || v.to.After(first) {
+			v.to = first
+		}
+		v.user.Id = into
+		moved = append(moved, v)
+	}
+	m.history[into], m.history[from] = append(moved, vs...), kept
+}
+
+// checkManager tells why manager cannot manage id, nil when it can.
+func (m *Memory) checkManager(manager, id int) error {
+	if u, ok := m.users[manager]; !ok || u.DeletedAt != nil {
+		return managerError(manager, id, false, false)
+	}
+	seen := map[int]bool{}
+	for cur := manager; cur != 0 && !seen[cur]; cur = m.users[cur].ManagerId {
+		if cur == id {
+			return managerError(manager, id, true, true)
+		}
+		seen[cur] = true
+	}
+	return nil
+}
+
+// Reports lists the users at most depth levels under id, see
+// Registry.Reports.
+func (m *Memory) Reports(id, depth int) ([]Report, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if u, ok := m.users[id]; !ok || u.DeletedAt != nil {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
+	var out []Report
+	level := []int{id}
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		var next []User
+		for _, u := range m.users {
+			if u.DeletedAt == nil && u.ManagerId != 0 && slices.Contains(level, u.ManagerId) {
+				next = append(next, u)
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return next[i].Id < next[j].Id })
+		level = level[:0]
+		for _, u := range next {
+			out = append(out, Report{User: u, Depth: d})
+			level = append(level, u.Id)
+		}
+	}
+	return out, nil
+}
+
+// Chain lists the managers of id up to the top, see Registry.Chain.
+func (m *Memory) Chain(id int) ([]Report, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
+	var out []Report
+	for d := 1; d <= maxOrgDepth; d++ {
+		if u, ok = m.users[u.ManagerId]; !ok || u.DeletedAt != nil {
+			break
+		}
+		out = append(out, Report{User: u, Depth: d})
+	}
+	return out, nil
+}
+
+func (m *Memory) AddUsers(us []User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range us {
+		m.add(u)
+	}
+	return nil
+}
+
+// DeleteUser only marks the user deleted, see RestoreUser and PurgeDeleted.
+func (m *Memory) DeleteUser(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok || u.DeletedAt != nil {
+		return fmt.Errorf("no user to delete with id %v: %w", id, ErrNotFound)
+	}
+	now := time.Now()
+	u.DeletedAt = &now
+	m.users[id] = u
+	m.record(id)
+	return nil
+}
+
+func (m *Memory) RestoreUser(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if !ok || u.DeletedAt == nil {
+		return fmt.Errorf("no deleted user to restore with id %v: %w", id, ErrNotFound)
+	}
+	u.DeletedAt = nil
+	m.users[id] = u
+	m.record(id)
+	return nil
+}
+
+// PurgeDeleted removes for good the users deleted before t.
+func (m *Memory) PurgeDeleted(t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, u := range m.users {
+		if u.DeletedAt != nil && u.DeletedAt.Before(t) {
+			delete(m.users, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) UpdateUser(id int, fields map[string]string) error {
+	return m.updateUser(id, nil, fields)
+}
+
+// UpdateUserIf is UpdateUser while user id still is was, see
+// ConditionalUpdater.
+func (m *Memory) UpdateUserIf(id int, was User, fields map[string]string) error {
+	return m.updateUser(id, &was, fields)
+}
+
+// updateUser is UpdateUser, only updating user id while it is was when
+// was is set.
+func (m *Memory) updateUser(id int, was *User, fields map[string]string) error {
+	allowed := append([]string{"name", "surname", "position", "project"}, profileColumns...)
+	for k := range fields {
+		if !slices.Contains(allowed, k) {
+			return fmt.Errorf("illegal key in the map")
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.users[id]
+	if was != nil && (!ok || u.DeletedAt != nil || UserETag(u) != UserETag(*was)) {
+		return fmt.Errorf("user %d changed or was deleted meanwhile: %w", id, ErrPreconditionFailed)
+	}
+	if !ok || u.DeletedAt != nil {
+		return fmt.Errorf("no user to update with id %v: %w", id, ErrNotFound)
+	}
+	var dup *DuplicateError
+	for _, o := range m.users {
+		field := ""
+		switch {
+		case o.Id == id:
+			continue
+		case fields["email"] != "" && o.Email == fields["email"]:
+			field = "email"
+		case fields["employee_number"] != "" && o.EmployeeNumber == fields["employee_number"]:
+			field = "employee_number"
+		default:
+			continue
+		}
+		if dup == nil || o.Id < dup.Id {
+			dup = &DuplicateError{Id: o.Id, Field: field}
+		}
+	}
+	if dup != nil {
+		return dup
+	}
+	for k, v := range fields {
+		switch k {
+		case "name":
+			u.Name = v
+		case "surname":
+			u.Surname = v
+		case "position":
+			g, ok := bGrades[v]
+			if !ok {
+				return fmt.Errorf("illegal position %q", v)
+			}
+			u.Position = g
+		case "project":
+			u.Project = v
+		case "email":
+			u.Email = v
+		case "employee_number":
+			u.EmployeeNumber = v
+		case "hire_date":
+			if _, err := time.Parse(DateLayout, v); err != nil && v != "" {
+				return fmt.Errorf("illegal hire_date %q", v)
+			}
+			u.HireDate = v
+		case "location":
+			u.Location = v
+		case "manager_id":
+			if v == "" {
+				u.ManagerId = 0
+				continue
+			}
+			manager, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("illegal manager_id %q", v)
+			}
+			if err = m.checkManager(manager, id); err != nil {
+				return err
+			}
+			u.ManagerId = manager
+		}
+	}
+	m.users[id] = u
+	m.record(id)
+	return nil
+}
+
+func (m *Memory) GetUser(id int) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.users[id]
+	if !ok || u.DeletedAt != nil {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
+	return &u, nil
+}
+
+// UserAsOf is user id as it was at t, see HistoryStore.
+func (m *Memory) UserAsOf(id int, t time.Time) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	u, ok := m.asOf(id, t)
+	if !ok {
+		return nil, fmt.Errorf("unable to get user with id %d as of %s: %w", id, t.Format(time.RFC3339), ErrNotFound)
+	}
+	return &u, nil
+}
+
+// UsersAsOf lists the users live at t, see HistoryStore.
+func (m *Memory) UsersAsOf(f UserFilter, t time.Time) ([]User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	us := make([]User, 0)
+	for id := range m.history {
+		u, ok := m.asOf(id, t)
+		if !ok || (f.Project != "" && u.Project != f.Project) || (f.Position != 0 && u.Position != f.Position) || !f.matchProfile(u) {
+			continue
+		}
+		us = append(us, u)
+	}
+	sort.Slice(us, func(i, j int) bool { return us[i].Id < us[j].Id })
+	return us, nil
+}
+
+// History lists the versions of user id, see HistoryStore.
+func (m *Memory) History(id int) ([]Version, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	vs := make([]Version, 0, len(m.history[id]))
+	for _, mv := range m.history[id] {
+		from := mv.from
+		v := Version{User: mv.user, ValidFrom: &from}
+		if !mv.to.IsZero() {
+			to := mv.to
+			v.ValidTo = &to
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
+
+func (m *Memory) GetAllUsers(f UserFilter) (*[]User, error) {
+	us := m.snapshot(f)
+	return &us, nil
+}
+
+// EachUser works on a snapshot, so fn may call back into m.
+func (m *Memory) EachUser(f UserFilter, fn func(User) error) error {
+	return m.EachUserContext(context.Background(), f, fn)
+}
+
+// EachUserContext is EachUser, stopping between users once ctx is done.
+func (m *Memory) EachUserContext(ctx context.Context, f UserFilter, fn func(User) error) error {
+	for _, u := range m.snapshot(f) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Memory) snapshot(f UserFilter) []User {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	us := make([]User, 0, len(m.users))
+	for _, u := range m.users {
+		if u.DeletedAt != nil && !f.IncludeDeleted {
+			continue
+		}
+		if f.Project != "" && u.Project != f.Project {
+			continue
+		}
+		if f.Position != 0 && u.Position != f.Position {
+			continue
+		}
+		if !f.matchProfile(u) {
+			continue
+		}
+		us = append(us, u)
+	}
+	sort.Slice(us, func(i, j int) bool { return us[i].Id < us[j].Id })
+	return us
+}
+
+type memoryKey struct {
+	resp    IdempotentResponse
+	expires time.Time
+}
+
+func (m *Memory) BeginIdempotent(key, fingerprint string, expires time.Time) (*IdempotentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.keys[key]; ok && !k.expires.Before(time.Now()) {
+		resp := k.resp
+		return &resp, nil
+	}
+	m.keys[key] = memoryKey{resp: IdempotentResponse{Fingerprint: fingerprint}, expires: expires}
+	return nil, nil
+}
+
+func (m *Memory) FinishIdempotent(key string, resp IdempotentResponse, expires time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.keys[key]; ok {
+		k.resp, k.expires = resp, expires
+		m.keys[key] = k
+	}
+	return nil
+}
+
+func (m *Memory) ReleaseIdempotent(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.keys[key]; ok && k.resp.Status == 0 {
+		delete(m.keys, key)
+	}
+	return nil
+}
+
+func (m *Memory) PurgeIdempotent(t time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for key, k := range m.keys {
+		if k.expires.Before(t) {
+			delete(m.keys, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Memory) Projects() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.projects))
+	for n := range m.projects {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *Memory) AddProject(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.projects[name] {
+		return fmt.Errorf("unable to add project %q: %w", name, ErrProjectExists)
+	}
+	m.projects[name] = true
+	return nil
+}
+
+func (m *Memory) ProjectExists(name string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.projects[name], nil
+}
+
+// memoryCycle is a review cycle with its reviews by user; cycle ids are
+// their index in m.cycles plus one.
+type memoryCycle struct {
+	cycle   Cycle
+	reviews map[int]Review
+}
+
+// cycle is cycle id, nil if there is none.
+func (m *Memory) cycle(id int) *memoryCycle {
+	if id < 1 || id > len(m.cycles) {
+		return nil
+	}
+	return &m.cycles[id-1]
+}
+
+func (m *Memory) CreateCycle(c Cycle) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c.Id = len(m.cycles) + 1
+	c.Status = CycleScheduled
+	c.Projects = append([]string{}, c.Projects...)
+	sort.Strings(c.Projects)
+	m.cycles = append(m.cycles, memoryCycle{cycle: c, reviews: make(map[int]Review)})
+	return c.Id, nil
+}
+
+func (m *Memory) Cycle(id int) (*Cycle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mc := m.cycle(id)
+	if mc == nil {
+		return nil, cycleError(id, nil, CycleScheduled)
+	}
+	c := mc.cycle
+	return &c, nil
+}
+
+func (m *Memory) Cycles() ([]Cycle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cs := make([]Cycle, 0, len(m.cycles))
+	for _, mc := range m.cycles {
+		cs = append(cs, mc.cycle)
+	}
+	return cs, nil
+}
+
+func (m *Memory) OpenCycle(id int, rs []Review) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc := m.cycle(id)
+	if mc == nil {
+		return cycleError(id, nil, CycleScheduled)
+	}
+	if err := cycleError(id, &mc.cycle, CycleScheduled); err != nil {
+		return err
+	}
+	for _, rv := range rs {
+		if _, ok := mc.reviews[rv.UserId]; ok {
+			continue
+		}
+		mc.reviews[rv.UserId] = Review{CycleId: id, UserId: rv.UserId, From: rv.From, To: rv.To, Status: ReviewPending}
+	}
+	mc.cycle.Status = CycleOpen
+	return nil
+}
+
+func (m *Memory) Reviews(id int) ([]Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	mc := m.cycle(id)
+	if mc == nil {
+		return nil, cycleError(id, nil, CycleScheduled)
+	}
+	rs := make([]Review, 0, len(mc.reviews))
+	for _, rv := range mc.reviews {
+		rs = append(rs, rv)
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].UserId < rs[j].UserId })
+	return rs, nil
+}
+
+func (m *Memory) SetReview(id, user int, status ReviewStatus, comment string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc := m.cycle(id)
+	if mc == nil {
+		return cycleError(id, nil, CycleOpen)
+	}
+	if err := cycleError(id, &mc.cycle, CycleOpen); err != nil {
+		return err
+	}
+	rv, ok := mc.reviews[user]
+	if !ok {
+		return fmt.Errorf("user %d is not enrolled in review cycle %d: %w", user, id, ErrReviewNotFound)
+	}
+	rv.Status, rv.Comment = status, comment
+	mc.reviews[user] = rv
+	return nil
+}
+
+// CloseCycle promotes the approved users still live in the grade they were
+// reviewed in and skips the others, see closeCycleQueries.
+func (m *Memory) CloseCycle(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mc := m.cycle(id)
+	if mc == nil {
+		return cycleError(id, nil, CycleOpen)
+	}
+	if err := cycleError(id, &mc.cycle, CycleOpen); err != nil {
+		return err
+	}
+	var promoted []int
+	for uid, rv := range mc.reviews {
+		if rv.Status != ReviewApproved {
+			continue
+		}
+		u, ok := m.users[uid]
+		if !ok || u.DeletedAt != nil || u.Position != rv.From {
+			rv.Status = ReviewSkipped
+		} else {
+			u.Position = rv.To
+			m.users[uid] = u
+			promoted = append(promoted, uid)
+			rv.Status = ReviewPromoted
+		}
+		mc.reviews[uid] = rv
+	}
+	m.record(promoted...)
+	mc.cycle.Status = CycleClosed
+	return nil
+}
+
+type memoryCalibration struct {
+	cycle   int
+	project string
+}
+
+// live tells whether user id is live.
+func (m *Memory) live(id int) (User, bool) {
+	u, ok := m.users[id]
+	return u, ok && u.DeletedAt == nil
+}
+
+func (m *Memory) AddAssessment(a Assessment) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cycle(a.CycleId) == nil {
+		return 0, cycleError(a.CycleId, nil, CycleScheduled)
+	}
+	u, ok := m.live(a.UserId)
+	if !ok {
+		return 0, fmt.Errorf("no user to assess with id %d: %w", a.UserId, ErrNotFound)
+	}
+	if _, ok = m.live(a.ReviewerId); a.ReviewerId != 0 && !ok {
+		return 0, fmt.Errorf("no reviewer with id %d: %w", a.ReviewerId, ErrNotFound)
+	}
+	if m.calibrated[memoryCalibration{a.CycleId, u.Project}] {
+		return 0, fmt.Errorf("%s is calibrated in review cycle %d: %w", u.Project, a.CycleId, ErrAssessmentLocked)
+	}
+	for _, o := range m.assessments {
+		if o.CycleId == a.CycleId && o.UserId == a.UserId && o.ReviewerId == a.ReviewerId {
+			return 0, fmt.Errorf("user %d is already assessed by %d in review cycle %d: %w", a.UserId, a.ReviewerId, a.CycleId,
+				ErrAssessmentExists)
+		}
+	}
+	a.Id = len(m.assessments) + 1
+	a.Grade, a.Project, a.Locked = u.Position, u.Project, false
+	a.Scores = copyScores(a.Scores)
+	m.assessments = append(m.assessments, a)
+	return a.Id, nil
+}
+
+func copyScores(scores map[string]int) map[string]int {
+	c := make(map[string]int, len(scores))
+	for k, v := range scores {
+		c[k] = v
+	}
+	return c
+}
+
+// assessment is assessment a as read back, not sharing its scores.
+func (m *Memory) assessment(a Assessment) Assessment {
+	a.Scores = copyScores(a.Scores)
+	a.Locked = m.calibrated[memoryCalibration{a.CycleId, a.Project}]
+	return a
+}
+
+func (m *Memory) Assessment(id int) (*Assessment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if id < 1 || id > len(m.assessments) || m.assessments[id-1].Id == 0 {
+		return nil, fmt.Errorf("no assessment with id %d: %w", id, ErrAssessmentNotFound)
+	}
+	a := m.assessment(m.assessments[id-1])
+	return &a, nil
+}
+
+func (m *Memory) Assessments(f AssessmentFilter) ([]Assessment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	as := make([]Assessment, 0)
+	for _, a := range m.assessments {
+		if a.Id != 0 && (f.CycleId == 0 || a.CycleId == f.CycleId) && (f.UserId == 0 || a.UserId == f.UserId) &&
+			(f.Project == "" || a.Project == f.Project) {
+			as = append(as, m.assessment(a))
+		}
+	}
+	return as, nil
+}
+
+func (m *Memory) UpdateAssessment(id, rating int, scores map[string]int, summary string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || id > len(m.assessments) || m.assessments[id-1].Id == 0 {
+		return fmt.Errorf("no assessment with id %d: %w", id, ErrAssessmentNotFound)
+	}
+	a := &m.assessments[id-1]
+	if m.calibrated[memoryCalibration{a.CycleId, a.Project}] {
+		return fmt.Errorf("unable to update assessment %d: %w", id, ErrAssessmentLocked)
+	}
+	a.Rating, a.Scores, a.Summary = rating, copyScores(scores), summary
+	return nil
+}
+
+func (m *Memory) Calibrate(cycle int, project string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cycle(cycle) == nil {
+		return cycleError(cycle, nil, CycleScheduled)
+	}
+	k := memoryCalibration{cycle, project}
+	if m.calibrated[k] {
+		return fmt.Errorf("%s is already calibrated in review cycle %d: %w", project, cycle, ErrAssessmentLocked)
+	}
+	m.calibrated[k] = true
+	return nil
+}
+
+func (m *Memory) Calibrated(cycle int, project string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.calibrated[memoryCalibration{cycle, project}], nil
+}
+
+// memoryFeedback is a feedback with the keys to it and the responses by
+// respondent.
+type memoryFeedback struct {
+	Feedback
+	managerKey string
+	keys       map[int]string
+	responses  map[int]FeedbackResponse
+}
+
+func (m *Memory) CreateFeedback(f Feedback, managerKey string, keys map[int]string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f.Id = len(m.feedback) + 1
+	f.Respondents = append([]Respondent{}, f.Respondents...)
+	mf := memoryFeedback{Feedback: f, managerKey: managerKey, keys: make(map[int]string), responses: make(map[int]FeedbackResponse)}
+	for i := range mf.Respondents {
+		mf.Respondents[i].Answered, mf.Respondents[i].RemindedAt = false, nil
+		mf.keys[mf.Respondents[i].UserId] = keys[mf.Respondents[i].UserId]
+	}
+	m.feedback = append(m.feedback, mf)
+	return f.Id, nil
+}
+
+// feedbackOf is the feedback id, nil if there is none.
+func (m *Memory) feedbackOf(id int) *memoryFeedback {
+	if id < 1 || id > len(m.feedback) {
+		return nil
+	}
+	return &m.feedback[id-1]
+}
+
+// respondent is respondent user of f, nil if not asked.
+func (f *memoryFeedback) respondent(user int) *Respondent {
+	for i := range f.Respondents {
+		if f.Respondents[i].UserId == user {
+			return &f.Respondents[i]
+		}
+	}
+	return nil
+}
+
+// copy is f as read back, not sharing its respondents.
+func (f *memoryFeedback) copy() Feedback {
+	c := f.Feedback
+	c.Respondents = make([]Respondent, len(f.Respondents))
+	for i, rs := range f.Respondents {
+		if rs.RemindedAt != nil {
+			at := *rs.RemindedAt
+			rs.RemindedAt = &at
+		}
+		c.Respondents[i] = rs
+	}
+	sort.Slice(c.Respondents, func(i, j int) bool { return c.Respondents[i].UserId < c.Respondents[j].UserId })
+	return c
+}
+
+func (m *Memory) Feedback(id int) (*Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f := m.feedbackOf(id)
+	if f == nil {
+		return nil, fmt.Errorf("no feedback with id %d: %w", id, ErrFeedbackNotFound)
+	}
+	c := f.copy()
+	return &c, nil
+}
+
+func (m *Memory) Feedbacks(user int) ([]Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	fs := make([]Feedback, 0)
+	for i := range m.feedback {
+		if user == 0 || m.feedback[i].UserId == user {
+			fs = append(fs, m.feedback[i].copy())
+		}
+	}
+	return fs, nil
+}
+
+func (m *Memory) FeedbackKey(id int, key string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if f := m.feedbackOf(id); f != nil {
+		for user, k := range f.keys {
+			if k == key {
+				return user, nil
+			}
+		}
+		if f.managerKey == key {
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("feedback %d: %w", id, ErrFeedbackKey)
+}
+
+func (m *Memory) FeedbackResponse(id, respondent int) (*FeedbackResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f := m.feedbackOf(id)
+	if f == nil || f.respondent(respondent) == nil {
+		return nil, fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	r, ok := f.responses[respondent]
+	if !ok {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+func (m *Memory) Respond(id, respondent int, r FeedbackResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f := m.feedbackOf(id)
+	if f == nil || f.respondent(respondent) == nil {
+		return fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	f.respondent(respondent).Answered = true
+	f.responses[respondent] = r
+	return nil
+}
+
+func (m *Memory) FeedbackAnswers(id int) ([]FeedbackAnswer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	as := make([]FeedbackAnswer, 0)
+	f := m.feedbackOf(id)
+	if f == nil {
+		return as, nil
+	}
+	for _, rs := range f.copy().Respondents {
+		if r, ok := f.responses[rs.UserId]; ok {
+			as = append(as, FeedbackAnswer{RespondentId: rs.UserId, Relation: rs.Relation, FeedbackResponse: r})
+		}
+	}
+	return as, nil
+}
+
+func (m *Memory) Remind(id, respondent int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f := m.feedbackOf(id)
+	if f == nil || f.respondent(respondent) == nil {
+		return fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	f.respondent(respondent).RemindedAt = &at
+	return nil
+}
+
+type memorySkill struct {
+	user, competency int
+}
+
+// competencyNamed tells whether a competency other than id is named name.
+func (m *Memory) competencyNamed(name string, id int) bool {
+	for _, c := range m.competencies {
+		if c.Name == name && c.Id != id {
+			return true
+		}
+	}
+	return false
+}
+
+// setCompetency records c, not sharing its levels.
+func (m *Memory) setCompetency(c Competency) {
+	c.Levels = append([]Expectation{}, c.Levels...)
+	sortLevels(&c)
+	m.competencies[c.Id] = c
+}
+
+func (m *Memory) CreateCompetency(c Competency) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.competencyNamed(c.Name, 0) {
+		return 0, fmt.Errorf("competency %q: %w", c.Name, ErrCompetencyExists)
+	}
+	m.lastCompetency++
+	c.Id = m.lastCompetency
+	m.setCompetency(c)
+	return c.Id, nil
+}
+
+func (m *Memory) Competency(id int) (*Competency, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.competencies[id]
+	if !ok {
+		return nil, fmt.Errorf("no competency with id %d: %w", id, ErrCompetencyNotFound)
+	}
+	c.Levels = append([]Expectation{}, c.Levels...)
+	return &c, nil
+}
+
+func (m *Memory) Competencies() ([]Competency, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cs := make([]Competency, 0, len(m.competencies))
+	for _, c := range m.competencies {
+		c.Levels = append([]Expectation{}, c.Levels...)
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Name < cs[j].Name })
+	return cs, nil
+}
+
+func (m *Memory) UpdateCompetency(c Competency) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.competencyNamed(c.Name, c.Id) {
+		return fmt.Errorf("competency %q: %w", c.Name, ErrCompetencyExists)
+	}
+	if _, ok := m.competencies[c.Id]; !ok {
+		return fmt.Errorf("no competency with id %d: %w", c.Id, ErrCompetencyNotFound)
+	}
+	m.setCompetency(c)
+	return nil
+}
+
+func (m *Memory) DeleteCompetency(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.competencies[id]; !ok {
+		return fmt.Errorf("no competency with id %d: %w", id, ErrCompetencyNotFound)
+	}
+	delete(m.competencies, id)
+	for k := range m.skills {
+		if k.competency == id {
+			delete(m.skills, k)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) SetSkill(s Skill) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.live(s.UserId); !ok {
+		return fmt.Errorf("no user with id %d: %w", s.UserId, ErrNotFound)
+	}
+	if _, ok := m.competencies[s.CompetencyId]; !ok {
+		return fmt.Errorf("no competency with id %d: %w", s.CompetencyId, ErrCompetencyNotFound)
+	}
+	m.skills[memorySkill{s.UserId, s.CompetencyId}] = s
+	return nil
+}
+
+func (m *Memory) Skills(f SkillFilter) ([]Skill, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ss := make([]Skill, 0)
+	for k, s := range m.skills {
+		u, ok := m.live(k.user)
+		if !ok || f.UserId != 0 && k.user != f.UserId || f.Project != "" && u.Project != f.Project {
+			continue
+		}
+		s.Competency = m.competencies[k.competency].Name
+		ss = append(ss, s)
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].UserId != ss[j].UserId {
+			return ss[i].UserId < ss[j].UserId
+		}
+		return ss[i].Competency < ss[j].Competency
+	})
+	return ss, nil
+}
+
+// mentorship is m as read back, not sharing its end.
+func mentorship(m Mentorship) Mentorship {
+	if m.EndsAt != nil {
+		at := *m.EndsAt
+		m.EndsAt = &at
+	}
+	return m
+}
+
+func (m *Memory) AddMentorship(ms Mentorship) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mentor, ok := m.live(ms.MentorId)
+	if !ok {
+		return 0, fmt.Errorf("no user with id %d: %w", ms.MentorId, ErrNotFound)
+	}
+	mentee, ok := m.live(ms.MenteeId)
+	if !ok {
+		return 0, fmt.Errorf("no user with id %d: %w", ms.MenteeId, ErrNotFound)
+	}
+	if mentor.Position <= mentee.Position {
+		return 0, fmt.Errorf("user %d cannot mentor user %d: %w", ms.MentorId, ms.MenteeId, ErrInvalidMentor)
+	}
+	for _, o := range m.mentorships {
+		if o.MentorId == ms.MentorId && o.MenteeId == ms.MenteeId && (o.EndsAt == nil || o.EndsAt.After(ms.StartsAt)) {
+			return 0, fmt.Errorf("user %d already mentors user %d: %w", ms.MentorId, ms.MenteeId, ErrMentorshipExists)
+		}
+	}
+	ms.Id = len(m.mentorships) + 1
+	m.mentorships = append(m.mentorships, mentorship(ms))
+	return ms.Id, nil
+}
+
+func (m *Memory) Mentorship(id int) (*Mentorship, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if id < 1 || id > len(m.mentorships) || m.mentorships[id-1].Id == 0 {
+		return nil, fmt.Errorf("no mentorship with id %d: %w", id, ErrMentorshipNotFound)
+	}
+	ms := mentorship(m.mentorships[id-1])
+	return &ms, nil
+}
+
+func (m *Memory) Mentorships(f MentorshipFilter) ([]Mentorship, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	list := make([]Mentorship, 0)
+	for _, ms := range m.mentorships {
+		if ms.Id == 0 || f.MentorId != 0 && ms.MentorId != f.MentorId || f.MenteeId != 0 && ms.MenteeId != f.MenteeId ||
+			!f.RunningAt.IsZero() && !ms.Running(f.RunningAt) {
+			continue
+		}
+		list = append(list, mentorship(ms))
+	}
+	return list, nil
+}
+
+func (m *Memory) EndMentorship(id int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || id > len(m.mentorships) || m.mentorships[id-1].Id == 0 {
+		return fmt.Errorf("no mentorship with id %d: %w", id, ErrMentorshipNotFound)
+	}
+	ms := &m.mentorships[id-1]
+	if !ms.Running(at) {
+		return fmt.Errorf("unable to end mentorship %d: %w", id, ErrMentorshipState)
+	}
+	ms.EndsAt = &at
+	return nil
+}
+
+// goal is g as read back, not sharing its notes.
+func goal(g Goal) Goal {
+	g.Notes = append([]GoalNote{}, g.Notes...)
+	return g
+}
+
+func (m *Memory) AddGoal(g Goal) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.live(g.UserId); !ok {
+		return 0, fmt.Errorf("no user with id %d: %w", g.UserId, ErrNotFound)
+	}
+	g.Id, g.Notes = len(m.goals)+1, nil
+	m.goals = append(m.goals, g)
+	return g.Id, nil
+}
+
+func (m *Memory) Goal(id int) (*Goal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if id < 1 || id > len(m.goals) {
+		return nil, fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	if _, ok := m.live(m.goals[id-1].UserId); !ok {
+		return nil, fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	g := goal(m.goals[id-1])
+	return &g, nil
+}
+
+func (m *Memory) Goals(f GoalFilter) ([]Goal, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	gs := make([]Goal, 0)
+	for _, g := range m.goals {
+		u, ok := m.live(g.UserId)
+		if !ok || f.UserId != 0 && g.UserId != f.UserId || f.Project != "" && u.Project != f.Project ||
+			f.TargetGrade != 0 && g.TargetGrade != f.TargetGrade || f.OverdueOn != "" && !g.Overdue(f.OverdueOn) {
+			continue
+		}
+		gs = append(gs, goal(g))
+	}
+	return gs, nil
+}
+
+func (m *Memory) UpdateGoal(g Goal) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if g.Id < 1 || g.Id > len(m.goals) {
+		return fmt.Errorf("no goal with id %d: %w", g.Id, ErrGoalNotFound)
+	}
+	old := &m.goals[g.Id-1]
+	old.Title, old.Description, old.TargetGrade, old.DueDate, old.Status = g.Title, g.Description, g.TargetGrade, g.DueDate, g.Status
+	return nil
+}
+
+func (m *Memory) AddGoalNote(id int, n GoalNote) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || id > len(m.goals) {
+		return fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	m.goals[id-1].Notes = append(m.goals[id-1].Notes, n)
+	return nil
+}
+
+// certification is c as read back, not sharing its flag.
+func certification(c Certification) Certification {
+	if c.FlaggedAt != nil {
+		at := *c.FlaggedAt
+		c.FlaggedAt = &at
+	}
+	return c
+}
+
+func (m *Memory) AddCertification(c Certification) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.live(c.UserId); !ok {
+		return 0, fmt.Errorf("no user with id %d: %w", c.UserId, ErrNotFound)
+	}
+	m.lastCertification++
+	c.Id, c.FlaggedAt = m.lastCertification, nil
+	m.certifications[c.Id] = c
+	return c.Id, nil
+}
+
+func (m *Memory) Certification(id int) (*Certification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c, ok := m.certifications[id]
+	if _, live := m.live(c.UserId); !ok || !live {
+		return nil, fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	c = certification(c)
+	return &c, nil
+}
+
+func (m *Memory) Certifications(f CertificationFilter) ([]Certification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	cs := make([]Certification, 0)
+	for _, c := range m.certifications {
+		u, ok := m.live(c.UserId)
+		if !ok || f.UserId != 0 && c.UserId != f.UserId || f.Project != "" && u.Project != f.Project ||
+			f.Kind != "" && c.Kind != f.Kind || f.Name != "" && c.Name != f.Name ||
+			f.ExpiringBy != "" && (c.ExpiresOn == "" || c.ExpiresOn > f.ExpiringBy) || f.Unflagged && c.FlaggedAt != nil {
+			continue
+		}
+		cs = append(cs, certification(c))
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].Id < cs[j].Id })
+	return cs, nil
+}
+
+func (m *Memory) UpdateCertification(c Certification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.certifications[c.Id]
+	if !ok {
+		return fmt.Errorf("no certification with id %d: %w", c.Id, ErrCertificationNotFound)
+	}
+	c.UserId, c.FlaggedAt = old.UserId, nil
+	m.certifications[c.Id] = c
+	return nil
+}
+
+func (m *Memory) DeleteCertification(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.certifications[id]; !ok {
+		return fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	delete(m.certifications, id)
+	return nil
+}
+
+func (m *Memory) FlagCertification(id int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.certifications[id]
+	if !ok {
+		return fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	c.FlaggedAt = &at
+	m.certifications[id] = c
+	return nil
+}
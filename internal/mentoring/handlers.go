@@ -0,0 +1,305 @@
+package mentoring
+
+import (
+	promo "AndersenPromo/internal"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultSuggestions is how many mentors are suggested unless asked.
+const defaultSuggestions = 5
+
+// Handlers serves the mentorships of a store, mentors taking up to
+// capacity mentees at a time, any number when 0.
+type Handlers struct {
+	dbc      promo.DBConnexion
+	capacity int
+	clock    promo.Clock
+}
+
+func NewHandlers(dbc promo.DBConnexion, capacity int) *Handlers {
+	return &Handlers{dbc, capacity, promo.SystemClock}
+}
+
+// Register mounts the mentorship routes on router. POST routes honour the
+// Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/mentorships", promo.Idempotent(h.dbc, h.CreateMentorship)).Methods(http.MethodPost)
+	router.HandleFunc("/mentorships", h.GetMentorships).Methods(http.MethodGet)
+	router.HandleFunc("/mentorships/{id:[0-9]+}", h.GetMentorship).Methods(http.MethodGet)
+	router.HandleFunc("/mentorships/{id:[0-9]+}/end", promo.Idempotent(h.dbc, h.EndMentorship)).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id:[0-9]+}/mentor-suggestions", h.SuggestMentors).Methods(http.MethodGet)
+}
+
+// MentorshipRequest pairs a mentee with a mentor from StartsAt, now when
+// zero, until EndsAt, open-ended when nil.
+type MentorshipRequest struct {
+	MentorId int        `json:"mentor_id" yaml:"mentor_id" validate:"required,min=1"`
+	MenteeId int        `json:"mentee_id" yaml:"mentee_id" validate:"required,min=1"`
+	StartsAt time.Time  `json:"starts_at" yaml:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at" yaml:"ends_at"`
+	Goals    string     `json:"goals" yaml:"goals" validate:"max=2000"`
+}
+
+// CheckFields checks that m pairs two users and ends after it starts.
+func (m *MentorshipRequest) CheckFields() promo.ValidationErrors {
+	var errs promo.ValidationErrors
+	if m.MenteeId > 0 && m.MenteeId == m.MentorId {
+		errs = append(errs, promo.FieldError{Field: "mentee_id", Rule: promo.RuleInvalid, Detail: "is the mentor"})
+	}
+	if m.EndsAt != nil && m.EndsAt.Before(m.StartsAt) {
+		errs = append(errs, promo.FieldError{Field: "ends_at", Rule: promo.RuleInvalid, Detail: "is before starts_at"})
+	}
+	return errs
+}
+
+// store is the MentorshipStore of h, answering 501 itself when there is
+// none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.MentorshipStore, bool) {
+	return promo.Capability[promo.MentorshipStore](w, h.dbc, ErrNotSupported)
+}
+
+// queryInt reads the positive integer query parameter name, 0 when absent,
+// answering 400 itself when illegal.
+func queryInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		promo.Error(w, fmt.Sprintf("illegal %s %q", name, s), http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+// CreateMentorship	 godoc
+//
+//	@Summary		Pair mentor and mentee
+//	@Description	start a mentorship of a mentee by a mentor of a higher grade with room for another mentee, now unless told when, towards some goals
+//	@Tags			mentorships
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			mentorship	body		mentoring.MentorshipRequest	true	"Mentorship"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201			{object}	promo.Mentorship
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		404			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		409			{object}	promo.Problem
+//	@Failure		415			{object}	promo.Problem
+//	@Failure		422			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/mentorships [post]
+func (h *Handlers) CreateMentorship(w http.ResponseWriter, r *http.Request) {
+	ms, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var req MentorshipRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	if req.StartsAt.IsZero() {
+		req.StartsAt = h.clock.Now()
+	}
+	req.Goals = strings.TrimSpace(req.Goals)
+	if err := promo.NewValidator(h.dbc).Validate(&req, false); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	if err := CheckRoom(ms, req.MentorId, h.capacity, req.StartsAt); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	id, err := ms.AddMentorship(promo.Mentorship{MentorId: req.MentorId, MenteeId: req.MenteeId, StartsAt: req.StartsAt,
+		EndsAt: req.EndsAt, Goals: req.Goals})
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	m, err := ms.Mentorship(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, m)
+}
+
+// GetMentorships	 godoc
+//
+//	@Summary		Mentorships
+//	@Description	the mentorships of a mentor or mentee, by id, only those running now when active is true
+//	@Tags			mentorships
+//	@Produce		json,application/yaml
+//	@Param			mentor_id	query		int		false	"Mentor ID"
+//	@Param			mentee_id	query		int		false	"Mentee ID"
+//	@Param			active		query		bool	false	"Only those running now"
+//	@Success		200			{array}		promo.Mentorship
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/mentorships [get]
+func (h *Handlers) GetMentorships(w http.ResponseWriter, r *http.Request) {
+	ms, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var f promo.MentorshipFilter
+	if f.MentorId, ok = queryInt(w, r, "mentor_id"); !ok {
+		return
+	}
+	if f.MenteeId, ok = queryInt(w, r, "mentee_id"); !ok {
+		return
+	}
+	if s := r.URL.Query().Get("active"); s != "" {
+		active, err := strconv.ParseBool(s)
+		if err != nil {
+			promo.Error(w, fmt.Sprintf("illegal active %q", s), http.StatusBadRequest)
+			return
+		}
+		if active {
+			f.RunningAt = h.clock.Now()
+		}
+	}
+	list, err := ms.Mentorships(f)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
+
+// GetMentorship	 godoc
+//
+//	@Summary		Mentorship
+//	@Tags			mentorships
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Mentorship ID"
+//	@Success		200	{object}	promo.Mentorship
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/mentorships/{id} [get]
+func (h *Handlers) GetMentorship(w http.ResponseWriter, r *http.Request) {
+	ms, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	m, err := ms.Mentorship(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, m)
+}
+
+// EndMentorship	 godoc
+//
+//	@Summary		End mentorship
+//	@Description	end a running mentorship now
+//	@Tags			mentorships
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Mentorship ID"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200	{object}	promo.Mentorship
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		409	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/mentorships/{id}/end [post]
+func (h *Handlers) EndMentorship(w http.ResponseWriter, r *http.Request) {
+	ms, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := ms.EndMentorship(id, h.clock.Now()); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	m, err := ms.Mentorship(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, m)
+}
+
+// SuggestMentors	 godoc
+//
+//	@Summary		Suggest mentors
+//	@Description	live users of a higher grade with room for a mentee, those covering most of the skill gaps of the user to the next grade first, then those of their project, then the least loaded
+//	@Tags			mentorships
+//	@Produce		json,application/yaml
+//	@Param			id		path		int	true	"User ID"
+//	@Param			limit	query		int	false	"How many, 5 by default"
+//	@Success		200		{array}		mentoring.Suggestion
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/users/{id}/mentor-suggestions [get]
+func (h *Handlers) SuggestMentors(w http.ResponseWriter, r *http.Request) {
+	ms, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+	if limit == 0 {
+		limit = defaultSuggestions
+	}
+	list, err := Suggest(h.dbc, ms, id, h.capacity, limit, h.clock.Now())
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, list)
+}
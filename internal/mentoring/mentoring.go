@@ -0,0 +1,170 @@
+// Package mentoring pairs mentees with mentors of a higher grade for a
+// while, towards some goals, and suggests mentors for a user: those who
+// cover the gaps of the user to the next grade first, then those of their
+// project, then the least loaded.
+//
+// Mentorships are kept by stores implementing promo.MentorshipStore; skill
+// gaps are only weighed against stores implementing promo.SkillStore too.
+package mentoring
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/skills"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrNotSupported is returned against stores keeping no mentorships.
+	ErrNotSupported = fmt.Errorf("mentorships are %w", promo.ErrNotSupported)
+	// ErrMentorBusy is returned when a mentor has as many mentees as they
+	// can take.
+	ErrMentorBusy = promo.ErrMentorBusy
+)
+
+// DefaultCapacity is how many mentees a mentor takes at a time by default.
+const DefaultCapacity = 3
+
+// Load counts the mentees of each mentor running at t.
+func Load(ms promo.MentorshipStore, t time.Time) (map[int]int, error) {
+	running, err := ms.Mentorships(promo.MentorshipFilter{RunningAt: t})
+	if err != nil {
+		return nil, err
+	}
+	load := make(map[int]int)
+	for _, m := range running {
+		load[m.MentorId]++
+	}
+	return load, nil
+}
+
+// CheckRoom returns ErrMentorBusy if mentor already has capacity mentees
+// running at t. A capacity of 0 has room for everyone.
+func CheckRoom(ms promo.MentorshipStore, mentor, capacity int, t time.Time) error {
+	if capacity == 0 {
+		return nil
+	}
+	load, err := Load(ms, t)
+	if err != nil {
+		return err
+	}
+	if load[mentor] >= capacity {
+		return fmt.Errorf("user %d mentors %d users already: %w", mentor, load[mentor], ErrMentorBusy)
+	}
+	return nil
+}
+
+// Suggestion is a mentor suggested for a mentee: whether they share a
+// project, which of the gaps of the mentee to the next grade they cover,
+// by name, and how many mentees they have.
+type Suggestion struct {
+	UserId      int      `json:"user_id" yaml:"user_id"`
+	Name        string   `json:"name" yaml:"name"`
+	Surname     string   `json:"surname" yaml:"surname"`
+	Grade       string   `json:"grade" yaml:"grade"`
+	Project     string   `json:"project,omitempty" yaml:"project,omitempty"`
+	SameProject bool     `json:"same_project" yaml:"same_project"`
+	Covers      []string `json:"covers" yaml:"covers"`
+	Load        int      `json:"load" yaml:"load"`
+}
+
+// Suggest lists up to limit live users who can mentor mentee at t, those
+// covering most of its skill gaps first, then those of its project, the
+// least loaded, the lowest grade and the lowest id. Users mentoring it
+// already or with no room under capacity are left out.
+func Suggest(dbc promo.DBConnexion, ms promo.MentorshipStore, mentee, capacity, limit int, t time.Time) ([]Suggestion, error) {
+	u, err := dbc.GetUser(mentee)
+	if err != nil {
+		return nil, err
+	}
+	load, err := Load(ms, t)
+	if err != nil {
+		return nil, err
+	}
+	current, err := ms.Mentorships(promo.MentorshipFilter{MenteeId: mentee, RunningAt: t})
+	if err != nil {
+		return nil, err
+	}
+	excluded := map[int]bool{mentee: true}
+	for _, m := range current {
+		excluded[m.MentorId] = true
+	}
+	gaps, levels, err := skillGaps(dbc, *u)
+	if err != nil {
+		return nil, err
+	}
+	users, err := dbc.GetAllUsers(promo.UserFilter{})
+	if err != nil {
+		return nil, err
+	}
+	suggestions := make([]Suggestion, 0)
+	grades := make(map[int]promo.Grade)
+	for _, c := range *users {
+		if c.Position <= u.Position || excluded[c.Id] || capacity > 0 && load[c.Id] >= capacity {
+			continue
+		}
+		s := Suggestion{UserId: c.Id, Name: c.Name, Surname: c.Surname, Grade: c.Position.String(), Project: c.Project,
+			SameProject: u.Project != "" && c.Project == u.Project, Covers: []string{}, Load: load[c.Id]}
+		for _, g := range gaps {
+			if levels[skillOf{c.Id, g.CompetencyId}] >= g.Target {
+				s.Covers = append(s.Covers, g.Competency)
+			}
+		}
+		grades[c.Id] = c.Position
+		suggestions = append(suggestions, s)
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		switch {
+		case len(a.Covers) != len(b.Covers):
+			return len(a.Covers) > len(b.Covers)
+		case a.SameProject != b.SameProject:
+			return a.SameProject
+		case a.Load != b.Load:
+			return a.Load < b.Load
+		case grades[a.UserId] != grades[b.UserId]:
+			return grades[a.UserId] < grades[b.UserId]
+		}
+		return a.UserId < b.UserId
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+type skillOf struct {
+	user, competency int
+}
+
+// skillGaps are the gaps of u to the next grade and the levels of every
+// user by competency, none when dbc keeps no skills.
+func skillGaps(dbc promo.DBConnexion, u promo.User) ([]skills.Gap, map[skillOf]int, error) {
+	ss, ok := dbc.(promo.SkillStore)
+	if !ok {
+		return nil, nil, nil
+	}
+	report, err := skills.GapsOf(ss, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	var gaps []skills.Gap
+	for _, g := range report.Skills {
+		if g.Gap > 0 {
+			gaps = append(gaps, g)
+		}
+	}
+	if len(gaps) == 0 {
+		return nil, nil, nil
+	}
+	all, err := ss.Skills(promo.SkillFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+	levels := make(map[skillOf]int, len(all))
+	for _, s := range all {
+		levels[skillOf{s.UserId, s.CompetencyId}] = s.Level
+	}
+	return gaps, levels, nil
+}
@@ -0,0 +1,158 @@
+package mentoring
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/promotest"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var at = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+// newStore has a junior and a middle on Alpha, a senior on Beta and one on
+// Alpha, and design, expected from middles, which only the senior on Beta
+// masters.
+func newStore(t *testing.T) *promo.Memory {
+	m := promotest.NewStore(t,
+		promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Cid", Surname: "Jones", Position: 3, Project: "Alpha"},
+		promo.User{Name: "Ann", Surname: "Boss", Position: 4, Project: "Beta"},
+		promo.User{Name: "Dan", Surname: "Gray", Position: 4, Project: "Alpha"})
+	_, err := m.CreateCompetency(promo.Competency{Name: "design", Levels: []promo.Expectation{{Grade: 3, Level: 2}}})
+	require.NoError(t, err)
+	for _, s := range []promo.Skill{
+		{UserId: 1, CompetencyId: 1, Level: 1, AssessedAt: at},
+		{UserId: 2, CompetencyId: 1, Level: 1, AssessedAt: at},
+		{UserId: 3, CompetencyId: 1, Level: 3, AssessedAt: at},
+	} {
+		require.NoError(t, m.SetSkill(s))
+	}
+	return m
+}
+
+func newRouter(dbc promo.DBConnexion) *mux.Router {
+	h := NewHandlers(dbc, 1)
+	h.clock = promo.ClockFunc(func() time.Time { return at })
+	return promotest.NewRouter(h)
+}
+
+func TestSuggest(t *testing.T) {
+	m := newStore(t)
+	list, err := Suggest(m, m, 1, 0, 0, at)
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{UserId: 3, Name: "Ann", Surname: "Boss", Grade: "senior", Project: "Beta", Covers: []string{"design"}},
+		{UserId: 2, Name: "Cid", Surname: "Jones", Grade: "middle", Project: "Alpha", SameProject: true, Covers: []string{}},
+		{UserId: 4, Name: "Dan", Surname: "Gray", Grade: "senior", Project: "Alpha", SameProject: true, Covers: []string{}},
+	}, list)
+
+	_, err = m.AddMentorship(promo.Mentorship{MentorId: 4, MenteeId: 2, StartsAt: at})
+	require.NoError(t, err)
+	_, err = m.AddMentorship(promo.Mentorship{MentorId: 2, MenteeId: 1, StartsAt: at})
+	require.NoError(t, err)
+	list, err = Suggest(m, m, 1, 1, 0, at.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, list, 1, "Cid mentors Bob already, Dan is full")
+	assert.Equal(t, 3, list[0].UserId)
+
+	list, err = Suggest(m, m, 1, 0, 2, at.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, []int{list[0].UserId, list[1].UserId})
+	assert.Equal(t, 1, list[1].Load)
+
+	list, err = Suggest(m, m, 3, 0, 0, at)
+	require.NoError(t, err)
+	assert.Empty(t, list, "nobody outranks a senior")
+}
+
+func TestHandlers(t *testing.T) {
+	t.Run("Check mentorships", func(t *testing.T) {
+		m := newStore(t)
+		router := newRouter(m)
+		w := promotest.Serve(router, http.MethodPost, "/mentorships", `{"mentor_id": 3, "mentee_id": 1, "goals": " design "}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var ms promo.Mentorship
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&ms))
+		assert.Equal(t, promo.Mentorship{Id: 1, MentorId: 3, MenteeId: 1, StartsAt: at, Goals: "design"}, ms)
+
+		w = promotest.Serve(router, http.MethodPost, "/mentorships", `{"mentor_id": 3, "mentee_id": 2}`)
+		assert.Equal(t, http.StatusConflict, w.Code, "Ann is full")
+		w = promotest.Serve(router, http.MethodPost, "/mentorships", `{"mentor_id": 1, "mentee_id": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Bob is a junior")
+		w = promotest.Serve(router, http.MethodPost, "/mentorships", `{"mentor_id": 4, "mentee_id": 2}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = promotest.Serve(router, http.MethodGet, "/users/1/mentor-suggestions", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list []Suggestion
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+		require.Len(t, list, 1)
+		assert.Equal(t, 2, list[0].UserId)
+
+		w = promotest.Serve(router, http.MethodPost, "/mentorships/1/end", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		ms = promo.Mentorship{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&ms))
+		require.NotNil(t, ms.EndsAt)
+		assert.Equal(t, at, *ms.EndsAt)
+		w = promotest.Serve(router, http.MethodPost, "/mentorships/1/end", "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = promotest.Serve(router, http.MethodGet, "/mentorships?mentee_id=2&active=true", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var all []promo.Mentorship
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&all))
+		require.Len(t, all, 1)
+		assert.Equal(t, 2, all[0].Id)
+		w = promotest.Serve(router, http.MethodGet, "/mentorships?active=true", "")
+		all = nil
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&all))
+		assert.Len(t, all, 1)
+		w = promotest.Serve(router, http.MethodGet, "/mentorships", "")
+		all = nil
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&all))
+		assert.Len(t, all, 2)
+	})
+	t.Run("Check ending a mentorship (retried)", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/mentorships", `{"mentor_id": 3, "mentee_id": 1}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		for _, replayed := range []string{"", "true"} {
+			w = promotest.Serve(router, http.MethodPost, "/mentorships/1/end", "", promo.IdempotencyKeyHeader, "k1")
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, replayed, w.Header().Get(promo.IdempotentReplayedHeader))
+		}
+	})
+	t.Run("Check mentorships (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t)), http.MethodPost, "/mentorships",
+			`{"mentee_id": 1, "starts_at": "2026-04-01T00:00:00Z", "ends_at": "2026-03-01T00:00:00Z"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "mentor_id", Rule: promo.RuleRequired, Detail: "is required"},
+			{Field: "ends_at", Rule: promo.RuleInvalid, Detail: "is before starts_at"},
+		}, p.Errors)
+	})
+	for name, req := range map[string]struct{ method, url, body string }{
+		"absent mentorship": {http.MethodGet, "/mentorships/42", ""},
+		"end absent":        {http.MethodPost, "/mentorships/42/end", ""},
+		"absent user":       {http.MethodGet, "/users/42/mentor-suggestions", ""},
+	} {
+		t.Run("Check mentorships ("+name+")", func(t *testing.T) {
+			w := promotest.Serve(newRouter(newStore(t)), req.method, req.url, req.body)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+	t.Run("Check mentorships (no mentorships)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(struct{ promo.DBConnexion }{newStore(t)}), http.MethodGet, "/mentorships", "")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
@@ -0,0 +1,98 @@
+package promo
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMentorshipNotFound = errors.New("mentorship not found")
+	// ErrInvalidMentor is returned for a mentor who is not a live user of
+	// a higher grade than the mentee.
+	ErrInvalidMentor = errors.New("mentor must be a live user of a higher grade than the mentee")
+	// ErrMentorshipExists is returned for a second mentorship of a pair
+	// before the first ends.
+	ErrMentorshipExists = errors.New("mentorship already exists")
+	// ErrMentorshipState is returned when ending a mentorship that is not
+	// running.
+	ErrMentorshipState = errors.New("mentorship is not running")
+	// ErrMentorBusy is returned when a mentor has as many mentees as they
+	// can take.
+	ErrMentorBusy = errors.New("mentor has no room for another mentee")
+)
+
+// Mentorship is the mentoring of user MenteeId by user MentorId from
+// StartsAt until EndsAt, nil while open-ended, towards Goals.
+type Mentorship struct {
+	Id       int        `json:"id" yaml:"id"`
+	MentorId int        `json:"mentor_id" yaml:"mentor_id"`
+	MenteeId int        `json:"mentee_id" yaml:"mentee_id"`
+	StartsAt time.Time  `json:"starts_at" yaml:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty" yaml:"ends_at,omitempty"`
+	Goals    string     `json:"goals,omitempty" yaml:"goals,omitempty"`
+}
+
+// Running tells whether m runs at t.
+func (m *Mentorship) Running(t time.Time) bool {
+	return !t.Before(m.StartsAt) && (m.EndsAt == nil || t.Before(*m.EndsAt))
+}
+
+// MentorshipFilter selects mentorships; zero fields match everything.
+// RunningAt selects those running then.
+type MentorshipFilter struct {
+	MentorId  int
+	MenteeId  int
+	RunningAt time.Time
+}
+
+// MentorshipStore keeps mentorships.
+type MentorshipStore interface {
+	// AddMentorship records m and returns its id. Mentor and mentee have to
+	// be live, the mentor of a higher grade, ErrInvalidMentor otherwise;
+	// the pair must have no mentorship left running by m.StartsAt.
+	AddMentorship(m Mentorship) (int, error)
+	// Mentorship is mentorship id, ErrMentorshipNotFound if there is none.
+	Mentorship(id int) (*Mentorship, error)
+	// Mentorships lists the mentorships matching f by id.
+	Mentorships(f MentorshipFilter) ([]Mentorship, error)
+	// EndMentorship ends mentorship id at at, ErrMentorshipState unless it
+	// runs then.
+	EndMentorship(id int, at time.Time) error
+}
+
+// Mentorship queries shared by Registry and SQLite.
+const (
+	mentorshipRunningQuery = "SELECT EXISTS (SELECT 1 FROM mentorship WHERE mentor_id=$1 AND mentee_id=$2 AND (ends_at IS NULL OR ends_at > $3))"
+	addMentorshipQuery     = "INSERT INTO mentorship (mentor_id, mentee_id, starts_at, ends_at, goals) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	endMentorshipQuery     = "UPDATE mentorship SET ends_at=$2 WHERE id=$1 AND starts_at <= $2 AND (ends_at IS NULL OR ends_at > $2)"
+)
+
+// mentorshipsQuery selects the mentorships matching f, and mentorship id
+// unless 0, by id, with $n parameters.
+func mentorshipsQuery(f MentorshipFilter, id int) (string, []any) {
+	var cond []string
+	var args []any
+	if id != 0 {
+		args = append(args, id)
+		cond = append(cond, "id="+pgParam(len(args)))
+	}
+	if f.MentorId != 0 {
+		args = append(args, f.MentorId)
+		cond = append(cond, "mentor_id="+pgParam(len(args)))
+	}
+	if f.MenteeId != 0 {
+		args = append(args, f.MenteeId)
+		cond = append(cond, "mentee_id="+pgParam(len(args)))
+	}
+	if !f.RunningAt.IsZero() {
+		args = append(args, f.RunningAt.UTC())
+		n := pgParam(len(args))
+		cond = append(cond, "starts_at <= "+n+" AND (ends_at IS NULL OR ends_at > "+n+")")
+	}
+	where := ""
+	if len(cond) > 0 {
+		where = " WHERE " + strings.Join(cond, " AND ")
+	}
+	return "SELECT id, mentor_id, mentee_id, starts_at, ends_at, COALESCE(goals, '') FROM mentorship" + where + " ORDER BY id", args
+}
@@ -0,0 +1,69 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Mentorships(t *testing.T) {
+	at := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	userRows := func(position string) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"position", "project"}).AddRow(position, "Alpha")
+	}
+	t.Run("Check starting a mentorship (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(3).WillReturnRows(userRows("senior"))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(1).WillReturnRows(userRows("junior"))
+		mock.ExpectQuery(regexp.QuoteMeta(mentorshipRunningQuery)).WithArgs(3, 1, at).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(regexp.QuoteMeta(addMentorshipQuery)).WithArgs(3, 1, at, (*time.Time)(nil), nullable("design")).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		id, err := r.AddMentorship(Mentorship{MentorId: 3, MenteeId: 1, StartsAt: at, Goals: "design"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check starting a mentorship (registry, mentor not above)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(2).WillReturnRows(userRows("middle"))
+		mock.ExpectQuery(regexp.QuoteMeta(assessmentUser)).WithArgs(1).WillReturnRows(userRows("middle"))
+		mock.ExpectRollback()
+		_, err := r.AddMentorship(Mentorship{MentorId: 2, MenteeId: 1, StartsAt: at})
+		assert.ErrorIs(t, err, ErrInvalidMentor)
+	})
+	t.Run("Check ending a mentorship (registry, ended)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		ended := at.AddDate(0, 1, 0)
+		mock.ExpectExec(regexp.QuoteMeta(endMentorshipQuery)).WithArgs(1, at.AddDate(0, 2, 0)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		req, args := mentorshipsQuery(MentorshipFilter{}, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "mentor_id", "mentee_id", "starts_at", "ends_at", "goals"}).
+				AddRow(1, 3, 1, at, &ended, "design"))
+		assert.ErrorIs(t, r.EndMentorship(1, at.AddDate(0, 2, 0)), ErrMentorshipState)
+	})
+	t.Run("Check listing mentorships (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		ended := at.AddDate(0, 1, 0)
+		f := MentorshipFilter{MentorId: 3}
+		req, args := mentorshipsQuery(f, 0)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "mentor_id", "mentee_id", "starts_at", "ends_at", "goals"}).
+				AddRow(1, 3, 1, at, &ended, "design").
+				AddRow(2, 3, 2, at, (*time.Time)(nil), ""))
+		ms, err := r.Mentorships(f)
+		require.NoError(t, err)
+		assert.Equal(t, []Mentorship{
+			{Id: 1, MentorId: 3, MenteeId: 1, StartsAt: at, EndsAt: &ended, Goals: "design"},
+			{Id: 2, MentorId: 3, MenteeId: 2, StartsAt: at},
+		}, ms)
+	})
+}
@@ -0,0 +1,9 @@
+-- SQLite has neither enums nor SERIAL: position is checked text and
+-- AUTOINCREMENT keeps ids from being reused, as a sequence would.
+CREATE TABLE IF NOT EXISTS usr (
+                                id          INTEGER PRIMARY KEY AUTOINCREMENT,
+                                name        TEXT NOT NULL,
+                                surname     TEXT NOT NULL,
+                                position    TEXT NOT NULL CHECK (position IN ('trainee', 'junior', 'middle', 'senior')),
+                                project     TEXT
+);
@@ -0,0 +1,3 @@
+ALTER TABLE usr ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS usr_live_idx ON usr (id) WHERE deleted_at IS NULL;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS idempotency_key (
+                                key          TEXT PRIMARY KEY,
+                                fingerprint  TEXT NOT NULL,
+                                status       INTEGER NOT NULL DEFAULT 0,
+                                content_type TEXT NOT NULL DEFAULT '',
+                                body         BLOB,
+                                expires_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_key_expires_idx ON idempotency_key (expires_at);
@@ -0,0 +1,5 @@
+ALTER TABLE usr ADD COLUMN hire_date TEXT;
+ALTER TABLE usr ADD COLUMN location TEXT;
+ALTER TABLE usr ADD COLUMN manager_id INTEGER REFERENCES usr (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS usr_manager_idx ON usr (manager_id);
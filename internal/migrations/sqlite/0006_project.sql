@@ -0,0 +1,8 @@
+-- Projects users may be put on. Existing user rows are not constrained, new
+-- and updated users are checked against it by the API.
+CREATE TABLE IF NOT EXISTS project (
+    name TEXT PRIMARY KEY
+);
+
+INSERT OR IGNORE INTO project (name)
+SELECT DISTINCT project FROM usr WHERE project IS NOT NULL AND project <> '';
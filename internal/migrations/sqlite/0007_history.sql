@@ -0,0 +1,52 @@
+-- Every version of a user while live, see postgres/init/08_history.sql.
+-- Times are written the way the driver writes a UTC time.Time, so that they
+-- compare as text with the parameters of queries.
+CREATE TABLE IF NOT EXISTS usr_history (
+    id              INTEGER NOT NULL,
+    name            TEXT NOT NULL,
+    surname         TEXT NOT NULL,
+    position        TEXT NOT NULL,
+    project         TEXT,
+    email           TEXT,
+    employee_number TEXT,
+    hire_date       TEXT,
+    location        TEXT,
+    manager_id      INTEGER,
+    valid_from      TIMESTAMP NOT NULL,
+    valid_to        TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS usr_history_id_idx ON usr_history (id, valid_from);
+CREATE INDEX IF NOT EXISTS usr_history_valid_idx ON usr_history (valid_from, valid_to);
+
+-- An empty valid_from sorts before any time: forever.
+INSERT INTO usr_history (id, name, surname, position, project, email, employee_number, hire_date, location, manager_id,
+                         valid_from, valid_to)
+SELECT id, name, surname, position, project, email, employee_number, hire_date, location, manager_id,
+       COALESCE(hire_date, ''), deleted_at
+FROM usr;
+
+CREATE TRIGGER IF NOT EXISTS usr_history_insert AFTER INSERT ON usr WHEN NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO usr_history (id, name, surname, position, project, email, employee_number, hire_date, location,
+                             manager_id, valid_from)
+    VALUES (NEW.id, NEW.name, NEW.surname, NEW.position, NEW.project, NEW.email, NEW.employee_number, NEW.hire_date,
+            NEW.location, NEW.manager_id, strftime('%Y-%m-%d %H:%M:%f +0000 UTC', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS usr_history_update AFTER UPDATE ON usr
+BEGIN
+    UPDATE usr_history SET valid_to = strftime('%Y-%m-%d %H:%M:%f +0000 UTC', 'now')
+    WHERE id = OLD.id AND valid_to IS NULL;
+    INSERT INTO usr_history (id, name, surname, position, project, email, employee_number, hire_date, location,
+                             manager_id, valid_from)
+    SELECT NEW.id, NEW.name, NEW.surname, NEW.position, NEW.project, NEW.email, NEW.employee_number, NEW.hire_date,
+           NEW.location, NEW.manager_id, strftime('%Y-%m-%d %H:%M:%f +0000 UTC', 'now')
+    WHERE NEW.deleted_at IS NULL;
+END;
+
+CREATE TRIGGER IF NOT EXISTS usr_history_delete AFTER DELETE ON usr
+BEGIN
+    UPDATE usr_history SET valid_to = strftime('%Y-%m-%d %H:%M:%f +0000 UTC', 'now')
+    WHERE id = OLD.id AND valid_to IS NULL;
+END;
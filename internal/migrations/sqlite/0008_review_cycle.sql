@@ -0,0 +1,25 @@
+-- Promotion review cycles, see postgres/init/09_review_cycle.sql.
+CREATE TABLE IF NOT EXISTS review_cycle (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    name      TEXT NOT NULL,
+    opens_at  TIMESTAMP NOT NULL,
+    closes_at TIMESTAMP NOT NULL,
+    status    TEXT NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'open', 'closed'))
+);
+
+CREATE TABLE IF NOT EXISTS review_cycle_project (
+    cycle_id INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    project  TEXT NOT NULL,
+    PRIMARY KEY (cycle_id, project)
+);
+
+CREATE TABLE IF NOT EXISTS review (
+    cycle_id   INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    user_id    INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    from_grade TEXT NOT NULL CHECK (from_grade IN ('trainee', 'junior', 'middle', 'senior')),
+    to_grade   TEXT NOT NULL CHECK (to_grade IN ('trainee', 'junior', 'middle', 'senior')),
+    status     TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'promoted', 'skipped')),
+    comment    TEXT,
+    PRIMARY KEY (cycle_id, user_id)
+);
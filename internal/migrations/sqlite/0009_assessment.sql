@@ -0,0 +1,28 @@
+-- Performance assessments, see postgres/init/10_assessment.sql.
+CREATE TABLE IF NOT EXISTS assessment (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id    INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    user_id     INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    reviewer_id INTEGER REFERENCES usr (id) ON DELETE SET NULL,
+    grade       TEXT NOT NULL CHECK (grade IN ('trainee', 'junior', 'middle', 'senior')),
+    project     TEXT NOT NULL,
+    rating      INTEGER NOT NULL,
+    summary     TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS assessment_reviewer ON assessment (cycle_id, user_id, COALESCE(reviewer_id, 0));
+CREATE INDEX IF NOT EXISTS assessment_user ON assessment (user_id);
+
+CREATE TABLE IF NOT EXISTS assessment_score (
+    assessment_id INTEGER NOT NULL REFERENCES assessment (id) ON DELETE CASCADE,
+    competency    TEXT NOT NULL,
+    score         INTEGER NOT NULL,
+    PRIMARY KEY (assessment_id, competency)
+);
+
+CREATE TABLE IF NOT EXISTS calibration (
+    cycle_id      INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    project       TEXT NOT NULL,
+    calibrated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (cycle_id, project)
+);
@@ -0,0 +1,24 @@
+-- 360-degree feedback, see postgres/init/11_feedback.sql.
+CREATE TABLE IF NOT EXISTS feedback (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id          INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    manager_id       INTEGER REFERENCES usr (id) ON DELETE SET NULL,
+    manager_key_hash TEXT NOT NULL UNIQUE,
+    due_at           TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS feedback_user ON feedback (user_id);
+
+CREATE TABLE IF NOT EXISTS feedback_respondent (
+    feedback_id   INTEGER NOT NULL REFERENCES feedback (id) ON DELETE CASCADE,
+    respondent_id INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    relation      TEXT NOT NULL CHECK (relation IN ('peer', 'manager', 'report')),
+    key_hash      TEXT NOT NULL UNIQUE,
+    rating        INTEGER,
+    strengths     TEXT,
+    improvements  TEXT,
+    anonymous     BOOLEAN NOT NULL DEFAULT false,
+    answered_at   TIMESTAMP,
+    reminded_at   TIMESTAMP,
+    PRIMARY KEY (feedback_id, respondent_id)
+);
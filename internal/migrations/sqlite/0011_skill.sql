@@ -0,0 +1,24 @@
+-- The competency framework and skills of users, see
+-- postgres/init/12_skill.sql.
+CREATE TABLE IF NOT EXISTS competency (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS competency_level (
+    competency_id INTEGER NOT NULL REFERENCES competency (id) ON DELETE CASCADE,
+    grade         TEXT NOT NULL CHECK (grade IN ('trainee', 'junior', 'middle', 'senior')),
+    level         INTEGER NOT NULL,
+    PRIMARY KEY (competency_id, grade)
+);
+
+CREATE TABLE IF NOT EXISTS user_skill (
+    user_id       INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    competency_id INTEGER NOT NULL REFERENCES competency (id) ON DELETE CASCADE,
+    level         INTEGER NOT NULL,
+    assessed_at   TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, competency_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_skill_competency ON user_skill (competency_id);
@@ -0,0 +1,14 @@
+-- Mentorships, see postgres/init/13_mentorship.sql.
+CREATE TABLE IF NOT EXISTS mentorship (
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    mentor_id INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    mentee_id INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at   TIMESTAMP,
+    goals     TEXT,
+    CHECK (mentor_id <> mentee_id),
+    CHECK (ends_at IS NULL OR ends_at >= starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS mentorship_mentor ON mentorship (mentor_id);
+CREATE INDEX IF NOT EXISTS mentorship_mentee ON mentorship (mentee_id);
@@ -0,0 +1,21 @@
+-- Development plans, see postgres/init/14_goal.sql.
+CREATE TABLE IF NOT EXISTS goal (
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    title        TEXT NOT NULL,
+    description  TEXT,
+    target_grade TEXT CHECK (target_grade IN ('trainee', 'junior', 'middle', 'senior')),
+    due_date     TEXT,
+    status       TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_progress', 'done', 'dropped'))
+);
+
+CREATE INDEX IF NOT EXISTS goal_user ON goal (user_id);
+
+CREATE TABLE IF NOT EXISTS goal_note (
+    id      INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL REFERENCES goal (id) ON DELETE CASCADE,
+    note    TEXT NOT NULL,
+    at      TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS goal_note_goal ON goal_note (goal_id);
@@ -0,0 +1,15 @@
+-- Certifications and courses, see postgres/init/15_certification.sql.
+CREATE TABLE IF NOT EXISTS certification (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK (kind IN ('certification', 'course')),
+    name       TEXT NOT NULL,
+    issuer     TEXT NOT NULL,
+    issued_on  TEXT NOT NULL,
+    expires_on TEXT,
+    evidence   TEXT,
+    flagged_at TIMESTAMP,
+    CHECK (expires_on IS NULL OR expires_on >= issued_on)
+);
+
+CREATE INDEX IF NOT EXISTS certification_user ON certification (user_id);
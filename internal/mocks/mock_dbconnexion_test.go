@@ -5,6 +5,8 @@ package mocks
 import (
 	promo "AndersenPromo/internal"
 
+	time "time"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -27,6 +29,44 @@ func (_m *DBConnexion) AddUser(_a0 string, _a1 string, _a2 promo.Grade, _a3 stri
 	return r0
 }
 
+// AddUsers provides a mock function with given fields: _a0
+func (_m *DBConnexion) AddUsers(_a0 []promo.User) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]promo.User) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateUser provides a mock function with given fields: _a0, _a1
+func (_m *DBConnexion) CreateUser(_a0 promo.User, _a1 bool) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(promo.User, bool) (int, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(promo.User, bool) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(promo.User, bool) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteUser provides a mock function with given fields: _a0
 func (_m *DBConnexion) DeleteUser(_a0 int) error {
 	ret := _m.Called(_a0)
@@ -41,25 +81,143 @@ func (_m *DBConnexion) DeleteUser(_a0 int) error {
 	return r0
 }
 
-// GetAllUsers provides a mock function with given fields:
-func (_m *DBConnexion) GetAllUsers() (*[]promo.User, error) {
-	ret := _m.Called()
+// RestoreUser provides a mock function with given fields: _a0
+func (_m *DBConnexion) RestoreUser(_a0 int) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MergeUsers provides a mock function with given fields: _a0, _a1
+func (_m *DBConnexion) MergeUsers(_a0 int, _a1 int) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reports provides a mock function with given fields: _a0, _a1
+func (_m *DBConnexion) Reports(_a0 int, _a1 int) ([]promo.Report, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []promo.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, int) ([]promo.Report, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(int, int) []promo.Report); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]promo.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Chain provides a mock function with given fields: _a0
+func (_m *DBConnexion) Chain(_a0 int) ([]promo.Report, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []promo.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int) ([]promo.Report, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(int) []promo.Report); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]promo.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeleted provides a mock function with given fields: _a0
+func (_m *DBConnexion) PurgeDeleted(_a0 time.Time) (int, error) {
+	ret := _m.Called(_a0)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int, error)); ok {
+		return rf(_a0)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EachUser provides a mock function with given fields: _a0, _a1
+func (_m *DBConnexion) EachUser(_a0 promo.UserFilter, _a1 func(promo.User) error) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(promo.UserFilter, func(promo.User) error) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAllUsers provides a mock function with given fields: _a0
+func (_m *DBConnexion) GetAllUsers(_a0 promo.UserFilter) (*[]promo.User, error) {
+	ret := _m.Called(_a0)
 
 	var r0 *[]promo.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func() (*[]promo.User, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(promo.UserFilter) (*[]promo.User, error)); ok {
+		return rf(_a0)
 	}
-	if rf, ok := ret.Get(0).(func() *[]promo.User); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(promo.UserFilter) *[]promo.User); ok {
+		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*[]promo.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(promo.UserFilter) error); ok {
+		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,232 @@
+package promo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonContentType = "application/json"
+	yamlContentType = "application/yaml"
+	xmlContentType  = "application/xml"
+)
+
+// Codec renders and parses one media type.
+type Codec struct {
+	contentType string
+	encode      func(io.Writer, any) error
+	decode      func([]byte, any) error
+}
+
+var (
+	jsonCodec = Codec{jsonContentType, encodeJSON, json.Unmarshal}
+	yamlCodec = Codec{yamlContentType, encodeYAML, yaml.Unmarshal}
+	xmlCodec  = Codec{xmlContentType, encodeXML, nil}
+	csvCodec  = Codec{csvContentType, encodeCSV, nil}
+)
+
+// codecs is keyed by every media type a client may name in Accept or
+// Content-Type, aliases included.
+var codecs = map[string]Codec{
+	jsonContentType:      jsonCodec,
+	yamlContentType:      yamlCodec,
+	"application/x-yaml": yamlCodec,
+	"text/yaml":          yamlCodec,
+	xmlContentType:       xmlCodec,
+	"text/xml":           xmlCodec,
+	csvContentType:       csvCodec,
+}
+
+// What read and write endpoints answer with, in order of preference.
+var (
+	readCodecs  = []Codec{jsonCodec, yamlCodec, xmlCodec, csvCodec}
+	writeCodecs = []Codec{jsonCodec, yamlCodec}
+)
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var rs []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			rs = append(rs, acceptRange{mt, q})
+		}
+	}
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].q > rs[j].q })
+	return rs
+}
+
+func (c Codec) matches(mediaType string) bool {
+	if mediaType == "*/*" {
+		return true
+	}
+	if typ, ok := strings.CutSuffix(mediaType, "/*"); ok {
+		return strings.HasPrefix(c.contentType, typ+"/")
+	}
+	return codecs[mediaType].contentType == c.contentType
+}
+
+// negotiate picks the Codec of offers the Accept header of r prefers. On
+// failure it has already answered 406.
+func negotiate(w http.ResponseWriter, r *http.Request, offers []Codec) (Codec, bool) {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return offers[0], true
+	}
+	for _, ar := range parseAccept(header) {
+		for _, c := range offers {
+			if c.matches(ar.mediaType) {
+				return c, true
+			}
+		}
+	}
+	types := make([]string, len(offers))
+	for i, c := range offers {
+		types[i] = c.contentType
+	}
+	Error(w, fmt.Sprintf("cannot produce %s, available: %s", header, strings.Join(types, ", ")),
+		http.StatusNotAcceptable)
+	return Codec{}, false
+}
+
+// Negotiate picks JSON or YAML, whichever the Accept header of r prefers,
+// as every endpoint but the user reads answers. On failure it has already
+// answered 406.
+func Negotiate(w http.ResponseWriter, r *http.Request) (Codec, bool) {
+	return negotiate(w, r, writeCodecs)
+}
+
+// Respond writes v encoded with c, or a 500 if v cannot be encoded.
+func Respond(w http.ResponseWriter, c Codec, status int, v any) {
+	var buf bytes.Buffer
+	if err := c.encode(&buf, v); err != nil {
+		Error(w, fmt.Sprintf("unable to encode response as %s: %v", c.contentType, err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", c.contentType)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+// DecodeBody parses the body of a write request according to its
+// Content-Type, JSON when there is none.
+func DecodeBody(r *http.Request, v any) error {
+	mt := jsonContentType
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		var err error
+		if mt, _, err = mime.ParseMediaType(ct); err != nil {
+			return fmt.Errorf("%w: %v", errUnsupportedMediaType, err)
+		}
+	}
+	c, ok := codecs[mt]
+	if !ok || c.decode == nil {
+		return fmt.Errorf("%w %s, expected %s or %s", errUnsupportedMediaType, mt, jsonContentType, yamlContentType)
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return c.decode(b, v)
+}
+
+// Decode is DecodeBody into v, answering 400 or 415 itself when the body
+// does not decode.
+func Decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := DecodeBody(r, v); err != nil {
+		BodyError(w, err)
+		return false
+	}
+	return true
+}
+
+// BodyError answers a DecodeBody failure.
+func BodyError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, errUnsupportedMediaType) {
+		status = http.StatusUnsupportedMediaType
+	}
+	Error(w, fmt.Sprintf("%v", err), status)
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func encodeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+type xmlUsers struct {
+	XMLName xml.Name `xml:"users"`
+	Users   []User   `xml:"user"`
+}
+
+func encodeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	switch v := v.(type) {
+	case []User:
+		return enc.Encode(xmlUsers{Users: v})
+	case *User:
+		return enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: "user"}})
+	}
+	return enc.Encode(v)
+}
+
+func encodeCSV(w io.Writer, v any) error {
+	var us []User
+	switch v := v.(type) {
+	case []User:
+		us = v
+	case *User:
+		us = []User{*v}
+	default:
+		return fmt.Errorf("%T has no CSV form", v)
+	}
+	e := &csvExporter{csv.NewWriter(w)}
+	_ = e.cw.Write(exportHeader)
+	for _, u := range us {
+		if err := e.write(u); err != nil {
+			return err
+		}
+	}
+	return e.close()
+}
@@ -0,0 +1,135 @@
+package promo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlers_Negotiation(t *testing.T) {
+	userRows := func() *pgxmock.Rows {
+		return pgxmock.NewRows(userCols).
+			AddRow(5, "And", "Ersen", "middle", "Test", "", "", "", "", 0)
+	}
+	cases := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"default", "", jsonContentType, `{"id":5,"name":"And","surname":"Ersen","position":3,"project":"Test"}`},
+		{"yaml", "application/yaml", yamlContentType, "id: 5\nname: And\nsurname: Ersen\nposition: 3\nproject: Test\n"},
+		{"xml", "text/xml", xmlContentType,
+			`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<user><id>5</id><name>And</name><surname>Ersen</surname><position>3</position><project>Test</project></user>`},
+		{"csv by wildcard", "text/*", csvContentType, "id,name,surname,position,project,email,employee_number,hire_date,location,manager_id\n5,And,Ersen,middle,Test,,,,,\n"},
+		{"by quality", "application/xml;q=0.5, application/x-yaml", yamlContentType,
+			"id: 5\nname: And\nsurname: Ersen\nposition: 3\nproject: Test\n"},
+	}
+	for _, c := range cases {
+		t.Run("Check getting user as "+c.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening mock", err)
+			}
+			defer mock.Close()
+			r := &Registry{mock}
+			h := &Handlers{r}
+
+			mock.ExpectQuery(selectUsers(" FROM")).WithArgs(5).WillReturnRows(userRows())
+			req := httptest.NewRequest(http.MethodGet, "/get/5", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "5"})
+			req.Header.Set("Accept", c.accept)
+			w := httptest.NewRecorder()
+			h.GetUser(w, req)
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+			assert.Equal(t, c.contentType, w.Result().Header.Get("Content-Type"))
+			body, _ := io.ReadAll(w.Result().Body)
+			assert.Equal(t, c.body, string(body))
+			err = mock.ExpectationsWereMet()
+			assert.NoErrorf(t, err, "there were unfulfilled expectations")
+		})
+	}
+	t.Run("Check getting user list as xml", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		rows := pgxmock.NewRows(userCols).
+			AddRow(1, "And", "Ersen", "middle", "Test", "", "", "", "", 0)
+		mock.ExpectQuery(selectUsers(" FROM")).WillReturnRows(rows)
+		req := httptest.NewRequest(http.MethodGet, "/getall", nil)
+		req.Header.Set("Accept", "application/xml")
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		body, _ := io.ReadAll(w.Result().Body)
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<users><user><id>1</id><name>And</name><surname>Ersen</surname><position>3</position><project>Test</project></user></users>`,
+			string(body))
+	})
+	t.Run("Check getting user (not acceptable)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		req := httptest.NewRequest(http.MethodGet, "/get/5", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		req.Header.Set("Accept", "application/pdf")
+		w := httptest.NewRecorder()
+		h.GetUser(w, req)
+		assert.Equal(t, http.StatusNotAcceptable, w.Result().StatusCode)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check creating user from yaml", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expectProject(mock, "Test", true)
+		mock.ExpectQuery("INSERT INTO usr").WithArgs("And", "Ersen", "junior", "Test", (*string)(nil), (*string)(nil), false, (*string)(nil), (*string)(nil), (*int)(nil)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "field"}).AddRow(1, ""))
+		body := bytes.NewReader([]byte("name: And\nsurname: Ersen\nposition: 2\nproject: Test\n"))
+		req := httptest.NewRequest(http.MethodPost, "/create", body)
+		req.Header.Set("Content-Type", "application/yaml")
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check creating user (unsupported media type)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		req := httptest.NewRequest(http.MethodPost, "/create", bytes.NewReader([]byte("<user/>")))
+		req.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Result().StatusCode)
+	})
+}
@@ -1,5 +1,11 @@
 package promo
 
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
 type Grade int
 
 const (
@@ -23,10 +29,77 @@ var bGrades = map[string]Grade{
 	"senior":  senior,
 }
 
+func (g Grade) String() string {
+	if s, ok := dGrades[g]; ok {
+		return s
+	}
+	return strconv.Itoa(int(g))
+}
+
+// Grades lists every grade, lowest first.
+func Grades() []Grade {
+	return []Grade{trainee, junior, middle, senior}
+}
+
+// Next returns the grade a user is promoted to, false for the top grade.
+func (g Grade) Next() (Grade, bool) {
+	n := g + 1
+	_, ok := dGrades[n]
+	return n, ok
+}
+
+// ParseGrade accepts either a grade name ("middle") or its numeric value ("3").
+func ParseGrade(s string) (Grade, error) {
+	if g, ok := bGrades[s]; ok {
+		return g, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && dGrades[Grade(n)] != "" {
+		return Grade(n), nil
+	}
+	return 0, fmt.Errorf("illegal grade %q", s)
+}
+
+// User declares the rules it is validated by, see validator.
 type User struct {
-	Id       int    `json:"id"`
-	Name     string `json:"name"`
-	Surname  string `json:"surname"`
-	Position Grade  `json:"position"`
-	Project  string `json:"project"`
+	Id       int    `json:"id" yaml:"id" xml:"id"`
+	Name     string `json:"name" yaml:"name" xml:"name" validate:"required,name"`
+	Surname  string `json:"surname" yaml:"surname" xml:"surname" validate:"required,name"`
+	Position Grade  `json:"position" yaml:"position" xml:"position" validate:"required,enum=grade"`
+	Project  string `json:"project" yaml:"project" xml:"project" validate:"max=100,ref=project"`
+	// Email and EmployeeNumber are optional, but unique when set.
+	Email          string `json:"email,omitempty" yaml:"email,omitempty" xml:"email,omitempty" validate:"email"`
+	EmployeeNumber string `json:"employee_number,omitempty" yaml:"employee_number,omitempty" xml:"employee_number,omitempty" validate:"max=32,pattern=employee_number"`
+	// HireDate is formatted as DateLayout.
+	HireDate string `json:"hire_date,omitempty" yaml:"hire_date,omitempty" xml:"hire_date,omitempty" validate:"date"`
+	Location string `json:"location,omitempty" yaml:"location,omitempty" xml:"location,omitempty" validate:"max=100"`
+	// ManagerId is another live user, 0 for none.
+	ManagerId int `json:"manager_id,omitempty" yaml:"manager_id,omitempty" xml:"manager_id,omitempty" validate:"min=1"`
+	// DeletedAt is only ever set when deleted users are asked for.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+}
+
+// CheckFields refuses a user managing themselves, when u is known.
+func (u User) CheckFields() ValidationErrors {
+	if u.Id != 0 && u.ManagerId == u.Id {
+		return ValidationErrors{{"manager_id", RuleInvalid, "cannot be the user themselves"}}
+	}
+	return nil
+}
+
+// DateLayout is how dates are written in and out of the API.
+const DateLayout = "2006-01-02"
+
+// UserFilter narrows GetAllUsers down; zero values match everything but
+// deleted users. HiredFrom and HiredTo are inclusive and formatted as
+// DateLayout.
+type UserFilter struct {
+	Project        string
+	Position       Grade
+	IncludeDeleted bool
+	Email          string
+	EmployeeNumber string
+	Location       string
+	ManagerId      int
+	HiredFrom      string
+	HiredTo        string
 }
@@ -0,0 +1,252 @@
+package promo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxOrgDepth bounds how many levels reports and chains walk through.
+const maxOrgDepth = 100
+
+// Report is a user found walking the hierarchy from another one, Depth
+// levels below (reports) or above (chain) them.
+type Report struct {
+	User  `yaml:",inline"`
+	Depth int `json:"depth" yaml:"depth"`
+}
+
+// OrgNode is a user of the org chart with their direct reports.
+type OrgNode struct {
+	User    `yaml:",inline"`
+	Reports []OrgNode `json:"reports,omitempty" yaml:"reports,omitempty"`
+}
+
+// reportsQuery walks down from user $1, itself at depth 0, at most $2 levels.
+const reportsQuery = `WITH RECURSIVE tree(id, depth) AS (
+	SELECT id, 0 FROM usr WHERE id=$1 AND deleted_at IS NULL
+	UNION ALL
+	SELECT u.id, t.depth+1 FROM usr u JOIN tree t ON u.manager_id=t.id
+	WHERE u.deleted_at IS NULL AND t.depth < $2
+)
+SELECT ` + userColumns + `, depth FROM usr JOIN tree USING (id) ORDER BY depth, id`
+
+// chainQuery walks up from user $1, itself at depth 0, at most $2 levels.
+// The chain stops at a deleted manager.
+const chainQuery = `WITH RECURSIVE chain(id, depth) AS (
+	SELECT id, 0 FROM usr WHERE id=$1 AND deleted_at IS NULL
+	UNION ALL
+	SELECT m.id, c.depth+1 FROM chain c JOIN usr u ON u.id=c.id JOIN usr m ON m.id=u.manager_id
+	WHERE m.deleted_at IS NULL AND c.depth < $2
+)
+SELECT ` + userColumns + `, depth FROM usr JOIN chain USING (id) ORDER BY depth`
+
+// orgChart builds the forest of the users matching f: a user whose manager
+// does not match is a root. Roots and reports are ordered by id.
+func orgChart(dbc DBConnexion, f UserFilter) ([]OrgNode, error) {
+	var us []User
+	in := make(map[int]bool)
+	err := dbc.EachUser(f, func(u User) error {
+		us = append(us, u)
+		in[u.Id] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	reports := make(map[int][]User)
+	var roots []User
+	for _, u := range us {
+		if u.ManagerId != 0 && in[u.ManagerId] {
+			reports[u.ManagerId] = append(reports[u.ManagerId], u)
+		} else {
+			roots = append(roots, u)
+		}
+	}
+	var build func(u User, depth int) OrgNode
+	build = func(u User, depth int) OrgNode {
+		n := OrgNode{User: u}
+		if depth < maxOrgDepth {
+			for _, r := range reports[u.Id] {
+				n.Reports = append(n.Reports, build(r, depth+1))
+			}
+		}
+		return n
+	}
+	nodes := make([]OrgNode, 0, len(roots))
+	for _, u := range roots {
+		nodes = append(nodes, build(u, 0))
+	}
+	return nodes, nil
+}
+
+func orgLabel(u User) string {
+	return fmt.Sprintf("%s %s\n%s", u.Name, u.Surname, u.Position)
+}
+
+// walkOrg calls fn for every node of roots, depth first, with its manager
+// (nil for roots).
+func walkOrg(roots []OrgNode, manager *OrgNode, fn func(n, manager *OrgNode)) {
+	for i := range roots {
+		fn(&roots[i], manager)
+		walkOrg(roots[i].Reports, &roots[i], fn)
+	}
+}
+
+// writeDot renders roots for Graphviz.
+func writeDot(w io.Writer, roots []OrgNode) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph orgchart {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+	walkOrg(roots, nil, func(n, m *OrgNode) {
+		fmt.Fprintf(bw, "\tu%d [label=%s];\n", n.Id, strconv.Quote(orgLabel(n.User)))
+		if m != nil {
+			fmt.Fprintf(bw, "\tu%d -> u%d;\n", m.Id, n.Id)
+		}
+	})
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>")
+
+// writeMermaid renders roots as a Mermaid flowchart.
+func writeMermaid(w io.Writer, roots []OrgNode) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph TD")
+	walkOrg(roots, nil, func(n, m *OrgNode) {
+		fmt.Fprintf(bw, "\tu%d[\"%s\"]\n", n.Id, mermaidEscaper.Replace(orgLabel(n.User)))
+		if m != nil {
+			fmt.Fprintf(bw, "\tu%d --> u%d\n", m.Id, n.Id)
+		}
+	})
+	return bw.Flush()
+}
+
+// Reports	 godoc
+//
+//	@Summary		Reports
+//	@Description	list the users under id, nearest first
+//	@Tags			orgchart
+//	@Produce		json,application/yaml
+//	@Param			id		path		int	true	"User ID"
+//	@Param			depth	query		int	false	"Levels to walk down, 100 at most and by default"
+//	@Success		200		{array}		Report
+//	@Failure		400		{object}	Problem
+//	@Failure		404		{object}	Problem
+//	@Failure		406		{object}	Problem
+//	@Failure		500		{object}	Problem
+//	@Router			/users/{id}/reports [get]
+func (h *Handlers) Reports(w http.ResponseWriter, r *http.Request) {
+	id, ok := PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	depth := maxOrgDepth
+	if d := r.URL.Query().Get("depth"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 || n > maxOrgDepth {
+			Error(w, fmt.Sprintf("illegal depth %q, want 1 to %d", d, maxOrgDepth), http.StatusBadRequest)
+			return
+		}
+		depth = n
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	reports, err := h.dbc.Reports(id, depth)
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	Respond(w, c, http.StatusOK, reports)
+}
+
+// Chain	 godoc
+//
+//	@Summary		Management chain
+//	@Description	list the managers of id up to the top, direct manager first
+//	@Tags			orgchart
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{array}		Report
+//	@Failure		400	{object}	Problem
+//	@Failure		404	{object}	Problem
+//	@Failure		406	{object}	Problem
+//	@Failure		500	{object}	Problem
+//	@Router			/users/{id}/chain [get]
+func (h *Handlers) Chain(w http.ResponseWriter, r *http.Request) {
+	id, ok := PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	chain, err := h.dbc.Chain(id)
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	Respond(w, c, http.StatusOK, chain)
+}
+
+// OrgChart	 godoc
+//
+//	@Summary		Org chart
+//	@Description	render the hierarchy of the users matching the filters; a user whose manager does not match is a root
+//	@Tags			orgchart
+//	@Produce		json,application/yaml,text/vnd.graphviz,text/vnd.mermaid
+//	@Param			format		query		string	false	"json (the default, or as accepted), dot or mermaid"
+//	@Param			project		query		string	false	"Project name"
+//	@Param			grade		query		string	false	"Grade name or number"
+//	@Param			location	query		string	false	"Location"
+//	@Success		200			{array}		OrgNode
+//	@Failure		400			{object}	Problem
+//	@Failure		406			{object}	Problem
+//	@Failure		500			{object}	Problem
+//	@Router			/orgchart [get]
+func (h *Handlers) OrgChart(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFilter(r)
+	if err != nil {
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+	var render func(io.Writer, []OrgNode) error
+	var contentType string
+	c := jsonCodec
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		var ok bool
+		if c, ok = Negotiate(w, r); !ok {
+			return
+		}
+	case "json":
+	case "dot":
+		render, contentType = writeDot, "text/vnd.graphviz; charset=utf-8"
+	case "mermaid":
+		render, contentType = writeMermaid, "text/vnd.mermaid; charset=utf-8"
+	default:
+		Error(w, fmt.Sprintf("unknown format %q, want json, dot or mermaid", format), http.StatusBadRequest)
+		return
+	}
+	roots, err := orgChart(h.dbc, f)
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	if render == nil {
+		Respond(w, c, http.StatusOK, roots)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	if err = render(w, roots); err != nil {
+		log.Printf("Failed to render org chart: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package promo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlers_OrgChart(t *testing.T) {
+	newHandlers := func(t *testing.T) *Handlers {
+		m := NewMemory()
+		for _, u := range []User{
+			{Name: "Ann", Surname: "Boss", Position: senior, Project: "Alpha"},
+			{Name: "Bob", Surname: "Smith", Position: middle, Project: "Alpha", ManagerId: 1},
+			{Name: "Cid", Surname: "Jones", Position: junior, Project: "Beta", ManagerId: 2},
+		} {
+			_, err := m.CreateUser(u, false)
+			require.NoError(t, err)
+		}
+		return &Handlers{m}
+	}
+	t.Run("Check org chart as dot", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/orgchart?format=dot", nil)
+		w := httptest.NewRecorder()
+		h.OrgChart(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/vnd.graphviz; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `digraph orgchart {
+	node [shape=box];
+	u1 [label="Ann Boss\nsenior"];
+	u2 [label="Bob Smith\nmiddle"];
+	u1 -> u2;
+	u3 [label="Cid Jones\njunior"];
+	u2 -> u3;
+}
+`, w.Body.String())
+	})
+	t.Run("Check org chart as mermaid (filtered)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/orgchart?format=mermaid&project=Beta", nil)
+		w := httptest.NewRecorder()
+		h.OrgChart(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "graph TD\n\tu3[\"Cid Jones<br/>junior\"]\n", w.Body.String())
+	})
+	t.Run("Check org chart as json", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/orgchart?project=Alpha", nil)
+		w := httptest.NewRecorder()
+		h.OrgChart(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var roots []OrgNode
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&roots))
+		require.Len(t, roots, 1)
+		assert.Equal(t, 1, roots[0].Id)
+		require.Len(t, roots[0].Reports, 1)
+		assert.Equal(t, 2, roots[0].Reports[0].Id)
+		assert.Empty(t, roots[0].Reports[0].Reports)
+	})
+	t.Run("Check org chart (unknown format)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/orgchart?format=svg", nil)
+		w := httptest.NewRecorder()
+		h.OrgChart(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("Check reports", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/1/reports?depth=1", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.Reports(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `[{"id":2,"name":"Bob","surname":"Smith","position":3,"project":"Alpha","manager_id":1,"depth":1}]`,
+			w.Body.String())
+	})
+	t.Run("Check reports (illegal depth)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/1/reports?depth=0", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.Reports(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+	t.Run("Check chain (absent user)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/42/chain", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "42"})
+		w := httptest.NewRecorder()
+		h.Chain(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestRegistry_Reports(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening mock", err)
+	}
+	defer mock.Close()
+	r := &Registry{mock}
+
+	mock.ExpectQuery("WITH RECURSIVE tree").WithArgs(1, 2).
+		WillReturnRows(pgxmock.NewRows(append(userCols, "depth")).
+			AddRow(1, "Ann", "Boss", "senior", "", "", "", "", "", 0, 0).
+			AddRow(2, "Bob", "Smith", "middle", "", "", "", "2020-01-13", "", 1, 1))
+	reports, err := r.Reports(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Report{{User: User{Id: 2, Name: "Bob", Surname: "Smith", Position: middle, HireDate: "2020-01-13", ManagerId: 1}, Depth: 1}},
+		reports)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
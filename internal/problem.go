@@ -0,0 +1,128 @@
+package promo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// ErrNotSupported is wrapped by the errors of the features a store does not
+// keep.
+var ErrNotSupported = errors.New("not supported by this store")
+
+// Problem is the RFC 7807 body every handler answers with on failure.
+type Problem struct {
+	// Type names the error reported, see ProblemType.
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// ConflictingId is the user a 409 is about.
+	ConflictingId int `json:"conflicting_id,omitempty"`
+	// Errors lists the fields failing validation.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// problemTypes are the types of the Problems reporting the errors clients
+// tell apart, relative URIs as RFC 7807 allows, with the status DBError
+// answers them with. Types never change once published.
+var problemTypes = []struct {
+	err    error
+	typ    string
+	status int
+}{
+	{ErrNotFound, "/problems/user-not-found", http.StatusNotFound},
+	{ErrDuplicate, "/problems/duplicate-user", http.StatusConflict},
+	{ErrInvalidManager, "/problems/invalid-manager", http.StatusUnprocessableEntity},
+	{ErrPreconditionFailed, "/problems/precondition-failed", http.StatusPreconditionFailed},
+	{ErrNotSupported, "/problems/not-supported", http.StatusNotImplemented},
+	{ErrNotReplayed, "/problems/not-replayed", http.StatusConflict},
+	{ErrProjectExists, "/problems/project-exists", http.StatusConflict},
+	{ErrProjectNotFound, "/problems/project-not-found", http.StatusNotFound},
+	{ErrCycleNotFound, "/problems/cycle-not-found", http.StatusNotFound},
+	{ErrReviewNotFound, "/problems/review-not-found", http.StatusNotFound},
+	{ErrCycleState, "/problems/cycle-state", http.StatusConflict},
+	{ErrAssessmentNotFound, "/problems/assessment-not-found", http.StatusNotFound},
+	{ErrAssessmentExists, "/problems/assessment-exists", http.StatusConflict},
+	{ErrAssessmentLocked, "/problems/assessment-locked", http.StatusConflict},
+	{ErrFeedbackNotFound, "/problems/feedback-not-found", http.StatusNotFound},
+	{ErrFeedbackKey, "/problems/feedback-key", http.StatusForbidden},
+	{ErrFeedbackClosed, "/problems/feedback-closed", http.StatusConflict},
+	{ErrResponseNotFound, "/problems/response-not-found", http.StatusNotFound},
+	{ErrCompetencyNotFound, "/problems/competency-not-found", http.StatusNotFound},
+	{ErrCompetencyExists, "/problems/competency-exists", http.StatusConflict},
+	{ErrMentorshipNotFound, "/problems/mentorship-not-found", http.StatusNotFound},
+	{ErrInvalidMentor, "/problems/invalid-mentor", http.StatusUnprocessableEntity},
+	{ErrMentorshipExists, "/problems/mentorship-exists", http.StatusConflict},
+	{ErrMentorshipState, "/problems/mentorship-state", http.StatusConflict},
+	{ErrMentorBusy, "/problems/mentor-busy", http.StatusConflict},
+	{ErrGoalNotFound, "/problems/goal-not-found", http.StatusNotFound},
+	{ErrCertificationNotFound, "/problems/certification-not-found", http.StatusNotFound},
+}
+
+// ProblemType is the type of the Problems reporting err, empty when err is
+// none that clients tell apart.
+func ProblemType(err error) string {
+	for _, pt := range problemTypes {
+		if errors.Is(err, pt.err) {
+			return pt.typ
+		}
+	}
+	return ""
+}
+
+// ProblemError is the error the Problems of type typ report, nil when typ
+// is unknown.
+func ProblemError(typ string) error {
+	for _, pt := range problemTypes {
+		if pt.typ == typ {
+			return pt.err
+		}
+	}
+	return nil
+}
+
+// Error answers status with a Problem telling detail, as http.Error does
+// with plain text.
+func Error(w http.ResponseWriter, detail string, status int) {
+	WriteProblem(w, Problem{Title: http.StatusText(status), Status: status, Detail: detail})
+}
+
+// Fail answers status with a Problem reporting err, of the type clients
+// map back to it.
+func Fail(w http.ResponseWriter, err error, status int) {
+	WriteProblem(w, Problem{Type: ProblemType(err), Title: http.StatusText(status), Status: status,
+		Detail: fmt.Sprintf("%v", err)})
+}
+
+// WriteProblem answers p with its status.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	content, _ := json.Marshal(p)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_, _ = w.Write(content)
+}
+
+// DBError reports a store failure with the status problemTypes give it,
+// 500 for errors clients do not tell apart, which are logged.
+func DBError(w http.ResponseWriter, err error) {
+	var dup *DuplicateError
+	if errors.As(err, &dup) {
+		WriteProblem(w, Problem{Type: ProblemType(err), Title: http.StatusText(http.StatusConflict),
+			Status: http.StatusConflict, Detail: fmt.Sprintf("%v", err), ConflictingId: dup.Id})
+		return
+	}
+	for _, pt := range problemTypes {
+		if errors.Is(err, pt.err) {
+			Fail(w, err, pt.status)
+			return
+		}
+	}
+	log.Println(err)
+	Fail(w, err, http.StatusInternalServerError)
+}
@@ -0,0 +1,34 @@
+package promo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBError(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+		typ    string
+	}{
+		{fmt.Errorf("no goal with id 7: %w", ErrGoalNotFound), http.StatusNotFound, "/problems/goal-not-found"},
+		{fmt.Errorf("unable to get user with id 7: %w", ErrNotFound), http.StatusNotFound, "/problems/user-not-found"},
+		{fmt.Errorf("review cycles are %w", ErrNotSupported), http.StatusNotImplemented, "/problems/not-supported"},
+		{fmt.Errorf("key: %w", ErrFeedbackKey), http.StatusForbidden, "/problems/feedback-key"},
+		{&DuplicateError{Id: 3, Field: "email"}, http.StatusConflict, "/problems/duplicate-user"},
+		{errors.New("connection refused"), http.StatusInternalServerError, ""},
+	} {
+		t.Run(fmt.Sprintf("Check %v", tc.err), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			DBError(w, tc.err)
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, tc.typ, ProblemType(tc.err))
+			assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package promo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidManager is wrapped when a manager is not a live user, or would
+// end up managing themselves, directly or not.
+var ErrInvalidManager = errors.New("invalid manager")
+
+// ErrPreconditionFailed is wrapped when a conditional update finds the user
+// changed since it was read.
+var ErrPreconditionFailed = errors.New("user changed since it was read")
+
+// userColumns are read, in this order, by userFields. Missing optional
+// fields read as zero values.
+const userColumns = "id, name, surname, position, COALESCE(project, ''), COALESCE(email, ''), " +
+	"COALESCE(employee_number, ''), COALESCE(CAST(hire_date AS TEXT), ''), COALESCE(location, ''), COALESCE(manager_id, 0)"
+
+// userFields lists where the userColumns of a row go, the position going to
+// pos first.
+func userFields(u *User, pos *string) []any {
+	return []any{&u.Id, &u.Name, &u.Surname, pos, &u.Project, &u.Email, &u.EmployeeNumber, &u.HireDate, &u.Location, &u.ManagerId}
+}
+
+// userMatch are the columns of a row that userValues compares, in this
+// order, for conditional updates. They read as the userColumns do.
+var userMatch = []string{"name", "surname", "CAST(position AS TEXT)", "COALESCE(project, '')", "COALESCE(email, '')",
+	"COALESCE(employee_number, '')", "COALESCE(CAST(hire_date AS TEXT), '')", "COALESCE(location, '')",
+	"COALESCE(manager_id, 0)"}
+
+// userValues are the fields of u the userMatch columns must hold.
+func userValues(u User) []any {
+	return []any{u.Name, u.Surname, dGrades[u.Position], u.Project, u.Email, u.EmployeeNumber, u.HireDate, u.Location,
+		u.ManagerId}
+}
+
+// UserETag is the entity tag of live user u, which GET /get/{id} answers
+// with and PATCH /update/{id} takes in If-Match.
+func UserETag(u User) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d", u.Id)
+	for _, v := range userValues(u) {
+		fmt.Fprintf(h, "\x00%v", v)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// profileColumns can be changed by UpdateUser besides the name, surname,
+// position and project.
+var profileColumns = []string{"email", "employee_number", "hire_date", "location", "manager_id"}
+
+// columnCasts type the UpdateUser arguments Postgres cannot infer.
+var columnCasts = map[string]string{"position": "::grade", "hire_date": "::date"}
+
+// columnArg is the argument setting column to v, v coming from the column
+// map UpdateUser takes. Empty optional columns are set to NULL.
+func columnArg(column, v string) any {
+	switch column {
+	case "name", "surname", "position":
+		return v
+	case "manager_id":
+		id, _ := strconv.Atoi(v)
+		return nullableId(id)
+	}
+	return nullable(v)
+}
+
+// managerCheck tells, for a manager $1 and a user $2, whether the manager is
+// live and whether the user is among the managers above them (themselves
+// included). UNION stops at cycles already in the table.
+const managerCheck = `WITH RECURSIVE chain(id, manager_id) AS (
+	SELECT id, manager_id FROM usr WHERE id=$1
+	UNION
+	SELECT u.id, u.manager_id FROM usr u JOIN chain c ON u.id=c.manager_id
+)
+SELECT EXISTS (SELECT 1 FROM usr WHERE id=$1 AND deleted_at IS NULL), EXISTS (SELECT 1 FROM chain WHERE id=$2)`
+
+// managesSelf tells whether user $1 is among the managers above them.
+const managesSelf = `WITH RECURSIVE chain(id, manager_id) AS (
+	SELECT id, manager_id FROM usr WHERE id=$1
+	UNION
+	SELECT u.id, u.manager_id FROM usr u JOIN chain c ON u.id=c.manager_id
+)
+SELECT EXISTS (SELECT 1 FROM chain WHERE manager_id=$1)`
+
+// keyConflict finds a user other than $3 holding the email $1 or the
+// employee number $2.
+const keyConflict = `SELECT id, CASE WHEN email=$1 THEN 'email' ELSE 'employee_number' END
+FROM usr WHERE (email=$1 OR employee_number=$2) AND id<>$3 ORDER BY id LIMIT 1`
+
+// managerError explains the outcome of managerCheck, nil when manager can
+// manage id.
+func managerError(manager, id int, live, cycle bool) error {
+	if !live {
+		return fmt.Errorf("manager %d is not a user: %w", manager, ErrInvalidManager)
+	}
+	if cycle {
+		return fmt.Errorf("user %d would manage themselves through %d: %w", id, manager, ErrInvalidManager)
+	}
+	return nil
+}
+
+// normalizeProfile trims the optional fields of u and lowers its email.
+func normalizeProfile(u *User) {
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+	u.EmployeeNumber = strings.TrimSpace(u.EmployeeNumber)
+	u.HireDate = strings.TrimSpace(u.HireDate)
+	u.Location = strings.TrimSpace(u.Location)
+}
+
+// matchProfile tells whether u passes the profile fields of f, for stores
+// that filter in Go.
+func (f UserFilter) matchProfile(u User) bool {
+	switch {
+	case f.Email != "" && u.Email != f.Email,
+		f.EmployeeNumber != "" && u.EmployeeNumber != f.EmployeeNumber,
+		f.Location != "" && u.Location != f.Location,
+		f.ManagerId != 0 && u.ManagerId != f.ManagerId,
+		f.HiredFrom != "" && (u.HireDate == "" || u.HireDate < f.HiredFrom),
+		f.HiredTo != "" && (u.HireDate == "" || u.HireDate > f.HiredTo):
+		return false
+	}
+	return true
+}
+
+// decodePatch is DecodeBody for partial updates, also telling which fields
+// the body holds, null ones included.
+func decodePatch(r *http.Request, v any) (map[string]bool, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	if err = DecodeBody(r, v); err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	if err = DecodeBody(r, &fields); err != nil {
+		return nil, err
+	}
+	present := make(map[string]bool, len(fields))
+	for k := range fields {
+		present[k] = true
+	}
+	return present, nil
+}
+
+// profileMap adds the optional fields of u that the body held to the column
+// map UpdateUser takes, null ones as empty values clearing them.
+func profileMap(u User, present map[string]bool, m map[string]string) {
+	for k, v := range map[string]string{
+		"email":           u.Email,
+		"employee_number": u.EmployeeNumber,
+		"hire_date":       u.HireDate,
+		"location":        u.Location,
+	} {
+		if present[k] {
+			m[k] = v
+		}
+	}
+	if present["manager_id"] {
+		m["manager_id"] = ""
+		if u.ManagerId != 0 {
+			m["manager_id"] = strconv.Itoa(u.ManagerId)
+		}
+	}
+}
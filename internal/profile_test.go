@@ -0,0 +1,139 @@
+package promo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProfile(t *testing.T) {
+	for _, c := range []struct {
+		u     User
+		valid bool
+	}{
+		{User{}, true},
+		{User{Email: "ann@example.com", EmployeeNumber: "E-001", HireDate: "2020-02-29", Location: "Minsk", ManagerId: 2}, true},
+		{User{Email: "ann"}, false},
+		{User{Email: "Ann <ann@example.com>"}, false},
+		{User{EmployeeNumber: "E 001"}, false},
+		{User{EmployeeNumber: strings.Repeat("1", 33)}, false},
+		{User{HireDate: "2021-02-29"}, false},
+		{User{HireDate: "01/02/2020"}, false},
+		{User{Location: strings.Repeat("ł", 101)}, false},
+		{User{ManagerId: -1}, false},
+		{User{Id: 3, ManagerId: 3}, false},
+	} {
+		err := Validator{}.Validate(c.u, true)
+		assert.Equal(t, c.valid, err == nil, "%+v: %v", c.u, err)
+	}
+}
+
+func TestHandlers_Profile(t *testing.T) {
+	newHandlers := func(t *testing.T) *Handlers {
+		m := NewMemory()
+		_, err := m.CreateUser(User{Name: "Ann", Surname: "Boss", Position: senior, Email: "ann@example.com"}, false)
+		require.NoError(t, err)
+		_, err = m.CreateUser(User{Name: "Bob", Surname: "Smith", Position: junior, ManagerId: 1, HireDate: "2022-07-15"}, false)
+		require.NoError(t, err)
+		return &Handlers{m}
+	}
+	t.Run("Check creating user with profile", func(t *testing.T) {
+		h := newHandlers(t)
+		body := `{"name":"Cid","surname":"Jones","position":2,"email":" Cid@Example.com ","hire_date":"2023-01-02","manager_id":1}`
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"id":3,"name":"Cid","surname":"Jones","position":2,"project":"","email":"cid@example.com","hire_date":"2023-01-02","manager_id":1}`,
+			w.Body.String())
+	})
+	t.Run("Check creating user with profile (invalid email)", func(t *testing.T) {
+		h := newHandlers(t)
+		body := `{"name":"Cid","surname":"Jones","position":2,"email":"cid"}`
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+	t.Run("Check creating user with profile (unknown manager)", func(t *testing.T) {
+		h := newHandlers(t)
+		body := `{"name":"Cid","surname":"Jones","position":2,"manager_id":42}`
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+	t.Run("Check updating manager (cycle)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPatch, "/update/1", strings.NewReader(`{"manager_id":2}`))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+	t.Run("Check updating manager (self)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPatch, "/update/1", strings.NewReader(`{"manager_id":1}`))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+	t.Run("Check updating profile (null clears)", func(t *testing.T) {
+		h := newHandlers(t)
+		body := `{"manager_id":null,"hire_date":null,"email":"bob@example.com"}`
+		req := httptest.NewRequest(http.MethodPatch, "/update/2", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": "2"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		u, err := h.dbc.GetUser(2)
+		require.NoError(t, err)
+		assert.Equal(t, User{Id: 2, Name: "Bob", Surname: "Smith", Position: junior, Email: "bob@example.com"}, *u)
+
+		req = httptest.NewRequest(http.MethodPatch, "/update/1", strings.NewReader("email: null\n"))
+		req.Header.Set("Content-Type", "application/yaml")
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w = httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		u, err = h.dbc.GetUser(1)
+		require.NoError(t, err)
+		assert.Empty(t, u.Email)
+	})
+	t.Run("Check updating email (taken)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPatch, "/update/2", strings.NewReader(`{"email":"ANN@example.com"}`))
+		req = mux.SetURLVars(req, map[string]string{"id": "2"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		var p Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, 1, p.ConflictingId)
+	})
+	t.Run("Check getting user list (profile filters)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/getall?manager_id=1&hired_from=2022-01-01&hired_to=2022-12-31", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var us []User
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&us))
+		require.Len(t, us, 1)
+		assert.Equal(t, 2, us[0].Id)
+	})
+	t.Run("Check getting user list (illegal hire date)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/getall?hired_from=2022", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,114 @@
+package promo
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrProjectExists is returned when adding a project twice.
+	ErrProjectExists = errors.New("project already exists")
+	// ErrProjectNotFound is returned for a project that is not known.
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+// Project is a project users can be put on.
+type Project struct {
+	Name string `json:"name" yaml:"name" xml:"name" validate:"required,max=100"`
+}
+
+// ProjectStore keeps the projects users may reference. Against stores that
+// do not implement it any project is accepted.
+type ProjectStore interface {
+	// Projects lists the project names in order.
+	Projects() ([]string, error)
+	// AddProject adds a project, ErrProjectExists if it is known already.
+	AddProject(name string) error
+	// ProjectExists tells whether name is a known project.
+	ProjectExists(name string) (bool, error)
+}
+
+// Project queries shared by Registry and SQLite.
+const (
+	projectsQuery      = "SELECT name FROM project ORDER BY name"
+	addProjectQuery    = "INSERT INTO project (name) VALUES ($1) ON CONFLICT DO NOTHING"
+	projectExistsQuery = "SELECT EXISTS (SELECT 1 FROM project WHERE name=$1)"
+)
+
+// GetProjects	 godoc
+//
+//	@Summary		List projects
+//	@Description	list the projects users can be put on
+//	@Tags			projects
+//	@Produce		json,application/yaml
+//	@Success		200	{array}		Project
+//	@Failure		406	{object}	Problem
+//	@Failure		500	{object}	Problem
+//	@Failure		501	{object}	Problem
+//	@Router			/projects [get]
+func (h *Handlers) GetProjects(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.dbc.(ProjectStore)
+	if !ok {
+		Error(w, "projects are not supported by this store", http.StatusNotImplemented)
+		return
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	names, err := ps.Projects()
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	projects := make([]Project, len(names))
+	for i, n := range names {
+		projects[i].Name = n
+	}
+	Respond(w, c, http.StatusOK, projects)
+}
+
+// CreateProject	 godoc
+//
+//	@Summary		Create project
+//	@Description	add a project users can be put on
+//	@Tags			projects
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201	{object}	Project
+//	@Failure		400	{object}	Problem
+//	@Failure		406	{object}	Problem
+//	@Failure		409	{object}	Problem
+//	@Failure		415	{object}	Problem
+//	@Failure		422	{object}	Problem
+//	@Failure		500	{object}	Problem
+//	@Failure		501	{object}	Problem
+//	@Router			/projects [post]
+func (h *Handlers) CreateProject(w http.ResponseWriter, r *http.Request) {
+	ps, ok := h.dbc.(ProjectStore)
+	if !ok {
+		Error(w, "projects are not supported by this store", http.StatusNotImplemented)
+		return
+	}
+	var p Project
+	if err := DecodeBody(r, &p); err != nil {
+		BodyError(w, err)
+		return
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if err := NewValidator(h.dbc).Validate(p, false); err != nil {
+		Invalid(w, err)
+		return
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	if err := ps.AddProject(p.Name); err != nil {
+		DBError(w, err)
+		return
+	}
+	Respond(w, c, http.StatusCreated, p)
+}
@@ -0,0 +1,106 @@
+package promo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectProject expects the validator to look project up.
+func expectProject(mock pgxmock.PgxPoolIface, project string, exists bool) {
+	mock.ExpectQuery(regexp.QuoteMeta(projectExistsQuery)).WithArgs(project).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(exists))
+}
+
+func TestHandlers_Projects(t *testing.T) {
+	newHandlers := func(t *testing.T) *Handlers {
+		m := NewMemory()
+		require.NoError(t, m.AddProject("Alpha"))
+		return &Handlers{m}
+	}
+	t.Run("Check creating project", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(`{"name":" Beta "}`))
+		w := httptest.NewRecorder()
+		h.CreateProject(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, `{"name":"Beta"}`, w.Body.String())
+
+		req = httptest.NewRequest(http.MethodGet, "/projects", nil)
+		w = httptest.NewRecorder()
+		h.GetProjects(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `[{"name":"Alpha"},{"name":"Beta"}]`, w.Body.String())
+	})
+	t.Run("Check creating project (duplicate)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(`{"name":"Alpha"}`))
+		w := httptest.NewRecorder()
+		h.CreateProject(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+	t.Run("Check creating project (field errors)", func(t *testing.T) {
+		h := newHandlers(t)
+		body := fmt.Sprintf(`{"name":%q}`, strings.Repeat("p", 101))
+		req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateProject(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []FieldError{{Field: "name", Rule: RuleTooLong, Detail: "is longer than 100 characters"}}, p.Errors)
+	})
+	t.Run("Check creating user (unknown project)", func(t *testing.T) {
+		h := newHandlers(t)
+		body := `{"name":"Ann","surname":"Boss","position":2,"project":"Gamma"}`
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []FieldError{{Field: "project", Rule: RuleReference, Detail: "is not an existing project"}}, p.Errors)
+	})
+	t.Run("Check creating user (project lookup error)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		h := &Handlers{&Registry{mock}}
+
+		mock.ExpectQuery(regexp.QuoteMeta(projectExistsQuery)).WithArgs("Alpha").WillReturnError(fmt.Errorf("db down"))
+		body := `{"name":"Ann","surname":"Boss","position":2,"project":"Alpha"}`
+		req := httptest.NewRequest(http.MethodPost, "/create", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestValidator(t *testing.T) {
+	v := Validator{refs: map[string]func(string) (bool, error){
+		"project": func(name string) (bool, error) { return name == "Alpha", nil },
+	}}
+	err := v.Validate(User{Name: "Ann", Position: 9, Project: "Beta", EmployeeNumber: "E 1", ManagerId: -2}, false)
+	var errs ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	got := make([]string, len(errs))
+	for i, fe := range errs {
+		got[i] = fe.Field + " " + fe.Rule
+	}
+	assert.Equal(t, []string{"surname required", "position enum", "project reference", "employee_number pattern",
+		"manager_id too_small"}, got)
+
+	assert.NoError(t, v.Validate(User{Surname: "Boss", Project: "Alpha"}, true), "partial users skip missing fields")
+	assert.NoError(t, v.Validate(&Project{Name: "Beta"}, false), "pointers are followed")
+}
@@ -0,0 +1,57 @@
+// Package promotest holds the fixtures the tests of the subsystems share,
+// the way net/http/httptest does for handlers.
+package promotest
+
+import (
+	promo "AndersenPromo/internal"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+// NewStore is a memory store with users, given ids in order, and the
+// projects they are on.
+func NewStore(t testing.TB, users ...promo.User) *promo.Memory {
+	t.Helper()
+	m := promo.NewMemory()
+	for _, u := range users {
+		if u.Project == "" {
+			continue
+		}
+		ok, err := m.ProjectExists(u.Project)
+		require.NoError(t, err)
+		if !ok {
+			require.NoError(t, m.AddProject(u.Project))
+		}
+	}
+	require.NoError(t, m.AddUsers(users))
+	return m
+}
+
+// Registerer is a subsystem's handlers.
+type Registerer interface {
+	Register(router *mux.Router)
+}
+
+// NewRouter is a router serving h.
+func NewRouter(h Registerer) *mux.Router {
+	router := mux.NewRouter()
+	h.Register(router)
+	return router
+}
+
+// Serve has h answer a request with body and header, pairs of names and
+// values.
+func Serve(h http.Handler, method, url, body string, header ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
@@ -0,0 +1,39 @@
+package promo
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PurgeLoop removes for good, every interval until ctx is done, the users
+// deleted more than retention ago (none when it is 0) and the expired
+// idempotency keys.
+func PurgeLoop(ctx context.Context, dbc DBConnexion, retention, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		purge(dbc, retention, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func purge(dbc DBConnexion, retention time.Duration, now time.Time) {
+	if retention > 0 {
+		n, err := dbc.PurgeDeleted(now.Add(-retention))
+		if err != nil {
+			log.Printf("Failed to purge deleted users: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d users deleted more than %v ago", n, retention)
+		}
+	}
+	if is, ok := dbc.(IdempotencyStore); ok {
+		if _, err := is.PurgeIdempotent(now); err != nil {
+			log.Printf("Failed to purge expired idempotency keys: %v", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package promo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	t.Run("Check purging users past retention", func(t *testing.T) {
+		m := NewMemory()
+		require.NoError(t, m.AddUser("And", "Ersen", middle, "Alpha"))
+		require.NoError(t, m.AddUser("Bob", "Smith", junior, "Beta"))
+		require.NoError(t, m.DeleteUser(1))
+
+		purge(m, time.Hour, time.Now())
+		assert.NoError(t, m.RestoreUser(1))
+
+		require.NoError(t, m.DeleteUser(1))
+		purge(m, time.Hour, time.Now().Add(2*time.Hour))
+		assert.ErrorIs(t, m.RestoreUser(1), ErrNotFound)
+		_, err := m.GetUser(2)
+		assert.NoError(t, err)
+	})
+}
@@ -2,20 +2,160 @@ package promo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/exp/slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+var ErrNotFound = errors.New("user not found")
+
+// ErrDuplicate is wrapped by every DuplicateError.
+var ErrDuplicate = errors.New("duplicate user")
+
+// DuplicateError tells which user a new one would duplicate, and on which
+// field: "name", "email" or "employee_number".
+type DuplicateError struct {
+	Id    int
+	Field string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("user %d has the same %s", e.Id, strings.ReplaceAll(e.Field, "_", " "))
+}
+
+func (e *DuplicateError) Unwrap() error {
+	return ErrDuplicate
+}
+
 type DBConnexion interface {
 	AddUser(string, string, Grade, string) error
 	DeleteUser(int) error
 	UpdateUser(int, map[string]string) error
 	GetUser(int) (*User, error)
-	GetAllUsers() (*[]User, error)
+	GetAllUsers(UserFilter) (*[]User, error)
+	AddUsers([]User) error
+	EachUser(UserFilter, func(User) error) error
+	RestoreUser(int) error
+	PurgeDeleted(time.Time) (int, error)
+	CreateUser(User, bool) (int, error)
+	MergeUsers(int, int) error
+	Reports(int, int) ([]Report, error)
+	Chain(int) ([]Report, error)
+}
+
+// ContextDBConnexion is DBConnexion with request-scoped contexts. WithContext
+// adapts a local store to it and client.Client implements it over HTTP, so
+// callers can swap one for the other.
+type ContextDBConnexion interface {
+	AddUser(context.Context, string, string, Grade, string) error
+	DeleteUser(context.Context, int) error
+	UpdateUser(context.Context, int, map[string]string) error
+	GetUser(context.Context, int) (*User, error)
+	GetAllUsers(context.Context, UserFilter) (*[]User, error)
+	AddUsers(context.Context, []User) error
+	EachUser(context.Context, UserFilter, func(User) error) error
+	RestoreUser(context.Context, int) error
+	CreateUser(context.Context, User, bool) (int, error)
+	MergeUsers(context.Context, int, int) error
+	Reports(context.Context, int, int) ([]Report, error)
+	Chain(context.Context, int) ([]Report, error)
+}
+
+// WithContext wraps dbc so it satisfies ContextDBConnexion. The context is
+// only checked before each call, the underlying store is not interrupted.
+func WithContext(dbc DBConnexion) ContextDBConnexion {
+	return ctxConnexion{dbc}
+}
+
+type ctxConnexion struct {
+	dbc DBConnexion
+}
+
+func (c ctxConnexion) AddUser(ctx context.Context, name string, surname string, position Grade, project string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.dbc.AddUser(name, surname, position, project)
+}
+
+func (c ctxConnexion) DeleteUser(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.dbc.DeleteUser(id)
+}
+
+func (c ctxConnexion) UpdateUser(ctx context.Context, id int, m map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.dbc.UpdateUser(id, m)
+}
+
+func (c ctxConnexion) GetUser(ctx context.Context, id int) (*User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.dbc.GetUser(id)
+}
+
+func (c ctxConnexion) GetAllUsers(ctx context.Context, f UserFilter) (*[]User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.dbc.GetAllUsers(f)
+}
+
+func (c ctxConnexion) AddUsers(ctx context.Context, us []User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.dbc.AddUsers(us)
+}
+
+func (c ctxConnexion) EachUser(ctx context.Context, f UserFilter, fn func(User) error) error {
+	return eachUser(ctx, c.dbc, f, fn)
+}
+
+func (c ctxConnexion) RestoreUser(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.dbc.RestoreUser(id)
+}
+
+func (c ctxConnexion) CreateUser(ctx context.Context, u User, force bool) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.dbc.CreateUser(u, force)
+}
+
+func (c ctxConnexion) MergeUsers(ctx context.Context, into, from int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.dbc.MergeUsers(into, from)
+}
+
+func (c ctxConnexion) Reports(ctx context.Context, id, depth int) ([]Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.dbc.Reports(id, depth)
+}
+
+func (c ctxConnexion) Chain(ctx context.Context, id int) ([]Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.dbc.Chain(id)
 }
 
 type Registry struct {
@@ -24,13 +164,27 @@ type Registry struct {
 
 type pool interface {
 	Ping(context.Context) error
+	Begin(context.Context) (pgx.Tx, error)
 	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
 	Query(context.Context, string, ...any) (pgx.Rows, error)
 	QueryRow(context.Context, string, ...any) pgx.Row
 }
 
 func initDB(connString string) (p pool, err error) {
-	if p, err = pgxpool.New(context.Background(), connString); err != nil {
+	cfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string %s: %w", connString, err)
+	}
+	// COPY encodes in binary and needs to know the grade enum.
+	cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		t, err := conn.LoadType(ctx, "grade")
+		if err != nil {
+			return fmt.Errorf("failed to load type grade: %w", err)
+		}
+		conn.TypeMap().RegisterType(t)
+		return nil
+	}
+	if p, err = pgxpool.NewWithConfig(context.Background(), cfg); err != nil {
 		return nil, fmt.Errorf("failed to create pool connection to database %s: %w", connString, err)
 	}
 	return p, nil
@@ -57,69 +211,1605 @@ func (r *Registry) AddUser(name string, surname string, position Grade, project
 	return nil
 }
 
+// nullable stores empty optional fields as NULL, which unique indexes
+// ignore.
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func nullableId(id int) *int {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+// CreateUser adds u unless it duplicates a user: one with the same email or
+// employee number, or, unless force, a live one with the same name. It
+// returns the id of the new user or a *DuplicateError.
+func (r *Registry) CreateUser(u User, force bool) (int, error) {
+	const req = `WITH dup AS (
+	SELECT id, CASE WHEN email=$5 THEN 'email' WHEN employee_number=$6 THEN 'employee_number' ELSE 'name' END AS field
+	FROM usr
+	WHERE email=$5 OR employee_number=$6
+		OR (NOT $7 AND deleted_at IS NULL AND lower(name)=lower($1) AND lower(surname)=lower($2))
+	ORDER BY id LIMIT 1
+), ins AS (
+	INSERT INTO usr (name, surname, position, project, email, employee_number, hire_date, location, manager_id)
+	SELECT $1, $2, $3::grade, $4, $5, $6, $8::date, $9, $10 WHERE NOT EXISTS (SELECT 1 FROM dup)
+	RETURNING id
+)
+SELECT id, '' FROM ins UNION ALL SELECT id, field FROM dup`
+	ctx := context.Background()
+	if u.ManagerId != 0 {
+		var live, cycle bool
+		if err := r.p.QueryRow(ctx, managerCheck, u.ManagerId, 0).Scan(&live, &cycle); err != nil {
+			return 0, fmt.Errorf("unable to check manager %d: %w", u.ManagerId, err)
+		}
+		if err := managerError(u.ManagerId, 0, live, cycle); err != nil {
+			return 0, err
+		}
+	}
+	// A concurrent insert of the same keys fails the unique indexes, the
+	// second attempt then sees it as a duplicate.
+	for attempt := 0; ; attempt++ {
+		var id int
+		var field string
+		err := r.p.QueryRow(ctx, req,
+			u.Name, u.Surname, dGrades[u.Position], u.Project, nullable(u.Email), nullable(u.EmployeeNumber), force,
+			nullable(u.HireDate), nullable(u.Location), nullableId(u.ManagerId)).
+			Scan(&id, &field)
+		var pgErr *pgconn.PgError
+		if attempt == 0 && errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO usr: %w", err)
+		}
+		if field != "" {
+			return 0, &DuplicateError{Id: id, Field: field}
+		}
+		return id, nil
+	}
+}
+
+// moveHistoryQuery hands the versions of user $2 from before the first of
+// user $1 over to $1, ending the last one where the history of $1 begins.
+const moveHistoryQuery = `UPDATE usr_history SET id=$1,
+valid_to=CASE WHEN valid_to IS NULL OR valid_to>f.first THEN f.first ELSE valid_to END
+FROM (SELECT MIN(valid_from) AS first FROM usr_history WHERE id=$1) f
+WHERE usr_history.id=$2 AND usr_history.valid_from<f.first`
+
+// MergeUsers folds user from into user into: into keeps its fields and takes
+// those it lacks from from, whatever refers to from is moved to into along
+// with the versions of from older than into, and from is deleted.
+func (r *Registry) MergeUsers(into, from int) (err error) {
+	if into == from {
+		return fmt.Errorf("unable to merge user %d into itself", into)
+	}
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Unique keys have to leave from before into can take them.
+	var project string
+	var email, number, hired, location *string
+	var manager *int
+	err = tx.QueryRow(ctx, `UPDATE usr u SET email=NULL, employee_number=NULL, deleted_at=now()
+FROM usr old WHERE u.id=old.id AND u.id=$1 AND u.deleted_at IS NULL
+RETURNING COALESCE(old.project, ''), old.email, old.employee_number, CAST(old.hire_date AS TEXT), old.location, old.manager_id`,
+		from).Scan(&project, &email, &number, &hired, &location, &manager)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("unable to merge user %d: %w", from, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to merge user %d: %w", from, err)
+	}
+	if manager != nil && *manager == into {
+		manager = nil
+	}
+	rp, err := tx.Exec(ctx, `UPDATE usr SET project=CASE WHEN COALESCE(project, '')='' THEN $2 ELSE project END,
+email=COALESCE(email, $3), employee_number=COALESCE(employee_number, $4), hire_date=COALESCE(hire_date, $5::date),
+location=COALESCE(location, $6), manager_id=COALESCE(manager_id, $7)
+WHERE id=$1 AND deleted_at IS NULL`, into, project, email, number, hired, location, manager)
+	if err != nil {
+		return fmt.Errorf("unable to merge into user %d: %w", into, err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("unable to merge into user %d: %w", into, ErrNotFound)
+	}
+	// The reports of from move to into, which reported to from takes from's
+	// manager.
+	_, err = tx.Exec(ctx, "UPDATE usr SET manager_id=CASE WHEN id=$1 THEN $3 ELSE $1 END WHERE manager_id=$2",
+		into, from, manager)
+	if err != nil {
+		return fmt.Errorf("unable to move the reports of user %d: %w", from, err)
+	}
+	var cycle bool
+	if err = tx.QueryRow(ctx, managesSelf, into).Scan(&cycle); err != nil {
+		return fmt.Errorf("unable to check the managers of user %d: %w", into, err)
+	}
+	if cycle {
+		return fmt.Errorf("user %d would manage themselves once merged: %w", into, ErrInvalidManager)
+	}
+	if _, err = tx.Exec(ctx, moveHistoryQuery, into, from); err != nil {
+		return fmt.Errorf("unable to move the history of user %d: %w", from, err)
+	}
+	for _, ref := range userRefs {
+		for _, q := range ref.mergeQueries() {
+			if _, err = tx.Exec(ctx, q, into, from); err != nil {
+				return fmt.Errorf("unable to move %s.%s to user %d: %w", ref.table, ref.column, into, err)
+			}
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit merge: %w", err)
+	}
+	return nil
+}
+
+// Reports lists the users at most depth levels under id, nearest first.
+func (r *Registry) Reports(id, depth int) ([]Report, error) {
+	return r.walkOrg(reportsQuery, id, depth)
+}
+
+// Chain lists the managers of id up to the top, direct manager first.
+func (r *Registry) Chain(id int) ([]Report, error) {
+	return r.walkOrg(chainQuery, id, maxOrgDepth)
+}
+
+// walkOrg runs reportsQuery or chainQuery, whose first row is user id itself.
+func (r *Registry) walkOrg(req string, id, depth int) ([]Report, error) {
+	rows, err := r.p.Query(context.Background(), req, id, depth)
+	if err != nil {
+		return nil, fmt.Errorf("unable to walk the hierarchy from user %d: %w", id, err)
+	}
+	var rep Report
+	var pos string
+	var out []Report
+	_, err = pgx.ForEachRow(rows, append(userFields(&rep.User, &pos), &rep.Depth), func() error {
+		rep.Position = bGrades[pos]
+		out = append(out, rep)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to walk the hierarchy from user %d: %w", id, err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
+	return out[1:], nil
+}
+
+// Search lists the live users matching q, see SearchStore. The typo
+// threshold only holds for the transaction.
+func (r *Registry) Search(q string, limit int) ([]SearchResult, error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)",
+		strconv.FormatFloat(searchThreshold, 'f', -1, 64))
+	if err != nil {
+		return nil, fmt.Errorf("unable to set search threshold: %w", err)
+	}
+	rows, err := tx.Query(ctx, searchQuery, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search users: %w", err)
+	}
+	var res SearchResult
+	var pos string
+	out := make([]SearchResult, 0)
+	_, err = pgx.ForEachRow(rows, append(userFields(&res.User, &pos), &res.Rank), func() error {
+		res.Position = bGrades[pos]
+		out = append(out, res)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to search users: %w", err)
+	}
+	return out, nil
+}
+
+// AddUsers inserts all of us or none of them. Unlike CreateUser it does not
+// look for duplicates, ImportUsers does beforehand; users breaking a unique
+// index fail it with ErrDuplicate.
+func (r *Registry) AddUsers(us []User) (err error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"usr"}, append([]string{"name", "surname", "position", "project"}, profileColumns...),
+		pgx.CopyFromSlice(len(us), func(i int) ([]any, error) {
+			u := us[i]
+			// COPY sends binary values, a date has to be one.
+			var hired *time.Time
+			if u.HireDate != "" {
+				t, err := time.Parse(DateLayout, u.HireDate)
+				if err != nil {
+					return nil, fmt.Errorf("illegal hire_date %q", u.HireDate)
+				}
+				hired = &t
+			}
+			return []any{u.Name, u.Surname, dGrades[u.Position], u.Project,
+				nullable(u.Email), nullable(u.EmployeeNumber), hired, nullable(u.Location), nullableId(u.ManagerId)}, nil
+		}))
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		// A user added since the rows were checked has the same keys.
+		return fmt.Errorf("unable to COPY users INTO usr: %w: %v", ErrDuplicate, err)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to COPY users INTO usr: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit users: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser only marks the user deleted, see RestoreUser and PurgeDeleted.
 func (r *Registry) DeleteUser(id int) (err error) {
 	rp, err := r.p.Exec(context.Background(),
-		"DELETE FROM usr WHERE id=$1", id)
+		"UPDATE usr SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
 	if err != nil {
-		return fmt.Errorf("unable to DELETE FROM usr: %w", err)
+		return fmt.Errorf("unable to soft delete user: %w", err)
 	}
 	if rp.RowsAffected() == 0 {
-		return fmt.Errorf("no rows affected when attempt to DELETE FROM usr with id: %v", id)
+		return fmt.Errorf("no rows affected when attempt to delete user with id %v: %w", id, ErrNotFound)
 	}
 	return nil
 }
 
-func (r *Registry) UpdateUser(id int, m map[string]string) (err error) {
-	fields := []string{"name", "surname", "position", "project"}
-	var s []string
-	for k, v := range m {
+func (r *Registry) RestoreUser(id int) (err error) {
+	rp, err := r.p.Exec(context.Background(),
+		"UPDATE usr SET deleted_at=NULL WHERE id=$1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("unable to restore user: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no deleted user to restore with id %v: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// PurgeDeleted removes for good the users deleted before t.
+func (r *Registry) PurgeDeleted(t time.Time) (int, error) {
+	rp, err := r.p.Exec(context.Background(), "DELETE FROM usr WHERE deleted_at < $1", t)
+	if err != nil {
+		return 0, fmt.Errorf("unable to DELETE FROM usr: %w", err)
+	}
+	return int(rp.RowsAffected()), nil
+}
+
+// UpdateUser sets the columns of user id that m holds, an empty value
+// clearing an optional column.
+func (r *Registry) UpdateUser(id int, m map[string]string) error {
+	return r.updateUser(id, nil, m)
+}
+
+// UpdateUserIf is UpdateUser while user id still is was, see
+// ConditionalUpdater.
+func (r *Registry) UpdateUserIf(id int, was User, m map[string]string) error {
+	return r.updateUser(id, &was, m)
+}
+
+// updateUser is UpdateUser, only updating user id while it is was when
+// was is set.
+func (r *Registry) updateUser(id int, was *User, m map[string]string) (err error) {
+	fields := append([]string{"name", "surname", "position", "project"}, profileColumns...)
+	for k := range m {
 		if !slices.Contains(fields, k) {
 			return fmt.Errorf("illegal key in the map")
 		}
-		s = append(s, fmt.Sprintf("%s='%s'", k, v))
 	}
-	req := fmt.Sprintf("UPDATE usr SET %s WHERE id=$1", strings.Join(s, ","))
-	rp, err := r.p.Exec(context.Background(), req, id)
+	var s []string
+	args := []any{id}
+	for _, k := range fields {
+		if v, ok := m[k]; ok {
+			args = append(args, columnArg(k, v))
+			s = append(s, fmt.Sprintf("%s=$%d%s", k, len(args), columnCasts[k]))
+		}
+	}
+	ctx := context.Background()
+	if m["email"] != "" || m["employee_number"] != "" {
+		var other int
+		var field string
+		err = r.p.QueryRow(ctx, keyConflict, nullable(m["email"]), nullable(m["employee_number"]), id).Scan(&other, &field)
+		if err == nil {
+			return &DuplicateError{Id: other, Field: field}
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("unable to look for duplicates: %w", err)
+		}
+	}
+	if v := m["manager_id"]; v != "" {
+		manager, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("illegal manager_id %q", v)
+		}
+		var live, cycle bool
+		if err = r.p.QueryRow(ctx, managerCheck, manager, id).Scan(&live, &cycle); err != nil {
+			return fmt.Errorf("unable to check manager %d: %w", manager, err)
+		}
+		if err = managerError(manager, id, live, cycle); err != nil {
+			return err
+		}
+	}
+	req := fmt.Sprintf("UPDATE usr SET %s WHERE id=$1 AND deleted_at IS NULL", strings.Join(s, ","))
+	if was != nil {
+		for i, v := range userValues(*was) {
+			args = append(args, v)
+			req += fmt.Sprintf(" AND %s=$%d", userMatch[i], len(args))
+		}
+	}
+	rp, err := r.p.Exec(ctx, req, args...)
 	if err != nil {
 		return fmt.Errorf("unable to UPDATE usr: %w", err)
 	}
+	if rp.RowsAffected() == 0 && was != nil {
+		return fmt.Errorf("user %d changed or was deleted meanwhile: %w", id, ErrPreconditionFailed)
+	}
 	if rp.RowsAffected() == 0 {
-		return fmt.Errorf("no rows affected while attempting to UPDATE usr with id: %v", id)
+		return fmt.Errorf("no rows affected while attempting to UPDATE usr with id %v: %w", id, ErrNotFound)
 	}
 	return nil
 }
 
 func (r *Registry) GetUser(id int) (*User, error) {
-	row := r.p.QueryRow(context.Background(), "SELECT name, surname, position, project FROM usr WHERE id=$1", id)
+	row := r.p.QueryRow(context.Background(), "SELECT "+userColumns+" FROM usr WHERE id=$1 AND deleted_at IS NULL", id)
 	u := &User{}
 	var pos string
-	err := row.Scan(&u.Name, &u.Surname, &pos, &u.Project)
+	err := row.Scan(userFields(u, &pos)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to get user with id %d: %w", id, err)
 	}
 	u.Position = bGrades[pos]
-	u.Id = id
 	return u, nil
 }
 
-func (r *Registry) GetAllUsers() (*[]User, error) {
-	rows, err := r.p.Query(context.Background(), "SELECT id, name, surname, position, project FROM usr")
-	if err != nil {
-		return nil, fmt.Errorf("unable to SELECT all users FROM usr: %w", err)
-	}
-	//log.Println("Printing rows")
+// UserAsOf is user id as it was at t, see HistoryStore.
+func (r *Registry) UserAsOf(id int, t time.Time) (*User, error) {
 	u := &User{}
-	var us []User
 	var pos string
+	err := r.p.QueryRow(context.Background(), userAsOfQuery, id, t).Scan(userFields(u, &pos)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("unable to get user with id %d as of %s: %w", id, t.Format(time.RFC3339), ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get user with id %d as of %s: %w", id, t.Format(time.RFC3339), err)
+	}
+	u.Position = bGrades[pos]
+	return u, nil
+}
 
-	_, err = pgx.ForEachRow(rows, []any{&u.Id, &u.Name, &u.Surname, &pos, &u.Project}, func() error {
+// UsersAsOf lists the users live at t, see HistoryStore.
+func (r *Registry) UsersAsOf(f UserFilter, t time.Time) ([]User, error) {
+	req, args := historyQuery(f, t)
+	rows, err := r.p.Query(context.Background(), req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT users FROM usr_history: %w", err)
+	}
+	var u User
+	var pos string
+	us := make([]User, 0)
+	_, err = pgx.ForEachRow(rows, userFields(&u, &pos), func() error {
 		u.Position = bGrades[pos]
-		us = append(us, *u)
+		us = append(us, u)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT users FROM usr_history: %w", err)
+	}
+	return us, nil
+}
+
+// History lists the versions of user id, see HistoryStore.
+func (r *Registry) History(id int) ([]Version, error) {
+	rows, err := r.p.Query(context.Background(), userHistoryQuery, id, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT history of user %d: %w", id, err)
+	}
+	var v Version
+	var pos string
+	vs := make([]Version, 0)
+	_, err = pgx.ForEachRow(rows, append(userFields(&v.User, &pos), &v.ValidFrom, &v.ValidTo), func() error {
+		v.Position = bGrades[pos]
+		vs = append(vs, v)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT history of user %d: %w", id, err)
+	}
+	return vs, nil
+}
+
+// pgParam numbers query parameters the way Postgres wants them.
+func pgParam(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// userConds are the conditions of f but for deleted users, with their
+// arguments.
+func userConds(f UserFilter, param func(int) string) ([]string, []any) {
+	var cond []string
+	var args []any
+	if f.Project != "" {
+		args = append(args, f.Project)
+		cond = append(cond, "project="+param(len(args)))
+	}
+	if f.Position != 0 {
+		args = append(args, dGrades[f.Position])
+		cond = append(cond, "position="+param(len(args)))
+	}
+	for _, c := range []struct {
+		cond  string
+		value string
+	}{
+		{"email=", f.Email},
+		{"employee_number=", f.EmployeeNumber},
+		{"location=", f.Location},
+		{"CAST(hire_date AS TEXT)>=", f.HiredFrom},
+		{"CAST(hire_date AS TEXT)<=", f.HiredTo},
+	} {
+		if c.value != "" {
+			args = append(args, c.value)
+			cond = append(cond, c.cond+param(len(args)))
+		}
+	}
+	if f.ManagerId != 0 {
+		args = append(args, f.ManagerId)
+		cond = append(cond, "manager_id="+param(len(args)))
+	}
+	return cond, args
+}
+
+func userQuery(f UserFilter, param func(int) string) (string, []any) {
+	cond, args := userConds(f, param)
+	req := "SELECT " + userColumns + " FROM usr"
+	if f.IncludeDeleted {
+		req = "SELECT " + userColumns + ", deleted_at FROM usr"
+	} else {
+		cond = append(cond, "deleted_at IS NULL")
+	}
+	if len(cond) > 0 {
+		req += " WHERE " + strings.Join(cond, " AND ")
+	}
+	return req + " ORDER BY id", args
+}
+
+func (r *Registry) GetAllUsers(f UserFilter) (*[]User, error) {
+	us := make([]User, 0)
+	err := r.EachUser(f, func(u User) error {
+		us = append(us, u)
 		return nil
 	})
-	//|| int(tr.RowsAffected()) != len(us)
 	if err != nil {
-		return nil, fmt.Errorf("unable to convert request into names list: %w", err)
+		return nil, err
 	}
 	return &us, nil
 }
+
+// EachUser hands the users matching f to fn one by one, straight off the
+// cursor, and stops at the first error fn returns.
+func (r *Registry) EachUser(f UserFilter, fn func(User) error) error {
+	return r.EachUserContext(context.Background(), f, fn)
+}
+
+// EachUserContext is EachUser, the query being cancelled with ctx.
+func (r *Registry) EachUserContext(ctx context.Context, f UserFilter, fn func(User) error) error {
+	req, args := userQuery(f, pgParam)
+	rows, err := r.p.Query(ctx, req, args...)
+	if err != nil {
+		return fmt.Errorf("unable to SELECT all users FROM usr: %w", err)
+	}
+	var u User
+	var pos string
+	var deletedAt *time.Time
+	dest := userFields(&u, &pos)
+	if f.IncludeDeleted {
+		dest = append(dest, &deletedAt)
+	}
+	_, err = pgx.ForEachRow(rows, dest, func() error {
+		u.Position = bGrades[pos]
+		u.DeletedAt = nil
+		if deletedAt != nil {
+			t := *deletedAt
+			u.DeletedAt = &t
+		}
+		// Rows read ahead are not checked against ctx by the driver.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(u)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to convert request into names list: %w", err)
+	}
+	return nil
+}
+
+// BeginIdempotent claims key unless a live claim exists, in which case the
+// claim is returned. An expired claim is taken over in the same statement.
+func (r *Registry) BeginIdempotent(key, fingerprint string, expires time.Time) (*IdempotentResponse, error) {
+	ctx := context.Background()
+	// The claim can vanish between the two statements (released or purged),
+	// then claiming again is the right thing to do.
+	for i := 0; i < 2; i++ {
+		rp, err := r.p.Exec(ctx, `INSERT INTO idempotency_key (key, fingerprint, expires_at) VALUES ($1, $2, $3)
+ON CONFLICT (key) DO UPDATE SET fingerprint=EXCLUDED.fingerprint, status=0, content_type='', body=NULL, expires_at=EXCLUDED.expires_at
+WHERE idempotency_key.expires_at < now()`, key, fingerprint, expires)
+		if err != nil {
+			return nil, fmt.Errorf("unable to INSERT INTO idempotency_key: %w", err)
+		}
+		if rp.RowsAffected() == 1 {
+			return nil, nil
+		}
+		resp := &IdempotentResponse{}
+		err = r.p.QueryRow(ctx, "SELECT fingerprint, status, content_type, body FROM idempotency_key WHERE key=$1", key).
+			Scan(&resp.Fingerprint, &resp.Status, &resp.ContentType, &resp.Body)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get idempotency key: %w", err)
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("unable to claim idempotency key %q", key)
+}
+
+func (r *Registry) FinishIdempotent(key string, resp IdempotentResponse, expires time.Time) error {
+	_, err := r.p.Exec(context.Background(),
+		"UPDATE idempotency_key SET status=$2, content_type=$3, body=$4, expires_at=$5 WHERE key=$1",
+		key, resp.Status, resp.ContentType, resp.Body, expires)
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE idempotency_key: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) ReleaseIdempotent(key string) error {
+	_, err := r.p.Exec(context.Background(), "DELETE FROM idempotency_key WHERE key=$1 AND status=0", key)
+	if err != nil {
+		return fmt.Errorf("unable to DELETE FROM idempotency_key: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) PurgeIdempotent(t time.Time) (int, error) {
+	rp, err := r.p.Exec(context.Background(), "DELETE FROM idempotency_key WHERE expires_at < $1", t)
+	if err != nil {
+		return 0, fmt.Errorf("unable to DELETE FROM idempotency_key: %w", err)
+	}
+	return int(rp.RowsAffected()), nil
+}
+
+func (r *Registry) Projects() ([]string, error) {
+	rows, err := r.p.Query(context.Background(), projectsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM project: %w", err)
+	}
+	var n string
+	var names []string
+	_, err = pgx.ForEachRow(rows, []any{&n}, func() error {
+		names = append(names, n)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan project: %w", err)
+	}
+	return names, nil
+}
+
+func (r *Registry) AddProject(name string) error {
+	tag, err := r.p.Exec(context.Background(), addProjectQuery, name)
+	if err != nil {
+		return fmt.Errorf("unable to INSERT INTO project: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("unable to add project %q: %w", name, ErrProjectExists)
+	}
+	return nil
+}
+
+func (r *Registry) ProjectExists(name string) (bool, error) {
+	var ok bool
+	if err := r.p.QueryRow(context.Background(), projectExistsQuery, name).Scan(&ok); err != nil {
+		return false, fmt.Errorf("unable to check project %q: %w", name, err)
+	}
+	return ok, nil
+}
+
+func (r *Registry) Aggregate(ctx context.Context, query string, args []any, row func(scan func(dest ...any) error) error) error {
+	rows, err := r.p.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err = row(rows.Scan); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("unable to aggregate: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) CreateCycle(c Cycle) (int, error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var id int
+	if err = tx.QueryRow(ctx, createCycleQuery, c.Name, c.OpensAt, c.ClosesAt).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO review_cycle: %w", err)
+	}
+	for _, p := range c.Projects {
+		if _, err = tx.Exec(ctx, cycleProjectQuery, id, p); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO review_cycle_project: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("unable to commit review cycle: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Cycle(id int) (*Cycle, error) {
+	cs, err := r.cycles(cycleQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("unable to get review cycle %d: %w", id, ErrCycleNotFound)
+	}
+	return &cs[0], nil
+}
+
+func (r *Registry) Cycles() ([]Cycle, error) {
+	return r.cycles(cyclesQuery)
+}
+
+// cycles runs cyclesQuery or cycleQuery and adds the projects of the cycles.
+func (r *Registry) cycles(req string, args ...any) ([]Cycle, error) {
+	ctx := context.Background()
+	rows, err := r.p.Query(ctx, req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle: %w", err)
+	}
+	var c Cycle
+	cs := make([]Cycle, 0)
+	byId := make(map[int]int)
+	_, err = pgx.ForEachRow(rows, []any{&c.Id, &c.Name, &c.OpensAt, &c.ClosesAt, &c.Status}, func() error {
+		c.Projects = []string{}
+		byId[c.Id] = len(cs)
+		cs = append(cs, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle: %w", err)
+	}
+	if len(cs) == 0 {
+		return cs, nil
+	}
+	rows, err = r.p.Query(ctx, cycleProjects)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle_project: %w", err)
+	}
+	var id int
+	var p string
+	_, err = pgx.ForEachRow(rows, []any{&id, &p}, func() error {
+		if i, ok := byId[id]; ok {
+			cs[i].Projects = append(cs[i].Projects, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle_project: %w", err)
+	}
+	return cs, nil
+}
+
+// cycleError tells why cycle id was not in status want.
+func (r *Registry) cycleError(id int, want CycleStatus) error {
+	c, err := r.Cycle(id)
+	if errors.Is(err, ErrCycleNotFound) {
+		return cycleError(id, nil, want)
+	}
+	if err != nil {
+		return err
+	}
+	return cycleError(id, c, want)
+}
+
+func (r *Registry) OpenCycle(id int, rs []Review) error {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	rp, err := tx.Exec(ctx, setCycleStatus, id, CycleOpen, CycleScheduled)
+	if err != nil {
+		return fmt.Errorf("unable to open review cycle %d: %w", id, err)
+	}
+	if rp.RowsAffected() == 0 {
+		return r.cycleError(id, CycleScheduled)
+	}
+	for _, rv := range rs {
+		if _, err = tx.Exec(ctx, enrolQuery, id, rv.UserId, dGrades[rv.From], dGrades[rv.To]); err != nil {
+			return fmt.Errorf("unable to enrol user %d: %w", rv.UserId, err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit review cycle %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Registry) Reviews(id int) ([]Review, error) {
+	if _, err := r.Cycle(id); err != nil {
+		return nil, err
+	}
+	rows, err := r.p.Query(context.Background(), reviewsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review: %w", err)
+	}
+	var rv Review
+	var from, to string
+	rs := make([]Review, 0)
+	_, err = pgx.ForEachRow(rows, []any{&rv.CycleId, &rv.UserId, &from, &to, &rv.Status, &rv.Comment}, func() error {
+		rv.From, rv.To = bGrades[from], bGrades[to]
+		rs = append(rs, rv)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review: %w", err)
+	}
+	return rs, nil
+}
+
+func (r *Registry) SetReview(id, user int, status ReviewStatus, comment string) error {
+	rp, err := r.p.Exec(context.Background(), setReviewQuery, id, user, status, nullable(comment))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE review: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		if err = r.cycleError(id, CycleOpen); err != nil {
+			return err
+		}
+		return fmt.Errorf("user %d is not enrolled in review cycle %d: %w", user, id, ErrReviewNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) CloseCycle(id int) error {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	rp, err := tx.Exec(ctx, setCycleStatus, id, CycleClosed, CycleOpen)
+	if err != nil {
+		return fmt.Errorf("unable to close review cycle %d: %w", id, err)
+	}
+	if rp.RowsAffected() == 0 {
+		return r.cycleError(id, CycleOpen)
+	}
+	for _, req := range closeCycleQueries {
+		if _, err = tx.Exec(ctx, req, id); err != nil {
+			return fmt.Errorf("unable to apply the promotions of review cycle %d: %w", id, err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit review cycle %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Registry) AddAssessment(a Assessment) (int, error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var ok bool
+	if err = tx.QueryRow(ctx, cycleExists, a.CycleId).Scan(&ok); err != nil {
+		return 0, fmt.Errorf("unable to look for review cycle %d: %w", a.CycleId, err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("unable to get review cycle %d: %w", a.CycleId, ErrCycleNotFound)
+	}
+	var pos, project string
+	err = tx.QueryRow(ctx, assessmentUser, a.UserId).Scan(&pos, &project)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("no user to assess with id %d: %w", a.UserId, ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to get user %d: %w", a.UserId, err)
+	}
+	if a.ReviewerId != 0 {
+		var rp, rproject string
+		err = tx.QueryRow(ctx, assessmentUser, a.ReviewerId).Scan(&rp, &rproject)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("no reviewer with id %d: %w", a.ReviewerId, ErrNotFound)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to get reviewer %d: %w", a.ReviewerId, err)
+		}
+	}
+	if err = tx.QueryRow(ctx, calibrated, a.CycleId, project).Scan(&ok); err != nil {
+		return 0, fmt.Errorf("unable to look for calibration: %w", err)
+	}
+	if ok {
+		return 0, fmt.Errorf("%s is calibrated in review cycle %d: %w", project, a.CycleId, ErrAssessmentLocked)
+	}
+	if err = tx.QueryRow(ctx, assessmentExists, a.CycleId, a.UserId, a.ReviewerId).Scan(&ok); err != nil {
+		return 0, fmt.Errorf("unable to look for assessments: %w", err)
+	}
+	if ok {
+		return 0, fmt.Errorf("user %d is already assessed by %d in review cycle %d: %w", a.UserId, a.ReviewerId, a.CycleId, ErrAssessmentExists)
+	}
+	var id int
+	err = tx.QueryRow(ctx, addAssessment, a.CycleId, a.UserId, nullableId(a.ReviewerId), pos, project, a.Rating, nullable(a.Summary)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO assessment: %w", err)
+	}
+	for c, score := range a.Scores {
+		if _, err = tx.Exec(ctx, addScore, id, c, score); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO assessment_score: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("unable to commit assessment: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Assessment(id int) (*Assessment, error) {
+	as, err := r.assessments(assessmentQueries(AssessmentFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(as) == 0 {
+		return nil, fmt.Errorf("no assessment with id %d: %w", id, ErrAssessmentNotFound)
+	}
+	return &as[0], nil
+}
+
+func (r *Registry) Assessments(f AssessmentFilter) ([]Assessment, error) {
+	return r.assessments(assessmentQueries(f, 0))
+}
+
+// assessments runs the queries of assessmentQueries.
+func (r *Registry) assessments(req, scores string, args []any) ([]Assessment, error) {
+	ctx := context.Background()
+	rows, err := r.p.Query(ctx, req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment: %w", err)
+	}
+	var a Assessment
+	var grade string
+	as := make([]Assessment, 0)
+	byId := make(map[int]int)
+	_, err = pgx.ForEachRow(rows, assessmentFields(&a, &grade), func() error {
+		a.Grade, a.Scores = bGrades[grade], map[string]int{}
+		byId[a.Id] = len(as)
+		as = append(as, a)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment: %w", err)
+	}
+	if len(as) == 0 {
+		return as, nil
+	}
+	rows, err = r.p.Query(ctx, scores, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment_score: %w", err)
+	}
+	var id, score int
+	var c string
+	_, err = pgx.ForEachRow(rows, []any{&id, &c, &score}, func() error {
+		if i, ok := byId[id]; ok {
+			as[i].Scores[c] = score
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment_score: %w", err)
+	}
+	return as, nil
+}
+
+func (r *Registry) UpdateAssessment(id, rating int, scores map[string]int, summary string) error {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	rp, err := tx.Exec(ctx, updateAssessment, id, rating, nullable(summary))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE assessment: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		if _, err = r.Assessment(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to update assessment %d: %w", id, ErrAssessmentLocked)
+	}
+	if _, err = tx.Exec(ctx, deleteScores, id); err != nil {
+		return fmt.Errorf("unable to DELETE FROM assessment_score: %w", err)
+	}
+	for c, score := range scores {
+		if _, err = tx.Exec(ctx, addScore, id, c, score); err != nil {
+			return fmt.Errorf("unable to INSERT INTO assessment_score: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit assessment %d: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Registry) Calibrate(cycle int, project string) error {
+	ctx := context.Background()
+	var ok bool
+	if err := r.p.QueryRow(ctx, cycleExists, cycle).Scan(&ok); err != nil {
+		return fmt.Errorf("unable to look for review cycle %d: %w", cycle, err)
+	}
+	if !ok {
+		return fmt.Errorf("unable to get review cycle %d: %w", cycle, ErrCycleNotFound)
+	}
+	rp, err := r.p.Exec(ctx, calibrate, cycle, project, time.Now())
+	if err != nil {
+		return fmt.Errorf("unable to INSERT INTO calibration: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("%s is already calibrated in review cycle %d: %w", project, cycle, ErrAssessmentLocked)
+	}
+	return nil
+}
+
+func (r *Registry) Calibrated(cycle int, project string) (bool, error) {
+	var ok bool
+	if err := r.p.QueryRow(context.Background(), calibrated, cycle, project).Scan(&ok); err != nil {
+		return false, fmt.Errorf("unable to look for calibration: %w", err)
+	}
+	return ok, nil
+}
+
+func (r *Registry) CreateFeedback(f Feedback, managerKey string, keys map[int]string) (int, error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var id int
+	if err = tx.QueryRow(ctx, createFeedbackQuery, f.UserId, nullableId(f.ManagerId), managerKey, f.DueAt).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO feedback: %w", err)
+	}
+	for _, rs := range f.Respondents {
+		if _, err = tx.Exec(ctx, addRespondentQuery, id, rs.UserId, rs.Relation, keys[rs.UserId]); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO feedback_respondent: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("unable to commit feedback: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Feedback(id int) (*Feedback, error) {
+	fs, err := r.feedbacks(feedbackQueries(id, 0))
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return nil, fmt.Errorf("no feedback with id %d: %w", id, ErrFeedbackNotFound)
+	}
+	return &fs[0], nil
+}
+
+func (r *Registry) Feedbacks(user int) ([]Feedback, error) {
+	return r.feedbacks(feedbackQueries(0, user))
+}
+
+// feedbacks runs the queries of feedbackQueries.
+func (r *Registry) feedbacks(req, respondents string, args []any) ([]Feedback, error) {
+	ctx := context.Background()
+	rows, err := r.p.Query(ctx, req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback: %w", err)
+	}
+	var f Feedback
+	fs := make([]Feedback, 0)
+	byId := make(map[int]int)
+	_, err = pgx.ForEachRow(rows, []any{&f.Id, &f.UserId, &f.ManagerId, &f.DueAt}, func() error {
+		f.Respondents = []Respondent{}
+		byId[f.Id] = len(fs)
+		fs = append(fs, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback: %w", err)
+	}
+	if len(fs) == 0 {
+		return fs, nil
+	}
+	rows, err = r.p.Query(ctx, respondents, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	var id int
+	var rs Respondent
+	// reminded is copied out as pgx scans into the same time every row.
+	var reminded *time.Time
+	_, err = pgx.ForEachRow(rows, []any{&id, &rs.UserId, &rs.Relation, &rs.Answered, &reminded}, func() error {
+		rs.RemindedAt = nil
+		if reminded != nil {
+			t := *reminded
+			rs.RemindedAt = &t
+		}
+		if i, ok := byId[id]; ok {
+			fs[i].Respondents = append(fs[i].Respondents, rs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	return fs, nil
+}
+
+func (r *Registry) FeedbackKey(id int, key string) (int, error) {
+	var respondent int
+	err := r.p.QueryRow(context.Background(), feedbackKeyQuery, id, key).Scan(&respondent)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("feedback %d: %w", id, ErrFeedbackKey)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to check feedback key: %w", err)
+	}
+	return respondent, nil
+}
+
+func (r *Registry) FeedbackResponse(id, respondent int) (*FeedbackResponse, error) {
+	var answered bool
+	var resp FeedbackResponse
+	err := r.p.QueryRow(context.Background(), feedbackResponseQuery, id, respondent).
+		Scan(&answered, &resp.Rating, &resp.Strengths, &resp.Improvements, &resp.Anonymous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	if !answered {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+func (r *Registry) Respond(id, respondent int, resp FeedbackResponse) error {
+	rp, err := r.p.Exec(context.Background(), respondQuery, id, respondent, resp.Rating, nullable(resp.Strengths),
+		nullable(resp.Improvements), resp.Anonymous, time.Now())
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE feedback_respondent: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) FeedbackAnswers(id int) ([]FeedbackAnswer, error) {
+	rows, err := r.p.Query(context.Background(), feedbackAnswersQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	var a FeedbackAnswer
+	as := make([]FeedbackAnswer, 0)
+	_, err = pgx.ForEachRow(rows, []any{&a.RespondentId, &a.Relation, &a.Rating, &a.Strengths, &a.Improvements, &a.Anonymous}, func() error {
+		as = append(as, a)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	return as, nil
+}
+
+func (r *Registry) Remind(id, respondent int, at time.Time) error {
+	rp, err := r.p.Exec(context.Background(), remindQuery, id, respondent, at)
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE feedback_respondent: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) CreateCompetency(c Competency) (int, error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var taken bool
+	if err = tx.QueryRow(ctx, competencyNamedQuery, c.Name, 0).Scan(&taken); err != nil {
+		return 0, fmt.Errorf("unable to look for competency %q: %w", c.Name, err)
+	}
+	if taken {
+		return 0, fmt.Errorf("competency %q: %w", c.Name, ErrCompetencyExists)
+	}
+	var id int
+	if err = tx.QueryRow(ctx, createCompetencyQuery, c.Name, nullable(c.Description)).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO competency: %w", err)
+	}
+	for _, e := range c.Levels {
+		if _, err = tx.Exec(ctx, addLevelQuery, id, dGrades[e.Grade], e.Level); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO competency_level: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("unable to commit competency: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Competency(id int) (*Competency, error) {
+	cs, err := r.competencies(competencyQueries(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("no competency with id %d: %w", id, ErrCompetencyNotFound)
+	}
+	return &cs[0], nil
+}
+
+func (r *Registry) Competencies() ([]Competency, error) {
+	return r.competencies(competencyQueries(0))
+}
+
+// competencies runs the queries of competencyQueries.
+func (r *Registry) competencies(req, levels string, args []any) ([]Competency, error) {
+	ctx := context.Background()
+	rows, err := r.p.Query(ctx, req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency: %w", err)
+	}
+	var c Competency
+	cs := make([]Competency, 0)
+	byId := make(map[int]int)
+	_, err = pgx.ForEachRow(rows, []any{&c.Id, &c.Name, &c.Description}, func() error {
+		c.Levels = []Expectation{}
+		byId[c.Id] = len(cs)
+		cs = append(cs, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency: %w", err)
+	}
+	if len(cs) == 0 {
+		return cs, nil
+	}
+	rows, err = r.p.Query(ctx, levels, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency_level: %w", err)
+	}
+	var id, level int
+	var grade string
+	_, err = pgx.ForEachRow(rows, []any{&id, &grade, &level}, func() error {
+		if i, ok := byId[id]; ok {
+			cs[i].Levels = append(cs[i].Levels, Expectation{Grade: bGrades[grade], Level: level})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency_level: %w", err)
+	}
+	for i := range cs {
+		sortLevels(&cs[i])
+	}
+	return cs, nil
+}
+
+func (r *Registry) UpdateCompetency(c Competency) error {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var taken bool
+	if err = tx.QueryRow(ctx, competencyNamedQuery, c.Name, c.Id).Scan(&taken); err != nil {
+		return fmt.Errorf("unable to look for competency %q: %w", c.Name, err)
+	}
+	if taken {
+		return fmt.Errorf("competency %q: %w", c.Name, ErrCompetencyExists)
+	}
+	rp, err := tx.Exec(ctx, updateCompetencyQuery, c.Id, c.Name, nullable(c.Description))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE competency: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no competency with id %d: %w", c.Id, ErrCompetencyNotFound)
+	}
+	if _, err = tx.Exec(ctx, deleteLevelsQuery, c.Id); err != nil {
+		return fmt.Errorf("unable to DELETE FROM competency_level: %w", err)
+	}
+	for _, e := range c.Levels {
+		if _, err = tx.Exec(ctx, addLevelQuery, c.Id, dGrades[e.Grade], e.Level); err != nil {
+			return fmt.Errorf("unable to INSERT INTO competency_level: %w", err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit competency %d: %w", c.Id, err)
+	}
+	return nil
+}
+
+func (r *Registry) DeleteCompetency(id int) error {
+	rp, err := r.p.Exec(context.Background(), deleteCompetencyQuery, id)
+	if err != nil {
+		return fmt.Errorf("unable to DELETE FROM competency: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no competency with id %d: %w", id, ErrCompetencyNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) SetSkill(s Skill) error {
+	ctx := context.Background()
+	var ok bool
+	if err := r.p.QueryRow(ctx, liveUserQuery, s.UserId).Scan(&ok); err != nil {
+		return fmt.Errorf("unable to get user %d: %w", s.UserId, err)
+	}
+	if !ok {
+		return fmt.Errorf("no user with id %d: %w", s.UserId, ErrNotFound)
+	}
+	if err := r.p.QueryRow(ctx, competencyExistsQuery, s.CompetencyId).Scan(&ok); err != nil {
+		return fmt.Errorf("unable to look for competency %d: %w", s.CompetencyId, err)
+	}
+	if !ok {
+		return fmt.Errorf("no competency with id %d: %w", s.CompetencyId, ErrCompetencyNotFound)
+	}
+	if _, err := r.p.Exec(ctx, setSkillQuery, s.UserId, s.CompetencyId, s.Level, s.AssessedAt); err != nil {
+		return fmt.Errorf("unable to INSERT INTO user_skill: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) Skills(f SkillFilter) ([]Skill, error) {
+	req, args := skillsQuery(f)
+	rows, err := r.p.Query(context.Background(), req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM user_skill: %w", err)
+	}
+	var s Skill
+	ss := make([]Skill, 0)
+	_, err = pgx.ForEachRow(rows, []any{&s.UserId, &s.CompetencyId, &s.Competency, &s.Level, &s.AssessedAt}, func() error {
+		ss = append(ss, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM user_skill: %w", err)
+	}
+	return ss, nil
+}
+
+func (r *Registry) AddMentorship(m Mentorship) (int, error) {
+	ctx := context.Background()
+	tx, err := r.p.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var grades [2]Grade
+	for i, id := range []int{m.MentorId, m.MenteeId} {
+		var pos, project string
+		err = tx.QueryRow(ctx, assessmentUser, id).Scan(&pos, &project)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("no user with id %d: %w", id, ErrNotFound)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to get user %d: %w", id, err)
+		}
+		grades[i] = bGrades[pos]
+	}
+	if grades[0] <= grades[1] {
+		return 0, fmt.Errorf("user %d cannot mentor user %d: %w", m.MentorId, m.MenteeId, ErrInvalidMentor)
+	}
+	var running bool
+	if err = tx.QueryRow(ctx, mentorshipRunningQuery, m.MentorId, m.MenteeId, m.StartsAt).Scan(&running); err != nil {
+		return 0, fmt.Errorf("unable to look for mentorships: %w", err)
+	}
+	if running {
+		return 0, fmt.Errorf("user %d already mentors user %d: %w", m.MentorId, m.MenteeId, ErrMentorshipExists)
+	}
+	var id int
+	err = tx.QueryRow(ctx, addMentorshipQuery, m.MentorId, m.MenteeId, m.StartsAt, m.EndsAt, nullable(m.Goals)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO mentorship: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("unable to commit mentorship: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Mentorship(id int) (*Mentorship, error) {
+	ms, err := r.mentorships(mentorshipsQuery(MentorshipFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, fmt.Errorf("no mentorship with id %d: %w", id, ErrMentorshipNotFound)
+	}
+	return &ms[0], nil
+}
+
+func (r *Registry) Mentorships(f MentorshipFilter) ([]Mentorship, error) {
+	return r.mentorships(mentorshipsQuery(f, 0))
+}
+
+// mentorships runs a query of mentorshipsQuery.
+func (r *Registry) mentorships(req string, args []any) ([]Mentorship, error) {
+	rows, err := r.p.Query(context.Background(), req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM mentorship: %w", err)
+	}
+	var m Mentorship
+	var ends *time.Time
+	ms := make([]Mentorship, 0)
+	_, err = pgx.ForEachRow(rows, []any{&m.Id, &m.MentorId, &m.MenteeId, &m.StartsAt, &ends, &m.Goals}, func() error {
+		m.EndsAt = nil
+		if ends != nil {
+			at := *ends
+			m.EndsAt = &at
+		}
+		ms = append(ms, m)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM mentorship: %w", err)
+	}
+	return ms, nil
+}
+
+func (r *Registry) EndMentorship(id int, at time.Time) error {
+	rp, err := r.p.Exec(context.Background(), endMentorshipQuery, id, at)
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE mentorship: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		if _, err = r.Mentorship(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to end mentorship %d: %w", id, ErrMentorshipState)
+	}
+	return nil
+}
+
+func (r *Registry) AddGoal(g Goal) (int, error) {
+	ctx := context.Background()
+	var live bool
+	if err := r.p.QueryRow(ctx, liveUserQuery, g.UserId).Scan(&live); err != nil {
+		return 0, fmt.Errorf("unable to get user %d: %w", g.UserId, err)
+	}
+	if !live {
+		return 0, fmt.Errorf("no user with id %d: %w", g.UserId, ErrNotFound)
+	}
+	var id int
+	err := r.p.QueryRow(ctx, addGoalQuery, g.UserId, g.Title, nullable(g.Description), nullable(dGrades[g.TargetGrade]),
+		nullable(g.DueDate), string(g.Status)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO goal: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Goal(id int) (*Goal, error) {
+	gs, err := r.goals(goalQueries(GoalFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(gs) == 0 {
+		return nil, fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	return &gs[0], nil
+}
+
+func (r *Registry) Goals(f GoalFilter) ([]Goal, error) {
+	return r.goals(goalQueries(f, 0))
+}
+
+// goals runs the queries of goalQueries.
+func (r *Registry) goals(req, notes string, args []any) ([]Goal, error) {
+	ctx := context.Background()
+	rows, err := r.p.Query(ctx, req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal: %w", err)
+	}
+	var g Goal
+	var grade, status string
+	gs := make([]Goal, 0)
+	byId := make(map[int]int)
+	_, err = pgx.ForEachRow(rows, []any{&g.Id, &g.UserId, &g.Title, &g.Description, &grade, &g.DueDate, &status}, func() error {
+		g.TargetGrade, g.Status, g.Notes = bGrades[grade], GoalStatus(status), []GoalNote{}
+		byId[g.Id] = len(gs)
+		gs = append(gs, g)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal: %w", err)
+	}
+	if len(gs) == 0 {
+		return gs, nil
+	}
+	rows, err = r.p.Query(ctx, notes, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal_note: %w", err)
+	}
+	var id int
+	var n GoalNote
+	_, err = pgx.ForEachRow(rows, []any{&id, &n.Note, &n.At}, func() error {
+		if i, ok := byId[id]; ok {
+			gs[i].Notes = append(gs[i].Notes, n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal_note: %w", err)
+	}
+	return gs, nil
+}
+
+func (r *Registry) UpdateGoal(g Goal) error {
+	rp, err := r.p.Exec(context.Background(), updateGoalQuery, g.Id, g.Title, nullable(g.Description),
+		nullable(dGrades[g.TargetGrade]), nullable(g.DueDate), string(g.Status))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE goal: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no goal with id %d: %w", g.Id, ErrGoalNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) AddGoalNote(id int, n GoalNote) error {
+	ctx := context.Background()
+	var exists bool
+	if err := r.p.QueryRow(ctx, goalExistsQuery, id).Scan(&exists); err != nil {
+		return fmt.Errorf("unable to get goal %d: %w", id, err)
+	}
+	if !exists {
+		return fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	if _, err := r.p.Exec(ctx, addGoalNoteQuery, id, n.Note, n.At); err != nil {
+		return fmt.Errorf("unable to INSERT INTO goal_note: %w", err)
+	}
+	return nil
+}
+
+func (r *Registry) AddCertification(c Certification) (int, error) {
+	ctx := context.Background()
+	var live bool
+	if err := r.p.QueryRow(ctx, liveUserQuery, c.UserId).Scan(&live); err != nil {
+		return 0, fmt.Errorf("unable to get user %d: %w", c.UserId, err)
+	}
+	if !live {
+		return 0, fmt.Errorf("no user with id %d: %w", c.UserId, ErrNotFound)
+	}
+	var id int
+	err := r.p.QueryRow(ctx, addCertificationQuery, c.UserId, string(c.Kind), c.Name, c.Issuer, c.IssuedOn,
+		nullable(c.ExpiresOn), nullable(c.Evidence)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO certification: %w", err)
+	}
+	return id, nil
+}
+
+func (r *Registry) Certification(id int) (*Certification, error) {
+	cs, err := r.certifications(certificationsQuery(CertificationFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	return &cs[0], nil
+}
+
+func (r *Registry) Certifications(f CertificationFilter) ([]Certification, error) {
+	return r.certifications(certificationsQuery(f, 0))
+}
+
+// certifications runs a query of certificationsQuery.
+func (r *Registry) certifications(req string, args []any) ([]Certification, error) {
+	rows, err := r.p.Query(context.Background(), req, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM certification: %w", err)
+	}
+	var c Certification
+	var kind string
+	var flagged *time.Time
+	cs := make([]Certification, 0)
+	_, err = pgx.ForEachRow(rows, []any{&c.Id, &c.UserId, &kind, &c.Name, &c.Issuer, &c.IssuedOn, &c.ExpiresOn, &c.Evidence,
+		&flagged}, func() error {
+		c.Kind, c.FlaggedAt = CertificationKind(kind), nil
+		if flagged != nil {
+			at := *flagged
+			c.FlaggedAt = &at
+		}
+		cs = append(cs, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM certification: %w", err)
+	}
+	return cs, nil
+}
+
+func (r *Registry) UpdateCertification(c Certification) error {
+	rp, err := r.p.Exec(context.Background(), updateCertificationQuery, c.Id, string(c.Kind), c.Name, c.Issuer, c.IssuedOn,
+		nullable(c.ExpiresOn), nullable(c.Evidence))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE certification: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no certification with id %d: %w", c.Id, ErrCertificationNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) DeleteCertification(id int) error {
+	rp, err := r.p.Exec(context.Background(), deleteCertificationQuery, id)
+	if err != nil {
+		return fmt.Errorf("unable to DELETE FROM certification: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	return nil
+}
+
+func (r *Registry) FlagCertification(id int, at time.Time) error {
+	rp, err := r.p.Exec(context.Background(), flagCertificationQuery, id, at)
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE certification: %w", err)
+	}
+	if rp.RowsAffected() == 0 {
+		return fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	return nil
+}
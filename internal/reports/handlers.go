@@ -0,0 +1,222 @@
+package reports
+
+import (
+	promo "AndersenPromo/internal"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	jsonContentType = "application/json"
+	csvContentType  = "text/csv"
+)
+
+// Handlers serves the reports of a store.
+type Handlers struct {
+	dbc promo.DBConnexion
+}
+
+func NewHandlers(dbc promo.DBConnexion) *Handlers {
+	return &Handlers{dbc}
+}
+
+// Register mounts the report routes on router.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/reports/grade-distribution", h.GradeDistribution).Methods(http.MethodGet)
+	router.HandleFunc("/reports/pyramid", h.Pyramid).Methods(http.MethodGet)
+	router.HandleFunc("/reports/headcount", h.Headcount).Methods(http.MethodGet)
+}
+
+// wantCSV tells whether the format parameter, or else the Accept header,
+// asks for CSV rather than JSON.
+func wantCSV(r *http.Request) (bool, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "json":
+		return false, nil
+	case "csv":
+		return true, nil
+	case "":
+	default:
+		return false, fmt.Errorf("unknown format %q, want json or csv", format)
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case csvContentType:
+			return true, nil
+		case jsonContentType, "application/*", "*/*":
+			return false, nil
+		}
+	}
+	return false, nil
+}
+
+// respond writes v as JSON, or records as CSV when csv is set.
+func respond(w http.ResponseWriter, csvOut bool, v any, records func() [][]string) {
+	if csvOut {
+		w.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(records()); err != nil {
+			log.Printf("Failed to write report: %v", err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write report: %v", err)
+	}
+}
+
+// gradeHeader is the grade columns of CSV reports, lowest grade first.
+func gradeHeader() []string {
+	var h []string
+	for _, g := range promo.Grades() {
+		h = append(h, g.String())
+	}
+	return h
+}
+
+func gradeRecord(grades map[string]int, total int) []string {
+	var rec []string
+	for _, g := range promo.Grades() {
+		rec = append(rec, strconv.Itoa(grades[g.String()]))
+	}
+	return append(rec, strconv.Itoa(total))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// GradeDistribution	 godoc
+//
+//	@Summary		Grade distribution
+//	@Description	current headcount per grade of every project, or of one
+//	@Tags			reports
+//	@Produce		json,text/csv
+//	@Param			project	query		string	false	"Project name"
+//	@Param			format	query		string	false	"json (the default, or as accepted) or csv"
+//	@Success		200		{array}		reports.Distribution
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Router			/reports/grade-distribution [get]
+func (h *Handlers) GradeDistribution(w http.ResponseWriter, r *http.Request) {
+	csvOut, err := wantCSV(r)
+	if err != nil {
+		promo.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ds, err := GradeDistribution(r.Context(), h.dbc, r.URL.Query().Get("project"))
+	if err != nil {
+		promo.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respond(w, csvOut, ds, func() [][]string {
+		recs := [][]string{append(append([]string{"project"}, gradeHeader()...), "total")}
+		for _, d := range ds {
+			recs = append(recs, append([]string{d.Project}, gradeRecord(d.Grades, d.Total)...))
+		}
+		return recs
+	})
+}
+
+// Pyramid	 godoc
+//
+//	@Summary		Grade pyramid
+//	@Description	current share of every grade and ratios between grades, of a project or of everyone
+//	@Tags			reports
+//	@Produce		json,text/csv
+//	@Param			project	query		string	false	"Project name"
+//	@Param			format	query		string	false	"json (the default, or as accepted) or csv"
+//	@Success		200		{object}	reports.Pyramid
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Router			/reports/pyramid [get]
+func (h *Handlers) Pyramid(w http.ResponseWriter, r *http.Request) {
+	csvOut, err := wantCSV(r)
+	if err != nil {
+		promo.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p, err := GradePyramid(r.Context(), h.dbc, r.URL.Query().Get("project"))
+	if err != nil {
+		promo.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respond(w, csvOut, p, func() [][]string {
+		recs := [][]string{{"grade", "count", "share", "per_below"}}
+		for _, l := range p.Levels {
+			perBelow := ""
+			if l.PerBelow != nil {
+				perBelow = formatFloat(*l.PerBelow)
+			}
+			recs = append(recs, []string{l.Grade, strconv.Itoa(l.Count), formatFloat(l.Share), perBelow})
+		}
+		return recs
+	})
+}
+
+// Headcount	 godoc
+//
+//	@Summary		Headcount trend
+//	@Description	headcount per grade at the end of every month, by hire and deletion dates with the grades of then when known
+//	@Tags			reports
+//	@Produce		json,text/csv
+//	@Param			project	query		string	false	"Project name"
+//	@Param			from	query		string	false	"First month, YYYY-MM, 11 months before to by default"
+//	@Param			to		query		string	false	"Last month, YYYY-MM, the current one by default"
+//	@Param			format	query		string	false	"json (the default, or as accepted) or csv"
+//	@Success		200		{array}		reports.Headcount
+//	@Failure		400		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Router			/reports/headcount [get]
+func (h *Handlers) Headcount(w http.ResponseWriter, r *http.Request) {
+	csvOut, err := wantCSV(r)
+	if err != nil {
+		promo.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if s := r.URL.Query().Get("to"); s != "" {
+		if to, err = time.Parse(MonthLayout, s); err != nil {
+			promo.Error(w, fmt.Sprintf("illegal month %q, want %s", s, MonthLayout), http.StatusBadRequest)
+			return
+		}
+	}
+	from := to.AddDate(0, -11, 0)
+	if s := r.URL.Query().Get("from"); s != "" {
+		if from, err = time.Parse(MonthLayout, s); err != nil {
+			promo.Error(w, fmt.Sprintf("illegal month %q, want %s", s, MonthLayout), http.StatusBadRequest)
+			return
+		}
+	}
+	if n := months(from, to); n < 1 || n > maxMonths {
+		promo.Error(w, fmt.Sprintf("want from before to and at most %d months", maxMonths), http.StatusBadRequest)
+		return
+	}
+	hs, err := HeadcountTrend(r.Context(), h.dbc, r.URL.Query().Get("project"), from, to)
+	if err != nil {
+		promo.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	respond(w, csvOut, hs, func() [][]string {
+		recs := [][]string{append(append([]string{"month"}, gradeHeader()...), "total")}
+		for _, h := range hs {
+			recs = append(recs, append([]string{h.Month}, gradeRecord(h.Grades, h.Total)...))
+		}
+		return recs
+	})
+}
@@ -0,0 +1,246 @@
+// Package reports aggregates the registry into headcounts for dashboards:
+// per grade and project, as a grade pyramid, and month by month.
+//
+// Stores implementing promo.Aggregator are counted in SQL, others in Go over
+// every user. Users are counted by hire and deletion dates, in the grade and
+// project they had then when the store keeps their history
+// (promo.HistoryStore), else in their current ones. Purged users are only
+// left in the history, so they are counted from it alone, while they were
+// live; stores without history forget them.
+package reports
+
+import (
+	promo "AndersenPromo/internal"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Distribution is the headcount of a project per grade name.
+type Distribution struct {
+	Project string         `json:"project" yaml:"project"`
+	Grades  map[string]int `json:"grades" yaml:"grades"`
+	Total   int            `json:"total" yaml:"total"`
+}
+
+// Level is a grade of a Pyramid.
+type Level struct {
+	Grade string `json:"grade" yaml:"grade"`
+	Count int    `json:"count" yaml:"count"`
+	// Share is Count over the headcount.
+	Share float64 `json:"share" yaml:"share"`
+	// PerBelow is Count over the count of the grade below, missing for the
+	// lowest grade or when the grade below is empty.
+	PerBelow *float64 `json:"per_below,omitempty" yaml:"per_below,omitempty"`
+}
+
+// Pyramid is the grade distribution of a project, or of everyone.
+type Pyramid struct {
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	Total   int    `json:"total" yaml:"total"`
+	// Levels go from the highest grade down.
+	Levels []Level `json:"levels" yaml:"levels"`
+	// Ratios compares every grade with each one below, e.g. "senior/junior"
+	// is seniors per junior. Ratios over an empty grade are left out.
+	Ratios map[string]float64 `json:"ratios" yaml:"ratios"`
+}
+
+// Headcount is the headcount per grade name at the end of Month, written
+// as YYYY-MM.
+type Headcount struct {
+	Month  string         `json:"month" yaml:"month"`
+	Grades map[string]int `json:"grades" yaml:"grades"`
+	Total  int            `json:"total" yaml:"total"`
+}
+
+// MonthLayout is how months are written in and out of the reports.
+const MonthLayout = "2006-01"
+
+// counts holds headcounts by project, then grade.
+type counts map[string]map[promo.Grade]int
+
+func (c counts) add(project string, g promo.Grade, n int) {
+	if c[project] == nil {
+		c[project] = make(map[promo.Grade]int)
+	}
+	c[project][g] += n
+}
+
+// countQuery counts the users of project $1 (every project when empty) on
+// the staff at $2: hired on day $3 or before (or with no hire date) and not
+// deleted yet. Their version valid at $2, if any, gives their project and
+// grade. Purged users count by that version alone.
+const countQuery = `WITH staff AS (
+	SELECT COALESCE(CASE WHEN h.id IS NULL THEN u.project ELSE h.project END, '') AS project,
+		CAST(COALESCE(h.position, u.position) AS TEXT) AS position
+	FROM usr u LEFT JOIN usr_history h ON h.id = u.id AND h.valid_from <= $2 AND (h.valid_to IS NULL OR h.valid_to > $2)
+	WHERE (u.deleted_at IS NULL OR u.deleted_at > $2)
+		AND (u.hire_date IS NULL OR CAST(u.hire_date AS TEXT) <= $3)
+	UNION ALL
+	SELECT COALESCE(h.project, ''), CAST(h.position AS TEXT)
+	FROM usr_history h
+	WHERE h.valid_from <= $2 AND (h.valid_to IS NULL OR h.valid_to > $2)
+		AND (h.hire_date IS NULL OR CAST(h.hire_date AS TEXT) <= $3)
+		AND NOT EXISTS (SELECT 1 FROM usr u WHERE u.id = h.id)
+)
+SELECT project, position, COUNT(*) FROM staff WHERE $1 = '' OR project = $1 GROUP BY project, position`
+
+// count returns the headcount of project (every project when empty) at t.
+func count(ctx context.Context, dbc promo.DBConnexion, project string, t time.Time) (counts, error) {
+	c := make(counts)
+	day := t.Format(promo.DateLayout)
+	if agg, ok := dbc.(promo.Aggregator); ok {
+		err := agg.Aggregate(ctx, countQuery, []any{project, t.UTC(), day}, func(scan func(...any) error) error {
+			var p, grade string
+			var n int
+			if err := scan(&p, &grade, &n); err != nil {
+				return fmt.Errorf("unable to scan headcount: %w", err)
+			}
+			g, err := promo.ParseGrade(grade)
+			if err != nil {
+				return err
+			}
+			c.add(p, g, n)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	then := make(map[int]promo.User)
+	if hs, ok := dbc.(promo.HistoryStore); ok {
+		us, err := hs.UsersAsOf(promo.UserFilter{}, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range us {
+			then[u.Id] = u
+		}
+	}
+	err := dbc.EachUser(promo.UserFilter{IncludeDeleted: true}, func(u promo.User) error {
+		if v, ok := then[u.Id]; ok {
+			u.Project, u.Position = v.Project, v.Position
+			delete(then, u.Id)
+		}
+		if (project == "" || u.Project == project) && (u.DeletedAt == nil || u.DeletedAt.After(t)) &&
+			(u.HireDate == "" || u.HireDate <= day) {
+			c.add(u.Project, u.Position, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// What is left of then are purged users.
+	for _, u := range then {
+		if (project == "" || u.Project == project) && (u.HireDate == "" || u.HireDate <= day) {
+			c.add(u.Project, u.Position, 1)
+		}
+	}
+	return c, nil
+}
+
+// byName turns grades into grade names, every grade being present.
+func byName(gs map[promo.Grade]int) (map[string]int, int) {
+	m := make(map[string]int)
+	total := 0
+	for _, g := range promo.Grades() {
+		m[g.String()] = gs[g]
+		total += gs[g]
+	}
+	return m, total
+}
+
+// GradeDistribution is the current headcount per grade of project, or of
+// every project ordered by name when empty.
+func GradeDistribution(ctx context.Context, dbc promo.DBConnexion, project string) ([]Distribution, error) {
+	c, err := count(ctx, dbc, project, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Distribution, 0, len(c))
+	for p, gs := range c {
+		d := Distribution{Project: p}
+		d.Grades, d.Total = byName(gs)
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Project < out[j].Project })
+	return out, nil
+}
+
+// GradePyramid is the current grade pyramid of project, or of everyone when
+// empty.
+func GradePyramid(ctx context.Context, dbc promo.DBConnexion, project string) (*Pyramid, error) {
+	c, err := count(ctx, dbc, project, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	gs := make(map[promo.Grade]int)
+	for _, pgs := range c {
+		for g, n := range pgs {
+			gs[g] += n
+		}
+	}
+	return pyramid(project, gs), nil
+}
+
+func pyramid(project string, gs map[promo.Grade]int) *Pyramid {
+	p := &Pyramid{Project: project, Levels: []Level{}, Ratios: map[string]float64{}}
+	grades := promo.Grades()
+	for _, g := range grades {
+		p.Total += gs[g]
+	}
+	for i := len(grades) - 1; i >= 0; i-- {
+		g := grades[i]
+		l := Level{Grade: g.String(), Count: gs[g]}
+		if p.Total > 0 {
+			l.Share = float64(l.Count) / float64(p.Total)
+		}
+		if i > 0 && gs[grades[i-1]] > 0 {
+			r := float64(l.Count) / float64(gs[grades[i-1]])
+			l.PerBelow = &r
+		}
+		p.Levels = append(p.Levels, l)
+		for _, below := range grades[:i] {
+			if gs[below] > 0 {
+				p.Ratios[g.String()+"/"+below.String()] = float64(gs[g]) / float64(gs[below])
+			}
+		}
+	}
+	return p
+}
+
+// maxMonths bounds the months of a headcount trend.
+const maxMonths = 120
+
+// months counts the months from from to to, both included.
+func months(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+}
+
+// HeadcountTrend is the headcount of project (every project when empty)
+// at the end of every month from from to to, both first days of a month.
+func HeadcountTrend(ctx context.Context, dbc promo.DBConnexion, project string, from, to time.Time) ([]Headcount, error) {
+	if n := months(from, to); n < 1 || n > maxMonths {
+		return nil, fmt.Errorf("want 1 to %d months, not from %s to %s", maxMonths, from.Format(MonthLayout), to.Format(MonthLayout))
+	}
+	out := make([]Headcount, 0)
+	for m := from; !m.After(to); m = m.AddDate(0, 1, 0) {
+		c, err := count(ctx, dbc, project, m.AddDate(0, 1, 0).Add(-time.Nanosecond))
+		if err != nil {
+			return nil, err
+		}
+		gs := make(map[promo.Grade]int)
+		for _, pgs := range c {
+			for g, n := range pgs {
+				gs[g] += n
+			}
+		}
+		h := Headcount{Month: m.Format(MonthLayout)}
+		h.Grades, h.Total = byName(gs)
+		out = append(out, h)
+	}
+	return out, nil
+}
@@ -0,0 +1,149 @@
+package reports
+
+import (
+	promo "AndersenPromo/internal"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stores are counted in Go (Memory) and in SQL (SQLite), which must agree.
+var stores = map[string]func(t *testing.T) promo.DBConnexion{
+	"memory": func(t *testing.T) promo.DBConnexion { return promo.NewMemory() },
+	"sqlite": func(t *testing.T) promo.DBConnexion {
+		s, err := promo.NewSQLite(":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	},
+}
+
+func seed(t *testing.T, dbc promo.DBConnexion) {
+	for _, u := range []promo.User{
+		{Name: "Ann", Surname: "Boss", Position: 4, Project: "Alpha", HireDate: "2025-01-10"},
+		{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha", HireDate: "2025-03-05"},
+		{Name: "Cid", Surname: "Jones", Position: 2, Project: "Beta"},
+		{Name: "Dan", Surname: "Brown", Position: 3, Project: "Alpha", HireDate: "2025-02-01"},
+		{Name: "Eve", Surname: "White", Position: 1, HireDate: "2099-01-01"},
+	} {
+		_, err := dbc.CreateUser(u, false)
+		require.NoError(t, err)
+	}
+	require.NoError(t, dbc.DeleteUser(4))
+}
+
+func TestReports(t *testing.T) {
+	ctx := context.Background()
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			dbc := newStore(t)
+			seed(t, dbc)
+
+			ds, err := GradeDistribution(ctx, dbc, "")
+			require.NoError(t, err)
+			assert.Equal(t, []Distribution{
+				{Project: "Alpha", Grades: map[string]int{"trainee": 0, "junior": 1, "middle": 0, "senior": 1}, Total: 2},
+				{Project: "Beta", Grades: map[string]int{"trainee": 0, "junior": 1, "middle": 0, "senior": 0}, Total: 1},
+			}, ds)
+
+			p, err := GradePyramid(ctx, dbc, "Alpha")
+			require.NoError(t, err)
+			zero := 0.0
+			assert.Equal(t, &Pyramid{Project: "Alpha", Total: 2, Levels: []Level{
+				{Grade: "senior", Count: 1, Share: 0.5},
+				{Grade: "middle", Count: 0, Share: 0, PerBelow: &zero},
+				{Grade: "junior", Count: 1, Share: 0.5},
+				{Grade: "trainee", Count: 0, Share: 0},
+			}, Ratios: map[string]float64{"senior/junior": 1, "middle/junior": 0}}, p)
+
+			hs, err := HeadcountTrend(ctx, dbc, "Alpha", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			totals := make([]int, len(hs))
+			for i, h := range hs {
+				totals[i] = h.Total
+			}
+			assert.Equal(t, []int{1, 2, 3}, totals, "Dan counts until deleted")
+			assert.Equal(t, "2025-02", hs[1].Month)
+			assert.Equal(t, 1, hs[1].Grades["middle"])
+
+			time.Sleep(5 * time.Millisecond)
+			before := time.Now()
+			time.Sleep(5 * time.Millisecond)
+			require.NoError(t, dbc.UpdateUser(2, map[string]string{"position": "senior", "project": "Beta"}))
+			c, err := count(ctx, dbc, "Alpha", before)
+			require.NoError(t, err)
+			assert.Equal(t, counts{"Alpha": {promo.Grades()[1]: 1, promo.Grades()[3]: 1}}, c, "Bob was a junior of Alpha then")
+			c, err = count(ctx, dbc, "Alpha", time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, counts{"Alpha": {promo.Grades()[3]: 1}}, c)
+
+			require.NoError(t, dbc.DeleteUser(2))
+			n, err := dbc.PurgeDeleted(time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			require.Equal(t, 2, n)
+			c, err = count(ctx, dbc, "", before)
+			require.NoError(t, err)
+			assert.Equal(t, counts{"Alpha": {promo.Grades()[1]: 1, promo.Grades()[3]: 1}, "Beta": {promo.Grades()[1]: 1}}, c,
+				"Bob still was a junior of Alpha then once purged")
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	newRouter := func(t *testing.T) *mux.Router {
+		m := promo.NewMemory()
+		seed(t, m)
+		router := mux.NewRouter()
+		NewHandlers(m).Register(router)
+		return router
+	}
+	get := func(t *testing.T, url, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		newRouter(t).ServeHTTP(w, req)
+		return w
+	}
+	t.Run("Check grade distribution (csv)", func(t *testing.T) {
+		w := get(t, "/reports/grade-distribution", "text/csv")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "project,trainee,junior,middle,senior,total\nAlpha,0,1,0,1,2\nBeta,0,1,0,0,1\n", w.Body.String())
+	})
+	t.Run("Check grade distribution (json)", func(t *testing.T) {
+		w := get(t, "/reports/grade-distribution?project=Beta", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"project":"Beta","grades":{"trainee":0,"junior":1,"middle":0,"senior":0},"total":1}]`, w.Body.String())
+	})
+	t.Run("Check pyramid (csv)", func(t *testing.T) {
+		w := get(t, "/reports/pyramid?project=Alpha&format=csv", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "grade,count,share,per_below\nsenior,1,0.5,\nmiddle,0,0,0\njunior,1,0.5,\ntrainee,0,0,\n", w.Body.String())
+	})
+	t.Run("Check headcount", func(t *testing.T) {
+		w := get(t, "/reports/headcount?project=Alpha&from=2025-02&to=2025-03&format=csv", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "month,trainee,junior,middle,senior,total\n2025-02,0,0,1,1,2\n2025-03,0,1,1,1,3\n", w.Body.String())
+	})
+	for _, url := range []string{
+		"/reports/pyramid?format=xlsx",
+		"/reports/headcount?from=2025",
+		"/reports/headcount?from=2025-03&to=2025-01",
+		"/reports/headcount?from=2000-01&to=2025-01",
+	} {
+		t.Run("Check bad request "+url, func(t *testing.T) {
+			w := get(t, url, "")
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package promo
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQuery     = 200
+	// searchThreshold is the least trigram similarity of a word to a query
+	// word for it to match, low enough for "Alexander" to find "Aleksandr".
+	searchThreshold = 0.2
+)
+
+// SearchResult is a user matching a search, best first. Highlights holds the
+// fields that matched, HTML escaped, with the matching words in <mark>.
+type SearchResult struct {
+	User       `yaml:",inline"`
+	Rank       float64           `json:"rank" yaml:"rank"`
+	Highlights map[string]string `json:"highlights,omitempty" yaml:"highlights,omitempty"`
+}
+
+// SearchStore searches users itself. Other stores are searched in Go by
+// searchUsers, with the same matching rules.
+type SearchStore interface {
+	// Search lists at most limit live users whose name, surname or project
+	// match q, best first. Ranks only compare within a result.
+	Search(q string, limit int) ([]SearchResult, error)
+}
+
+// searchDocument is what Registry searches in, matching the indexes of
+// postgres/init/07_search.sql.
+const searchDocument = `name || ' ' || surname || ' ' || COALESCE(project, '')`
+
+// searchQuery matches q $1 with full-text search first, and trigrams above
+// pg_trgm.word_similarity_threshold for typos. Full-text matches rank
+// higher as they add both scores.
+const searchQuery = `SELECT ` + userColumns + `,
+	ts_rank(to_tsvector('simple', ` + searchDocument + `), plainto_tsquery('simple', $1)) + word_similarity($1, ` + searchDocument + `) AS rank
+FROM usr
+WHERE deleted_at IS NULL AND (to_tsvector('simple', ` + searchDocument + `) @@ plainto_tsquery('simple', $1)
+	OR $1 <% (` + searchDocument + `))
+ORDER BY rank DESC, id LIMIT $2`
+
+// searchWords splits s into lowercase words of letters and digits.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams are those of pg_trgm: the word is padded with two spaces in
+// front and one behind.
+func trigrams(word string) map[string]bool {
+	r := []rune("  " + word + " ")
+	t := make(map[string]bool, len(r)-2)
+	for i := 0; i+3 <= len(r); i++ {
+		t[string(r[i:i+3])] = true
+	}
+	return t
+}
+
+// similarity is the share of trigrams two words have in common, 1 when
+// equal.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ta, tb := trigrams(a), trigrams(b)
+	common := 0
+	for t := range ta {
+		if tb[t] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// bestMatch is the highest similarity of word to any of words.
+func bestMatch(word string, words []string) float64 {
+	best := 0.0
+	for _, w := range words {
+		if s := similarity(word, w); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// searchRank tells how well u matches the query words qw: every query word
+// has to match a word of u, the rank is their mean similarity.
+func searchRank(u User, qw []string) (float64, bool) {
+	words := searchWords(u.Name + " " + u.Surname + " " + u.Project)
+	total := 0.0
+	for _, q := range qw {
+		s := bestMatch(q, words)
+		if s < searchThreshold {
+			return 0, false
+		}
+		total += s
+	}
+	return total / float64(len(qw)), true
+}
+
+// searchUsers is Search for stores that are not SearchStores.
+func searchUsers(dbc DBConnexion, q string, limit int) ([]SearchResult, error) {
+	qw := searchWords(q)
+	results := make([]SearchResult, 0)
+	if len(qw) == 0 {
+		return results, nil
+	}
+	err := dbc.EachUser(UserFilter{}, func(u User) error {
+		if rank, ok := searchRank(u, qw); ok {
+			results = append(results, SearchResult{User: u, Rank: rank})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// EachUser goes by id, which breaks ties.
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// highlight wraps the words of s matching a query word in <mark>, escaping
+// the rest. ok is false when no word matched.
+func highlight(s string, qw []string) (marked string, ok bool) {
+	var b strings.Builder
+	for len(s) > 0 {
+		i := strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+		if i < 0 {
+			i = len(s)
+		}
+		b.WriteString(html.EscapeString(s[:i]))
+		s = s[i:]
+		j := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		if j < 0 {
+			j = len(s)
+		}
+		word := s[:j]
+		s = s[j:]
+		if word == "" {
+			continue
+		}
+		match := false
+		for _, q := range qw {
+			if similarity(q, strings.ToLower(word)) >= searchThreshold {
+				match = true
+				break
+			}
+		}
+		if match {
+			ok = true
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+	}
+	return b.String(), ok
+}
+
+// highlightResults fills the Highlights of results for the query q.
+func highlightResults(results []SearchResult, q string) {
+	qw := searchWords(q)
+	for i := range results {
+		r := &results[i]
+		for field, s := range map[string]string{"name": r.Name, "surname": r.Surname, "project": r.Project} {
+			if marked, ok := highlight(s, qw); ok {
+				if r.Highlights == nil {
+					r.Highlights = make(map[string]string)
+				}
+				r.Highlights[field] = marked
+			}
+		}
+	}
+}
+
+// SearchUsers	 godoc
+//
+//	@Summary		Search users
+//	@Description	find live users by name, surname or project, tolerating typos; best matches first
+//	@Tags			users
+//	@Produce		json,application/yaml
+//	@Param			q		query		string	true	"Words to look for"
+//	@Param			limit	query		int		false	"Most results, 20 by default and 100 at most"
+//	@Success		200		{array}		SearchResult
+//	@Failure		400		{object}	Problem
+//	@Failure		406		{object}	Problem
+//	@Failure		500		{object}	Problem
+//	@Router			/users/search [get]
+func (h *Handlers) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	switch {
+	case q == "":
+		Error(w, "missing search query q", http.StatusBadRequest)
+		return
+	case utf8.RuneCountInString(q) > maxSearchQuery:
+		Error(w, fmt.Sprintf("search query longer than %d characters", maxSearchQuery), http.StatusBadRequest)
+		return
+	}
+	limit := defaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > maxSearchLimit {
+			Error(w, fmt.Sprintf("illegal limit %q, want 1 to %d", l, maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	c, ok := Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var results []SearchResult
+	var err error
+	if ss, ok := h.dbc.(SearchStore); ok {
+		results, err = ss.Search(q, limit)
+	} else {
+		results, err = searchUsers(h.dbc, q, limit)
+	}
+	if err != nil {
+		DBError(w, err)
+		return
+	}
+	highlightResults(results, q)
+	Respond(w, c, http.StatusOK, results)
+}
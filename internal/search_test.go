@@ -0,0 +1,104 @@
+package promo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, similarity("ann", "ann"))
+	assert.Equal(t, 0.25, similarity("alexander", "aleksandr"))
+	assert.Less(t, similarity("bob", "rob"), searchThreshold)
+}
+
+func TestHighlight(t *testing.T) {
+	marked, ok := highlight("R&D Lab", []string{"lab"})
+	assert.True(t, ok)
+	assert.Equal(t, "R&amp;D <mark>Lab</mark>", marked)
+	_, ok = highlight("Alpha", []string{"smith"})
+	assert.False(t, ok)
+}
+
+func TestHandlers_SearchUsers(t *testing.T) {
+	newHandlers := func(t *testing.T) *Handlers {
+		m := NewMemory()
+		require.NoError(t, m.AddUsers([]User{
+			{Name: "Aleksandr", Surname: "Ivanov", Position: middle, Project: "Alpha"},
+			{Name: "Bob", Surname: "Smith", Position: junior, Project: "Beta"},
+			{Name: "Alexandra", Surname: "Petrova", Position: senior, Project: "Alpha"},
+		}))
+		return &Handlers{m}
+	}
+	t.Run("Check searching users (typos)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/search?q=Alexander", nil)
+		w := httptest.NewRecorder()
+		h.SearchUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var rs []SearchResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&rs))
+		require.Len(t, rs, 2)
+		assert.Equal(t, []int{3, 1}, []int{rs[0].Id, rs[1].Id})
+		assert.Equal(t, map[string]string{"name": "<mark>Aleksandr</mark>"}, rs[1].Highlights)
+	})
+	t.Run("Check searching users (several words)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/search?q=alpha+ivanov&limit=5", nil)
+		w := httptest.NewRecorder()
+		h.SearchUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var rs []SearchResult
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&rs))
+		require.Len(t, rs, 1)
+		assert.Equal(t, 1.0, rs[0].Rank)
+		assert.Equal(t, map[string]string{"surname": "<mark>Ivanov</mark>", "project": "<mark>Alpha</mark>"}, rs[0].Highlights)
+	})
+	t.Run("Check searching users (no results)", func(t *testing.T) {
+		h := newHandlers(t)
+		req := httptest.NewRequest(http.MethodGet, "/users/search?q=zzz", nil)
+		w := httptest.NewRecorder()
+		h.SearchUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "[]", w.Body.String())
+	})
+	for _, q := range []string{"", "?q=+", "?q=ann&limit=0", "?q=ann&limit=101"} {
+		t.Run("Check searching users (bad query "+q+")", func(t *testing.T) {
+			h := newHandlers(t)
+			req := httptest.NewRequest(http.MethodGet, "/users/search"+q, nil)
+			w := httptest.NewRecorder()
+			h.SearchUsers(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+	t.Run("Check searching users (registry)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)")).
+			WithArgs("0.2").WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectQuery(regexp.QuoteMeta(searchQuery)).WithArgs("Alexander", defaultSearchLimit).
+			WillReturnRows(pgxmock.NewRows(append(userCols, "rank")).
+				AddRow(1, "Aleksandr", "Ivanov", "middle", "Alpha", "", "", "", "", 0, 0.3))
+		mock.ExpectRollback()
+		req := httptest.NewRequest(http.MethodGet, "/users/search?q=Alexander", nil)
+		w := httptest.NewRecorder()
+		h.SearchUsers(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"id":1,"name":"Aleksandr","surname":"Ivanov","position":3,"project":"Alpha","rank":0.3,
+			"highlights":{"name":"<mark>Aleksandr</mark>"}}]`, w.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
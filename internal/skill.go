@@ -0,0 +1,128 @@
+package promo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCompetencyNotFound = errors.New("competency not found")
+	// ErrCompetencyExists is returned for a second competency of a name.
+	ErrCompetencyExists = errors.New("competency already exists")
+)
+
+// Expectation is the level expected of the users of Grade.
+type Expectation struct {
+	Grade Grade `json:"grade" yaml:"grade"`
+	Level int   `json:"level" yaml:"level"`
+}
+
+// Competency is a competency of the framework with the levels expected of
+// each grade, by grade; grades left out expect none.
+type Competency struct {
+	Id          int           `json:"id" yaml:"id"`
+	Name        string        `json:"name" yaml:"name" validate:"required,max=64"`
+	Description string        `json:"description,omitempty" yaml:"description,omitempty" validate:"max=500"`
+	Levels      []Expectation `json:"levels" yaml:"levels"`
+}
+
+// Expected is the level c expects of grade, 0 for none.
+func (c *Competency) Expected(grade Grade) int {
+	for _, e := range c.Levels {
+		if e.Grade == grade {
+			return e.Level
+		}
+	}
+	return 0
+}
+
+// Skill is the level of user UserId in competency CompetencyId when last
+// assessed, at AssessedAt.
+type Skill struct {
+	UserId       int       `json:"user_id" yaml:"user_id"`
+	CompetencyId int       `json:"competency_id" yaml:"competency_id"`
+	Competency   string    `json:"competency" yaml:"competency"`
+	Level        int       `json:"level" yaml:"level"`
+	AssessedAt   time.Time `json:"assessed_at" yaml:"assessed_at"`
+}
+
+// SkillFilter selects the skills of live users; zero fields match
+// everything.
+type SkillFilter struct {
+	UserId  int
+	Project string
+}
+
+// SkillStore keeps the competency framework and the skills of users.
+// Levels are stored as given, checking them against a scale is up to the
+// caller.
+type SkillStore interface {
+	// CreateCompetency records c and returns its id, ErrCompetencyExists if
+	// its name is taken.
+	CreateCompetency(c Competency) (int, error)
+	// Competency is competency id, ErrCompetencyNotFound if there is none.
+	Competency(id int) (*Competency, error)
+	// Competencies lists the framework by name.
+	Competencies() ([]Competency, error)
+	// UpdateCompetency replaces the name, description and levels of
+	// competency c.Id.
+	UpdateCompetency(c Competency) error
+	// DeleteCompetency deletes competency id with the skills in it.
+	DeleteCompetency(id int) error
+	// SetSkill records or replaces the level of a live user in a
+	// competency.
+	SetSkill(s Skill) error
+	// Skills lists the skills matching f by user and competency name.
+	Skills(f SkillFilter) ([]Skill, error)
+}
+
+// Skill queries shared by Registry and SQLite.
+const (
+	competencyNamedQuery  = "SELECT EXISTS (SELECT 1 FROM competency WHERE name=$1 AND id<>$2)"
+	createCompetencyQuery = "INSERT INTO competency (name, description) VALUES ($1, $2) RETURNING id"
+	updateCompetencyQuery = "UPDATE competency SET name=$2, description=$3 WHERE id=$1"
+	deleteCompetencyQuery = "DELETE FROM competency WHERE id=$1"
+	addLevelQuery         = "INSERT INTO competency_level (competency_id, grade, level) VALUES ($1, $2, $3)"
+	deleteLevelsQuery     = "DELETE FROM competency_level WHERE competency_id=$1"
+	liveUserQuery         = "SELECT EXISTS (SELECT 1 FROM usr WHERE id=$1 AND deleted_at IS NULL)"
+	competencyExistsQuery = "SELECT EXISTS (SELECT 1 FROM competency WHERE id=$1)"
+	setSkillQuery         = `INSERT INTO user_skill (user_id, competency_id, level, assessed_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, competency_id) DO UPDATE SET level=excluded.level, assessed_at=excluded.assessed_at`
+)
+
+// competencyQueries select competency id, or all of them when 0, by name,
+// and their levels.
+func competencyQueries(id int) (competencies, levels string, args []any) {
+	where := ""
+	if id != 0 {
+		where, args = " WHERE id=$1", []any{id}
+	}
+	competencies = "SELECT id, name, COALESCE(description, '') FROM competency" + where + " ORDER BY name"
+	levels = "SELECT competency_id, grade, level FROM competency_level WHERE competency_id IN (SELECT id FROM competency" +
+		where + ")"
+	return competencies, levels, args
+}
+
+// skillsQuery selects the skills matching f, with $n parameters.
+func skillsQuery(f SkillFilter) (string, []any) {
+	cond := []string{"u.deleted_at IS NULL"}
+	var args []any
+	if f.UserId != 0 {
+		args = append(args, f.UserId)
+		cond = append(cond, "s.user_id="+pgParam(len(args)))
+	}
+	if f.Project != "" {
+		args = append(args, f.Project)
+		cond = append(cond, "u.project="+pgParam(len(args)))
+	}
+	return "SELECT s.user_id, s.competency_id, c.name, s.level, s.assessed_at FROM user_skill s " +
+		"JOIN competency c ON c.id=s.competency_id JOIN usr u ON u.id=s.user_id " +
+		"WHERE " + strings.Join(cond, " AND ") + " ORDER BY s.user_id, c.name", args
+}
+
+// sortLevels orders the levels of c by grade.
+func sortLevels(c *Competency) {
+	sort.Slice(c.Levels, func(i, j int) bool { return c.Levels[i].Grade < c.Levels[j].Grade })
+}
@@ -0,0 +1,87 @@
+package promo
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Skills(t *testing.T) {
+	exists := func(ok bool) *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"exists"}).AddRow(ok)
+	}
+	t.Run("Check creating a competency (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(competencyNamedQuery)).WithArgs("design", 0).WillReturnRows(exists(false))
+		mock.ExpectQuery(regexp.QuoteMeta(createCompetencyQuery)).WithArgs("design", (*string)(nil)).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta(addLevelQuery)).WithArgs(1, "middle", 2).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectRollback()
+		id, err := r.CreateCompetency(Competency{Name: "design", Levels: []Expectation{{Grade: middle, Level: 2}}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, id)
+	})
+	t.Run("Check creating a competency (registry, name taken)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(competencyNamedQuery)).WithArgs("design", 0).WillReturnRows(exists(true))
+		mock.ExpectRollback()
+		_, err := r.CreateCompetency(Competency{Name: "design"})
+		assert.ErrorIs(t, err, ErrCompetencyExists)
+	})
+	t.Run("Check getting a competency (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		req, levels, args := competencyQueries(1)
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "design", "draws systems"))
+		mock.ExpectQuery(regexp.QuoteMeta(levels)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"competency_id", "grade", "level"}).
+				AddRow(1, "senior", 4).AddRow(1, "middle", 2))
+		c, err := r.Competency(1)
+		require.NoError(t, err)
+		assert.Equal(t, &Competency{Id: 1, Name: "design", Description: "draws systems",
+			Levels: []Expectation{{Grade: middle, Level: 2}, {Grade: senior, Level: 4}}}, c)
+	})
+	t.Run("Check updating a competency (registry, absent)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(competencyNamedQuery)).WithArgs("design", 42).WillReturnRows(exists(false))
+		mock.ExpectExec(regexp.QuoteMeta(updateCompetencyQuery)).WithArgs(42, "design", (*string)(nil)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+		assert.ErrorIs(t, r.UpdateCompetency(Competency{Id: 42, Name: "design"}), ErrCompetencyNotFound)
+		mock.ExpectExec(regexp.QuoteMeta(deleteCompetencyQuery)).WithArgs(42).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		assert.ErrorIs(t, r.DeleteCompetency(42), ErrCompetencyNotFound)
+	})
+	t.Run("Check skills (registry)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		at := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(regexp.QuoteMeta(liveUserQuery)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectQuery(regexp.QuoteMeta(competencyExistsQuery)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectExec(regexp.QuoteMeta(setSkillQuery)).WithArgs(1, 1, 2, at).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		require.NoError(t, r.SetSkill(Skill{UserId: 1, CompetencyId: 1, Level: 2, AssessedAt: at}))
+
+		req, args := skillsQuery(SkillFilter{UserId: 1})
+		mock.ExpectQuery(regexp.QuoteMeta(req)).WithArgs(args...).
+			WillReturnRows(pgxmock.NewRows([]string{"user_id", "competency_id", "competency", "level", "assessed_at"}).
+				AddRow(1, 1, "design", 2, at))
+		ss, err := r.Skills(SkillFilter{UserId: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []Skill{{UserId: 1, CompetencyId: 1, Competency: "design", Level: 2, AssessedAt: at}}, ss)
+	})
+	t.Run("Check skills (registry, absent competency)", func(t *testing.T) {
+		r, mock := newMockRegistry(t)
+		mock.ExpectQuery(regexp.QuoteMeta(liveUserQuery)).WithArgs(1).WillReturnRows(exists(true))
+		mock.ExpectQuery(regexp.QuoteMeta(competencyExistsQuery)).WithArgs(42).WillReturnRows(exists(false))
+		assert.ErrorIs(t, r.SetSkill(Skill{UserId: 1, CompetencyId: 42, Level: 2}), ErrCompetencyNotFound)
+	})
+}
@@ -0,0 +1,428 @@
+package skills
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/assessments"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers serves the competency framework and the skills of a store,
+// levelled on scale.
+type Handlers struct {
+	dbc   promo.DBConnexion
+	scale assessments.Scale
+}
+
+func NewHandlers(dbc promo.DBConnexion, scale assessments.Scale) *Handlers {
+	return &Handlers{dbc, scale}
+}
+
+// Register mounts the skill routes on router. POST and PUT routes honour
+// the Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/competencies", promo.Idempotent(h.dbc, h.CreateCompetency)).Methods(http.MethodPost)
+	router.HandleFunc("/competencies", h.GetCompetencies).Methods(http.MethodGet)
+	router.HandleFunc("/competencies/{id:[0-9]+}", h.GetCompetency).Methods(http.MethodGet)
+	router.HandleFunc("/competencies/{id:[0-9]+}", promo.Idempotent(h.dbc, h.UpdateCompetency)).Methods(http.MethodPut)
+	router.HandleFunc("/competencies/{id:[0-9]+}", h.DeleteCompetency).Methods(http.MethodDelete)
+	router.HandleFunc("/users/{id:[0-9]+}/skills", h.GetUserSkills).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id:[0-9]+}/skills/{competency_id:[0-9]+}", promo.Idempotent(h.dbc, h.SetSkill)).Methods(http.MethodPut)
+	router.HandleFunc("/projects/{name}/skills-coverage", h.GetCoverage).Methods(http.MethodGet)
+}
+
+// CompetencyRequest is a competency of the framework to record.
+type CompetencyRequest struct {
+	Name        string              `json:"name" yaml:"name"`
+	Description string              `json:"description" yaml:"description"`
+	Levels      []promo.Expectation `json:"levels" yaml:"levels"`
+}
+
+// SkillRequest is the level a user is assessed at in a competency.
+type SkillRequest struct {
+	Level int `json:"level" yaml:"level"`
+}
+
+// store is the SkillStore of h, answering 501 itself when there is none.
+func (h *Handlers) store(w http.ResponseWriter) (promo.SkillStore, bool) {
+	return promo.Capability[promo.SkillStore](w, h.dbc, ErrNotSupported)
+}
+
+// checkLevel adds to errs a FieldError for field unless level is on the
+// scale of h.
+func (h *Handlers) checkLevel(errs promo.ValidationErrors, field string, level int) promo.ValidationErrors {
+	if level < h.scale.Min || level > h.scale.Max {
+		return append(errs, promo.FieldError{Field: field, Rule: promo.RuleInvalid,
+			Detail: fmt.Sprintf("is not on the scale %s", h.scale)})
+	}
+	return errs
+}
+
+// competency validates req, trimming it, and returns it as a competency. The
+// error lists the failing fields as promo.ValidationErrors.
+func (h *Handlers) competency(req CompetencyRequest) (promo.Competency, error) {
+	c := promo.Competency{Name: strings.TrimSpace(req.Name), Description: strings.TrimSpace(req.Description),
+		Levels: []promo.Expectation{}}
+	var errs promo.ValidationErrors
+	if err := promo.NewValidator(h.dbc).Validate(&c, false); err != nil && !errors.As(err, &errs) {
+		return c, err
+	}
+	grades := make(map[promo.Grade]bool)
+	for _, g := range promo.Grades() {
+		grades[g] = true
+	}
+	seen := make(map[promo.Grade]bool)
+	for i, e := range req.Levels {
+		field := fmt.Sprintf("levels[%d]", i)
+		switch {
+		case !grades[e.Grade]:
+			errs = append(errs, promo.FieldError{Field: field + ".grade", Rule: promo.RuleEnum, Detail: "is not a grade"})
+		case seen[e.Grade]:
+			errs = append(errs, promo.FieldError{Field: field + ".grade", Rule: promo.RuleInvalid, Detail: "is repeated"})
+		}
+		seen[e.Grade] = true
+		errs = h.checkLevel(errs, field+".level", e.Level)
+		c.Levels = append(c.Levels, e)
+	}
+	return c, errs.Err()
+}
+
+// CreateCompetency	 godoc
+//
+//	@Summary		Add competency
+//	@Description	add a competency to the framework with the levels expected of each grade on the rating scale; grades left out expect none
+//	@Tags			skills
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			competency	body		skills.CompetencyRequest	true	"Competency"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		201			{object}	promo.Competency
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		409			{object}	promo.Problem
+//	@Failure		415			{object}	promo.Problem
+//	@Failure		422			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/competencies [post]
+func (h *Handlers) CreateCompetency(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	var req CompetencyRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	c, err := h.competency(req)
+	if err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	id, err := ss.CreateCompetency(c)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	created, err := ss.Competency(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusCreated, created)
+}
+
+// GetCompetencies	 godoc
+//
+//	@Summary		Competency framework
+//	@Description	the competencies of the framework by name, with the levels expected of each grade
+//	@Tags			skills
+//	@Produce		json,application/yaml
+//	@Success		200	{array}		promo.Competency
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/competencies [get]
+func (h *Handlers) GetCompetencies(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	cs, err := ss.Competencies()
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, cs)
+}
+
+// GetCompetency	 godoc
+//
+//	@Summary		Competency
+//	@Tags			skills
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"Competency ID"
+//	@Success		200	{object}	promo.Competency
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/competencies/{id} [get]
+func (h *Handlers) GetCompetency(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	c, err := ss.Competency(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, c)
+}
+
+// UpdateCompetency	 godoc
+//
+//	@Summary		Revise competency
+//	@Description	replace the name, description and expected levels of a competency; the skills of users in it stay
+//	@Tags			skills
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id			path		int							true	"Competency ID"
+//	@Param			competency	body		skills.CompetencyRequest	true	"Competency"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200			{object}	promo.Competency
+//	@Failure		400			{object}	promo.Problem
+//	@Failure		404			{object}	promo.Problem
+//	@Failure		406			{object}	promo.Problem
+//	@Failure		409			{object}	promo.Problem
+//	@Failure		415			{object}	promo.Problem
+//	@Failure		422			{object}	promo.Problem
+//	@Failure		500			{object}	promo.Problem
+//	@Failure		501			{object}	promo.Problem
+//	@Router			/competencies/{id} [put]
+func (h *Handlers) UpdateCompetency(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	var req CompetencyRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	c, err := h.competency(req)
+	if err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	c.Id = id
+	if err := ss.UpdateCompetency(c); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	updated, err := ss.Competency(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, updated)
+}
+
+// DeleteCompetency	 godoc
+//
+//	@Summary		Remove competency
+//	@Description	remove a competency from the framework with the skills of users in it
+//	@Tags			skills
+//	@Param			id	path	int	true	"Competency ID"
+//	@Success		200
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/competencies/{id} [delete]
+func (h *Handlers) DeleteCompetency(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := ss.DeleteCompetency(id); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetUserSkills	 godoc
+//
+//	@Summary		User skills
+//	@Description	the level of a user in each competency against the levels expected of their grade and the next one, and the gaps to it
+//	@Tags			skills
+//	@Produce		json,application/yaml
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	skills.UserSkills
+//	@Failure		400	{object}	promo.Problem
+//	@Failure		404	{object}	promo.Problem
+//	@Failure		406	{object}	promo.Problem
+//	@Failure		500	{object}	promo.Problem
+//	@Failure		501	{object}	promo.Problem
+//	@Router			/users/{id}/skills [get]
+func (h *Handlers) GetUserSkills(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	id, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	u, err := h.dbc.GetUser(id)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	report, err := GapsOf(ss, *u)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, report)
+}
+
+// SetSkill	 godoc
+//
+//	@Summary		Assess skill
+//	@Description	record the level a user is assessed at in a competency, replacing the last one
+//	@Tags			skills
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			id				path		int					true	"User ID"
+//	@Param			competency_id	path		int					true	"Competency ID"
+//	@Param			skill			body		skills.SkillRequest	true	"Level"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200				{object}	promo.Skill
+//	@Failure		400				{object}	promo.Problem
+//	@Failure		404				{object}	promo.Problem
+//	@Failure		406				{object}	promo.Problem
+//	@Failure		415				{object}	promo.Problem
+//	@Failure		422				{object}	promo.Problem
+//	@Failure		500				{object}	promo.Problem
+//	@Failure		501				{object}	promo.Problem
+//	@Router			/users/{id}/skills/{competency_id} [put]
+func (h *Handlers) SetSkill(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	user, ok := promo.PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	competency, ok := promo.PathID(w, r, "competency_id")
+	if !ok {
+		return
+	}
+	var req SkillRequest
+	if !promo.Decode(w, r, &req) {
+		return
+	}
+	if err := h.checkLevel(nil, "level", req.Level).Err(); err != nil {
+		promo.Invalid(w, err)
+		return
+	}
+	s := promo.Skill{UserId: user, CompetencyId: competency, Level: req.Level, AssessedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := ss.SetSkill(s); err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	c, err := ss.Competency(competency)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	s.Competency = c.Name
+	promo.Respond(w, codec, http.StatusOK, s)
+}
+
+// GetCoverage	 godoc
+//
+//	@Summary		Project skills coverage
+//	@Description	how the users of a project cover each competency: how many their grade expects a level of, how many meet it and who does not
+//	@Tags			skills
+//	@Produce		json,application/yaml
+//	@Param			name	path		string	true	"Project name"
+//	@Success		200		{object}	skills.Coverage
+//	@Failure		404		{object}	promo.Problem
+//	@Failure		406		{object}	promo.Problem
+//	@Failure		500		{object}	promo.Problem
+//	@Failure		501		{object}	promo.Problem
+//	@Router			/projects/{name}/skills-coverage [get]
+func (h *Handlers) GetCoverage(w http.ResponseWriter, r *http.Request) {
+	ss, ok := h.store(w)
+	if !ok {
+		return
+	}
+	codec, ok := promo.Negotiate(w, r)
+	if !ok {
+		return
+	}
+	project := mux.Vars(r)["name"]
+	if ps, ok := h.dbc.(promo.ProjectStore); ok {
+		exists, err := ps.ProjectExists(project)
+		if err != nil {
+			promo.DBError(w, err)
+			return
+		}
+		if !exists {
+			promo.Fail(w, fmt.Errorf("no project %q: %w", project, promo.ErrProjectNotFound), http.StatusNotFound)
+			return
+		}
+	}
+	cov, err := CoverageOf(h.dbc, ss, project)
+	if err != nil {
+		promo.DBError(w, err)
+		return
+	}
+	promo.Respond(w, codec, http.StatusOK, cov)
+}
@@ -0,0 +1,147 @@
+// Package skills keeps a competency framework, the levels expected of each
+// grade in competencies such as "system design" or "mentoring", against
+// the levels users were assessed at. It reports the gaps of a user to the
+// next grade and how well the users of a project cover the framework.
+//
+// Levels are on the rating scale of assessments. The framework and skills
+// are kept by stores implementing promo.SkillStore.
+package skills
+
+import (
+	promo "AndersenPromo/internal"
+	"fmt"
+	"time"
+)
+
+// ErrNotSupported is returned against stores keeping no skills.
+var ErrNotSupported = fmt.Errorf("skills are %w", promo.ErrNotSupported)
+
+// Gap is where a user stands in a competency: the level they were
+// assessed at, 0 if never, the level expected of their grade and the
+// level expected of the next one, Target, which Gap is short of.
+type Gap struct {
+	CompetencyId int        `json:"competency_id" yaml:"competency_id"`
+	Competency   string     `json:"competency" yaml:"competency"`
+	Level        int        `json:"level" yaml:"level"`
+	AssessedAt   *time.Time `json:"assessed_at,omitempty" yaml:"assessed_at,omitempty"`
+	Expected     int        `json:"expected" yaml:"expected"`
+	Target       int        `json:"target" yaml:"target"`
+	Gap          int        `json:"gap" yaml:"gap"`
+}
+
+// UserSkills is the gap report of user UserId of Grade to Next, in every
+// competency of the framework by name. Users of the top grade have no next
+// one and are measured against their own. Ready is true when there is no
+// gap.
+type UserSkills struct {
+	UserId int    `json:"user_id" yaml:"user_id"`
+	Grade  string `json:"grade" yaml:"grade"`
+	Next   string `json:"next,omitempty" yaml:"next,omitempty"`
+	Ready  bool   `json:"ready" yaml:"ready"`
+	Skills []Gap  `json:"skills" yaml:"skills"`
+}
+
+// GapsOf is the gap report of u.
+func GapsOf(ss promo.SkillStore, u promo.User) (*UserSkills, error) {
+	framework, err := ss.Competencies()
+	if err != nil {
+		return nil, err
+	}
+	skills, err := ss.Skills(promo.SkillFilter{UserId: u.Id})
+	if err != nil {
+		return nil, err
+	}
+	byCompetency := make(map[int]promo.Skill, len(skills))
+	for _, s := range skills {
+		byCompetency[s.CompetencyId] = s
+	}
+	r := &UserSkills{UserId: u.Id, Grade: u.Position.String(), Ready: true, Skills: make([]Gap, 0, len(framework))}
+	next, ok := u.Position.Next()
+	if ok {
+		r.Next = next.String()
+	} else {
+		next = u.Position
+	}
+	for _, c := range framework {
+		g := Gap{CompetencyId: c.Id, Competency: c.Name, Expected: c.Expected(u.Position), Target: c.Expected(next)}
+		if s, ok := byCompetency[c.Id]; ok {
+			at := s.AssessedAt
+			g.Level, g.AssessedAt = s.Level, &at
+		}
+		if g.Level < g.Target {
+			g.Gap, r.Ready = g.Target-g.Level, false
+		}
+		r.Skills = append(r.Skills, g)
+	}
+	return r, nil
+}
+
+// CompetencyCoverage is how the users of a project cover a competency:
+// Expected is how many of them their grade expects a level of, Meeting how
+// many of those are assessed at it or above and Below who are not, by id.
+// Mean is the mean level of the Assessed ones, 0 when none.
+type CompetencyCoverage struct {
+	CompetencyId int     `json:"competency_id" yaml:"competency_id"`
+	Competency   string  `json:"competency" yaml:"competency"`
+	Expected     int     `json:"expected" yaml:"expected"`
+	Meeting      int     `json:"meeting" yaml:"meeting"`
+	Assessed     int     `json:"assessed" yaml:"assessed"`
+	Mean         float64 `json:"mean" yaml:"mean"`
+	Below        []int   `json:"below" yaml:"below"`
+}
+
+// Coverage is how the Users of Project cover each competency of the
+// framework, by name.
+type Coverage struct {
+	Project      string               `json:"project" yaml:"project"`
+	Users        int                  `json:"users" yaml:"users"`
+	Competencies []CompetencyCoverage `json:"competencies" yaml:"competencies"`
+}
+
+// CoverageOf is the skills coverage of project by its live users.
+func CoverageOf(dbc promo.DBConnexion, ss promo.SkillStore, project string) (*Coverage, error) {
+	users, err := dbc.GetAllUsers(promo.UserFilter{Project: project})
+	if err != nil {
+		return nil, err
+	}
+	framework, err := ss.Competencies()
+	if err != nil {
+		return nil, err
+	}
+	skills, err := ss.Skills(promo.SkillFilter{Project: project})
+	if err != nil {
+		return nil, err
+	}
+	type key struct{ user, competency int }
+	levels := make(map[key]int, len(skills))
+	for _, s := range skills {
+		levels[key{s.UserId, s.CompetencyId}] = s.Level
+	}
+	cov := &Coverage{Project: project, Users: len(*users), Competencies: make([]CompetencyCoverage, 0, len(framework))}
+	for _, c := range framework {
+		cc := CompetencyCoverage{CompetencyId: c.Id, Competency: c.Name, Below: []int{}}
+		sum := 0
+		for _, u := range *users {
+			level, assessed := levels[key{u.Id, c.Id}]
+			if assessed {
+				cc.Assessed++
+				sum += level
+			}
+			expected := c.Expected(u.Position)
+			if expected == 0 {
+				continue
+			}
+			cc.Expected++
+			if level >= expected {
+				cc.Meeting++
+			} else {
+				cc.Below = append(cc.Below, u.Id)
+			}
+		}
+		if cc.Assessed > 0 {
+			cc.Mean = float64(sum) / float64(cc.Assessed)
+		}
+		cov.Competencies = append(cov.Competencies, cc)
+	}
+	return cov, nil
+}
@@ -0,0 +1,165 @@
+package skills
+
+import (
+	promo "AndersenPromo/internal"
+	"AndersenPromo/internal/assessments"
+	"AndersenPromo/internal/promotest"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var at = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+// newStore has a junior, a middle and a senior on Alpha, and a framework
+// of design, expected from middles, and mentoring, from seniors.
+func newStore(t *testing.T) *promo.Memory {
+	m := promotest.NewStore(t,
+		promo.User{Name: "Bob", Surname: "Smith", Position: 2, Project: "Alpha"},
+		promo.User{Name: "Cid", Surname: "Jones", Position: 3, Project: "Alpha"},
+		promo.User{Name: "Ann", Surname: "Boss", Position: 4, Project: "Alpha"})
+	_, err := m.CreateCompetency(promo.Competency{Name: "design", Levels: []promo.Expectation{{Grade: 3, Level: 2}, {Grade: 4, Level: 4}}})
+	require.NoError(t, err)
+	_, err = m.CreateCompetency(promo.Competency{Name: "mentoring", Levels: []promo.Expectation{{Grade: 4, Level: 3}}})
+	require.NoError(t, err)
+	return m
+}
+
+func newRouter(dbc promo.DBConnexion) *mux.Router {
+	return promotest.NewRouter(NewHandlers(dbc, assessments.DefaultScale))
+}
+
+func TestGapsOf(t *testing.T) {
+	m := newStore(t)
+	require.NoError(t, m.SetSkill(promo.Skill{UserId: 2, CompetencyId: 1, Level: 3, AssessedAt: at}))
+	require.NoError(t, m.SetSkill(promo.Skill{UserId: 2, CompetencyId: 2, Level: 3, AssessedAt: at}))
+	u, err := m.GetUser(2)
+	require.NoError(t, err)
+	r, err := GapsOf(m, *u)
+	require.NoError(t, err)
+	assert.Equal(t, &UserSkills{UserId: 2, Grade: "middle", Next: "senior", Skills: []Gap{
+		{CompetencyId: 1, Competency: "design", Level: 3, AssessedAt: &at, Expected: 2, Target: 4, Gap: 1},
+		{CompetencyId: 2, Competency: "mentoring", Level: 3, AssessedAt: &at, Target: 3},
+	}}, r)
+
+	u, err = m.GetUser(3)
+	require.NoError(t, err)
+	r, err = GapsOf(m, *u)
+	require.NoError(t, err)
+	assert.Empty(t, r.Next, "top grade")
+	assert.False(t, r.Ready)
+	assert.Equal(t, []int{4, 3}, []int{r.Skills[0].Gap, r.Skills[1].Gap}, "never assessed")
+}
+
+func TestCoverageOf(t *testing.T) {
+	m := newStore(t)
+	for _, s := range []promo.Skill{
+		{UserId: 1, CompetencyId: 1, Level: 1},
+		{UserId: 2, CompetencyId: 1, Level: 2},
+		{UserId: 3, CompetencyId: 1, Level: 3},
+	} {
+		require.NoError(t, m.SetSkill(s))
+	}
+	c, err := CoverageOf(m, m, "Alpha")
+	require.NoError(t, err)
+	assert.Equal(t, &Coverage{Project: "Alpha", Users: 3, Competencies: []CompetencyCoverage{
+		{CompetencyId: 1, Competency: "design", Expected: 2, Meeting: 1, Assessed: 3, Mean: 2, Below: []int{3}},
+		{CompetencyId: 2, Competency: "mentoring", Expected: 1, Below: []int{3}},
+	}}, c)
+}
+
+func TestHandlers(t *testing.T) {
+	t.Run("Check framework and skills", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		w := promotest.Serve(router, http.MethodPost, "/competencies",
+			`{"name": " testing ", "description": "writes tests", "levels": [{"grade": 2, "level": 2}]}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var c promo.Competency
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, promo.Competency{Id: 3, Name: "testing", Description: "writes tests",
+			Levels: []promo.Expectation{{Grade: 2, Level: 2}}}, c)
+		w = promotest.Serve(router, http.MethodPost, "/competencies", `{"name": "testing"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = promotest.Serve(router, http.MethodPut, "/competencies/3", `{"name": "testing", "levels": [{"grade": 3, "level": 3}]}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		c = promo.Competency{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&c))
+		assert.Equal(t, []promo.Expectation{{Grade: 3, Level: 3}}, c.Levels)
+		assert.Empty(t, c.Description)
+
+		w = promotest.Serve(router, http.MethodPut, "/users/1/skills/3", `{"level": 3}`)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var s promo.Skill
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&s))
+		assert.Equal(t, "testing", s.Competency)
+		w = promotest.Serve(router, http.MethodGet, "/users/1/skills", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var r UserSkills
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&r))
+		assert.Equal(t, "middle", r.Next)
+		assert.False(t, r.Ready, "middles are expected design")
+		require.Len(t, r.Skills, 3)
+		assert.Equal(t, Gap{CompetencyId: 3, Competency: "testing", Level: 3, AssessedAt: r.Skills[2].AssessedAt, Target: 3},
+			r.Skills[2])
+		assert.Equal(t, 2, r.Skills[0].Gap)
+
+		w = promotest.Serve(router, http.MethodGet, "/projects/Alpha/skills-coverage", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var cov Coverage
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&cov))
+		assert.Equal(t, 3, cov.Users)
+		require.Len(t, cov.Competencies, 3)
+		assert.Equal(t, 1, cov.Competencies[2].Expected)
+
+		w = promotest.Serve(router, http.MethodDelete, "/competencies/3", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		w = promotest.Serve(router, http.MethodGet, "/competencies", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var cs []promo.Competency
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&cs))
+		assert.Len(t, cs, 2)
+	})
+	t.Run("Check framework (retried)", func(t *testing.T) {
+		router := newRouter(newStore(t))
+		for _, replayed := range []string{"", "true"} {
+			w := promotest.Serve(router, http.MethodPost, "/competencies", `{"name": "testing"}`, promo.IdempotencyKeyHeader, "k1")
+			assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			assert.Equal(t, replayed, w.Header().Get(promo.IdempotentReplayedHeader))
+		}
+	})
+	t.Run("Check framework (invalid)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(newStore(t)), http.MethodPost, "/competencies",
+			`{"name": " ", "levels": [{"grade": 9, "level": 2}, {"grade": 2, "level": 6}, {"grade": 2, "level": 1}]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p promo.Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		assert.Equal(t, []promo.FieldError{
+			{Field: "name", Rule: promo.RuleRequired, Detail: "is required"},
+			{Field: "levels[0].grade", Rule: promo.RuleEnum, Detail: "is not a grade"},
+			{Field: "levels[1].level", Rule: promo.RuleInvalid, Detail: "is not on the scale 1-5"},
+			{Field: "levels[2].grade", Rule: promo.RuleInvalid, Detail: "is repeated"},
+		}, p.Errors)
+	})
+	for name, req := range map[string]struct{ method, url, body string }{
+		"absent competency": {http.MethodGet, "/competencies/42", ""},
+		"absent user":       {http.MethodGet, "/users/42/skills", ""},
+		"absent project":    {http.MethodGet, "/projects/Omega/skills-coverage", ""},
+		"skill of absent":   {http.MethodPut, "/users/1/skills/42", `{"level": 3}`},
+	} {
+		t.Run("Check skills ("+name+")", func(t *testing.T) {
+			w := promotest.Serve(newRouter(newStore(t)), req.method, req.url, req.body)
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, promo.ProblemContentType, w.Header().Get("Content-Type"))
+		})
+	}
+	t.Run("Check skills (no skills)", func(t *testing.T) {
+		w := promotest.Serve(newRouter(struct{ promo.DBConnexion }{newStore(t)}), http.MethodGet, "/competencies", "")
+		assert.Equal(t, http.StatusNotImplemented, w.Code)
+	})
+}
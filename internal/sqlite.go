@@ -0,0 +1,1659 @@
+package promo
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// SQLite is a DBConnexion in a single SQLite file, for running without a
+// Postgres server. The schema is migrated on open.
+type SQLite struct {
+	db *sql.DB
+}
+
+func sqliteParam(int) string {
+	return "?"
+}
+
+var pgPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// sqliteQuery turns the $n parameters of a query shared with Registry into
+// the ?n of SQLite.
+func sqliteQuery(req string) string {
+	return pgPlaceholder.ReplaceAllString(req, "?$1")
+}
+
+// checkManager tells why manager cannot manage id, nil when it can.
+func checkManager(q interface {
+	QueryRow(string, ...any) *sql.Row
+}, manager, id int) error {
+	var live, cycle bool
+	if err := q.QueryRow(sqliteQuery(managerCheck), manager, id).Scan(&live, &cycle); err != nil {
+		return fmt.Errorf("unable to check manager %d: %w", manager, err)
+	}
+	return managerError(manager, id, live, cycle)
+}
+
+// NewSQLite opens (creating if needed) the database file at path, or a
+// private in-memory database for ":memory:".
+func NewSQLite(path string) (*SQLite, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+	// One connection: SQLite serialises writers anyway, and ":memory:" is
+	// per connection.
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec("PRAGMA foreign_keys = ON; PRAGMA busy_timeout = 5000"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to configure sqlite database %s: %w", path, err)
+	}
+	if err = migrateSQLite(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &SQLite{db}, nil
+}
+
+// migrateSQLite applies, in name order, every embedded migration that is
+// not recorded in schema_migrations yet.
+func migrateSQLite(db *sql.DB) error {
+	if _, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version TEXT PRIMARY KEY)"); err != nil {
+		return fmt.Errorf("unable to create schema_migrations: %w", err)
+	}
+	names, err := fs.Glob(sqliteMigrations, "migrations/sqlite/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		version := strings.TrimSuffix(path.Base(name), ".sql")
+		var n int
+		if err = db.QueryRow("SELECT count(*) FROM schema_migrations WHERE version=?", version).Scan(&n); err != nil {
+			return fmt.Errorf("unable to read schema_migrations: %w", err)
+		}
+		if n > 0 {
+			continue
+		}
+		b, err := sqliteMigrations.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(string(b)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to apply migration %s: %w", version, err)
+		}
+		if _, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES (?)", version); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("unable to record migration %s: %w", version, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("unable to commit migration %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLite) AddUser(name string, surname string, position Grade, project string) error {
+	_, err := s.db.Exec("INSERT INTO usr (name, surname, position, project) VALUES (?, ?, ?, ?)",
+		name, surname, dGrades[position], project)
+	if err != nil {
+		return fmt.Errorf("unable to INSERT INTO usr: %w", err)
+	}
+	return nil
+}
+
+// CreateUser adds u unless it duplicates a user, see Registry.CreateUser.
+func (s *SQLite) CreateUser(u User, force bool) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var id int
+	var field string
+	err = tx.QueryRow(`SELECT id, CASE WHEN email=?1 THEN 'email' WHEN employee_number=?2 THEN 'employee_number' ELSE 'name' END
+FROM usr
+WHERE email=?1 OR employee_number=?2
+	OR (NOT ?3 AND deleted_at IS NULL AND lower(name)=lower(?4) AND lower(surname)=lower(?5))
+ORDER BY id LIMIT 1`, nullable(u.Email), nullable(u.EmployeeNumber), force, u.Name, u.Surname).Scan(&id, &field)
+	if err == nil {
+		return 0, &DuplicateError{Id: id, Field: field}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("unable to look for duplicates: %w", err)
+	}
+	if u.ManagerId != 0 {
+		if err = checkManager(tx, u.ManagerId, 0); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.Exec(`INSERT INTO usr (name, surname, position, project, email, employee_number, hire_date, location, manager_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		u.Name, u.Surname, dGrades[u.Position], u.Project, nullable(u.Email), nullable(u.EmployeeNumber),
+		nullable(u.HireDate), nullable(u.Location), nullableId(u.ManagerId))
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO usr: %w", err)
+	}
+	n, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get the id of the new user: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit user: %w", err)
+	}
+	return int(n), nil
+}
+
+// MergeUsers folds user from into user into, see Registry.MergeUsers.
+func (s *SQLite) MergeUsers(into, from int) error {
+	if into == from {
+		return fmt.Errorf("unable to merge user %d into itself", into)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var project sql.NullString
+	var email, number, hired, location *string
+	var manager *int
+	err = tx.QueryRow(`SELECT project, email, employee_number, hire_date, location, manager_id
+FROM usr WHERE id=? AND deleted_at IS NULL`, from).Scan(&project, &email, &number, &hired, &location, &manager)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("unable to merge user %d: %w", from, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to merge user %d: %w", from, err)
+	}
+	if manager != nil && *manager == into {
+		manager = nil
+	}
+	// Unique keys have to leave from before into can take them.
+	_, err = tx.Exec("UPDATE usr SET email=NULL, employee_number=NULL, deleted_at=? WHERE id=?", time.Now().UTC(), from)
+	if err != nil {
+		return fmt.Errorf("unable to merge user %d: %w", from, err)
+	}
+	res, err := tx.Exec(`UPDATE usr SET project=CASE WHEN COALESCE(project, '')='' THEN ? ELSE project END,
+email=COALESCE(email, ?), employee_number=COALESCE(employee_number, ?), hire_date=COALESCE(hire_date, ?),
+location=COALESCE(location, ?), manager_id=COALESCE(manager_id, ?)
+WHERE id=? AND deleted_at IS NULL`, project.String, email, number, hired, location, manager, into)
+	if err != nil {
+		return fmt.Errorf("unable to merge into user %d: %w", into, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("unable to merge into user %d: %w", into, ErrNotFound)
+	}
+	_, err = tx.Exec("UPDATE usr SET manager_id=CASE WHEN id=?1 THEN ?3 ELSE ?1 END WHERE manager_id=?2",
+		into, from, manager)
+	if err != nil {
+		return fmt.Errorf("unable to move the reports of user %d: %w", from, err)
+	}
+	var cycle bool
+	if err = tx.QueryRow(sqliteQuery(managesSelf), into).Scan(&cycle); err != nil {
+		return fmt.Errorf("unable to check the managers of user %d: %w", into, err)
+	}
+	if cycle {
+		return fmt.Errorf("user %d would manage themselves once merged: %w", into, ErrInvalidManager)
+	}
+	// The first version of into is read beforehand, as SQLite would see the
+	// versions moved while updating.
+	var first sql.NullString
+	if err = tx.QueryRow("SELECT MIN(valid_from) FROM usr_history WHERE id=?", into).Scan(&first); err != nil {
+		return fmt.Errorf("unable to move the history of user %d: %w", from, err)
+	}
+	if first.Valid {
+		_, err = tx.Exec(`UPDATE usr_history SET id=?1, valid_to=CASE WHEN valid_to IS NULL OR valid_to>?3 THEN ?3 ELSE valid_to END
+WHERE id=?2 AND valid_from<?3`, into, from, first.String)
+		if err != nil {
+			return fmt.Errorf("unable to move the history of user %d: %w", from, err)
+		}
+	}
+	for _, ref := range userRefs {
+		for _, q := range ref.mergeQueries() {
+			if _, err = tx.Exec(sqliteQuery(q), into, from); err != nil {
+				return fmt.Errorf("unable to move %s.%s to user %d: %w", ref.table, ref.column, into, err)
+			}
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit merge: %w", err)
+	}
+	return nil
+}
+
+// Reports lists the users at most depth levels under id, see
+// Registry.Reports.
+func (s *SQLite) Reports(id, depth int) ([]Report, error) {
+	return s.walkOrg(reportsQuery, id, depth)
+}
+
+// Chain lists the managers of id up to the top, see Registry.Chain.
+func (s *SQLite) Chain(id int) ([]Report, error) {
+	return s.walkOrg(chainQuery, id, maxOrgDepth)
+}
+
+func (s *SQLite) walkOrg(req string, id, depth int) ([]Report, error) {
+	rows, err := s.db.Query(sqliteQuery(req), id, depth)
+	if err != nil {
+		return nil, fmt.Errorf("unable to walk the hierarchy from user %d: %w", id, err)
+	}
+	defer rows.Close()
+	var out []Report
+	for rows.Next() {
+		var rep Report
+		var pos string
+		if err = rows.Scan(append(userFields(&rep.User, &pos), &rep.Depth)...); err != nil {
+			return nil, fmt.Errorf("unable to walk the hierarchy from user %d: %w", id, err)
+		}
+		rep.Position = bGrades[pos]
+		out = append(out, rep)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to walk the hierarchy from user %d: %w", id, err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
+	return out[1:], nil
+}
+
+// AddUsers inserts all of us or none of them.
+func (s *SQLite) AddUsers(us []User) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	stmt, err := tx.Prepare(`INSERT INTO usr (name, surname, position, project, email, employee_number, hire_date,
+	location, manager_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("unable to prepare INSERT INTO usr: %w", err)
+	}
+	defer stmt.Close()
+	for _, u := range us {
+		if _, err = stmt.Exec(u.Name, u.Surname, dGrades[u.Position], u.Project, nullable(u.Email),
+			nullable(u.EmployeeNumber), nullable(u.HireDate), nullable(u.Location), nullableId(u.ManagerId)); err != nil {
+			return fmt.Errorf("unable to INSERT INTO usr: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit users: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser only marks the user deleted, see RestoreUser and PurgeDeleted.
+func (s *SQLite) DeleteUser(id int) error {
+	res, err := s.db.Exec("UPDATE usr SET deleted_at=? WHERE id=? AND deleted_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("unable to soft delete user: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no rows affected when attempt to delete user with id %v: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) RestoreUser(id int) error {
+	res, err := s.db.Exec("UPDATE usr SET deleted_at=NULL WHERE id=? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("unable to restore user: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no deleted user to restore with id %v: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// PurgeDeleted removes for good the users deleted before t.
+func (s *SQLite) PurgeDeleted(t time.Time) (int, error) {
+	res, err := s.db.Exec("DELETE FROM usr WHERE deleted_at < ?", t.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("unable to DELETE FROM usr: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (s *SQLite) UpdateUser(id int, m map[string]string) error {
+	return s.updateUser(id, nil, m)
+}
+
+// UpdateUserIf is UpdateUser while user id still is was, see
+// ConditionalUpdater.
+func (s *SQLite) UpdateUserIf(id int, was User, m map[string]string) error {
+	return s.updateUser(id, &was, m)
+}
+
+// updateUser is UpdateUser, only updating user id while it is was when
+// was is set.
+func (s *SQLite) updateUser(id int, was *User, m map[string]string) error {
+	fields := append([]string{"name", "surname", "position", "project"}, profileColumns...)
+	for k := range m {
+		if !slices.Contains(fields, k) {
+			return fmt.Errorf("illegal key in the map")
+		}
+	}
+	var set []string
+	var args []any
+	for _, k := range fields {
+		if v, ok := m[k]; ok {
+			set = append(set, k+"=?")
+			args = append(args, columnArg(k, v))
+		}
+	}
+	args = append(args, id)
+	if m["email"] != "" || m["employee_number"] != "" {
+		var other int
+		var field string
+		err := s.db.QueryRow(sqliteQuery(keyConflict), nullable(m["email"]), nullable(m["employee_number"]), id).
+			Scan(&other, &field)
+		if err == nil {
+			return &DuplicateError{Id: other, Field: field}
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("unable to look for duplicates: %w", err)
+		}
+	}
+	if v := m["manager_id"]; v != "" {
+		manager, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("illegal manager_id %q", v)
+		}
+		if err = checkManager(s.db, manager, id); err != nil {
+			return err
+		}
+	}
+	req := fmt.Sprintf("UPDATE usr SET %s WHERE id=? AND deleted_at IS NULL", strings.Join(set, ","))
+	if was != nil {
+		for i, v := range userValues(*was) {
+			req += " AND " + userMatch[i] + "=?"
+			args = append(args, v)
+		}
+	}
+	res, err := s.db.Exec(req, args...)
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE usr: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 && was != nil {
+		return fmt.Errorf("user %d changed or was deleted meanwhile: %w", id, ErrPreconditionFailed)
+	}
+	if n == 0 {
+		return fmt.Errorf("no rows affected while attempting to UPDATE usr with id %v: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) GetUser(id int) (*User, error) {
+	u := &User{}
+	var pos string
+	err := s.db.QueryRow("SELECT "+userColumns+" FROM usr WHERE id=? AND deleted_at IS NULL", id).
+		Scan(userFields(u, &pos)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get user with id %d: %w", id, err)
+	}
+	u.Position = bGrades[pos]
+	return u, nil
+}
+
+// UserAsOf is user id as it was at t, see HistoryStore.
+func (s *SQLite) UserAsOf(id int, t time.Time) (*User, error) {
+	u := &User{}
+	var pos string
+	err := s.db.QueryRow(sqliteQuery(userAsOfQuery), id, t.UTC()).Scan(userFields(u, &pos)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unable to get user with id %d as of %s: %w", id, t.Format(time.RFC3339), ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get user with id %d as of %s: %w", id, t.Format(time.RFC3339), err)
+	}
+	u.Position = bGrades[pos]
+	return u, nil
+}
+
+// UsersAsOf lists the users live at t, see HistoryStore.
+func (s *SQLite) UsersAsOf(f UserFilter, t time.Time) ([]User, error) {
+	req, args := historyQuery(f, t.UTC())
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT users FROM usr_history: %w", err)
+	}
+	defer rows.Close()
+	us := make([]User, 0)
+	for rows.Next() {
+		var u User
+		var pos string
+		if err = rows.Scan(userFields(&u, &pos)...); err != nil {
+			return nil, fmt.Errorf("unable to SELECT users FROM usr_history: %w", err)
+		}
+		u.Position = bGrades[pos]
+		us = append(us, u)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT users FROM usr_history: %w", err)
+	}
+	return us, nil
+}
+
+// History lists the versions of user id, see HistoryStore.
+func (s *SQLite) History(id int) ([]Version, error) {
+	rows, err := s.db.Query(sqliteQuery(userHistoryQuery), id, time.Time{}.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT history of user %d: %w", id, err)
+	}
+	defer rows.Close()
+	vs := make([]Version, 0)
+	for rows.Next() {
+		var v Version
+		var pos string
+		// The driver only reads columns declared TIMESTAMP as times, not
+		// the CASE of valid_from.
+		var from sql.NullString
+		var to sql.NullTime
+		if err = rows.Scan(append(userFields(&v.User, &pos), &from, &to)...); err != nil {
+			return nil, fmt.Errorf("unable to SELECT history of user %d: %w", id, err)
+		}
+		v.Position = bGrades[pos]
+		if from.Valid {
+			t, err := sqliteTime(from.String)
+			if err != nil {
+				return nil, fmt.Errorf("unable to SELECT history of user %d: %w", id, err)
+			}
+			v.ValidFrom = &t
+		}
+		if to.Valid {
+			v.ValidTo = &to.Time
+		}
+		vs = append(vs, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT history of user %d: %w", id, err)
+	}
+	return vs, nil
+}
+
+// sqliteTime reads a time as the driver writes it, or a date.
+func sqliteTime(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("illegal time %q", s)
+	}
+	return t, nil
+}
+
+func (s *SQLite) GetAllUsers(f UserFilter) (*[]User, error) {
+	us := make([]User, 0)
+	err := s.EachUser(f, func(u User) error {
+		us = append(us, u)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &us, nil
+}
+
+// EachUser hands the users matching f to fn one by one and stops at the
+// first error fn returns. The only connection is busy meanwhile, so fn must
+// not call back into s.
+func (s *SQLite) EachUser(f UserFilter, fn func(User) error) error {
+	return s.EachUserContext(context.Background(), f, fn)
+}
+
+// EachUserContext is EachUser, the query being cancelled with ctx.
+func (s *SQLite) EachUserContext(ctx context.Context, f UserFilter, fn func(User) error) error {
+	req, args := userQuery(f, sqliteParam)
+	rows, err := s.db.QueryContext(ctx, req, args...)
+	if err != nil {
+		return fmt.Errorf("unable to SELECT all users FROM usr: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var u User
+		var pos string
+		var deletedAt sql.NullTime
+		dest := userFields(&u, &pos)
+		if f.IncludeDeleted {
+			dest = append(dest, &deletedAt)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return fmt.Errorf("unable to convert request into names list: %w", err)
+		}
+		u.Position = bGrades[pos]
+		if deletedAt.Valid {
+			u.DeletedAt = &deletedAt.Time
+		}
+		// Rows read ahead are not checked against ctx by the driver.
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = fn(u); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("unable to convert request into names list: %w", err)
+	}
+	return nil
+}
+
+// BeginIdempotent claims key unless a live claim exists, in which case the
+// claim is returned. An expired claim is taken over in the same statement.
+func (s *SQLite) BeginIdempotent(key, fingerprint string, expires time.Time) (*IdempotentResponse, error) {
+	now := time.Now().UTC()
+	res, err := s.db.Exec(`INSERT INTO idempotency_key (key, fingerprint, expires_at) VALUES (?, ?, ?)
+ON CONFLICT (key) DO UPDATE SET fingerprint=excluded.fingerprint, status=0, content_type='', body=NULL, expires_at=excluded.expires_at
+WHERE idempotency_key.expires_at < ?`, key, fingerprint, expires.UTC(), now)
+	if err != nil {
+		return nil, fmt.Errorf("unable to INSERT INTO idempotency_key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+	// With a single connection nothing can remove the row in between.
+	resp := &IdempotentResponse{}
+	err = s.db.QueryRow("SELECT fingerprint, status, content_type, body FROM idempotency_key WHERE key=?", key).
+		Scan(&resp.Fingerprint, &resp.Status, &resp.ContentType, &resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get idempotency key: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *SQLite) FinishIdempotent(key string, resp IdempotentResponse, expires time.Time) error {
+	_, err := s.db.Exec("UPDATE idempotency_key SET status=?, content_type=?, body=?, expires_at=? WHERE key=?",
+		resp.Status, resp.ContentType, resp.Body, expires.UTC(), key)
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE idempotency_key: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) ReleaseIdempotent(key string) error {
+	_, err := s.db.Exec("DELETE FROM idempotency_key WHERE key=? AND status=0", key)
+	if err != nil {
+		return fmt.Errorf("unable to DELETE FROM idempotency_key: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) PurgeIdempotent(t time.Time) (int, error) {
+	res, err := s.db.Exec("DELETE FROM idempotency_key WHERE expires_at < ?", t.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("unable to DELETE FROM idempotency_key: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func (s *SQLite) Projects() ([]string, error) {
+	rows, err := s.db.Query(projectsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM project: %w", err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var n string
+		if err = rows.Scan(&n); err != nil {
+			return nil, fmt.Errorf("unable to scan project: %w", err)
+		}
+		names = append(names, n)
+	}
+	return names, rows.Err()
+}
+
+func (s *SQLite) AddProject(name string) error {
+	res, err := s.db.Exec(sqliteQuery(addProjectQuery), name)
+	if err != nil {
+		return fmt.Errorf("unable to INSERT INTO project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("unable to add project %q: %w", name, ErrProjectExists)
+	}
+	return nil
+}
+
+func (s *SQLite) ProjectExists(name string) (bool, error) {
+	var ok bool
+	if err := s.db.QueryRow(sqliteQuery(projectExistsQuery), name).Scan(&ok); err != nil {
+		return false, fmt.Errorf("unable to check project %q: %w", name, err)
+	}
+	return ok, nil
+}
+
+func (s *SQLite) Aggregate(ctx context.Context, query string, args []any, row func(scan func(dest ...any) error) error) error {
+	rows, err := s.db.QueryContext(ctx, sqliteQuery(query), args...)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err = row(rows.Scan); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("unable to aggregate: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) CreateCycle(c Cycle) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var id int
+	if err = tx.QueryRow(sqliteQuery(createCycleQuery), c.Name, c.OpensAt.UTC(), c.ClosesAt.UTC()).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO review_cycle: %w", err)
+	}
+	for _, p := range c.Projects {
+		if _, err = tx.Exec(sqliteQuery(cycleProjectQuery), id, p); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO review_cycle_project: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit review cycle: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Cycle(id int) (*Cycle, error) {
+	cs, err := s.cycles(cycleQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, cycleError(id, nil, CycleScheduled)
+	}
+	return &cs[0], nil
+}
+
+func (s *SQLite) Cycles() ([]Cycle, error) {
+	return s.cycles(cyclesQuery)
+}
+
+// cycles runs cyclesQuery or cycleQuery and adds the projects of the cycles.
+func (s *SQLite) cycles(req string, args ...any) ([]Cycle, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle: %w", err)
+	}
+	defer rows.Close()
+	cs := make([]Cycle, 0)
+	byId := make(map[int]int)
+	for rows.Next() {
+		c := Cycle{Projects: []string{}}
+		if err = rows.Scan(&c.Id, &c.Name, &c.OpensAt, &c.ClosesAt, &c.Status); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM review_cycle: %w", err)
+		}
+		byId[c.Id] = len(cs)
+		cs = append(cs, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle: %w", err)
+	}
+	if len(cs) == 0 {
+		return cs, nil
+	}
+	prows, err := s.db.Query(sqliteQuery(cycleProjects))
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle_project: %w", err)
+	}
+	defer prows.Close()
+	for prows.Next() {
+		var id int
+		var p string
+		if err = prows.Scan(&id, &p); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM review_cycle_project: %w", err)
+		}
+		if i, ok := byId[id]; ok {
+			cs[i].Projects = append(cs[i].Projects, p)
+		}
+	}
+	if err = prows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review_cycle_project: %w", err)
+	}
+	return cs, nil
+}
+
+// cycleError tells why cycle id was not in status want.
+func (s *SQLite) cycleError(id int, want CycleStatus) error {
+	c, err := s.Cycle(id)
+	if errors.Is(err, ErrCycleNotFound) {
+		return cycleError(id, nil, want)
+	}
+	if err != nil {
+		return err
+	}
+	return cycleError(id, c, want)
+}
+
+func (s *SQLite) OpenCycle(id int, rs []Review) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.Exec(sqliteQuery(setCycleStatus), id, CycleOpen, CycleScheduled)
+	if err != nil {
+		return fmt.Errorf("unable to open review cycle %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		_ = tx.Rollback()
+		return s.cycleError(id, CycleScheduled)
+	}
+	for _, rv := range rs {
+		if _, err = tx.Exec(sqliteQuery(enrolQuery), id, rv.UserId, dGrades[rv.From], dGrades[rv.To]); err != nil {
+			return fmt.Errorf("unable to enrol user %d: %w", rv.UserId, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit review cycle %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *SQLite) Reviews(id int) ([]Review, error) {
+	if _, err := s.Cycle(id); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(sqliteQuery(reviewsQuery), id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review: %w", err)
+	}
+	defer rows.Close()
+	rs := make([]Review, 0)
+	for rows.Next() {
+		var rv Review
+		var from, to string
+		if err = rows.Scan(&rv.CycleId, &rv.UserId, &from, &to, &rv.Status, &rv.Comment); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM review: %w", err)
+		}
+		rv.From, rv.To = bGrades[from], bGrades[to]
+		rs = append(rs, rv)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM review: %w", err)
+	}
+	return rs, nil
+}
+
+func (s *SQLite) SetReview(id, user int, status ReviewStatus, comment string) error {
+	res, err := s.db.Exec(sqliteQuery(setReviewQuery), id, user, status, nullable(comment))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE review: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err = s.cycleError(id, CycleOpen); err != nil {
+			return err
+		}
+		return fmt.Errorf("user %d is not enrolled in review cycle %d: %w", user, id, ErrReviewNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) CloseCycle(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.Exec(sqliteQuery(setCycleStatus), id, CycleClosed, CycleOpen)
+	if err != nil {
+		return fmt.Errorf("unable to close review cycle %d: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		_ = tx.Rollback()
+		return s.cycleError(id, CycleOpen)
+	}
+	for _, req := range closeCycleQueries {
+		if _, err = tx.Exec(sqliteQuery(req), id); err != nil {
+			return fmt.Errorf("unable to apply the promotions of review cycle %d: %w", id, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit review cycle %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *SQLite) AddAssessment(a Assessment) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var ok bool
+	if err = tx.QueryRow(sqliteQuery(cycleExists), a.CycleId).Scan(&ok); err != nil {
+		return 0, fmt.Errorf("unable to look for review cycle %d: %w", a.CycleId, err)
+	}
+	if !ok {
+		return 0, fmt.Errorf("unable to get review cycle %d: %w", a.CycleId, ErrCycleNotFound)
+	}
+	var pos, project string
+	err = tx.QueryRow(sqliteQuery(assessmentUser), a.UserId).Scan(&pos, &project)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("no user to assess with id %d: %w", a.UserId, ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to get user %d: %w", a.UserId, err)
+	}
+	if a.ReviewerId != 0 {
+		var rp, rproject string
+		err = tx.QueryRow(sqliteQuery(assessmentUser), a.ReviewerId).Scan(&rp, &rproject)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("no reviewer with id %d: %w", a.ReviewerId, ErrNotFound)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to get reviewer %d: %w", a.ReviewerId, err)
+		}
+	}
+	if err = tx.QueryRow(sqliteQuery(calibrated), a.CycleId, project).Scan(&ok); err != nil {
+		return 0, fmt.Errorf("unable to look for calibration: %w", err)
+	}
+	if ok {
+		return 0, fmt.Errorf("%s is calibrated in review cycle %d: %w", project, a.CycleId, ErrAssessmentLocked)
+	}
+	if err = tx.QueryRow(sqliteQuery(assessmentExists), a.CycleId, a.UserId, a.ReviewerId).Scan(&ok); err != nil {
+		return 0, fmt.Errorf("unable to look for assessments: %w", err)
+	}
+	if ok {
+		return 0, fmt.Errorf("user %d is already assessed by %d in review cycle %d: %w", a.UserId, a.ReviewerId, a.CycleId, ErrAssessmentExists)
+	}
+	var id int
+	err = tx.QueryRow(sqliteQuery(addAssessment), a.CycleId, a.UserId, nullableId(a.ReviewerId), pos, project, a.Rating,
+		nullable(a.Summary)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO assessment: %w", err)
+	}
+	for c, score := range a.Scores {
+		if _, err = tx.Exec(sqliteQuery(addScore), id, c, score); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO assessment_score: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit assessment: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Assessment(id int) (*Assessment, error) {
+	as, err := s.assessments(assessmentQueries(AssessmentFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(as) == 0 {
+		return nil, fmt.Errorf("no assessment with id %d: %w", id, ErrAssessmentNotFound)
+	}
+	return &as[0], nil
+}
+
+func (s *SQLite) Assessments(f AssessmentFilter) ([]Assessment, error) {
+	return s.assessments(assessmentQueries(f, 0))
+}
+
+// assessments runs the queries of assessmentQueries.
+func (s *SQLite) assessments(req, scores string, args []any) ([]Assessment, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment: %w", err)
+	}
+	defer rows.Close()
+	as := make([]Assessment, 0)
+	byId := make(map[int]int)
+	for rows.Next() {
+		a := Assessment{Scores: map[string]int{}}
+		var grade string
+		if err = rows.Scan(assessmentFields(&a, &grade)...); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM assessment: %w", err)
+		}
+		a.Grade = bGrades[grade]
+		byId[a.Id] = len(as)
+		as = append(as, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment: %w", err)
+	}
+	if len(as) == 0 {
+		return as, nil
+	}
+	srows, err := s.db.Query(sqliteQuery(scores), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment_score: %w", err)
+	}
+	defer srows.Close()
+	for srows.Next() {
+		var id, score int
+		var c string
+		if err = srows.Scan(&id, &c, &score); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM assessment_score: %w", err)
+		}
+		if i, ok := byId[id]; ok {
+			as[i].Scores[c] = score
+		}
+	}
+	if err = srows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM assessment_score: %w", err)
+	}
+	return as, nil
+}
+
+func (s *SQLite) UpdateAssessment(id, rating int, scores map[string]int, summary string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.Exec(sqliteQuery(updateAssessment), id, rating, nullable(summary))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE assessment: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		_ = tx.Rollback()
+		if _, err = s.Assessment(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to update assessment %d: %w", id, ErrAssessmentLocked)
+	}
+	if _, err = tx.Exec(sqliteQuery(deleteScores), id); err != nil {
+		return fmt.Errorf("unable to DELETE FROM assessment_score: %w", err)
+	}
+	for c, score := range scores {
+		if _, err = tx.Exec(sqliteQuery(addScore), id, c, score); err != nil {
+			return fmt.Errorf("unable to INSERT INTO assessment_score: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit assessment %d: %w", id, err)
+	}
+	return nil
+}
+
+func (s *SQLite) Calibrate(cycle int, project string) error {
+	var ok bool
+	if err := s.db.QueryRow(sqliteQuery(cycleExists), cycle).Scan(&ok); err != nil {
+		return fmt.Errorf("unable to look for review cycle %d: %w", cycle, err)
+	}
+	if !ok {
+		return fmt.Errorf("unable to get review cycle %d: %w", cycle, ErrCycleNotFound)
+	}
+	res, err := s.db.Exec(sqliteQuery(calibrate), cycle, project, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("unable to INSERT INTO calibration: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("%s is already calibrated in review cycle %d: %w", project, cycle, ErrAssessmentLocked)
+	}
+	return nil
+}
+
+func (s *SQLite) Calibrated(cycle int, project string) (bool, error) {
+	var ok bool
+	if err := s.db.QueryRow(sqliteQuery(calibrated), cycle, project).Scan(&ok); err != nil {
+		return false, fmt.Errorf("unable to look for calibration: %w", err)
+	}
+	return ok, nil
+}
+
+func (s *SQLite) CreateFeedback(f Feedback, managerKey string, keys map[int]string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var id int
+	err = tx.QueryRow(sqliteQuery(createFeedbackQuery), f.UserId, nullableId(f.ManagerId), managerKey, f.DueAt.UTC()).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO feedback: %w", err)
+	}
+	for _, rs := range f.Respondents {
+		if _, err = tx.Exec(sqliteQuery(addRespondentQuery), id, rs.UserId, rs.Relation, keys[rs.UserId]); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO feedback_respondent: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit feedback: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Feedback(id int) (*Feedback, error) {
+	fs, err := s.feedbacks(feedbackQueries(id, 0))
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 {
+		return nil, fmt.Errorf("no feedback with id %d: %w", id, ErrFeedbackNotFound)
+	}
+	return &fs[0], nil
+}
+
+func (s *SQLite) Feedbacks(user int) ([]Feedback, error) {
+	return s.feedbacks(feedbackQueries(0, user))
+}
+
+// feedbacks runs the queries of feedbackQueries.
+func (s *SQLite) feedbacks(req, respondents string, args []any) ([]Feedback, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback: %w", err)
+	}
+	defer rows.Close()
+	fs := make([]Feedback, 0)
+	byId := make(map[int]int)
+	for rows.Next() {
+		f := Feedback{Respondents: []Respondent{}}
+		if err = rows.Scan(&f.Id, &f.UserId, &f.ManagerId, &f.DueAt); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM feedback: %w", err)
+		}
+		byId[f.Id] = len(fs)
+		fs = append(fs, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback: %w", err)
+	}
+	if len(fs) == 0 {
+		return fs, nil
+	}
+	rrows, err := s.db.Query(sqliteQuery(respondents), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	defer rrows.Close()
+	for rrows.Next() {
+		var id int
+		var rs Respondent
+		var reminded sql.NullTime
+		if err = rrows.Scan(&id, &rs.UserId, &rs.Relation, &rs.Answered, &reminded); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+		}
+		if reminded.Valid {
+			rs.RemindedAt = &reminded.Time
+		}
+		if i, ok := byId[id]; ok {
+			fs[i].Respondents = append(fs[i].Respondents, rs)
+		}
+	}
+	if err = rrows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	return fs, nil
+}
+
+func (s *SQLite) FeedbackKey(id int, key string) (int, error) {
+	var respondent int
+	err := s.db.QueryRow(sqliteQuery(feedbackKeyQuery), id, key).Scan(&respondent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("feedback %d: %w", id, ErrFeedbackKey)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("unable to check feedback key: %w", err)
+	}
+	return respondent, nil
+}
+
+func (s *SQLite) FeedbackResponse(id, respondent int) (*FeedbackResponse, error) {
+	var answered bool
+	var resp FeedbackResponse
+	err := s.db.QueryRow(sqliteQuery(feedbackResponseQuery), id, respondent).
+		Scan(&answered, &resp.Rating, &resp.Strengths, &resp.Improvements, &resp.Anonymous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	if !answered {
+		return nil, nil
+	}
+	return &resp, nil
+}
+
+func (s *SQLite) Respond(id, respondent int, resp FeedbackResponse) error {
+	res, err := s.db.Exec(sqliteQuery(respondQuery), id, respondent, resp.Rating, nullable(resp.Strengths),
+		nullable(resp.Improvements), resp.Anonymous, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE feedback_respondent: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) FeedbackAnswers(id int) ([]FeedbackAnswer, error) {
+	rows, err := s.db.Query(sqliteQuery(feedbackAnswersQuery), id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	defer rows.Close()
+	as := make([]FeedbackAnswer, 0)
+	for rows.Next() {
+		var a FeedbackAnswer
+		if err = rows.Scan(&a.RespondentId, &a.Relation, &a.Rating, &a.Strengths, &a.Improvements, &a.Anonymous); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+		}
+		as = append(as, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM feedback_respondent: %w", err)
+	}
+	return as, nil
+}
+
+func (s *SQLite) Remind(id, respondent int, at time.Time) error {
+	res, err := s.db.Exec(sqliteQuery(remindQuery), id, respondent, at.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE feedback_respondent: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("user %d is not asked for feedback %d: %w", respondent, id, ErrFeedbackNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) CreateCompetency(c Competency) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var taken bool
+	if err = tx.QueryRow(sqliteQuery(competencyNamedQuery), c.Name, 0).Scan(&taken); err != nil {
+		return 0, fmt.Errorf("unable to look for competency %q: %w", c.Name, err)
+	}
+	if taken {
+		return 0, fmt.Errorf("competency %q: %w", c.Name, ErrCompetencyExists)
+	}
+	var id int
+	if err = tx.QueryRow(sqliteQuery(createCompetencyQuery), c.Name, nullable(c.Description)).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO competency: %w", err)
+	}
+	for _, e := range c.Levels {
+		if _, err = tx.Exec(sqliteQuery(addLevelQuery), id, dGrades[e.Grade], e.Level); err != nil {
+			return 0, fmt.Errorf("unable to INSERT INTO competency_level: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit competency: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Competency(id int) (*Competency, error) {
+	cs, err := s.competencies(competencyQueries(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("no competency with id %d: %w", id, ErrCompetencyNotFound)
+	}
+	return &cs[0], nil
+}
+
+func (s *SQLite) Competencies() ([]Competency, error) {
+	return s.competencies(competencyQueries(0))
+}
+
+// competencies runs the queries of competencyQueries.
+func (s *SQLite) competencies(req, levels string, args []any) ([]Competency, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency: %w", err)
+	}
+	defer rows.Close()
+	cs := make([]Competency, 0)
+	byId := make(map[int]int)
+	for rows.Next() {
+		c := Competency{Levels: []Expectation{}}
+		if err = rows.Scan(&c.Id, &c.Name, &c.Description); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM competency: %w", err)
+		}
+		byId[c.Id] = len(cs)
+		cs = append(cs, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency: %w", err)
+	}
+	if len(cs) == 0 {
+		return cs, nil
+	}
+	lrows, err := s.db.Query(sqliteQuery(levels), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency_level: %w", err)
+	}
+	defer lrows.Close()
+	for lrows.Next() {
+		var id, level int
+		var grade string
+		if err = lrows.Scan(&id, &grade, &level); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM competency_level: %w", err)
+		}
+		if i, ok := byId[id]; ok {
+			cs[i].Levels = append(cs[i].Levels, Expectation{Grade: bGrades[grade], Level: level})
+		}
+	}
+	if err = lrows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM competency_level: %w", err)
+	}
+	for i := range cs {
+		sortLevels(&cs[i])
+	}
+	return cs, nil
+}
+
+func (s *SQLite) UpdateCompetency(c Competency) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var taken bool
+	if err = tx.QueryRow(sqliteQuery(competencyNamedQuery), c.Name, c.Id).Scan(&taken); err != nil {
+		return fmt.Errorf("unable to look for competency %q: %w", c.Name, err)
+	}
+	if taken {
+		return fmt.Errorf("competency %q: %w", c.Name, ErrCompetencyExists)
+	}
+	res, err := tx.Exec(sqliteQuery(updateCompetencyQuery), c.Id, c.Name, nullable(c.Description))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE competency: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("no competency with id %d: %w", c.Id, ErrCompetencyNotFound)
+	}
+	if _, err = tx.Exec(sqliteQuery(deleteLevelsQuery), c.Id); err != nil {
+		return fmt.Errorf("unable to DELETE FROM competency_level: %w", err)
+	}
+	for _, e := range c.Levels {
+		if _, err = tx.Exec(sqliteQuery(addLevelQuery), c.Id, dGrades[e.Grade], e.Level); err != nil {
+			return fmt.Errorf("unable to INSERT INTO competency_level: %w", err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit competency %d: %w", c.Id, err)
+	}
+	return nil
+}
+
+func (s *SQLite) DeleteCompetency(id int) error {
+	res, err := s.db.Exec(sqliteQuery(deleteCompetencyQuery), id)
+	if err != nil {
+		return fmt.Errorf("unable to DELETE FROM competency: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("no competency with id %d: %w", id, ErrCompetencyNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) SetSkill(sk Skill) error {
+	var ok bool
+	if err := s.db.QueryRow(sqliteQuery(liveUserQuery), sk.UserId).Scan(&ok); err != nil {
+		return fmt.Errorf("unable to get user %d: %w", sk.UserId, err)
+	}
+	if !ok {
+		return fmt.Errorf("no user with id %d: %w", sk.UserId, ErrNotFound)
+	}
+	if err := s.db.QueryRow(sqliteQuery(competencyExistsQuery), sk.CompetencyId).Scan(&ok); err != nil {
+		return fmt.Errorf("unable to look for competency %d: %w", sk.CompetencyId, err)
+	}
+	if !ok {
+		return fmt.Errorf("no competency with id %d: %w", sk.CompetencyId, ErrCompetencyNotFound)
+	}
+	if _, err := s.db.Exec(sqliteQuery(setSkillQuery), sk.UserId, sk.CompetencyId, sk.Level, sk.AssessedAt.UTC()); err != nil {
+		return fmt.Errorf("unable to INSERT INTO user_skill: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) Skills(f SkillFilter) ([]Skill, error) {
+	req, args := skillsQuery(f)
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM user_skill: %w", err)
+	}
+	defer rows.Close()
+	ss := make([]Skill, 0)
+	for rows.Next() {
+		var sk Skill
+		if err = rows.Scan(&sk.UserId, &sk.CompetencyId, &sk.Competency, &sk.Level, &sk.AssessedAt); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM user_skill: %w", err)
+		}
+		ss = append(ss, sk)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM user_skill: %w", err)
+	}
+	return ss, nil
+}
+
+func (s *SQLite) AddMentorship(m Mentorship) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var grades [2]Grade
+	for i, id := range []int{m.MentorId, m.MenteeId} {
+		var pos, project string
+		err = tx.QueryRow(sqliteQuery(assessmentUser), id).Scan(&pos, &project)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("no user with id %d: %w", id, ErrNotFound)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("unable to get user %d: %w", id, err)
+		}
+		grades[i] = bGrades[pos]
+	}
+	if grades[0] <= grades[1] {
+		return 0, fmt.Errorf("user %d cannot mentor user %d: %w", m.MentorId, m.MenteeId, ErrInvalidMentor)
+	}
+	var running bool
+	if err = tx.QueryRow(sqliteQuery(mentorshipRunningQuery), m.MentorId, m.MenteeId, m.StartsAt.UTC()).Scan(&running); err != nil {
+		return 0, fmt.Errorf("unable to look for mentorships: %w", err)
+	}
+	if running {
+		return 0, fmt.Errorf("user %d already mentors user %d: %w", m.MentorId, m.MenteeId, ErrMentorshipExists)
+	}
+	var ends any
+	if m.EndsAt != nil {
+		ends = m.EndsAt.UTC()
+	}
+	var id int
+	err = tx.QueryRow(sqliteQuery(addMentorshipQuery), m.MentorId, m.MenteeId, m.StartsAt.UTC(), ends, nullable(m.Goals)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO mentorship: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit mentorship: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Mentorship(id int) (*Mentorship, error) {
+	ms, err := s.mentorships(mentorshipsQuery(MentorshipFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(ms) == 0 {
+		return nil, fmt.Errorf("no mentorship with id %d: %w", id, ErrMentorshipNotFound)
+	}
+	return &ms[0], nil
+}
+
+func (s *SQLite) Mentorships(f MentorshipFilter) ([]Mentorship, error) {
+	return s.mentorships(mentorshipsQuery(f, 0))
+}
+
+// mentorships runs a query of mentorshipsQuery.
+func (s *SQLite) mentorships(req string, args []any) ([]Mentorship, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM mentorship: %w", err)
+	}
+	defer rows.Close()
+	ms := make([]Mentorship, 0)
+	for rows.Next() {
+		var m Mentorship
+		var ends sql.NullTime
+		if err = rows.Scan(&m.Id, &m.MentorId, &m.MenteeId, &m.StartsAt, &ends, &m.Goals); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM mentorship: %w", err)
+		}
+		if ends.Valid {
+			m.EndsAt = &ends.Time
+		}
+		ms = append(ms, m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM mentorship: %w", err)
+	}
+	return ms, nil
+}
+
+func (s *SQLite) EndMentorship(id int, at time.Time) error {
+	res, err := s.db.Exec(sqliteQuery(endMentorshipQuery), id, at.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE mentorship: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if _, err = s.Mentorship(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("unable to end mentorship %d: %w", id, ErrMentorshipState)
+	}
+	return nil
+}
+
+func (s *SQLite) AddGoal(g Goal) (int, error) {
+	var live bool
+	if err := s.db.QueryRow(sqliteQuery(liveUserQuery), g.UserId).Scan(&live); err != nil {
+		return 0, fmt.Errorf("unable to get user %d: %w", g.UserId, err)
+	}
+	if !live {
+		return 0, fmt.Errorf("no user with id %d: %w", g.UserId, ErrNotFound)
+	}
+	var id int
+	err := s.db.QueryRow(sqliteQuery(addGoalQuery), g.UserId, g.Title, nullable(g.Description), nullable(dGrades[g.TargetGrade]),
+		nullable(g.DueDate), string(g.Status)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO goal: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Goal(id int) (*Goal, error) {
+	gs, err := s.goals(goalQueries(GoalFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(gs) == 0 {
+		return nil, fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	return &gs[0], nil
+}
+
+func (s *SQLite) Goals(f GoalFilter) ([]Goal, error) {
+	return s.goals(goalQueries(f, 0))
+}
+
+// goals runs the queries of goalQueries.
+func (s *SQLite) goals(req, notes string, args []any) ([]Goal, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal: %w", err)
+	}
+	defer rows.Close()
+	gs := make([]Goal, 0)
+	byId := make(map[int]int)
+	for rows.Next() {
+		g := Goal{Notes: []GoalNote{}}
+		var grade, status string
+		if err = rows.Scan(&g.Id, &g.UserId, &g.Title, &g.Description, &grade, &g.DueDate, &status); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM goal: %w", err)
+		}
+		g.TargetGrade, g.Status = bGrades[grade], GoalStatus(status)
+		byId[g.Id] = len(gs)
+		gs = append(gs, g)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal: %w", err)
+	}
+	if len(gs) == 0 {
+		return gs, nil
+	}
+	nrows, err := s.db.Query(sqliteQuery(notes), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal_note: %w", err)
+	}
+	defer nrows.Close()
+	for nrows.Next() {
+		var id int
+		var n GoalNote
+		if err = nrows.Scan(&id, &n.Note, &n.At); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM goal_note: %w", err)
+		}
+		if i, ok := byId[id]; ok {
+			gs[i].Notes = append(gs[i].Notes, n)
+		}
+	}
+	if err = nrows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM goal_note: %w", err)
+	}
+	return gs, nil
+}
+
+func (s *SQLite) UpdateGoal(g Goal) error {
+	res, err := s.db.Exec(sqliteQuery(updateGoalQuery), g.Id, g.Title, nullable(g.Description),
+		nullable(dGrades[g.TargetGrade]), nullable(g.DueDate), string(g.Status))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE goal: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("no goal with id %d: %w", g.Id, ErrGoalNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) AddGoalNote(id int, n GoalNote) error {
+	var exists bool
+	if err := s.db.QueryRow(sqliteQuery(goalExistsQuery), id).Scan(&exists); err != nil {
+		return fmt.Errorf("unable to get goal %d: %w", id, err)
+	}
+	if !exists {
+		return fmt.Errorf("no goal with id %d: %w", id, ErrGoalNotFound)
+	}
+	if _, err := s.db.Exec(sqliteQuery(addGoalNoteQuery), id, n.Note, n.At.UTC()); err != nil {
+		return fmt.Errorf("unable to INSERT INTO goal_note: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLite) AddCertification(c Certification) (int, error) {
+	var live bool
+	if err := s.db.QueryRow(sqliteQuery(liveUserQuery), c.UserId).Scan(&live); err != nil {
+		return 0, fmt.Errorf("unable to get user %d: %w", c.UserId, err)
+	}
+	if !live {
+		return 0, fmt.Errorf("no user with id %d: %w", c.UserId, ErrNotFound)
+	}
+	var id int
+	err := s.db.QueryRow(sqliteQuery(addCertificationQuery), c.UserId, string(c.Kind), c.Name, c.Issuer, c.IssuedOn,
+		nullable(c.ExpiresOn), nullable(c.Evidence)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to INSERT INTO certification: %w", err)
+	}
+	return id, nil
+}
+
+func (s *SQLite) Certification(id int) (*Certification, error) {
+	cs, err := s.certifications(certificationsQuery(CertificationFilter{}, id))
+	if err != nil {
+		return nil, err
+	}
+	if len(cs) == 0 {
+		return nil, fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	return &cs[0], nil
+}
+
+func (s *SQLite) Certifications(f CertificationFilter) ([]Certification, error) {
+	return s.certifications(certificationsQuery(f, 0))
+}
+
+// certifications runs a query of certificationsQuery.
+func (s *SQLite) certifications(req string, args []any) ([]Certification, error) {
+	rows, err := s.db.Query(sqliteQuery(req), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM certification: %w", err)
+	}
+	defer rows.Close()
+	cs := make([]Certification, 0)
+	for rows.Next() {
+		var c Certification
+		var kind string
+		var flagged sql.NullTime
+		if err = rows.Scan(&c.Id, &c.UserId, &kind, &c.Name, &c.Issuer, &c.IssuedOn, &c.ExpiresOn, &c.Evidence, &flagged); err != nil {
+			return nil, fmt.Errorf("unable to SELECT FROM certification: %w", err)
+		}
+		c.Kind = CertificationKind(kind)
+		if flagged.Valid {
+			c.FlaggedAt = &flagged.Time
+		}
+		cs = append(cs, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to SELECT FROM certification: %w", err)
+	}
+	return cs, nil
+}
+
+func (s *SQLite) UpdateCertification(c Certification) error {
+	res, err := s.db.Exec(sqliteQuery(updateCertificationQuery), c.Id, string(c.Kind), c.Name, c.Issuer, c.IssuedOn,
+		nullable(c.ExpiresOn), nullable(c.Evidence))
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE certification: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("no certification with id %d: %w", c.Id, ErrCertificationNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) DeleteCertification(id int) error {
+	res, err := s.db.Exec(sqliteQuery(deleteCertificationQuery), id)
+	if err != nil {
+		return fmt.Errorf("unable to DELETE FROM certification: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	return nil
+}
+
+func (s *SQLite) FlagCertification(id int, at time.Time) error {
+	res, err := s.db.Exec(sqliteQuery(flagCertificationQuery), id, at.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to UPDATE certification: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return fmt.Errorf("no certification with id %d: %w", id, ErrCertificationNotFound)
+	}
+	return nil
+}
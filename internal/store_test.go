@@ -0,0 +1,59 @@
+package promo
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStore(t *testing.T) {
+	t.Run("Check memory scheme", func(t *testing.T) {
+		s, err := NewStore("memory://")
+		require.NoError(t, err)
+		assert.IsType(t, &Memory{}, s)
+	})
+	t.Run("Check sqlite scheme (migrated once)", func(t *testing.T) {
+		dsn := "sqlite://" + filepath.Join(t.TempDir(), "registry.db")
+		s, err := NewStore(dsn)
+		require.NoError(t, err)
+		require.IsType(t, &SQLite{}, s)
+		require.NoError(t, s.AddUser("And", "Ersen", middle, "Test"))
+		require.NoError(t, s.(*SQLite).Close())
+
+		s, err = NewStore(dsn)
+		require.NoError(t, err)
+		defer s.(*SQLite).Close()
+		u, err := s.GetUser(1)
+		require.NoError(t, err)
+		assert.Equal(t, "And", u.Name)
+	})
+	t.Run("Check sqlite rejects unknown grades", func(t *testing.T) {
+		s, err := NewStore("sqlite://:memory:")
+		require.NoError(t, err)
+		defer s.(*SQLite).Close()
+		assert.Error(t, s.AddUser("And", "Ersen", Grade(9), "Test"))
+	})
+	t.Run("Check unknown scheme", func(t *testing.T) {
+		_, err := NewStore("mysql://localhost/registry")
+		assert.Error(t, err)
+		_, err = NewStore("registry.db")
+		assert.Error(t, err)
+	})
+}
+
+// newMockRegistry is a Registry over a mock pool whose expectations have to
+// be met by the end of the test.
+func newMockRegistry(t *testing.T) (*Registry, pgxmock.PgxPoolIface) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening mock", err)
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.Close()
+	})
+	return &Registry{mock}, mock
+}
@@ -0,0 +1,303 @@
+package promo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Validation rule codes, for clients to tell failures apart.
+const (
+	RuleRequired  = "required"
+	RuleTooLong   = "too_long"
+	RuleTooSmall  = "too_small"
+	RuleCharset   = "charset"
+	RuleFormat    = "format"
+	RuleEnum      = "enum"
+	RulePattern   = "pattern"
+	RuleEmail     = "email"
+	RuleDate      = "date"
+	RuleReference = "reference"
+	RuleDuplicate = "duplicate"
+	RuleInvalid   = "invalid"
+)
+
+// FieldError tells which field of a request broke which rule.
+type FieldError struct {
+	Field  string `json:"field" yaml:"field"`
+	Rule   string `json:"rule" yaml:"rule"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+// ValidationErrors lists every FieldError of a request.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	s := make([]string, len(e))
+	for i, fe := range e {
+		s[i] = fe.Field + ": " + fe.Detail
+	}
+	return strings.Join(s, "; ")
+}
+
+// Err is e as an error, nil when it lists no failure.
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Request types declare their rules in validate tags, comma separated:
+//
+//	required      the field is not its zero value
+//	max=N         a string has at most N characters, a number is at most N
+//	min=N         a number is at least N
+//	name          a person's name, see checkName
+//	email         a bare email address
+//	date          a date written as DateLayout
+//	pattern=NAME  a string matches patterns[NAME]
+//	enum=NAME     the value is one of enums[NAME]
+//	ref=NAME      the value names an existing entity, see Validator.refs
+//
+// Rules other than required are skipped for zero values. Types needing
+// rules across fields implement FieldChecker.
+
+var patterns = map[string]*regexp.Regexp{
+	"employee_number": regexp.MustCompile(`^\S+$`),
+}
+
+var enums = map[string]func(reflect.Value) bool{
+	"grade": func(v reflect.Value) bool { return dGrades[Grade(v.Int())] != "" },
+}
+
+// FieldChecker is implemented by request types with rules that tags cannot
+// declare, whose errors Validate appends to those of the tags.
+type FieldChecker interface {
+	CheckFields() ValidationErrors
+}
+
+// Validator checks request types against their rules. refs tells whether
+// an entity exists, by entity name.
+type Validator struct {
+	refs map[string]func(string) (bool, error)
+}
+
+// NewValidator checks references against dbc, skipping those to entities
+// it does not keep.
+func NewValidator(dbc DBConnexion) Validator {
+	v := Validator{refs: map[string]func(string) (bool, error){}}
+	if ps, ok := dbc.(ProjectStore); ok {
+		v.refs["project"] = ps.ProjectExists
+	}
+	return v
+}
+
+type fieldRules struct {
+	index int
+	name  string
+	rules []rule
+}
+
+type rule struct {
+	name, arg string
+}
+
+var rulesCache sync.Map
+
+// rulesOf parses the validate tags of struct type t once.
+func rulesOf(t reflect.Type) []fieldRules {
+	if fr, ok := rulesCache.Load(t); ok {
+		return fr.([]fieldRules)
+	}
+	var frs []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fr := fieldRules{index: i, name: name}
+		for _, r := range strings.Split(tag, ",") {
+			n, arg, _ := strings.Cut(r, "=")
+			fr.rules = append(fr.rules, rule{n, arg})
+		}
+		frs = append(frs, fr)
+	}
+	rulesCache.Store(t, frs)
+	return frs
+}
+
+// Validate checks v, a struct or a pointer to one, against the rules its
+// fields declare. A partial v, such as an update, only has its non-zero
+// fields checked. It returns ValidationErrors listing every failing field,
+// or another error when a reference could not be checked.
+func (vr Validator) Validate(v any, partial bool) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	var errs ValidationErrors
+	for _, fr := range rulesOf(rv.Type()) {
+		fv := rv.Field(fr.index)
+		if fv.IsZero() {
+			if !partial && slicesContainsRule(fr.rules, "required") {
+				errs = append(errs, FieldError{fr.name, RuleRequired, "is required"})
+			}
+			continue
+		}
+		for _, r := range fr.rules {
+			fe, err := vr.check(fr.name, r, fv)
+			if err != nil {
+				return err
+			}
+			if fe != nil {
+				errs = append(errs, *fe)
+				break
+			}
+		}
+	}
+	if fc, ok := v.(FieldChecker); ok {
+		errs = append(errs, fc.CheckFields()...)
+	}
+	return errs.Err()
+}
+
+func slicesContainsRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// check applies r to the non-zero value v of field.
+func (vr Validator) check(field string, r rule, v reflect.Value) (*FieldError, error) {
+	switch r.name {
+	case "required":
+	case "max":
+		n, _ := strconv.Atoi(r.arg)
+		switch v.Kind() {
+		case reflect.String:
+			if utf8.RuneCountInString(v.String()) > n {
+				return &FieldError{field, RuleTooLong, fmt.Sprintf("is longer than %d characters", n)}, nil
+			}
+		default:
+			if v.Int() > int64(n) {
+				return &FieldError{field, RuleTooLong, fmt.Sprintf("is more than %d", n)}, nil
+			}
+		}
+	case "min":
+		n, _ := strconv.Atoi(r.arg)
+		if v.Int() < int64(n) {
+			return &FieldError{field, RuleTooSmall, fmt.Sprintf("is less than %d", n)}, nil
+		}
+	case "name":
+		return checkName(field, v.String()), nil
+	case "email":
+		if a, err := mail.ParseAddress(v.String()); err != nil || a.Address != v.String() {
+			return &FieldError{field, RuleEmail, "is not an email address"}, nil
+		}
+	case "date":
+		if _, err := time.Parse(DateLayout, v.String()); err != nil {
+			return &FieldError{field, RuleDate, "is not a date written " + DateLayout}, nil
+		}
+	case "pattern":
+		if !patterns[r.arg].MatchString(v.String()) {
+			return &FieldError{field, RulePattern, "is not a valid " + strings.ReplaceAll(r.arg, "_", " ")}, nil
+		}
+	case "enum":
+		if !enums[r.arg](v) {
+			return &FieldError{field, RuleEnum, "is not a known " + r.arg}, nil
+		}
+	case "ref":
+		exists, ok := vr.refs[r.arg]
+		if !ok {
+			return nil, nil
+		}
+		found, err := exists(v.String())
+		if err != nil {
+			return nil, fmt.Errorf("unable to check %s %q: %w", r.arg, v.String(), err)
+		}
+		if !found {
+			return &FieldError{field, RuleReference, fmt.Sprintf("is not an existing %s", r.arg)}, nil
+		}
+	default:
+		panic(fmt.Sprintf("unknown validation rule %q", r.name))
+	}
+	return nil, nil
+}
+
+// maxNameLength bounds names and surnames, in characters.
+const maxNameLength = 64
+
+// normalizeName puts a name in NFC, trims it and collapses inner spaces, so
+// that the same name is always stored the same way.
+func normalizeName(s string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(s)), " ")
+}
+
+func isNameSeparator(r rune) bool {
+	return r == ' ' || r == '-' || r == '\'' || r == '’'
+}
+
+// checkName tells why s, normalised, is not a valid name for field. Names
+// are letters, possibly joined by single spaces, hyphens or apostrophes.
+func checkName(field, s string) *FieldError {
+	switch {
+	case s == "":
+		return &FieldError{field, RuleRequired, "is required"}
+	case utf8.RuneCountInString(s) > maxNameLength:
+		return &FieldError{field, RuleTooLong, fmt.Sprintf("is longer than %d characters", maxNameLength)}
+	}
+	prevSep := true
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+			prevSep = false
+		case unicode.Is(unicode.Mn, r) && !prevSep:
+		case isNameSeparator(r):
+			if prevSep {
+				return &FieldError{field, RuleFormat, "must start with a letter and not repeat separators"}
+			}
+			prevSep = true
+		default:
+			return &FieldError{field, RuleCharset, "may only hold letters, spaces, hyphens and apostrophes"}
+		}
+	}
+	if prevSep {
+		return &FieldError{field, RuleFormat, "must end with a letter"}
+	}
+	return nil
+}
+
+// normalizeUser normalises the names of u in place.
+func normalizeUser(u *User) {
+	u.Name = normalizeName(u.Name)
+	u.Surname = normalizeName(u.Surname)
+}
+
+// Invalid answers 422 listing the failing fields for ValidationErrors, and
+// reports any other err as a store failure.
+func Invalid(w http.ResponseWriter, err error) {
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		DBError(w, err)
+		return
+	}
+	WriteProblem(w, Problem{Title: http.StatusText(http.StatusUnprocessableEntity), Status: http.StatusUnprocessableEntity,
+		Detail: err.Error(), Errors: errs})
+}
@@ -0,0 +1,79 @@
+package promo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckName(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		rule string
+	}{
+		{"Łukasz", ""},
+		{"Jose\u0301", ""},
+		{"Anne-Marie", ""},
+		{"O'Brien", ""},
+		{"O’Brien", ""},
+		{"Mary Ann", ""},
+		{"Zoë", ""},
+		{"Дмитрий", ""},
+		{"", RuleRequired},
+		{strings.Repeat("a", maxNameLength+1), RuleTooLong},
+		{"A1d", RuleCharset},
+		{"Er^en", RuleCharset},
+		{"-Ann", RuleFormat},
+		{"Ann--Marie", RuleFormat},
+		{"Ann-", RuleFormat},
+	} {
+		fe := checkName("name", normalizeName(c.name))
+		if c.rule == "" {
+			assert.Nil(t, fe, c.name)
+			continue
+		}
+		if assert.NotNil(t, fe, c.name) {
+			assert.Equal(t, c.rule, fe.Rule, c.name)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "Jos\u00e9", normalizeName("Jose\u0301"), "composed to NFC")
+	assert.Equal(t, "Mary Ann", normalizeName("  Mary \t Ann "))
+}
+
+func TestHandlers_CreateUser_Validation(t *testing.T) {
+	t.Run("Check creating user (unicode names)", func(t *testing.T) {
+		h := &Handlers{NewMemory()}
+		body := strings.NewReader(`{"name": " Łukasz ", "surname": "O'Brien-Wójcik", "position": 1}`)
+		req := httptest.NewRequest(http.MethodPost, "/create", body)
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		u, err := h.dbc.GetUser(1)
+		require.NoError(t, err)
+		assert.Equal(t, "Łukasz", u.Name)
+		assert.Equal(t, "O'Brien-Wójcik", u.Surname)
+	})
+	t.Run("Check creating user (field errors)", func(t *testing.T) {
+		h := &Handlers{NewMemory()}
+		body := strings.NewReader(`{"name": "A1d", "surname": "", "position": 8}`)
+		req := httptest.NewRequest(http.MethodPost, "/create", body)
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+		got := make([]string, len(p.Errors))
+		for i, fe := range p.Errors {
+			got[i] = fe.Field + " " + fe.Rule
+		}
+		assert.Equal(t, []string{"name charset", "surname required", "position enum"}, got)
+	})
+}
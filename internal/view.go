@@ -1,31 +1,115 @@
 package promo
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/gorilla/mux"
-	"io"
 	"log"
 	"net/http"
-	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Handlers struct {
 	dbc DBConnexion
 }
 
+// NewHandlers serves the store connString points at, see NewStore.
 func NewHandlers(connString string) (*Handlers, error) {
-	r, err := NewRegistry(connString)
+	dbc, err := NewStore(connString)
 	if err != nil {
-		log.Fatalf("Failed to create Registry: %v", err)
+		return nil, fmt.Errorf("failed to create store: %w", err)
 	}
-	return &Handlers{r}, nil
+	return &Handlers{dbc}, nil
 }
 
-func isText(u User) bool {
-	re := regexp.MustCompile(`^[a-zA-Z]+$`)
-	return re.MatchString(u.Name) && re.MatchString(u.Surname)
+// NewHandlersWith serves any DBConnexion, e.g. a stub in tests.
+func NewHandlersWith(dbc DBConnexion) *Handlers {
+	return &Handlers{dbc}
+}
+
+// Register mounts the API routes on router. POST and PATCH routes honour
+// the Idempotency-Key header.
+func (h *Handlers) Register(router *mux.Router) {
+	router.HandleFunc("/healthcheck", h.HealthCheck).Methods(http.MethodGet)
+	router.HandleFunc("/create", Idempotent(h.dbc, h.CreateUser)).Methods(http.MethodPost)
+	router.HandleFunc("/delete/{id}", h.DeleteUser).Methods(http.MethodDelete)
+	router.HandleFunc("/update/{id}", Idempotent(h.dbc, h.UpdateUser)).Methods(http.MethodPatch)
+	router.HandleFunc("/get/{id}", h.GetUser).Methods(http.MethodGet)
+	router.HandleFunc("/getall", h.GetUserList).Methods(http.MethodGet)
+	router.HandleFunc("/users/import", Idempotent(h.dbc, h.ImportUsers)).Methods(http.MethodPost)
+	router.HandleFunc("/users/export", h.ExportUsers).Methods(http.MethodGet)
+	router.HandleFunc("/users/duplicates", h.Duplicates).Methods(http.MethodGet)
+	router.HandleFunc("/users/search", h.SearchUsers).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id}/restore", Idempotent(h.dbc, h.RestoreUser)).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id}/merge", Idempotent(h.dbc, h.MergeUsers)).Methods(http.MethodPost)
+	router.HandleFunc("/users/{id}/reports", h.Reports).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id}/chain", h.Chain).Methods(http.MethodGet)
+	router.HandleFunc("/users", h.GetUserList).Methods(http.MethodGet)
+	router.HandleFunc("/users/{id:[0-9]+}", h.GetUser).Methods(http.MethodGet)
+	router.HandleFunc("/orgchart", h.OrgChart).Methods(http.MethodGet)
+	router.HandleFunc("/projects", h.GetProjects).Methods(http.MethodGet)
+	router.HandleFunc("/projects", Idempotent(h.dbc, h.CreateProject)).Methods(http.MethodPost)
+}
+
+// parseFilter reads the list filters shared by every endpoint returning users.
+func parseFilter(r *http.Request) (UserFilter, error) {
+	f := UserFilter{Project: r.URL.Query().Get("project")}
+	if g := r.URL.Query().Get("grade"); g != "" {
+		pos, err := ParseGrade(g)
+		if err != nil {
+			return f, err
+		}
+		f.Position = pos
+	}
+	if d := r.URL.Query().Get("include_deleted"); d != "" {
+		inc, err := strconv.ParseBool(d)
+		if err != nil {
+			return f, fmt.Errorf("illegal include_deleted %q", d)
+		}
+		f.IncludeDeleted = inc
+	}
+	q := r.URL.Query()
+	f.Email = strings.ToLower(strings.TrimSpace(q.Get("email")))
+	f.EmployeeNumber = strings.TrimSpace(q.Get("employee_number"))
+	f.Location = q.Get("location")
+	if m := q.Get("manager_id"); m != "" {
+		id, err := strconv.Atoi(m)
+		if err != nil || id <= 0 {
+			return f, fmt.Errorf("illegal manager_id %q", m)
+		}
+		f.ManagerId = id
+	}
+	for _, d := range []struct {
+		name string
+		dst  *string
+	}{{"hired_from", &f.HiredFrom}, {"hired_to", &f.HiredTo}} {
+		v := q.Get(d.name)
+		if v == "" {
+			continue
+		}
+		if _, err := time.Parse(DateLayout, v); err != nil {
+			return f, fmt.Errorf("illegal %s %q, want %s", d.name, v, DateLayout)
+		}
+		*d.dst = v
+	}
+	return f, nil
+}
+
+// PathID reads the route variable name holding an id, answering 400 itself
+// when it is missing or malformed.
+func PathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	v := mux.Vars(r)[name]
+	if v == "" {
+		Error(w, "empty "+name, http.StatusBadRequest)
+		return 0, false
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil {
+		Error(w, fmt.Sprintf("illegal %s %q", name, v), http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
 }
 
 // HealthCheck	 godoc
@@ -43,68 +127,80 @@ func (h *Handlers) HealthCheck(w http.ResponseWriter, r *http.Request) {
 // CreateUser	 godoc
 //
 //	@Summary		Create new user
-//	@Description	set new user
+//	@Description	set new user, unless it duplicates one: same email, employee number or (unless forced) name
 //	@Tags			users
-//	@Accept			json
+//	@Accept			json,application/yaml
+//	@Produce		json,application/yaml
+//	@Param			force	query	bool	false	"Create even if a user has the same name"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
 //	@Success		200	{object}	User
-//	@Failure		500	{object}	string
+//	@Failure		400	{object}	Problem
+//	@Failure		406	{object}	Problem
+//	@Failure		409	{object}	Problem
+//	@Failure		415	{object}	Problem
+//	@Failure		422	{object}	Problem
+//	@Failure		500	{object}	Problem
 //	@Router			/create [post]
 func (h *Handlers) CreateUser(w http.ResponseWriter, r *http.Request) {
 	//log.Println("Trying to create")
-	b, _ := io.ReadAll(r.Body)
 	var u User
-	err := json.Unmarshal(b, &u)
+	err := DecodeBody(r, &u)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		BodyError(w, err)
 		return
 	}
 
-	if !isText(u) {
-		http.Error(w, "invalid name and/or surname", http.StatusBadRequest)
+	normalizeUser(&u)
+	normalizeProfile(&u)
+	if err = NewValidator(h.dbc).Validate(u, false); err != nil {
+		Invalid(w, err)
 		return
 	}
-	if dGrades[u.Position] == "" {
-		http.Error(w, "illegal position", http.StatusBadRequest)
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+	c, ok := negotiate(w, r, writeCodecs)
+	if !ok {
 		return
 	}
 
-	err = h.dbc.AddUser(u.Name, u.Surname, u.Position, u.Project)
+	u.Id, err = h.dbc.CreateUser(u, force)
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
+		DBError(w, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	u.DeletedAt = nil
+	Respond(w, c, http.StatusOK, &u)
 }
 
 // DeleteUser	 godoc
 //
 //	@Summary		Delete user
-//	@Description	remove user
+//	@Description	mark user deleted, it can be restored until purged
 //	@Tags			users
 //	@Param			id	path	int	true	"User ID"
 //	@Success		200
-//	@Failure		400				{object}	string
-//	@Failure		500				{object}	string
+//	@Failure		400				{object}	Problem
+//	@Failure		404				{object}	Problem
+//	@Failure		500				{object}	Problem
 //	@Router			/delete/{id}	[delete]
 func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	//log.Println("Trying to delete", id)
 	if id == "" {
-		http.Error(w, "empty index", http.StatusBadRequest)
+		Error(w, "empty index", http.StatusBadRequest)
 		return
 	}
 	val, err := strconv.Atoi(id)
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
 		return
 	}
 
 	err = h.dbc.DeleteUser(val)
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
+		DBError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -113,39 +209,49 @@ func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 // UpdateUser	 godoc
 //
 //	@Summary		Update user
-//	@Description	change user
+//	@Description	change user; null email, employee_number, hire_date, location or manager_id clear them
 //	@Tags			users
-//	@Accept			json
+//	@Accept			json,application/yaml
 //	@Param			id				path		int	true	"User ID"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Param			If-Match		header	string	false	"Only update while the user has this ETag"
 //	@Success		200				{object}	User
-//	@Failure		400				{object}	string
-//	@Failure		500				{object}	string
+//	@Failure		400				{object}	Problem
+//	@Failure		404				{object}	Problem
+//	@Failure		409				{object}	Problem
+//	@Failure		412				{object}	Problem
+//	@Failure		415				{object}	Problem
+//	@Failure		422				{object}	Problem
+//	@Failure		500				{object}	Problem
+//	@Failure		501				{object}	Problem
 //	@Router			/update/{id}	[patch]
 func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	//log.Println("Trying to update", id)
 
 	if id == "" {
-		http.Error(w, "empty index", http.StatusBadRequest)
+		Error(w, "empty index", http.StatusBadRequest)
 		return
 	}
 	val, err := strconv.Atoi(id)
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
 		return
 	}
-	b, _ := io.ReadAll(r.Body)
 	var u User
-	err = json.Unmarshal(b, &u)
+	present, err := decodePatch(r, &u)
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		BodyError(w, err)
 		return
 	}
 
-	if !isText(u) {
-		http.Error(w, "invalid name and/or surname", http.StatusBadRequest)
+	normalizeUser(&u)
+	normalizeProfile(&u)
+	u.Id = val
+	if err = NewValidator(h.dbc).Validate(u, true); err != nil {
+		Invalid(w, err)
 		return
 	}
 	m := make(map[string]string)
@@ -159,13 +265,66 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if dGrades[u.Position] != "" {
 		m["position"] = dGrades[u.Position]
 	}
-	if u.Name != "" {
+	if u.Project != "" {
 		m["project"] = u.Project
 	}
-	err = h.dbc.UpdateUser(val, m)
+	profileMap(u, present, m)
+	if len(m) == 0 {
+		Error(w, "nothing to update", http.StatusBadRequest)
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" {
+		cu, ok := h.dbc.(ConditionalUpdater)
+		if !ok {
+			Error(w, "If-Match is not supported by this store", http.StatusNotImplemented)
+			return
+		}
+		err = updateUserIf(h.dbc, cu, val, match, m)
+	} else {
+		err = h.dbc.UpdateUser(val, m)
+	}
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
+		DBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// updateUserIf sets m on user id of dbc while its ETag is match, * matching
+// any live user.
+func updateUserIf(dbc DBConnexion, cu ConditionalUpdater, id int, match string, m map[string]string) error {
+	was, err := dbc.GetUser(id)
+	if err != nil {
+		return err
+	}
+	if match != "*" && match != UserETag(*was) {
+		return fmt.Errorf("user %d does not match %s: %w", id, match, ErrPreconditionFailed)
+	}
+	return cu.UpdateUserIf(id, *was, m)
+}
+
+// RestoreUser	 godoc
+//
+//	@Summary		Restore user
+//	@Description	undo the deletion of a user not purged yet
+//	@Tags			users
+//	@Param			id	path	int	true	"User ID"
+//	@Param			Idempotency-Key	header	string	false	"Replay the first response sent with this key"
+//	@Success		200
+//	@Failure		400						{object}	Problem
+//	@Failure		404						{object}	Problem
+//	@Failure		409						{object}	Problem
+//	@Failure		422						{object}	Problem
+//	@Failure		500						{object}	Problem
+//	@Router			/users/{id}/restore	[post]
+func (h *Handlers) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := PathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := h.dbc.RestoreUser(id); err != nil {
+		DBError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -174,59 +333,124 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 // GetUser	 	 godoc
 //
 //	@Summary		Get user
-//	@Description	get user by id
+//	@Description	get user by id, as it is or as it was at as_of
 //	@Tags			users
-//	@Produce		json
+//	@Produce		json,application/yaml,xml,text/csv
 //	@Param			id			path		int	true	"User ID"
+//	@Param			as_of		query		string	false	"Past time, RFC 3339, or date, YYYY-MM-DD, standing for its midnight UTC"
 //	@Success		200			{object}	User
-//	@Failure		400			{object}	string
-//	@Failure		500			{object}	string
+//	@Header			200			{string}	ETag	"Tag of the user as it is, for If-Match"
+//	@Failure		400			{object}	Problem
+//	@Failure		404			{object}	Problem
+//	@Failure		406			{object}	Problem
+//	@Failure		500			{object}	Problem
+//	@Failure		501			{object}	Problem
 //	@Router			/get/{id}																						[get]
+//	@Router			/users/{id}	[get]
 func (h *Handlers) GetUser(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	//log.Println("Trying to get", id)
 	if id == "" {
-		http.Error(w, "empty index", http.StatusBadRequest)
+		Error(w, "empty index", http.StatusBadRequest)
 		return
 	}
 	val, err := strconv.Atoi(id)
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+	asOf, past, err := parseAsOf(r)
+	if err != nil {
+		Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, ok := negotiate(w, r, readCodecs)
+	if !ok {
 		return
 	}
 
-	u, err := h.dbc.GetUser(val)
+	var u *User
+	if past {
+		hs, ok := h.historyStore(w)
+		if !ok {
+			return
+		}
+		u, err = hs.UserAsOf(val, asOf)
+	} else {
+		u, err = h.dbc.GetUser(val)
+	}
 	if err != nil {
 		log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
+		DBError(w, err)
 		return
 	}
-	content, _ := json.Marshal(u)
-	r.Header.Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(content)
+	if !past {
+		w.Header().Set("ETag", UserETag(*u))
+	}
+	Respond(w, c, http.StatusOK, u)
 }
 
 // GetUserList	 godoc
 //
 //	@Summary		List users
-//	@Description	get users
+//	@Description	get users, as they are or as they were at as_of
 //	@Tags			users
-//	@Produce		json
-//	@Success		200	{array}		User
-//	@Failure		500	{object}	string
+//	@Produce		json,application/yaml,xml,text/csv
+//	@Param			project	query		string	false	"Project name"
+//	@Param			grade	query		string	false	"Grade name or number"
+//	@Param			include_deleted	query	bool	false	"List deleted users too"
+//	@Param			email	query		string	false	"Email"
+//	@Param			employee_number	query	string	false	"Employee number"
+//	@Param			location	query	string	false	"Location"
+//	@Param			manager_id	query	int	false	"Direct reports of this user"
+//	@Param			hired_from	query	string	false	"Hired on or after this date, YYYY-MM-DD"
+//	@Param			hired_to	query	string	false	"Hired on or before this date, YYYY-MM-DD"
+//	@Param			as_of	query		string	false	"Past time, RFC 3339, or date, YYYY-MM-DD, standing for its midnight UTC; not with include_deleted"
+//	@Success		200		{array}		User
+//	@Failure		400		{object}	Problem
+//	@Failure		406		{object}	Problem
+//	@Failure		500		{object}	Problem
+//	@Failure		501		{object}	Problem
 //	@Router			/getall [get]
+//	@Router			/users [get]
 func (h *Handlers) GetUserList(w http.ResponseWriter, r *http.Request) {
 	// log.Println("Trying to get all user list")
-	us, err := h.dbc.GetAllUsers()
+	f, err := parseFilter(r)
+	if err != nil {
+		Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
+		return
+	}
+	asOf, past, err := parseAsOf(r)
+	if err != nil {
+		Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if past && f.IncludeDeleted {
+		Error(w, "as_of only lists the users live then, not with include_deleted", http.StatusBadRequest)
+		return
+	}
+	c, ok := negotiate(w, r, readCodecs)
+	if !ok {
+		return
+	}
+	var us *[]User
+	if past {
+		hs, ok := h.historyStore(w)
+		if !ok {
+			return
+		}
+		var then []User
+		if then, err = hs.UsersAsOf(f, asOf); err == nil {
+			us = &then
+		}
+	} else {
+		us, err = h.dbc.GetAllUsers(f)
+	}
 	if err != nil {
 		//log.Println(err)
-		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
+		DBError(w, err)
 		return
 	}
-	content, _ := json.Marshal(*us)
-	r.Header.Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(content)
+	Respond(w, c, http.StatusOK, *us)
 }
@@ -9,12 +9,22 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 var _ DBConnexion = &Registry{}
 
+// userCols name the userColumns of mock rows.
+var userCols = []string{"id", "name", "surname", "position", "project", "email", "employee_number", "hire_date", "location", "manager_id"}
+
+// selectUsers matches a query reading the userColumns, rest following them.
+func selectUsers(rest string) string {
+	return regexp.QuoteMeta("SELECT "+userColumns) + rest
+}
+
 func TestHandlers_HealthCheck(t *testing.T) {
 	t.Run("Check server health (no errors)", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
@@ -48,8 +58,9 @@ func TestHandlers_CreateUser(t *testing.T) {
 		surname := "Ersen"
 		var position Grade = 1
 		project := "Test"
-		mock.ExpectExec("INSERT INTO usr").WithArgs(name, surname, dGrades[position], project).
-			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		expectProject(mock, project, true)
+		mock.ExpectQuery("INSERT INTO usr").WithArgs(name, surname, dGrades[position], project, (*string)(nil), (*string)(nil), false, (*string)(nil), (*string)(nil), (*int)(nil)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "field"}).AddRow(1, ""))
 		expected := http.StatusOK
 		body := bytes.NewReader([]byte(`{"name": "And","surname": "Ersen", "position": 1, "project": "Test"}`))
 		req := httptest.NewRequest(http.MethodPost, "/create", body)
@@ -60,6 +71,30 @@ func TestHandlers_CreateUser(t *testing.T) {
 		err = mock.ExpectationsWereMet()
 		assert.NoErrorf(t, err, "there were unfulfilled expectations")
 	})
+	t.Run("Check creating user (duplicate)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		email := "and.ersen@example.com"
+		expectProject(mock, "Test", true)
+		mock.ExpectQuery("INSERT INTO usr").WithArgs("And", "Ersen", "trainee", "Test", &email, (*string)(nil), true, (*string)(nil), (*string)(nil), (*int)(nil)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "field"}).AddRow(3, "email"))
+		expected := http.StatusConflict
+		body := bytes.NewReader([]byte(`{"name": "And","surname": "Ersen", "position": 1, "project": "Test", "email": " And.Ersen@example.com"}`))
+		req := httptest.NewRequest(http.MethodPost, "/create?force=true", body)
+		w := httptest.NewRecorder()
+		h.CreateUser(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		assert.JSONEq(t, `{"type":"/problems/duplicate-user","title":"Conflict","status":409,"detail":"user 3 has the same email","conflicting_id":3}`, w.Body.String())
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
 	t.Run("Check creating user (unmarshal error)", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
 		if err != nil {
@@ -86,7 +121,8 @@ func TestHandlers_CreateUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		expected := http.StatusBadRequest
+		expectProject(mock, "Test", true)
+		expected := http.StatusUnprocessableEntity
 		body := bytes.NewReader([]byte(`{"name": "A1d", "surname": "Er^en", "position": 1, "project": "Test"}`))
 		req := httptest.NewRequest(http.MethodPost, "/create", body)
 		w := httptest.NewRecorder()
@@ -103,7 +139,8 @@ func TestHandlers_CreateUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		expected := http.StatusBadRequest
+		expectProject(mock, "Test", true)
+		expected := http.StatusUnprocessableEntity
 		body := bytes.NewReader([]byte(`{"name": "And","surname": "Ersen", "position": 8, "project": "Test"}`))
 		req := httptest.NewRequest(http.MethodPost, "/create", body)
 		w := httptest.NewRecorder()
@@ -124,8 +161,8 @@ func TestHandlers_DeleteUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		mock.ExpectExec("DELETE FROM usr").WithArgs(id).
-			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec("UPDATE usr SET deleted_at").WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 		expected := http.StatusOK
 		req := httptest.NewRequest(http.MethodDelete, "/delete/5", nil)
 		req = mux.SetURLVars(req, map[string]string{"id": "5"})
@@ -178,9 +215,9 @@ func TestHandlers_DeleteUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		mock.ExpectExec("DELETE FROM usr").WithArgs(id).
-			WillReturnResult(pgxmock.NewResult("DELETE", 0))
-		expected := http.StatusInternalServerError
+		mock.ExpectExec("UPDATE usr SET deleted_at").WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		expected := http.StatusNotFound
 		req := httptest.NewRequest(http.MethodDelete, "/delete/5", nil)
 		req = mux.SetURLVars(req, map[string]string{"id": "5"})
 		w := httptest.NewRecorder()
@@ -192,6 +229,70 @@ func TestHandlers_DeleteUser(t *testing.T) {
 	})
 }
 
+func TestHandlers_RestoreUser(t *testing.T) {
+	t.Run("Check restoring user (no errors)", func(t *testing.T) {
+		id := 5
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectExec("UPDATE usr SET deleted_at=NULL WHERE id=\\$1 AND deleted_at IS NOT NULL").WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		expected := http.StatusOK
+		req := httptest.NewRequest(http.MethodPost, "/users/5/restore", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.RestoreUser(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check restoring user (wrong index type)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expected := http.StatusBadRequest
+		req := httptest.NewRequest(http.MethodPost, "/users/abc/restore", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+		w := httptest.NewRecorder()
+		h.RestoreUser(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+	})
+	t.Run("Check restoring user (not deleted)", func(t *testing.T) {
+		id := 5
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectExec("UPDATE usr SET deleted_at=NULL").WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		expected := http.StatusNotFound
+		req := httptest.NewRequest(http.MethodPost, "/users/5/restore", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.RestoreUser(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+}
+
 func TestHandlers_UpdateUser(t *testing.T) {
 	t.Run("Check updating user (no errors)", func(t *testing.T) {
 		id := 5
@@ -203,8 +304,9 @@ func TestHandlers_UpdateUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		//"name='And9',surname='Ersen9',position='middle',project='Test9'",
-		mock.ExpectExec("UPDATE usr SET").WithArgs(id).
+		expectProject(mock, "Test9", true)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE usr SET name=$2,surname=$3,position=$4::grade,project=$5 WHERE id=$1")).
+			WithArgs(id, "Andi", "Erseni", "middle", nullable("Test9")).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 		expected := http.StatusOK
 		body := `{"name":"Andi","surname":"Erseni","position":3,"project":"Test9"}`
@@ -283,6 +385,69 @@ func TestHandlers_UpdateUser(t *testing.T) {
 		got := w.Result().StatusCode
 		assert.Equal(t, expected, got)
 	})
+	t.Run("Check updating user (only position)", func(t *testing.T) {
+		id := 5
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE usr SET position=$2::grade WHERE")).WithArgs(id, "senior").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		expected := http.StatusOK
+		body := bytes.NewReader([]byte(`{"position": 4}`))
+		req := httptest.NewRequest(http.MethodPatch, "/update/5", body)
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check updating user (quotes)", func(t *testing.T) {
+		id := 5
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE usr SET surname=$2,location=$3 WHERE id=$1 AND deleted_at IS NULL")).
+			WithArgs(id, "O'Neil", nullable("Rock'n'roll")).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		body := bytes.NewReader([]byte(`{"surname": "O'Neil", "location": "Rock'n'roll"}`))
+		req := httptest.NewRequest(http.MethodPatch, "/update/5", body)
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check updating user (nothing to update)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expected := http.StatusBadRequest
+		req := httptest.NewRequest(http.MethodPatch, "/update/5", bytes.NewReader([]byte(`{}`)))
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		assert.Equal(t, ProblemContentType, w.Result().Header.Get("Content-Type"))
+	})
 	t.Run("Check updating user (absent index in DB)", func(t *testing.T) {
 		id := 5
 		mock, err := pgxmock.NewPool()
@@ -293,10 +458,10 @@ func TestHandlers_UpdateUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		//"name='And9',surname='Ersen9',position='middle',project='Test9'",
-		mock.ExpectExec("UPDATE usr SET").WithArgs(id).
+		expectProject(mock, "Test9", true)
+		mock.ExpectExec("UPDATE usr SET").WithArgs(id, "Andi", "Erseni", "middle", nullable("Test9")).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
-		expected := http.StatusInternalServerError
+		expected := http.StatusNotFound
 		body := bytes.NewReader([]byte(`{"name":"Andi","surname": "Erseni", "position": 3, "project": "Test9"}`))
 		req := httptest.NewRequest(http.MethodPatch, "/update/5", body)
 		req = mux.SetURLVars(req, map[string]string{"id": "5"})
@@ -307,6 +472,55 @@ func TestHandlers_UpdateUser(t *testing.T) {
 		err = mock.ExpectationsWereMet()
 		assert.NoErrorf(t, err, "there were unfulfilled expectations")
 	})
+	t.Run("Check updating user (If-Match)", func(t *testing.T) {
+		id := 5
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		was := User{Id: 5, Name: "And", Surname: "Ersen", Position: 2, Project: "Test"}
+		mock.ExpectQuery(selectUsers(" FROM")).WithArgs(id).
+			WillReturnRows(pgxmock.NewRows(userCols).AddRow(5, "And", "Ersen", "junior", "Test", "", "", "", "", 0))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE usr SET position=$2::grade WHERE id=$1 AND deleted_at IS NULL "+
+			"AND name=$3 AND surname=$4 AND CAST(position AS TEXT)=$5")).
+			WithArgs(id, "middle", "And", "Ersen", "junior", "Test", "", "", "", "", 0).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		req := httptest.NewRequest(http.MethodPatch, "/update/5", bytes.NewReader([]byte(`{"position":3}`)))
+		req.Header.Set("If-Match", UserETag(was))
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check updating user (If-Match stale)", func(t *testing.T) {
+		id := 5
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		was := User{Id: 5, Name: "And", Surname: "Ersen", Position: 2, Project: "Test"}
+		mock.ExpectQuery(selectUsers(" FROM")).WithArgs(id).
+			WillReturnRows(pgxmock.NewRows(userCols).AddRow(5, "And", "Ersen", "middle", "Test", "", "", "", "", 0))
+		req := httptest.NewRequest(http.MethodPatch, "/update/5", bytes.NewReader([]byte(`{"position":3}`)))
+		req.Header.Set("If-Match", UserETag(was))
+		req = mux.SetURLVars(req, map[string]string{"id": "5"})
+		w := httptest.NewRecorder()
+		h.UpdateUser(w, req)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Contains(t, w.Body.String(), `"type":"/problems/precondition-failed"`)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
 }
 
 func TestHandlers_GetUser(t *testing.T) {
@@ -320,9 +534,9 @@ func TestHandlers_GetUser(t *testing.T) {
 		r := &Registry{mock}
 		h := &Handlers{r}
 
-		rows := pgxmock.NewRows([]string{"name", "surname", "position", "project"}).
-			AddRow("And", "Ersen", "middle", "Test")
-		mock.ExpectQuery("SELECT name, surname, position, project FROM").WithArgs(id).
+		rows := pgxmock.NewRows(userCols).
+			AddRow(5, "And", "Ersen", "middle", "Test", "", "", "", "", 0)
+		mock.ExpectQuery(selectUsers(" FROM")).WithArgs(id).
 			WillReturnRows(rows)
 		expected := http.StatusOK
 		expBody := `{"id":5,"name":"And","surname":"Ersen","position":3,"project":"Test"}`
@@ -337,6 +551,8 @@ func TestHandlers_GetUser(t *testing.T) {
 		n, err := w.Result().Body.Read(bytez)
 		gotBody := string(bytez[:n])
 		assert.Equal(t, expBody, gotBody)
+		assert.Equal(t, UserETag(User{Id: 5, Name: "And", Surname: "Ersen", Position: 3, Project: "Test"}),
+			w.Result().Header.Get("ETag"))
 		err = mock.ExpectationsWereMet()
 		assert.NoErrorf(t, err, "there were unfulfilled expectations")
 	})
@@ -383,7 +599,7 @@ func TestHandlers_GetUser(t *testing.T) {
 		h := &Handlers{r}
 
 		expBody := "id error"
-		mock.ExpectQuery("SELECT name, surname, position, project FROM").WithArgs(id).
+		mock.ExpectQuery(selectUsers(" FROM")).WithArgs(id).
 			WillReturnError(fmt.Errorf(expBody))
 		expected := http.StatusInternalServerError
 		req := httptest.NewRequest(http.MethodGet, "/get/5", nil)
@@ -422,13 +638,13 @@ func TestHandlers_GetUserList(t *testing.T) {
 {"id":2,"name":"And2","surname":"Ersen2","position":4,"project":"Test2"},
 {"id":3,"name":"And3","surname":"Ersen3","position":1,"project":"Test3"}]`
 		expBody = strings.ReplaceAll(expBody, "\n", "")
-		rows := pgxmock.NewRows([]string{"id", "name", "surname", "position", "project"})
+		rows := pgxmock.NewRows(userCols)
 		var tag pgconn.CommandTag
 		for i, entry := range Entries {
-			rows.AddRow(i+1, entry[0], entry[1], entry[2], entry[3])
+			rows.AddRow(i+1, entry[0], entry[1], entry[2], entry[3], "", "", "", "", 0)
 		}
 		rows.AddCommandTag(tag)
-		mock.ExpectQuery("SELECT id, name, surname, position, project FROM").WillReturnRows(rows)
+		mock.ExpectQuery(selectUsers(" FROM")).WillReturnRows(rows)
 
 		expected := http.StatusOK
 		req := httptest.NewRequest(http.MethodGet, "/getall", nil)
@@ -444,4 +660,95 @@ func TestHandlers_GetUserList(t *testing.T) {
 		err = mock.ExpectationsWereMet()
 		assert.NoErrorf(t, err, "there were unfulfilled expectations")
 	})
+	t.Run("Check getting user list (filtered)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		rows := pgxmock.NewRows(userCols).
+			AddRow(2, "And2", "Ersen2", "middle", "Test2", "", "", "", "", 0)
+		mock.ExpectQuery(selectUsers(" FROM usr WHERE project=\\$1 AND position=\\$2")).
+			WithArgs("Test2", "middle").WillReturnRows(rows)
+
+		expected := http.StatusOK
+		expBody := `[{"id":2,"name":"And2","surname":"Ersen2","position":3,"project":"Test2"}]`
+		req := httptest.NewRequest(http.MethodGet, "/getall?project=Test2&grade=middle", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		defer w.Result().Body.Close()
+		bytez := make([]byte, 1000)
+		n, err := w.Result().Body.Read(bytez)
+		gotBody := string(bytez[:n])
+		assert.Equal(t, expBody, gotBody)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check getting user list (deleted included)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		deletedAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+		rows := pgxmock.NewRows(append(userCols, "deleted_at")).
+			AddRow(2, "And2", "Ersen2", "middle", "Test", "", "", "", "", 0, &deletedAt)
+		mock.ExpectQuery(selectUsers(", deleted_at FROM usr ORDER BY id")).
+			WillReturnRows(rows)
+
+		expected := http.StatusOK
+		expBody := `[{"id":2,"name":"And2","surname":"Ersen2","position":3,"project":"Test","deleted_at":"2023-05-01T12:00:00Z"}]`
+		req := httptest.NewRequest(http.MethodGet, "/getall?include_deleted=true", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+		defer w.Result().Body.Close()
+		bytez := make([]byte, 1000)
+		n, err := w.Result().Body.Read(bytez)
+		gotBody := string(bytez[:n])
+		assert.Equal(t, expBody, gotBody)
+		err = mock.ExpectationsWereMet()
+		assert.NoErrorf(t, err, "there were unfulfilled expectations")
+	})
+	t.Run("Check getting user list (wrong include_deleted)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expected := http.StatusBadRequest
+		req := httptest.NewRequest(http.MethodGet, "/getall?include_deleted=maybe", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+	})
+	t.Run("Check getting user list (wrong grade)", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening mock", err)
+		}
+		defer mock.Close()
+		r := &Registry{mock}
+		h := &Handlers{r}
+
+		expected := http.StatusBadRequest
+		req := httptest.NewRequest(http.MethodGet, "/getall?grade=lead", nil)
+		w := httptest.NewRecorder()
+		h.GetUserList(w, req)
+		got := w.Result().StatusCode
+		assert.Equal(t, expected, got)
+	})
 }
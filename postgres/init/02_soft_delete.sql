@@ -0,0 +1,3 @@
+ALTER TABLE usr ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS usr_live_idx ON usr (id) WHERE deleted_at IS NULL;
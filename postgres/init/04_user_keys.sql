@@ -0,0 +1,7 @@
+ALTER TABLE usr ADD COLUMN IF NOT EXISTS email TEXT;
+ALTER TABLE usr ADD COLUMN IF NOT EXISTS employee_number TEXT;
+
+-- Deleted users keep their keys, so that restoring them cannot clash.
+CREATE UNIQUE INDEX IF NOT EXISTS usr_email_key ON usr (email);
+CREATE UNIQUE INDEX IF NOT EXISTS usr_employee_number_key ON usr (employee_number);
+CREATE INDEX IF NOT EXISTS usr_name_idx ON usr (lower(surname), lower(name)) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE usr ADD COLUMN IF NOT EXISTS hire_date DATE;
+ALTER TABLE usr ADD COLUMN IF NOT EXISTS location TEXT;
+ALTER TABLE usr ADD COLUMN IF NOT EXISTS manager_id INTEGER REFERENCES usr (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS usr_manager_idx ON usr (manager_id);
@@ -0,0 +1,8 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Both index the document Registry.Search looks in, which has to stay the
+-- same expression for them to be used.
+CREATE INDEX IF NOT EXISTS usr_search_idx ON usr
+    USING GIN (to_tsvector('simple', name || ' ' || surname || ' ' || COALESCE(project, '')));
+CREATE INDEX IF NOT EXISTS usr_search_trgm_idx ON usr
+    USING GIN ((name || ' ' || surname || ' ' || COALESCE(project, '')) gin_trgm_ops);
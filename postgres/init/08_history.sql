@@ -0,0 +1,52 @@
+-- Every version of a user while live, valid from valid_from until valid_to
+-- (open while current), kept by the trigger below whatever writes to usr.
+-- Deleting a user closes its version, restoring opens a new one; purged
+-- users keep their past.
+CREATE TABLE IF NOT EXISTS usr_history (
+    id              INTEGER NOT NULL,
+    name            TEXT NOT NULL,
+    surname         TEXT NOT NULL,
+    position        grade NOT NULL,
+    project         TEXT,
+    email           TEXT,
+    employee_number TEXT,
+    hire_date       DATE,
+    location        TEXT,
+    manager_id      INTEGER,
+    valid_from      TIMESTAMPTZ NOT NULL,
+    valid_to        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS usr_history_id_idx ON usr_history (id, valid_from);
+CREATE INDEX IF NOT EXISTS usr_history_valid_idx ON usr_history (valid_from, valid_to);
+
+-- Users already there are taken to have been as they are since they were
+-- hired, or forever when that is unknown.
+INSERT INTO usr_history (id, name, surname, position, project, email, employee_number, hire_date, location, manager_id,
+                         valid_from, valid_to)
+SELECT id, name, surname, position, project, email, employee_number, hire_date, location, manager_id,
+       COALESCE(hire_date::timestamptz, '-infinity'), deleted_at
+FROM usr
+WHERE NOT EXISTS (SELECT 1 FROM usr_history);
+
+CREATE OR REPLACE FUNCTION usr_history_record() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        UPDATE usr_history SET valid_to = now() WHERE id = OLD.id AND valid_to IS NULL;
+    END IF;
+    IF TG_OP <> 'DELETE' AND NEW.deleted_at IS NULL THEN
+        INSERT INTO usr_history (id, name, surname, position, project, email, employee_number, hire_date, location,
+                                 manager_id, valid_from)
+        VALUES (NEW.id, NEW.name, NEW.surname, NEW.position, NEW.project, NEW.email, NEW.employee_number, NEW.hire_date,
+                NEW.location, NEW.manager_id, now());
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS usr_history_insert ON usr;
+CREATE TRIGGER usr_history_insert AFTER INSERT OR DELETE ON usr
+    FOR EACH ROW EXECUTE FUNCTION usr_history_record();
+DROP TRIGGER IF EXISTS usr_history_update ON usr;
+CREATE TRIGGER usr_history_update AFTER UPDATE ON usr
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION usr_history_record();
@@ -0,0 +1,29 @@
+-- Promotion review cycles: scheduled, then open between opens_at and
+-- closes_at for reviewing the users enrolled, then closed.
+CREATE TABLE IF NOT EXISTS review_cycle (
+    id        SERIAL PRIMARY KEY,
+    name      TEXT NOT NULL,
+    opens_at  TIMESTAMPTZ NOT NULL,
+    closes_at TIMESTAMPTZ NOT NULL CHECK (closes_at > opens_at),
+    status    TEXT NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'open', 'closed'))
+);
+
+CREATE TABLE IF NOT EXISTS review_cycle_project (
+    cycle_id INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    project  TEXT NOT NULL,
+    PRIMARY KEY (cycle_id, project)
+);
+
+-- A user enrolled in a cycle for promotion from from_grade to to_grade.
+-- Reviews move to the user kept when users are merged, unless that user is
+-- enrolled in the cycle too.
+CREATE TABLE IF NOT EXISTS review (
+    cycle_id   INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    user_id    INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    from_grade grade NOT NULL,
+    to_grade   grade NOT NULL,
+    status     TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'promoted', 'skipped')),
+    comment    TEXT,
+    PRIMARY KEY (cycle_id, user_id)
+);
@@ -0,0 +1,34 @@
+-- Performance assessments of users in review cycles. The grade and project
+-- are those of the user when assessed, for calibration to group by.
+-- Assessments by and of a user move to the user kept when users are merged,
+-- but for those the user kept already has from the same reviewer in the
+-- cycle and those of the user kept by themselves.
+CREATE TABLE IF NOT EXISTS assessment (
+    id          SERIAL PRIMARY KEY,
+    cycle_id    INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    user_id     INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    reviewer_id INTEGER REFERENCES usr (id) ON DELETE SET NULL,
+    grade       grade NOT NULL,
+    project     TEXT NOT NULL,
+    rating      INTEGER NOT NULL,
+    summary     TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS assessment_reviewer ON assessment (cycle_id, user_id, COALESCE(reviewer_id, 0));
+CREATE INDEX IF NOT EXISTS assessment_user ON assessment (user_id);
+
+CREATE TABLE IF NOT EXISTS assessment_score (
+    assessment_id INTEGER NOT NULL REFERENCES assessment (id) ON DELETE CASCADE,
+    competency    TEXT NOT NULL,
+    score         INTEGER NOT NULL,
+    PRIMARY KEY (assessment_id, competency)
+);
+
+-- The projects whose assessments in a cycle were calibrated, which locks
+-- them.
+CREATE TABLE IF NOT EXISTS calibration (
+    cycle_id      INTEGER NOT NULL REFERENCES review_cycle (id) ON DELETE CASCADE,
+    project       TEXT NOT NULL,
+    calibrated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (cycle_id, project)
+);
@@ -0,0 +1,31 @@
+-- 360-degree feedback on a user, asked of peers, their manager and their
+-- reports. Access is by keys handed out once when feedback is requested,
+-- kept hashed: one per respondent for their own response, and one for the
+-- manager of the user for the summary.
+CREATE TABLE IF NOT EXISTS feedback (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    manager_id       INTEGER REFERENCES usr (id) ON DELETE SET NULL,
+    manager_key_hash TEXT NOT NULL UNIQUE,
+    due_at           TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS feedback_user ON feedback (user_id);
+
+-- The respondent is kept with anonymous responses too, so that each
+-- answers once and only reads their own; summaries leave them out.
+-- Merging users moves feedback and responses to the user kept, but for
+-- the responses to feedback the user kept was asked for as well.
+CREATE TABLE IF NOT EXISTS feedback_respondent (
+    feedback_id   INTEGER NOT NULL REFERENCES feedback (id) ON DELETE CASCADE,
+    respondent_id INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    relation      TEXT NOT NULL CHECK (relation IN ('peer', 'manager', 'report')),
+    key_hash      TEXT NOT NULL UNIQUE,
+    rating        INTEGER,
+    strengths     TEXT,
+    improvements  TEXT,
+    anonymous     BOOLEAN NOT NULL DEFAULT false,
+    answered_at   TIMESTAMPTZ,
+    reminded_at   TIMESTAMPTZ,
+    PRIMARY KEY (feedback_id, respondent_id)
+);
@@ -0,0 +1,26 @@
+-- The competency framework: competencies with the level expected of each
+-- grade, on the rating scale. Grades without a level expect none.
+CREATE TABLE IF NOT EXISTS competency (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS competency_level (
+    competency_id INTEGER NOT NULL REFERENCES competency (id) ON DELETE CASCADE,
+    grade         grade NOT NULL,
+    level         INTEGER NOT NULL,
+    PRIMARY KEY (competency_id, grade)
+);
+
+-- The level of each user in a competency when last assessed. Merging users
+-- moves skills to the user kept, but for those it has a level in already.
+CREATE TABLE IF NOT EXISTS user_skill (
+    user_id       INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    competency_id INTEGER NOT NULL REFERENCES competency (id) ON DELETE CASCADE,
+    level         INTEGER NOT NULL,
+    assessed_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, competency_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_skill_competency ON user_skill (competency_id);
@@ -0,0 +1,17 @@
+-- Mentorships of a mentee by a mentor of a higher grade when it started,
+-- open-ended while ends_at is NULL. A pair has one mentorship at a time.
+-- Merging users moves mentorships to the user kept, but for those between
+-- the two users merged.
+CREATE TABLE IF NOT EXISTS mentorship (
+    id        SERIAL PRIMARY KEY,
+    mentor_id INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    mentee_id INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at   TIMESTAMPTZ,
+    goals     TEXT,
+    CHECK (mentor_id <> mentee_id),
+    CHECK (ends_at IS NULL OR ends_at >= starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS mentorship_mentor ON mentorship (mentor_id);
+CREATE INDEX IF NOT EXISTS mentorship_mentee ON mentorship (mentee_id);
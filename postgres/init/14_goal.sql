@@ -0,0 +1,23 @@
+-- Development plans: goals of users, towards the promotion to target_grade
+-- unless NULL, with notes on their progress. Merging users moves goals to
+-- the user kept.
+CREATE TABLE IF NOT EXISTS goal (
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    title        TEXT NOT NULL,
+    description  TEXT,
+    target_grade grade,
+    due_date     DATE,
+    status       TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'in_progress', 'done', 'dropped'))
+);
+
+CREATE INDEX IF NOT EXISTS goal_user ON goal (user_id);
+
+CREATE TABLE IF NOT EXISTS goal_note (
+    id      SERIAL PRIMARY KEY,
+    goal_id INTEGER NOT NULL REFERENCES goal (id) ON DELETE CASCADE,
+    note    TEXT NOT NULL,
+    at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS goal_note_goal ON goal_note (goal_id);
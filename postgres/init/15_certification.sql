@@ -0,0 +1,19 @@
+-- Certifications and courses of users, valid from issued_on until
+-- expires_on included, forever while NULL. flagged_at is when one was
+-- flagged as expiring; changing it clears the flag. Merging users moves
+-- certifications to the user kept.
+CREATE TABLE IF NOT EXISTS certification (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER NOT NULL REFERENCES usr (id) ON DELETE CASCADE,
+    kind       TEXT NOT NULL CHECK (kind IN ('certification', 'course')),
+    name       TEXT NOT NULL,
+    issuer     TEXT NOT NULL,
+    issued_on  DATE NOT NULL,
+    expires_on DATE,
+    evidence   TEXT,
+    flagged_at TIMESTAMPTZ,
+    CHECK (expires_on IS NULL OR expires_on >= issued_on)
+);
+
+CREATE INDEX IF NOT EXISTS certification_user ON certification (user_id);
+CREATE INDEX IF NOT EXISTS certification_expires ON certification (expires_on) WHERE flagged_at IS NULL;